@@ -1,12 +1,53 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/agent"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
 )
 
 func main() {
-	fmt.Println("GPU Agent - Coming soon")
-	fmt.Println("This component will monitor GPU metrics and execute jobs")
-	os.Exit(0)
+	// Initialize logger
+	if err := utils.InitLogger("development"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer utils.Sync()
+
+	// Load configuration
+	config, err := utils.LoadConfig("")
+	if err != nil {
+		utils.Fatal("Failed to load configuration", zap.Error(err))
+	}
+
+	// Create agent
+	nodeAgent, err := agent.New(config.Agent)
+	if err != nil {
+		utils.Fatal("Failed to create agent", zap.Error(err))
+	}
+
+	scheduler := config.Agent.SchedulerURL
+	if config.Agent.Protocol == agent.ProtocolGRPC {
+		scheduler = config.Agent.SchedulerGRPCAddress
+	}
+	utils.Info("Starting GPU Agent",
+		zap.String("node_id", nodeAgent.NodeID()),
+		zap.String("protocol", config.Agent.Protocol),
+		zap.String("scheduler", scheduler))
+
+	// Run until interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := nodeAgent.Run(ctx); err != nil && ctx.Err() == nil {
+		utils.Fatal("Agent error", zap.Error(err))
+	}
+
+	utils.Info("Agent stopped gracefully")
 }
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -32,8 +35,13 @@ func main() {
 		listJobsCmd(),
 		getJobCmd(),
 		cancelJobCmd(),
+		extendJobCmd(),
+		reservationsCmd(),
+		cronCmd(),
 		clusterStatusCmd(),
 		createTenantCmd(),
+		tenantCmd(),
+		invoiceCmd(),
 	)
 
 	if err := rootCmd.Execute(); err != nil {
@@ -50,6 +58,12 @@ func submitJobCmd() *cobra.Command {
 		cpuCores    int
 		image       string
 		script      string
+		array       string
+		maxRuntime  int
+		maxAttempts int
+		deadline    string
+		reservation string
+		queue       string
 	)
 
 	cmd := &cobra.Command{
@@ -67,6 +81,28 @@ func submitJobCmd() *cobra.Command {
 				"image":        image,
 				"script":       script,
 			}
+			if array != "" {
+				job["array"] = array
+			}
+			if maxRuntime > 0 {
+				job["max_runtime_minutes"] = maxRuntime
+			}
+			if deadline != "" {
+				if _, err := time.Parse(time.RFC3339, deadline); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: deadline must be RFC3339, e.g. 2024-01-15T06:00:00Z\n")
+					os.Exit(1)
+				}
+				job["deadline"] = deadline
+			}
+			if maxAttempts > 1 {
+				job["retry_policy"] = map[string]interface{}{"max_attempts": maxAttempts}
+			}
+			if reservation != "" {
+				job["reservation_id"] = reservation
+			}
+			if queue != "" {
+				job["queue"] = queue
+			}
 
 			resp, err := postJSON(apiURL+"/api/v1/jobs", job)
 			if err != nil {
@@ -86,6 +122,12 @@ func submitJobCmd() *cobra.Command {
 	cmd.Flags().IntVar(&cpuCores, "cpus", 4, "Number of CPU cores")
 	cmd.Flags().StringVar(&image, "image", "nvidia/cuda:12.0-base", "Container image")
 	cmd.Flags().StringVar(&script, "script", "nvidia-smi", "Script to run")
+	cmd.Flags().StringVar(&array, "array", "", "Submit a job array, e.g. 0-499%20 for indexes 0-499 with at most 20 at a time")
+	cmd.Flags().IntVar(&maxRuntime, "max-runtime", 0, "Maximum runtime in minutes (0 for no limit)")
+	cmd.Flags().StringVar(&deadline, "deadline", "", "Time the job must finish by (RFC3339)")
+	cmd.Flags().IntVar(&maxAttempts, "max-attempts", 1, "Run the job up to this many times after node failures or preemption")
+	cmd.Flags().StringVar(&reservation, "reservation", "", "Run on the GPUs of this reservation")
+	cmd.Flags().StringVar(&queue, "queue", "", "Queue to submit to (defaults to the scheduler's default queue)")
 
 	return cmd
 }
@@ -159,6 +201,32 @@ func getJobCmd() *cobra.Command {
 			if status["queue_position"] != nil && status["queue_position"].(float64) > 0 {
 				fmt.Printf("Queue Position: %.0f\n", status["queue_position"])
 			}
+			if status["attempt"] != nil {
+				fmt.Printf("Attempt: %.0f\n", status["attempt"])
+			}
+			if attempts, ok := status["attempts"].([]interface{}); ok {
+				fmt.Println("Failed Attempts:")
+				for _, a := range attempts {
+					attempt := a.(map[string]interface{})
+					fmt.Printf("  #%.0f on %s: %s (%s)\n",
+						attempt["attempt"], attempt["node_id"], attempt["failure_class"], attempt["reason"])
+				}
+			}
+			if status["deadline"] != nil {
+				fmt.Printf("Deadline: %s\n", formatTime(status["deadline"]))
+				if unreachable, _ := status["deadline_unreachable"].(bool); unreachable {
+					fmt.Println("  Deadline can no longer be met")
+				}
+			}
+			if status["runtime_deadline"] != nil {
+				fmt.Printf("Runtime Deadline: %s\n", formatTime(status["runtime_deadline"]))
+			}
+			if counts, ok := status["array_jobs"].(map[string]interface{}); ok {
+				fmt.Println("Array Jobs:")
+				for state, count := range counts {
+					fmt.Printf("  %s: %.0f\n", state, count)
+				}
+			}
 		},
 	}
 }
@@ -190,6 +258,390 @@ func cancelJobCmd() *cobra.Command {
 	}
 }
 
+func extendJobCmd() *cobra.Command {
+	var minutes int
+
+	cmd := &cobra.Command{
+		Use:   "extend [job-id]",
+		Short: "Extend the max runtime of a running job",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			jobID := args[0]
+			url := fmt.Sprintf("%s/api/v1/jobs/%s/extend", apiURL, jobID)
+
+			body, _ := json.Marshal(map[string]int{"minutes": minutes})
+			resp, err := http.Post(url, "application/json", bytes.NewBuffer(body))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				msg, _ := io.ReadAll(resp.Body)
+				fmt.Fprintf(os.Stderr, "Failed to extend job: HTTP %d %s", resp.StatusCode, msg)
+				os.Exit(1)
+			}
+
+			var result map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Println("Job runtime extended")
+			fmt.Printf("New Deadline: %s\n", formatTime(result["deadline"]))
+			fmt.Printf("Extensions Used: %.0f\n", result["extended_count"])
+		},
+	}
+
+	cmd.Flags().IntVar(&minutes, "minutes", 60, "Minutes to add to the job's max runtime")
+
+	return cmd
+}
+
+func reservationsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reservations",
+		Short: "Manage GPU reservations",
+	}
+
+	cmd.AddCommand(
+		createReservationCmd(),
+		listReservationsCmd(),
+		deleteReservationCmd(),
+	)
+
+	return cmd
+}
+
+func createReservationCmd() *cobra.Command {
+	var (
+		name     string
+		gpuCount int
+		start    string
+		end      string
+		nodes    []string
+		model    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Reserve GPUs for a time window",
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, t := range []string{start, end} {
+				if _, err := time.Parse(time.RFC3339, t); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: start and end must be RFC3339, e.g. 2024-01-15T06:00:00Z\n")
+					os.Exit(1)
+				}
+			}
+
+			reservation := map[string]interface{}{
+				"tenant_id":  tenantID,
+				"name":       name,
+				"gpu_count":  gpuCount,
+				"node_ids":   nodes,
+				"gpu_model":  model,
+				"start_time": start,
+				"end_time":   end,
+			}
+
+			resp, err := postJSON(apiURL+"/api/v1/reservations", reservation)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if resp["error"] != nil {
+				fmt.Fprintf(os.Stderr, "Failed to create reservation: %s\n", resp["error"])
+				os.Exit(1)
+			}
+
+			fmt.Printf("Reservation created successfully!\n")
+			fmt.Printf("Reservation ID: %s\n", resp["id"])
+			fmt.Printf("GPUs: %v\n", resp["gpu_ids"])
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Reservation name")
+	cmd.Flags().IntVar(&gpuCount, "gpus", 1, "Number of GPUs to reserve")
+	cmd.Flags().StringVar(&start, "start", "", "Start of the window (RFC3339)")
+	cmd.Flags().StringVar(&end, "end", "", "End of the window (RFC3339)")
+	cmd.Flags().StringSliceVar(&nodes, "nodes", nil, "Only reserve GPUs on these nodes")
+	cmd.Flags().StringVar(&model, "model", "", "Only reserve GPUs of this model, e.g. A100")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("end")
+
+	return cmd
+}
+
+func listReservationsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List reservations",
+		Run: func(cmd *cobra.Command, args []string) {
+			url := fmt.Sprintf("%s/api/v1/reservations?tenant_id=%s", apiURL, tenantID)
+
+			var result struct {
+				Reservations []map[string]interface{} `json:"reservations"`
+				Total        int                      `json:"total"`
+			}
+
+			if err := getJSON(url, &result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "RESERVATION ID\tNAME\tPHASE\tGPUs\tSTART\tEND")
+
+			for _, reservation := range result.Reservations {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%.0f\t%s\t%s\n",
+					reservation["id"],
+					reservation["name"],
+					reservation["phase"],
+					reservation["gpu_count"],
+					formatTime(reservation["start_time"]),
+					formatTime(reservation["end_time"]),
+				)
+			}
+
+			w.Flush()
+			fmt.Printf("\nTotal: %d reservations\n", result.Total)
+		},
+	}
+}
+
+func deleteReservationCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete [reservation-id]",
+		Short: "Delete a reservation",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			url := fmt.Sprintf("%s/api/v1/reservations/%s", apiURL, args[0])
+
+			req, _ := http.NewRequest("DELETE", url, nil)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode == http.StatusOK {
+				fmt.Println("Reservation deleted successfully")
+			} else {
+				fmt.Fprintf(os.Stderr, "Failed to delete reservation: HTTP %d\n", resp.StatusCode)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func cronCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cron",
+		Short: "Manage cron-scheduled jobs",
+	}
+
+	cmd.AddCommand(
+		createCronJobCmd(),
+		listCronJobsCmd(),
+		deleteCronJobCmd(),
+		cronRunsCmd(),
+	)
+
+	return cmd
+}
+
+func createCronJobCmd() *cobra.Command {
+	var (
+		name        string
+		schedule    string
+		timezone    string
+		concurrency string
+		missed      string
+		priority    int
+		gpuCount    int
+		cpuCores    int
+		image       string
+		script      string
+		maxRuntime  int
+		queue       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Submit a job on a cron schedule",
+		Run: func(cmd *cobra.Command, args []string) {
+			job := map[string]interface{}{
+				"name":          name,
+				"priority":      priority,
+				"gpu_count":     gpuCount,
+				"gpu_memory_mb": 16000,
+				"cpu_cores":     cpuCores,
+				"memory_mb":     32000,
+				"image":         image,
+				"script":        script,
+				"queue":         queue,
+			}
+			if maxRuntime > 0 {
+				job["max_runtime_minutes"] = maxRuntime
+			}
+
+			cronJob := map[string]interface{}{
+				"tenant_id":          tenantID,
+				"name":               name,
+				"schedule":           schedule,
+				"timezone":           timezone,
+				"concurrency_policy": concurrency,
+				"missed_run_policy":  missed,
+				"job":                job,
+			}
+
+			resp, err := postJSON(apiURL+"/api/v1/cronjobs", cronJob)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if resp["error"] != nil {
+				fmt.Fprintf(os.Stderr, "Failed to create cron job: %s\n", resp["error"])
+				os.Exit(1)
+			}
+
+			fmt.Printf("Cron job created successfully!\n")
+			fmt.Printf("Cron job ID: %s\n", resp["id"])
+			fmt.Printf("Next run: %s\n", formatTime(resp["next_run_at"]))
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "my-cron-job", "Cron job name, also used for the jobs it submits")
+	cmd.Flags().StringVar(&schedule, "schedule", "", "Cron expression, e.g. \"0 2 * * *\" or @daily")
+	cmd.Flags().StringVar(&timezone, "timezone", "UTC", "Timezone the schedule is read in, e.g. Europe/Paris")
+	cmd.Flags().StringVar(&concurrency, "concurrency", "allow", "What to do when the previous run is still active: allow, forbid or replace")
+	cmd.Flags().StringVar(&missed, "missed", "run_once", "What to do about runs missed while the scheduler was down: run_once or skip")
+	cmd.Flags().IntVar(&priority, "priority", 100, "Job priority")
+	cmd.Flags().IntVar(&gpuCount, "gpus", 1, "Number of GPUs")
+	cmd.Flags().IntVar(&cpuCores, "cpus", 4, "Number of CPU cores")
+	cmd.Flags().StringVar(&image, "image", "nvidia/cuda:12.0-base", "Container image")
+	cmd.Flags().StringVar(&script, "script", "nvidia-smi", "Script to run")
+	cmd.Flags().IntVar(&maxRuntime, "max-runtime", 0, "Maximum runtime in minutes (0 for no limit)")
+	cmd.Flags().StringVar(&queue, "queue", "", "Queue to submit runs to")
+	cmd.MarkFlagRequired("schedule")
+
+	return cmd
+}
+
+func listCronJobsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List cron jobs",
+		Run: func(cmd *cobra.Command, args []string) {
+			url := fmt.Sprintf("%s/api/v1/cronjobs?tenant_id=%s", apiURL, tenantID)
+
+			var result struct {
+				CronJobs []map[string]interface{} `json:"cron_jobs"`
+				Total    int                      `json:"total"`
+			}
+
+			if err := getJSON(url, &result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CRON JOB ID\tNAME\tSCHEDULE\tTIMEZONE\tCONCURRENCY\tLAST RUN\tNEXT RUN")
+
+			for _, cronJob := range result.CronJobs {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					cronJob["id"],
+					cronJob["name"],
+					cronJob["schedule"],
+					cronJob["timezone"],
+					cronJob["concurrency_policy"],
+					formatTime(cronJob["last_run_at"]),
+					formatTime(cronJob["next_run_at"]),
+				)
+			}
+
+			w.Flush()
+			fmt.Printf("\nTotal: %d cron jobs\n", result.Total)
+		},
+	}
+}
+
+func deleteCronJobCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete [cron-job-id]",
+		Short: "Delete a cron job",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			url := fmt.Sprintf("%s/api/v1/cronjobs/%s", apiURL, args[0])
+
+			req, _ := http.NewRequest("DELETE", url, nil)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode == http.StatusOK {
+				fmt.Println("Cron job deleted successfully")
+			} else {
+				fmt.Fprintf(os.Stderr, "Failed to delete cron job: HTTP %d\n", resp.StatusCode)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func cronRunsCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "runs [cron-job-id]",
+		Short: "Show a cron job's run history",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			url := fmt.Sprintf("%s/api/v1/cronjobs/%s/runs?limit=%d", apiURL, args[0], limit)
+
+			var result struct {
+				Runs  []map[string]interface{} `json:"runs"`
+				Total int                      `json:"total"`
+			}
+
+			if err := getJSON(url, &result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SCHEDULED FOR\tSTATUS\tJOB ID\tREASON")
+
+			for _, run := range result.Runs {
+				jobID, _ := run["job_id"].(string)
+				if jobID == "" {
+					jobID = "-"
+				}
+				reason, _ := run["reason"].(string)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+					formatTime(run["scheduled_for"]),
+					run["status"],
+					jobID,
+					reason,
+				)
+			}
+
+			w.Flush()
+			fmt.Printf("\nTotal: %d runs\n", result.Total)
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "Number of runs to show")
+
+	return cmd
+}
+
 func clusterStatusCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "status",
@@ -208,6 +660,23 @@ func clusterStatusCmd() *cobra.Command {
 			fmt.Printf("  Nodes: %.0f total, %.0f online\n", status["total_nodes"], status["online_nodes"])
 			fmt.Printf("  Jobs: %.0f total, %.0f running, %.0f pending\n", 
 				status["total_jobs"], status["running_jobs"], status["pending_jobs"])
+
+			queues, _ := status["queues"].([]interface{})
+			if len(queues) > 0 {
+				fmt.Println("\nQueues:")
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "  NAME\tDEPTH\tRUNNING\tORDERING\tPREEMPTION")
+				for _, q := range queues {
+					queue, _ := q.(map[string]interface{})
+					name := queue["name"]
+					if queue["default"] == true {
+						name = fmt.Sprintf("%s (default)", name)
+					}
+					fmt.Fprintf(w, "  %s\t%.0f\t%.0f\t%s\t%s\n",
+						name, queue["depth"], queue["running"], queue["ordering"], queue["preemption"])
+				}
+				w.Flush()
+			}
 		},
 	}
 }
@@ -250,6 +719,322 @@ func createTenantCmd() *cobra.Command {
 	return cmd
 }
 
+func tenantCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tenant",
+		Short: "Manage tenants",
+	}
+
+	create := createTenantCmd()
+	create.Use = "create"
+
+	cmd.AddCommand(
+		create,
+		listTenantsCmd(),
+		getTenantCmd(),
+		updateTenantCmd(),
+		deactivateTenantCmd(),
+		activateTenantCmd(),
+		deleteTenantCmd(),
+	)
+
+	return cmd
+}
+
+func listTenantsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List tenants",
+		Run: func(cmd *cobra.Command, args []string) {
+			var result struct {
+				Tenants []map[string]interface{} `json:"tenants"`
+				Total   int                      `json:"total"`
+			}
+
+			if err := getJSON(apiURL+"/api/v1/tenants", &result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TENANT ID\tNAME\tACTIVE\tTIER\tGPUs\tJOBS")
+
+			for _, tenant := range result.Tenants {
+				fmt.Fprintf(w, "%s\t%s\t%v\t%s\t%.0f/%.0f\t%.0f/%.0f\n",
+					tenant["id"],
+					tenant["name"],
+					tenant["active"],
+					tenant["priority_tier"],
+					tenant["current_gpus"],
+					tenant["max_gpus"],
+					tenant["current_jobs"],
+					tenant["max_concurrent_jobs"],
+				)
+			}
+
+			w.Flush()
+			fmt.Printf("\nTotal: %d tenants\n", result.Total)
+		},
+	}
+}
+
+func getTenantCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get [tenant-id]",
+		Short: "Show a tenant's limits and usage",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			tenant, err := tenantRequest("GET", fmt.Sprintf("%s/api/v1/tenants/%s", apiURL, args[0]), nil)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			printTenant(tenant)
+		},
+	}
+}
+
+func updateTenantCmd() *cobra.Command {
+	var (
+		name              string
+		email             string
+		maxGPUs           int
+		guaranteedGPUs    int
+		maxConcurrentJobs int
+		priority          string
+		strategy          string
+		billing           bool
+		costPerGPUHour    float64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update [tenant-id]",
+		Short: "Change a tenant's limits and policies",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Only the flags given on the command line are changed
+			update := map[string]interface{}{}
+			flags := cmd.Flags()
+			for flag, field := range map[string]struct {
+				key   string
+				value interface{}
+			}{
+				"name":                {"name", name},
+				"email":               {"email", email},
+				"max-gpus":            {"max_gpus", maxGPUs},
+				"guaranteed-gpus":     {"guaranteed_gpus", guaranteedGPUs},
+				"max-concurrent-jobs": {"max_concurrent_jobs", maxConcurrentJobs},
+				"priority":            {"priority_tier", priority},
+				"placement-strategy":  {"placement_strategy", strategy},
+				"billing":             {"billing_enabled", billing},
+				"cost-per-gpu-hour":   {"cost_per_gpu_hour", costPerGPUHour},
+			} {
+				if flags.Changed(flag) {
+					update[field.key] = field.value
+				}
+			}
+			if len(update) == 0 {
+				fmt.Fprintln(os.Stderr, "Error: nothing to update")
+				os.Exit(1)
+			}
+
+			tenant, err := tenantRequest("PATCH", fmt.Sprintf("%s/api/v1/tenants/%s", apiURL, args[0]), update)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to update tenant: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Println("Tenant updated successfully")
+			printTenant(tenant)
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Tenant name")
+	cmd.Flags().StringVar(&email, "email", "", "Contact email")
+	cmd.Flags().IntVar(&maxGPUs, "max-gpus", 0, "Maximum GPUs")
+	cmd.Flags().IntVar(&guaranteedGPUs, "guaranteed-gpus", 0, "GPUs reserved for the tenant")
+	cmd.Flags().IntVar(&maxConcurrentJobs, "max-concurrent-jobs", 0, "Maximum running jobs")
+	cmd.Flags().StringVar(&priority, "priority", "", "Priority tier: low, medium, high or critical")
+	cmd.Flags().StringVar(&strategy, "placement-strategy", "", "Placement strategy for the tenant's jobs")
+	cmd.Flags().BoolVar(&billing, "billing", false, "Charge the tenant for GPU-hours")
+	cmd.Flags().Float64Var(&costPerGPUHour, "cost-per-gpu-hour", 0, "Negotiated GPU-hour rate")
+
+	return cmd
+}
+
+func deactivateTenantCmd() *cobra.Command {
+	var drain bool
+
+	cmd := &cobra.Command{
+		Use:   "deactivate [tenant-id]",
+		Short: "Stop a tenant from submitting jobs",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			url := fmt.Sprintf("%s/api/v1/tenants/%s/deactivate?drain=%t", apiURL, args[0], drain)
+			result, err := tenantRequest("POST", url, nil)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to deactivate tenant: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Println("Tenant deactivated")
+			if drain {
+				fmt.Printf("Cancelled Jobs: %.0f\n", result["cancelled_jobs"])
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&drain, "drain", false, "Also cancel the tenant's pending and running jobs")
+
+	return cmd
+}
+
+func activateTenantCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "activate [tenant-id]",
+		Short: "Let an inactive tenant submit jobs again",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			url := fmt.Sprintf("%s/api/v1/tenants/%s/activate", apiURL, args[0])
+			if _, err := tenantRequest("POST", url, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to activate tenant: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("Tenant activated")
+		},
+	}
+}
+
+func deleteTenantCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete [tenant-id]",
+		Short: "Delete a tenant without active jobs",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			url := fmt.Sprintf("%s/api/v1/tenants/%s", apiURL, args[0])
+			if _, err := tenantRequest("DELETE", url, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to delete tenant: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("Tenant deleted successfully")
+		},
+	}
+}
+
+// tenantRequest sends a tenant API request and decodes the JSON reply,
+// returning the server's message for unsuccessful requests
+func tenantRequest(method, url string, data interface{}) (map[string]interface{}, error) {
+	var body io.Reader
+	if data != nil {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewBuffer(payload)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("HTTP %d %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func printTenant(tenant map[string]interface{}) {
+	fmt.Printf("Tenant ID: %s\n", tenant["id"])
+	fmt.Printf("Name: %s\n", tenant["name"])
+	fmt.Printf("Active: %v\n", tenant["active"])
+	fmt.Printf("Priority Tier: %s\n", tenant["priority_tier"])
+	fmt.Printf("GPUs: %.0f in use, %.0f guaranteed, %.0f max\n",
+		tenant["current_gpus"], tenant["guaranteed_gpus"], tenant["max_gpus"])
+	fmt.Printf("Jobs: %.0f running, %.0f max\n", tenant["current_jobs"], tenant["max_concurrent_jobs"])
+	fmt.Printf("Total GPU Hours: %.2f\n", tenant["total_gpu_hours"])
+	fmt.Printf("Total Cost: %.2f\n", tenant["total_cost"])
+}
+
+func invoiceCmd() *cobra.Command {
+	var (
+		month  string
+		from   string
+		to     string
+		format string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "invoice [tenant-id]",
+		Short: "Export a tenant's invoice for a period as CSV or JSON",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			query := url.Values{"format": {format}}
+			if month != "" {
+				query.Set("month", month)
+			}
+			if from != "" {
+				query.Set("from", from)
+			}
+			if to != "" {
+				query.Set("to", to)
+			}
+
+			resp, err := http.Get(fmt.Sprintf("%s/api/v1/tenants/%s/invoice?%s", apiURL, args[0], query.Encode()))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				msg, _ := io.ReadAll(resp.Body)
+				fmt.Fprintf(os.Stderr, "Failed to export invoice: HTTP %d %s", resp.StatusCode, msg)
+				os.Exit(1)
+			}
+
+			out := os.Stdout
+			if output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				defer file.Close()
+				out = file
+			}
+			if _, err := io.Copy(out, resp.Body); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if output != "" {
+				fmt.Printf("Invoice written to %s\n", output)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&month, "month", "", "Billing month (YYYY-MM), the current month by default")
+	cmd.Flags().StringVar(&from, "from", "", "Start of the period (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&to, "to", "", "End of the period, exclusive (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&format, "format", "csv", "Output format: csv or json")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the invoice to this file instead of stdout")
+
+	return cmd
+}
+
 func getJSON(url string, result interface{}) error {
 	resp, err := http.Get(url)
 	if err != nil {
@@ -3,12 +3,14 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	grpcapi "github.com/azizbahloul/gpu-scheduler/pkg/api/grpc"
 	"github.com/azizbahloul/gpu-scheduler/pkg/api/rest"
 	"github.com/azizbahloul/gpu-scheduler/pkg/scheduler/core"
 	"github.com/azizbahloul/gpu-scheduler/pkg/storage/postgres"
@@ -76,6 +78,25 @@ func main() {
 		}
 	}()
 
+	// Create gRPC server for node agents and tenant quota updates
+	agentServer := grpcapi.NewAgentServer(scheduler, time.Duration(config.Scheduler.SchedulingInterval)*time.Millisecond)
+	grpcServer, err := grpcapi.NewServer(agentServer, grpcapi.NewSchedulerServer(scheduler), &config.API)
+	if err != nil {
+		utils.Fatal("Failed to create gRPC server", zap.Error(err))
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", config.API.GRPCPort))
+	if err != nil {
+		utils.Fatal("Failed to listen for gRPC", zap.Error(err))
+	}
+
+	// Start gRPC server
+	go func() {
+		utils.Info("Starting gRPC server", zap.Int("port", config.API.GRPCPort))
+		if err := grpcServer.Serve(listener); err != nil {
+			utils.Fatal("gRPC server error", zap.Error(err))
+		}
+	}()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -92,6 +113,9 @@ func main() {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		utils.Error("Server shutdown error", zap.Error(err))
 	}
+	// Agent streams stay open as long as their agent runs, so they are
+	// closed rather than drained
+	grpcServer.Stop()
 
 	utils.Info("Scheduler stopped gracefully")
 }
@@ -0,0 +1,486 @@
+// Package agent implements the daemon that runs on each GPU node. It
+// registers the node with the scheduler, keeps it alive with heartbeats,
+// reports metrics and runs the jobs placed on the node.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// maxRegisterBackoff caps the wait between registration attempts
+const maxRegisterBackoff = 30 * time.Second
+
+// Agent manages one node on behalf of the scheduler
+type Agent struct {
+	config   utils.AgentConfig
+	client   schedulerClient
+	provider Provider
+	executor *executor
+	cpu      cpuSampler
+
+	// gpuIndexes maps the scheduler's GPU IDs to local device indexes and
+	// gpuIDs maps the provider's GPU IDs to the scheduler's
+	gpuIndexes map[string]int
+	gpuIDs     map[string]string
+	// samples holds the latest metrics of each GPU by scheduler GPU ID
+	samples map[string]models.GPUMetrics
+
+	// finished holds results of exited jobs until the scheduler has
+	// accepted them. Finished jobs are not started again while the
+	// scheduler still lists them.
+	finished map[string]models.JobMetrics
+	reported map[string]bool
+}
+
+// New creates an agent from its configuration, using the GPU provider it
+// selects. The node ID defaults to the host name.
+func New(config utils.AgentConfig) (*Agent, error) {
+	provider, err := NewProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithProvider(config, provider)
+}
+
+// NewWithProvider creates an agent that finds and samples its GPUs with
+// the given provider
+func NewWithProvider(config utils.AgentConfig, provider Provider) (*Agent, error) {
+	if config.NodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("%w: node ID not set and host name unknown: %v", utils.ErrMissingConfig, err)
+		}
+		config.NodeID = hostname
+	}
+	if config.HeartbeatInterval <= 0 || config.MetricsInterval <= 0 {
+		return nil, fmt.Errorf("%w: agent intervals must be positive", utils.ErrInvalidConfig)
+	}
+	client, err := newSchedulerClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agent{
+		config:     config,
+		client:     client,
+		provider:   provider,
+		executor:   newExecutor(config.ContainerRuntime),
+		gpuIndexes: make(map[string]int),
+		gpuIDs:     make(map[string]string),
+		samples:    make(map[string]models.GPUMetrics),
+		finished:   make(map[string]models.JobMetrics),
+		reported:   make(map[string]bool),
+	}, nil
+}
+
+// Protocols accepted in agent.protocol
+const (
+	ProtocolREST = "rest"
+	ProtocolGRPC = "grpc"
+)
+
+// newSchedulerClient creates the client for the protocol the config
+// selects, REST when unset
+func newSchedulerClient(config utils.AgentConfig) (schedulerClient, error) {
+	switch config.Protocol {
+	case "", ProtocolREST:
+		if config.SchedulerURL == "" {
+			return nil, fmt.Errorf("%w: agent.scheduler_url", utils.ErrMissingConfig)
+		}
+		return NewClient(config.SchedulerURL), nil
+	case ProtocolGRPC:
+		if config.SchedulerGRPCAddress == "" {
+			return nil, fmt.Errorf("%w: agent.scheduler_grpc_address", utils.ErrMissingConfig)
+		}
+		return NewGRPCClient(config.SchedulerGRPCAddress, config.TLSCAPath)
+	default:
+		return nil, fmt.Errorf("%w: unknown agent protocol %q", utils.ErrInvalidConfig, config.Protocol)
+	}
+}
+
+// NodeID returns the ID the node is registered under
+func (a *Agent) NodeID() string {
+	return a.config.NodeID
+}
+
+// Run registers the node and serves it until ctx is cancelled. Jobs
+// still running then are stopped. Assignments are synced on every
+// heartbeat, and as soon as the scheduler pushes them over gRPC.
+func (a *Agent) Run(ctx context.Context) error {
+	defer a.client.Close()
+	if err := a.registerWithRetry(ctx); err != nil {
+		return err
+	}
+	defer a.executor.stopAll()
+
+	heartbeat := time.NewTicker(time.Duration(a.config.HeartbeatInterval) * time.Millisecond)
+	defer heartbeat.Stop()
+	metrics := time.NewTicker(time.Duration(a.config.MetricsInterval) * time.Millisecond)
+	defer metrics.Stop()
+
+	a.syncAssignments(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			a.heartbeat(ctx)
+			a.syncAssignments(ctx)
+		case <-a.client.AssignmentUpdates():
+			a.syncAssignments(ctx)
+		case <-metrics.C:
+			a.checkInventory(ctx)
+			a.reportMetrics(ctx)
+		case result := <-a.executor.results:
+			a.jobExited(result)
+			a.reportMetrics(ctx)
+		}
+	}
+}
+
+// registerWithRetry registers the node, retrying with backoff until it
+// succeeds or ctx is cancelled
+func (a *Agent) registerWithRetry(ctx context.Context) error {
+	backoff := time.Second
+	for {
+		err := a.register(ctx)
+		if err == nil {
+			return nil
+		}
+
+		utils.Warn("Registration failed, retrying",
+			zap.String("node_id", a.config.NodeID),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxRegisterBackoff {
+			backoff = maxRegisterBackoff
+		}
+	}
+}
+
+// register announces the node and its GPUs to the scheduler
+func (a *Agent) register(ctx context.Context) error {
+	gpus, err := a.provider.Discover(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to discover GPUs: %w", err)
+	}
+
+	indexes := make(map[string]int, len(gpus))
+	ids := make(map[string]string, len(gpus))
+	for i := range gpus {
+		id := a.gpuID(gpus[i].Index)
+		ids[gpus[i].GPUID] = id
+		indexes[id] = gpus[i].Index
+		gpus[i].GPUID = id
+	}
+
+	hostname, _ := os.Hostname()
+	totalMemory, _ := hostMemory()
+	result, err := a.client.Register(ctx, &models.AgentRegistration{
+		NodeID:        a.config.NodeID,
+		Hostname:      hostname,
+		IPAddress:     hostIP(),
+		TotalGPUs:     len(gpus),
+		TotalCPUCores: hostCPUCores(),
+		TotalMemoryMB: totalMemory,
+		GPUs:          gpus,
+		Labels:        a.config.Labels,
+	})
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("registration refused: %s", result.Message)
+	}
+
+	a.gpuIndexes = indexes
+	a.gpuIDs = ids
+	a.samples = make(map[string]models.GPUMetrics)
+	utils.Info("Node registered",
+		zap.String("node_id", a.config.NodeID),
+		zap.Int("gpus", len(gpus)))
+	return nil
+}
+
+// checkInventory registers the node again when GPUs have appeared or
+// disappeared, so the scheduler stops placing jobs on lost GPUs and
+// fails over the jobs running on them
+func (a *Agent) checkInventory(ctx context.Context) {
+	gpus, err := a.provider.Discover(ctx)
+	if err != nil {
+		utils.Error("Failed to discover GPUs", zap.Error(err))
+		return
+	}
+
+	changed := len(gpus) != len(a.gpuIDs)
+	for _, gpu := range gpus {
+		if _, ok := a.gpuIDs[gpu.GPUID]; !ok {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	utils.Warn("GPU inventory changed, registering again",
+		zap.String("node_id", a.config.NodeID),
+		zap.Int("gpus", len(gpus)),
+		zap.Int("registered_gpus", len(a.gpuIDs)))
+	if err := a.register(ctx); err != nil {
+		utils.Error("Registration failed", zap.Error(err))
+	}
+}
+
+// sampleGPUs returns the latest metrics of the registered GPUs under the
+// scheduler's GPU IDs
+func (a *Agent) sampleGPUs(ctx context.Context) []models.GPUMetrics {
+	samples, err := a.provider.Metrics(ctx)
+	if err != nil {
+		utils.Error("Failed to sample GPU metrics", zap.Error(err))
+		return nil
+	}
+
+	metrics := make([]models.GPUMetrics, 0, len(samples))
+	for _, sample := range samples {
+		id, ok := a.gpuIDs[sample.GPUID]
+		if !ok {
+			continue
+		}
+		sample.GPUID = id
+		a.samples[id] = sample
+		metrics = append(metrics, sample)
+	}
+	return metrics
+}
+
+// gpuID returns the scheduler's ID for a local GPU
+func (a *Agent) gpuID(index int) string {
+	return fmt.Sprintf("%s-gpu-%d", a.config.NodeID, index)
+}
+
+// heartbeat tells the scheduler the node is alive and carries out the
+// commands it sends back. A scheduler that no longer knows the node gets
+// a fresh registration.
+func (a *Agent) heartbeat(ctx context.Context) {
+	ack, err := a.client.Heartbeat(ctx, &models.NodeHeartbeat{
+		NodeID:            a.config.NodeID,
+		Timestamp:         time.Now(),
+		GPUMetrics:        a.sampleGPUs(ctx),
+		CPUUtilization:    a.cpu.sample(),
+		MemoryUtilization: memoryUtilization(),
+		ActiveJobs:        len(a.executor.jobs),
+	})
+	if errors.Is(err, utils.ErrNodeNotFound) {
+		utils.Warn("Scheduler does not know this node, registering again", zap.String("node_id", a.config.NodeID))
+		if err := a.register(ctx); err != nil {
+			utils.Error("Registration failed", zap.Error(err))
+		}
+		return
+	}
+	if err != nil {
+		utils.Error("Heartbeat failed", zap.Error(err))
+		return
+	}
+
+	for _, command := range ack.Commands {
+		a.runCommand(command)
+	}
+}
+
+// runCommand carries out a command from a heartbeat acknowledgement
+func (a *Agent) runCommand(command string) {
+	fields := strings.Fields(command)
+	if len(fields) == 2 && fields[0] == models.AgentCommandTerminate {
+		if _, ok := a.executor.jobs[fields[1]]; ok {
+			utils.Info("Terminating job at scheduler's request", zap.String("job_id", fields[1]))
+			a.executor.stop(fields[1])
+		}
+		return
+	}
+	utils.Warn("Unknown agent command", zap.String("command", command))
+}
+
+// syncAssignments starts the jobs placed on the node that are not
+// running yet and stops the ones the scheduler no longer lists. Jobs
+// whose GPUs on the node changed, such as elastic jobs that grew or
+// shrank, are stopped and started again on their new GPUs.
+func (a *Agent) syncAssignments(ctx context.Context) {
+	assignments, err := a.client.Assignments(ctx, a.config.NodeID)
+	if err != nil {
+		utils.Error("Failed to fetch job assignments", zap.Error(err))
+		return
+	}
+
+	assigned := make(map[string]bool, len(assignments))
+	for _, assignment := range assignments {
+		assigned[assignment.JobID] = true
+		if _, ok := a.finished[assignment.JobID]; ok || a.reported[assignment.JobID] {
+			continue
+		}
+		if running, ok := a.executor.jobs[assignment.JobID]; ok {
+			if !running.stopped && !sameGPUs(running.assignment.GPUIDs, assignment.GPUIDs) {
+				utils.Info("Restarting job on its new GPUs",
+					zap.String("job_id", assignment.JobID),
+					zap.Strings("gpu_ids", assignment.GPUIDs))
+				a.executor.stop(assignment.JobID)
+			}
+			continue
+		}
+		a.startJob(assignment)
+	}
+
+	for jobID := range a.executor.jobs {
+		if !assigned[jobID] {
+			utils.Info("Stopping job no longer assigned to this node", zap.String("job_id", jobID))
+			a.executor.stop(jobID)
+		}
+	}
+	for jobID := range a.reported {
+		if !assigned[jobID] {
+			delete(a.reported, jobID)
+		}
+	}
+}
+
+// sameGPUs returns true if both lists hold the same GPUs
+func sameGPUs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	held := make(map[string]bool, len(a))
+	for _, id := range a {
+		held[id] = true
+	}
+	for _, id := range b {
+		if !held[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// startJob launches an assigned job, or records it as failed if it
+// cannot be started
+func (a *Agent) startJob(assignment *models.JobAssignment) {
+	devices := make([]int, 0, len(assignment.GPUIDs))
+	for _, gpuID := range assignment.GPUIDs {
+		index, ok := a.gpuIndexes[gpuID]
+		if !ok {
+			a.jobFailed(assignment.JobID, fmt.Sprintf("GPU %s is not on this node", gpuID), -1)
+			return
+		}
+		devices = append(devices, index)
+	}
+
+	if err := a.executor.start(assignment, devices); err != nil {
+		a.jobFailed(assignment.JobID, err.Error(), -1)
+		return
+	}
+	if observer, ok := a.provider.(JobObserver); ok {
+		observer.JobStarted(assignment.JobID, devices)
+	}
+	utils.Info("Job started",
+		zap.String("job_id", assignment.JobID),
+		zap.Strings("gpu_ids", assignment.GPUIDs))
+}
+
+// jobExited records the result of a job that exited. Jobs the agent
+// stopped are not reported; the scheduler has already moved on, or the
+// job starts again on its new GPUs.
+func (a *Agent) jobExited(result jobResult) {
+	job := a.executor.remove(result)
+	if job == nil {
+		return
+	}
+	if observer, ok := a.provider.(JobObserver); ok {
+		observer.JobStopped(result.jobID)
+	}
+	if job.stopped {
+		return
+	}
+
+	if result.err != nil {
+		utils.Warn("Job failed", zap.String("job_id", result.jobID), zap.Error(result.err))
+		a.jobFailed(result.jobID, result.err.Error(), result.exitCode)
+		return
+	}
+
+	utils.Info("Job completed", zap.String("job_id", result.jobID))
+	a.finished[result.jobID] = models.JobMetrics{
+		JobID:     result.jobID,
+		State:     models.JobStateCompleted,
+		StartedAt: &job.startedAt,
+		ExitCode:  "0",
+	}
+}
+
+func (a *Agent) jobFailed(jobID, message string, exitCode int) {
+	a.finished[jobID] = models.JobMetrics{
+		JobID:        jobID,
+		State:        models.JobStateFailed,
+		ExitCode:     strconv.Itoa(exitCode),
+		ErrorMessage: message,
+	}
+}
+
+// jobGPUUtilization returns the mean utilization of a job's GPUs
+func (a *Agent) jobGPUUtilization(assignment *models.JobAssignment) float64 {
+	total, n := 0.0, 0
+	for _, gpuID := range assignment.GPUIDs {
+		if sample, ok := a.samples[gpuID]; ok {
+			total += sample.Utilization
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return total / float64(n)
+}
+
+// reportMetrics sends GPU metrics and the state of the node's jobs,
+// including the results of jobs that exited since the last report
+func (a *Agent) reportMetrics(ctx context.Context) {
+	report := &models.MetricsReport{
+		NodeID:     a.config.NodeID,
+		GPUMetrics: a.sampleGPUs(ctx),
+		Timestamp:  time.Now(),
+	}
+	for jobID, job := range a.executor.jobs {
+		startedAt := job.startedAt
+		report.JobMetrics = append(report.JobMetrics, models.JobMetrics{
+			JobID:          jobID,
+			State:          models.JobStateRunning,
+			GPUUtilization: a.jobGPUUtilization(job.assignment),
+			StartedAt:      &startedAt,
+		})
+	}
+	for _, metrics := range a.finished {
+		report.JobMetrics = append(report.JobMetrics, metrics)
+	}
+
+	if err := a.client.ReportMetrics(ctx, report); err != nil {
+		utils.Error("Failed to report metrics", zap.Error(err))
+		return
+	}
+	for jobID := range a.finished {
+		a.reported[jobID] = true
+		delete(a.finished, jobID)
+	}
+}
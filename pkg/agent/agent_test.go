@@ -0,0 +1,314 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScheduler serves the scheduler's agent endpoints and records what
+// agents send
+type fakeScheduler struct {
+	mu            sync.Mutex
+	registrations []models.AgentRegistration
+	heartbeats    int
+	gpuMetrics    []models.GPUMetrics
+	assignments   []*models.JobAssignment
+	jobs          map[string]models.JobMetrics
+	commands      []string
+	forget        bool
+}
+
+func newFakeScheduler() *fakeScheduler {
+	return &fakeScheduler{jobs: make(map[string]models.JobMetrics)}
+}
+
+func (f *fakeScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/agents/")
+	switch {
+	case path == "register":
+		var reg models.AgentRegistration
+		json.NewDecoder(r.Body).Decode(&reg)
+		f.registrations = append(f.registrations, reg)
+		f.forget = false
+		json.NewEncoder(w).Encode(models.AgentRegistrationResult{Success: true, AgentID: reg.NodeID})
+	case f.forget:
+		http.Error(w, "Node not found", http.StatusNotFound)
+	case strings.HasSuffix(path, "/heartbeat"):
+		var heartbeat models.NodeHeartbeat
+		json.NewDecoder(r.Body).Decode(&heartbeat)
+		f.heartbeats++
+		f.gpuMetrics = heartbeat.GPUMetrics
+		json.NewEncoder(w).Encode(models.HeartbeatAck{Acknowledged: true, Commands: f.commands})
+		f.commands = nil
+	case strings.HasSuffix(path, "/metrics"):
+		var report models.MetricsReport
+		json.NewDecoder(r.Body).Decode(&report)
+		for _, metrics := range report.JobMetrics {
+			f.jobs[metrics.JobID] = metrics
+			// Finished jobs are no longer assigned
+			if metrics.State != models.JobStateRunning {
+				f.unassign(metrics.JobID)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	case strings.HasSuffix(path, "/assignments"):
+		json.NewEncoder(w).Encode(map[string]interface{}{"assignments": f.assignments})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeScheduler) assign(a *models.JobAssignment) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.assignments = append(f.assignments, a)
+}
+
+// unassign is called with mu held
+func (f *fakeScheduler) unassign(jobID string) {
+	for i, a := range f.assignments {
+		if a.JobID == jobID {
+			f.assignments = append(f.assignments[:i], f.assignments[i+1:]...)
+			return
+		}
+	}
+}
+
+func (f *fakeScheduler) job(jobID string) (models.JobMetrics, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	metrics, ok := f.jobs[jobID]
+	return metrics, ok
+}
+
+// startAgent runs an agent against the fake scheduler until the test
+// ends. Without a provider the node has no GPUs.
+func startAgent(t *testing.T, fake *fakeScheduler, provider Provider) {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	if provider == nil {
+		var err error
+		provider, err = NewSimulatedProvider(nil, 1)
+		require.NoError(t, err)
+	}
+	agent, err := NewWithProvider(utils.AgentConfig{
+		NodeID:            "node-1",
+		SchedulerURL:      server.URL,
+		HeartbeatInterval: 20,
+		MetricsInterval:   50,
+		Labels:            map[string]string{"zone": "a"},
+	}, provider)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, agent.Run(ctx))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestAgentRegistersAndSendsHeartbeats(t *testing.T) {
+	fake := newFakeScheduler()
+	startAgent(t, fake, nil)
+
+	require.Eventually(t, func() bool {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		return fake.heartbeats >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	fake.mu.Lock()
+	require.Len(t, fake.registrations, 1)
+	reg := fake.registrations[0]
+	fake.mu.Unlock()
+	assert.Equal(t, "node-1", reg.NodeID)
+	assert.Equal(t, "a", reg.Labels["zone"])
+	assert.Positive(t, reg.TotalCPUCores)
+
+	// A scheduler that lost the node gets a new registration
+	fake.mu.Lock()
+	fake.forget = true
+	fake.mu.Unlock()
+	require.Eventually(t, func() bool {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		return len(fake.registrations) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestAgentRunsAssignedJobs(t *testing.T) {
+	fake := newFakeScheduler()
+	fake.assign(&models.JobAssignment{JobID: "ok", Script: "exit 0"})
+	fake.assign(&models.JobAssignment{JobID: "broken", Script: "exit 3"})
+	fake.assign(&models.JobAssignment{JobID: "misplaced", Script: "exit 0", GPUIDs: []string{"node-2-gpu-0"}})
+	startAgent(t, fake, nil)
+
+	for _, jobID := range []string{"ok", "broken", "misplaced"} {
+		require.Eventually(t, func() bool {
+			metrics, ok := fake.job(jobID)
+			return ok && metrics.State != models.JobStateRunning
+		}, 5*time.Second, 10*time.Millisecond, jobID)
+	}
+
+	ok, _ := fake.job("ok")
+	assert.Equal(t, models.JobStateCompleted, ok.State)
+	assert.Equal(t, "0", ok.ExitCode)
+
+	broken, _ := fake.job("broken")
+	assert.Equal(t, models.JobStateFailed, broken.State)
+	assert.Equal(t, "3", broken.ExitCode)
+
+	misplaced, _ := fake.job("misplaced")
+	assert.Equal(t, models.JobStateFailed, misplaced.State)
+	assert.Contains(t, misplaced.ErrorMessage, "not on this node")
+}
+
+func TestAgentStopsJobsNoLongerAssigned(t *testing.T) {
+	fake := newFakeScheduler()
+	fake.assign(&models.JobAssignment{JobID: "long", Script: "sleep 30"})
+	startAgent(t, fake, nil)
+
+	require.Eventually(t, func() bool {
+		metrics, ok := fake.job("long")
+		return ok && metrics.State == models.JobStateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	fake.mu.Lock()
+	fake.unassign("long")
+	fake.mu.Unlock()
+
+	// The stopped job is not reported as finished, and the agent no
+	// longer lists it as running
+	time.Sleep(300 * time.Millisecond)
+	fake.mu.Lock()
+	delete(fake.jobs, "long")
+	fake.mu.Unlock()
+	time.Sleep(200 * time.Millisecond)
+	_, reported := fake.job("long")
+	assert.False(t, reported)
+}
+
+func TestAgentRestartsResizedJob(t *testing.T) {
+	devices := filepath.Join(t.TempDir(), "devices")
+	fake := newFakeScheduler()
+	assignment := &models.JobAssignment{
+		JobID:  "train",
+		Script: "echo $CUDA_VISIBLE_DEVICES >> " + devices + "; sleep 30",
+		GPUIDs: []string{"node-1-gpu-0"},
+	}
+	fake.assign(assignment)
+	startAgent(t, fake, newSimulatedNode(t))
+
+	started := func() []string {
+		data, _ := os.ReadFile(devices)
+		return strings.Fields(string(data))
+	}
+	require.Eventually(t, func() bool {
+		return len(started()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The job grows onto another GPU of the node and starts again on both
+	fake.mu.Lock()
+	assignment.GPUIDs = []string{"node-1-gpu-0", "node-1-gpu-2"}
+	fake.mu.Unlock()
+	require.Eventually(t, func() bool {
+		return len(started()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"0", "0,2"}, started())
+
+	// The restart is not reported as the job exiting
+	require.Eventually(t, func() bool {
+		metrics, ok := fake.job("train")
+		return ok && metrics.State == models.JobStateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestAgentWithSimulatedGPUs(t *testing.T) {
+	fake := newFakeScheduler()
+	fake.assign(&models.JobAssignment{JobID: "train", Script: "sleep 30", GPUIDs: []string{"node-1-gpu-0", "node-1-gpu-1"}})
+	provider := newSimulatedNode(t)
+	startAgent(t, fake, provider)
+
+	// The running job drives its GPUs' utilization
+	require.Eventually(t, func() bool {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		for _, sample := range fake.gpuMetrics {
+			if sample.GPUID == "node-1-gpu-1" {
+				return sample.Utilization > 80
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+
+	fake.mu.Lock()
+	reg := fake.registrations[0]
+	fake.mu.Unlock()
+	require.Len(t, reg.GPUs, 4)
+	assert.Equal(t, "node-1-gpu-0", reg.GPUs[0].GPUID)
+	assert.Equal(t, "A100", reg.GPUs[0].Model)
+
+	// A GPU falling off the bus leads to a registration without it
+	require.NoError(t, provider.FallOffBus(1))
+	require.Eventually(t, func() bool {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		return len(fake.registrations) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	fake.mu.Lock()
+	reg = fake.registrations[1]
+	fake.mu.Unlock()
+	require.Len(t, reg.GPUs, 3)
+	for _, gpu := range reg.GPUs {
+		assert.NotEqual(t, "node-1-gpu-1", gpu.GPUID)
+	}
+}
+
+func TestAgentProtocolSelectsClient(t *testing.T) {
+	provider, err := NewSimulatedProvider(nil, 1)
+	require.NoError(t, err)
+	config := utils.AgentConfig{NodeID: "node-1", HeartbeatInterval: 20, MetricsInterval: 50}
+
+	config.SchedulerURL = "http://scheduler:8080"
+	rest, err := NewWithProvider(config, provider)
+	require.NoError(t, err)
+	assert.IsType(t, &Client{}, rest.client)
+
+	config.Protocol = ProtocolGRPC
+	_, err = NewWithProvider(config, provider)
+	assert.ErrorIs(t, err, utils.ErrMissingConfig)
+
+	config.SchedulerGRPCAddress = "scheduler:9090"
+	pushed, err := NewWithProvider(config, provider)
+	require.NoError(t, err)
+	assert.IsType(t, &GRPCClient{}, pushed.client)
+	require.NoError(t, pushed.client.Close())
+
+	config.Protocol = "carrier-pigeon"
+	_, err = NewWithProvider(config, provider)
+	assert.ErrorIs(t, err, utils.ErrInvalidConfig)
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+)
+
+// schedulerClient is how the agent talks to the scheduler. Assignments
+// returns every job placed on the node. Clients told of assignment
+// changes between calls signal them on AssignmentUpdates.
+type schedulerClient interface {
+	Register(ctx context.Context, reg *models.AgentRegistration) (*models.AgentRegistrationResult, error)
+	Heartbeat(ctx context.Context, heartbeat *models.NodeHeartbeat) (*models.HeartbeatAck, error)
+	ReportMetrics(ctx context.Context, report *models.MetricsReport) error
+	Assignments(ctx context.Context, nodeID string) ([]*models.JobAssignment, error)
+	AssignmentUpdates() <-chan struct{}
+	Close() error
+}
+
+// Client talks to the scheduler's agent endpoints
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a client for the scheduler at baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/") + "/api/v1",
+		http:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Register announces the node and its GPUs
+func (c *Client) Register(ctx context.Context, reg *models.AgentRegistration) (*models.AgentRegistrationResult, error) {
+	var result models.AgentRegistrationResult
+	if err := c.do(ctx, http.MethodPost, "/agents/register", reg, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Heartbeat tells the scheduler the node is alive. It returns
+// utils.ErrNodeNotFound when the node must register again.
+func (c *Client) Heartbeat(ctx context.Context, heartbeat *models.NodeHeartbeat) (*models.HeartbeatAck, error) {
+	var ack models.HeartbeatAck
+	if err := c.do(ctx, http.MethodPost, "/agents/"+url.PathEscape(heartbeat.NodeID)+"/heartbeat", heartbeat, &ack); err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+// ReportMetrics sends GPU and job metrics
+func (c *Client) ReportMetrics(ctx context.Context, report *models.MetricsReport) error {
+	return c.do(ctx, http.MethodPost, "/agents/"+url.PathEscape(report.NodeID)+"/metrics", report, nil)
+}
+
+// Assignments returns the jobs the node should be running
+func (c *Client) Assignments(ctx context.Context, nodeID string) ([]*models.JobAssignment, error) {
+	var resp struct {
+		Assignments []*models.JobAssignment `json:"assignments"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/agents/"+url.PathEscape(nodeID)+"/assignments", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Assignments, nil
+}
+
+// AssignmentUpdates returns nil, since assignments are polled over REST
+func (c *Client) AssignmentUpdates() <-chan struct{} {
+	return nil
+}
+
+// Close drops idle connections to the scheduler
+func (c *Client) Close() error {
+	c.http.CloseIdleConnections()
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach scheduler: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return utils.ErrNodeNotFound
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("scheduler returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+)
+
+// DCGM exporter fields mapped into GPU metrics
+const (
+	dcgmGPUUtil         = "DCGM_FI_DEV_GPU_UTIL"
+	dcgmGPUTemp         = "DCGM_FI_DEV_GPU_TEMP"
+	dcgmPowerUsage      = "DCGM_FI_DEV_POWER_USAGE"
+	dcgmFBUsed          = "DCGM_FI_DEV_FB_USED"
+	dcgmFBFree          = "DCGM_FI_DEV_FB_FREE"
+	dcgmFBReserved      = "DCGM_FI_DEV_FB_RESERVED"
+	dcgmThrottleReasons = "DCGM_FI_DEV_CLOCK_THROTTLE_REASONS"
+	dcgmECCDoubleBit    = "DCGM_FI_DEV_ECC_DBE_VOL_TOTAL"
+)
+
+// Thermal bits of the NVML clock throttle reasons mask
+const (
+	throttleSWThermal = 0x20
+	throttleHWThermal = 0x40
+)
+
+// dcgmExporter finds and samples GPUs by scraping the Prometheus metrics
+// of NVIDIA's DCGM exporter
+type dcgmExporter struct {
+	url  string
+	http *http.Client
+}
+
+// newDCGMExporter creates a provider scraping the exporter at hostPort
+func newDCGMExporter(hostPort string) *dcgmExporter {
+	return &dcgmExporter{
+		url:  "http://" + hostPort + "/metrics",
+		http: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Discover lists the GPUs the exporter reports
+func (d *dcgmExporter) Discover(ctx context.Context) ([]models.GPUInfo, error) {
+	gpus, err := d.scrape(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]models.GPUInfo, 0, len(gpus))
+	for _, gpu := range gpus {
+		infos = append(infos, gpu.info())
+	}
+	return infos, nil
+}
+
+// Metrics samples the GPUs the exporter reports
+func (d *dcgmExporter) Metrics(ctx context.Context) ([]models.GPUMetrics, error) {
+	gpus, err := d.scrape(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]models.GPUMetrics, 0, len(gpus))
+	for _, gpu := range gpus {
+		samples = append(samples, gpu.metrics())
+	}
+	return samples, nil
+}
+
+func (d *dcgmExporter) scrape(ctx context.Context) ([]*dcgmGPU, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach DCGM exporter: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DCGM exporter returned %s", resp.Status)
+	}
+	return parseDCGMMetrics(resp.Body)
+}
+
+// dcgmGPU collects the exporter's samples for one GPU
+type dcgmGPU struct {
+	index  int
+	uuid   string
+	model  string
+	values map[string]float64
+}
+
+func (g *dcgmGPU) info() models.GPUInfo {
+	memory := g.values[dcgmFBUsed] + g.values[dcgmFBFree] + g.values[dcgmFBReserved]
+	return models.GPUInfo{
+		GPUID:         g.uuid,
+		Index:         g.index,
+		Model:         g.model,
+		MemoryTotalMB: int64(memory),
+	}
+}
+
+func (g *dcgmGPU) metrics() models.GPUMetrics {
+	reasons := uint64(g.values[dcgmThrottleReasons])
+	return models.GPUMetrics{
+		GPUID:           g.uuid,
+		Utilization:     g.values[dcgmGPUUtil],
+		Temperature:     g.values[dcgmGPUTemp],
+		PowerUsage:      g.values[dcgmPowerUsage],
+		MemoryUsedMB:    int64(g.values[dcgmFBUsed]),
+		MemoryFreeMB:    int64(g.values[dcgmFBFree]),
+		ThermalThrottle: reasons&(throttleSWThermal|throttleHWThermal) != 0,
+		ErrorCount:      int(g.values[dcgmECCDoubleBit]),
+	}
+}
+
+// parseDCGMMetrics parses the exporter's Prometheus text format into
+// GPUs ordered by index. Samples without a GPU label, such as those of
+// NVSwitches, are skipped.
+func parseDCGMMetrics(r io.Reader) ([]*dcgmGPU, error) {
+	gpus := make(map[int]*dcgmGPU)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		name, labels, value, err := parsePrometheusSample(text)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected DCGM exporter output on line %d: %w", line, err)
+		}
+		gpuLabel, ok := labels["gpu"]
+		if !ok {
+			continue
+		}
+		index, err := strconv.Atoi(gpuLabel)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected GPU index %q on line %d", gpuLabel, line)
+		}
+
+		gpu, ok := gpus[index]
+		if !ok {
+			gpu = &dcgmGPU{index: index, values: make(map[string]float64)}
+			gpus[index] = gpu
+		}
+		if uuid := labels["UUID"]; uuid != "" {
+			gpu.uuid = uuid
+		}
+		if model := labels["modelName"]; model != "" {
+			gpu.model = model
+		}
+		gpu.values[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]*dcgmGPU, 0, len(gpus))
+	for _, gpu := range gpus {
+		result = append(result, gpu)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].index < result[j].index })
+	return result, nil
+}
+
+// parsePrometheusSample parses a sample line of the Prometheus text
+// format: a metric name, optional labels, a value and an optional
+// timestamp
+func parsePrometheusSample(line string) (string, map[string]string, float64, error) {
+	labels := make(map[string]string)
+	name, rest := line, ""
+	if i := strings.IndexAny(line, "{ "); i >= 0 {
+		name, rest = line[:i], line[i:]
+	}
+
+	if strings.HasPrefix(rest, "{") {
+		end, err := parsePrometheusLabels(rest[1:], labels)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		rest = rest[1+end:]
+	}
+
+	fields := strings.Fields(rest)
+	if name == "" || len(fields) == 0 {
+		return "", nil, 0, fmt.Errorf("malformed sample %q", line)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("malformed value %q", fields[0])
+	}
+	return name, labels, value, nil
+}
+
+// parsePrometheusLabels parses `name="value",...}` into labels and returns
+// the offset just past the closing brace
+func parsePrometheusLabels(s string, labels map[string]string) (int, error) {
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == ',') {
+			i++
+		}
+		if i < len(s) && s[i] == '}' {
+			return i + 1, nil
+		}
+
+		eq := strings.IndexByte(s[i:], '=')
+		if eq < 0 || i+eq+1 >= len(s) || s[i+eq+1] != '"' {
+			return 0, fmt.Errorf("malformed labels %q", s)
+		}
+		key := strings.TrimSpace(s[i : i+eq])
+		i += eq + 2
+
+		var value bytes.Buffer
+		for {
+			if i >= len(s) {
+				return 0, fmt.Errorf("unterminated label value in %q", s)
+			}
+			c := s[i]
+			i++
+			if c == '"' {
+				break
+			}
+			if c == '\\' && i < len(s) {
+				switch s[i] {
+				case 'n':
+					c = '\n'
+				default:
+					c = s[i]
+				}
+				i++
+			}
+			value.WriteByte(c)
+		}
+		labels[key] = value.String()
+	}
+}
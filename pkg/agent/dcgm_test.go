@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureDCGMExporter serves recorded DCGM exporter output
+func fixtureDCGMExporter(t *testing.T) *dcgmExporter {
+	t.Helper()
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	t.Cleanup(server.Close)
+	exporter := newDCGMExporter(strings.TrimPrefix(server.URL, "http://"))
+	exporter.url = server.URL + "/dcgm-exporter.txt"
+	return exporter
+}
+
+func TestDCGMExporterDiscover(t *testing.T) {
+	gpus, err := fixtureDCGMExporter(t).Discover(context.Background())
+	require.NoError(t, err)
+	require.Len(t, gpus, 2)
+
+	assert.Equal(t, "GPU-5f1a3c2e-8a4b-4c1d-9e2f-0a1b2c3d4e5f", gpus[0].GPUID)
+	assert.Equal(t, 0, gpus[0].Index)
+	assert.Equal(t, "NVIDIA A100-SXM4-80GB", gpus[0].Model)
+	assert.Equal(t, int64(81920), gpus[0].MemoryTotalMB)
+	assert.Equal(t, 1, gpus[1].Index)
+}
+
+func TestDCGMExporterMetrics(t *testing.T) {
+	samples, err := fixtureDCGMExporter(t).Metrics(context.Background())
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+
+	assert.Equal(t, "GPU-5f1a3c2e-8a4b-4c1d-9e2f-0a1b2c3d4e5f", samples[0].GPUID)
+	assert.Equal(t, 97.0, samples[0].Utilization)
+	assert.Equal(t, 71.0, samples[0].Temperature)
+	assert.InDelta(t, 389.42, samples[0].PowerUsage, 0.001)
+	assert.Equal(t, int64(65210), samples[0].MemoryUsedMB)
+	assert.Equal(t, int64(16244), samples[0].MemoryFreeMB)
+	assert.False(t, samples[0].ThermalThrottle)
+	assert.Zero(t, samples[0].ErrorCount)
+
+	assert.True(t, samples[1].ThermalThrottle)
+	assert.Equal(t, 2, samples[1].ErrorCount)
+}
+
+func TestDCGMExporterUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := newDCGMExporter(strings.TrimPrefix(server.URL, "http://")).Metrics(context.Background())
+	assert.Error(t, err)
+}
+
+func TestParsePrometheusSample(t *testing.T) {
+	name, labels, value, err := parsePrometheusSample(`metric{a="x, \"y\"",b="z\\"} 1.5e3 1700000000000`)
+	require.NoError(t, err)
+	assert.Equal(t, "metric", name)
+	assert.Equal(t, `x, "y"`, labels["a"])
+	assert.Equal(t, `z\`, labels["b"])
+	assert.Equal(t, 1500.0, value)
+
+	name, labels, value, err = parsePrometheusSample("up 1")
+	require.NoError(t, err)
+	assert.Equal(t, "up", name)
+	assert.Empty(t, labels)
+	assert.Equal(t, 1.0, value)
+
+	for _, line := range []string{`metric{a="x} 1`, `metric{a=x} 1`, "metric", "metric{} NaNish"} {
+		_, _, _, err := parsePrometheusSample(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestNewProviderSelection(t *testing.T) {
+	provider, err := NewProvider(utils.AgentConfig{})
+	require.NoError(t, err)
+	assert.IsType(t, &nvidiaSMI{}, provider)
+
+	provider, err = NewProvider(utils.AgentConfig{DCGMEnabled: true, DCGMHostPort: "localhost:9400"})
+	require.NoError(t, err)
+	assert.IsType(t, &dcgmExporter{}, provider)
+
+	provider, err = NewProvider(utils.AgentConfig{GPUProvider: ProviderNvidiaSMI, DCGMEnabled: true})
+	require.NoError(t, err)
+	assert.IsType(t, &nvidiaSMI{}, provider)
+
+	_, err = NewProvider(utils.AgentConfig{GPUProvider: ProviderDCGM})
+	assert.Error(t, err)
+	_, err = NewProvider(utils.AgentConfig{GPUProvider: "rocm-smi"})
+	assert.Error(t, err)
+}
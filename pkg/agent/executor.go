@@ -0,0 +1,188 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+)
+
+// stopGracePeriod is how long a stopped job gets to exit after SIGTERM
+// before it is killed
+const stopGracePeriod = 10 * time.Second
+
+// jobResult is the outcome of a job that exited
+type jobResult struct {
+	jobID    string
+	exitCode int
+	err      error
+}
+
+type runningJob struct {
+	assignment *models.JobAssignment
+	startedAt  time.Time
+	cancel     context.CancelFunc
+	done       chan struct{}
+	stopped    bool
+}
+
+// executor runs assigned jobs as local processes or containers. Its
+// methods are called from the agent's loop only; exited jobs are
+// delivered on results and must be passed back to remove.
+type executor struct {
+	runtime string
+	jobs    map[string]*runningJob
+	results chan jobResult
+}
+
+func newExecutor(runtime string) *executor {
+	return &executor{
+		runtime: runtime,
+		jobs:    make(map[string]*runningJob),
+		results: make(chan jobResult),
+	}
+}
+
+// start launches a job on the given local GPU indexes
+func (e *executor) start(assignment *models.JobAssignment, devices []int) error {
+	if _, ok := e.jobs[assignment.JobID]; ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd, err := e.command(ctx, assignment, devices)
+	if err != nil {
+		cancel()
+		return err
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Jobs run in their own process group so stopping one reaches the
+	// processes it started too
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM) }
+	cmd.WaitDelay = stopGracePeriod
+
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return fmt.Errorf("failed to start job: %w", err)
+	}
+
+	job := &runningJob{
+		assignment: assignment,
+		startedAt:  time.Now(),
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	e.jobs[assignment.JobID] = job
+
+	go func() {
+		err := cmd.Wait()
+		close(job.done)
+
+		result := jobResult{jobID: assignment.JobID}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.exitCode = exitErr.ExitCode()
+		} else if err != nil {
+			result.exitCode = -1
+			result.err = err
+		}
+		if result.exitCode != 0 && result.err == nil {
+			result.err = fmt.Errorf("exited with code %d", result.exitCode)
+		}
+		e.results <- result
+	}()
+	return nil
+}
+
+// command builds the command for a job. Jobs with an image run in a
+// container; others run directly on the host.
+func (e *executor) command(ctx context.Context, a *models.JobAssignment, devices []int) (*exec.Cmd, error) {
+	visible := make([]string, len(devices))
+	for i, d := range devices {
+		visible[i] = strconv.Itoa(d)
+	}
+	deviceList := strings.Join(visible, ",")
+
+	env := make([]string, 0, len(a.Environment)+2)
+	for _, k := range sortedKeys(a.Environment) {
+		env = append(env, k+"="+a.Environment[k])
+	}
+	env = append(env, "GPU_SCHEDULER_JOB_ID="+a.JobID, "CUDA_VISIBLE_DEVICES="+deviceList)
+
+	var argv []string
+	if a.Script != "" {
+		argv = []string{"sh", "-c", a.Script}
+	} else {
+		argv = append(append(argv, a.Command...), a.Args...)
+	}
+
+	if a.Image == "" {
+		if len(argv) == 0 {
+			return nil, fmt.Errorf("job %s has no command, script or image", a.JobID)
+		}
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Env = append(os.Environ(), env...)
+		return cmd, nil
+	}
+
+	if e.runtime == "" {
+		return nil, fmt.Errorf("job %s needs a container runtime", a.JobID)
+	}
+	args := []string{"run", "--rm", "--name", "gpu-job-" + a.JobID}
+	if deviceList != "" {
+		args = append(args, "--gpus", fmt.Sprintf(`"device=%s"`, deviceList))
+	}
+	for _, kv := range env {
+		args = append(args, "-e", kv)
+	}
+	args = append(args, a.Image)
+	args = append(args, argv...)
+	return exec.CommandContext(ctx, e.runtime, args...), nil
+}
+
+// stop asks a job to exit. Its result still arrives on results.
+func (e *executor) stop(jobID string) {
+	if job, ok := e.jobs[jobID]; ok && !job.stopped {
+		job.stopped = true
+		job.cancel()
+	}
+}
+
+// remove forgets an exited job and returns it, or nil if it is unknown
+func (e *executor) remove(result jobResult) *runningJob {
+	job := e.jobs[result.jobID]
+	delete(e.jobs, result.jobID)
+	return job
+}
+
+// stopAll stops every job and waits for them to exit
+func (e *executor) stopAll() {
+	for id := range e.jobs {
+		e.stop(id)
+	}
+	for _, job := range e.jobs {
+		select {
+		case <-job.done:
+		case <-time.After(stopGracePeriod + time.Second):
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,280 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	agentpb "github.com/azizbahloul/gpu-scheduler/pkg/api/grpc/generated/agent"
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCClient talks to the scheduler's AgentService. Heartbeats share one
+// stream, and job assignments pushed down the ReceiveJobAssignment stream
+// are kept until the agent asks for them.
+type GRPCClient struct {
+	conn   *grpc.ClientConn
+	agents agentpb.AgentServiceClient
+
+	// heartbeats is used from the agent's loop only
+	heartbeats agentpb.AgentService_HeartbeatClient
+
+	mu          sync.Mutex
+	streaming   bool
+	assignments map[string]*models.JobAssignment
+	updates     chan struct{}
+}
+
+// NewGRPCClient creates a client for the AgentService at address, over
+// TLS trusting the CA at caPath unless caPath is empty
+func NewGRPCClient(address, caPath string) (*GRPCClient, error) {
+	creds := insecure.NewCredentials()
+	if caPath != "" {
+		tls, err := credentials.NewClientTLSFromFile(caPath, "")
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to load CA certificate: %v", utils.ErrInvalidConfig, err)
+		}
+		creds = tls
+	}
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("%w: scheduler gRPC address %q: %v", utils.ErrInvalidConfig, address, err)
+	}
+	return &GRPCClient{
+		conn:        conn,
+		agents:      agentpb.NewAgentServiceClient(conn),
+		assignments: make(map[string]*models.JobAssignment),
+		updates:     make(chan struct{}, 1),
+	}, nil
+}
+
+// Register announces the node and its GPUs
+func (c *GRPCClient) Register(ctx context.Context, reg *models.AgentRegistration) (*models.AgentRegistrationResult, error) {
+	resp, err := c.agents.RegisterAgent(ctx, registrationToProto(reg))
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return &models.AgentRegistrationResult{
+		Success: resp.GetSuccess(),
+		Message: resp.GetMessage(),
+		AgentID: resp.GetAgentId(),
+	}, nil
+}
+
+// Heartbeat sends a heartbeat on the heartbeat stream, opening it first
+// if needed. It returns utils.ErrNodeNotFound when the node must register
+// again; the stream is then opened anew on the next heartbeat.
+func (c *GRPCClient) Heartbeat(ctx context.Context, heartbeat *models.NodeHeartbeat) (*models.HeartbeatAck, error) {
+	if c.heartbeats == nil {
+		stream, err := c.agents.Heartbeat(ctx)
+		if err != nil {
+			return nil, fromStatus(err)
+		}
+		c.heartbeats = stream
+	}
+
+	// A stream the server ended fails to send with io.EOF; the reason
+	// is returned by Recv
+	stream := c.heartbeats
+	var resp *agentpb.HeartbeatResponse
+	err := stream.Send(heartbeatToProto(heartbeat))
+	if err == nil || errors.Is(err, io.EOF) {
+		resp, err = stream.Recv()
+	}
+	if err != nil {
+		stream.CloseSend()
+		c.heartbeats = nil
+		return nil, fromStatus(err)
+	}
+	return &models.HeartbeatAck{Acknowledged: resp.GetAcknowledged(), Commands: resp.GetCommands()}, nil
+}
+
+// ReportMetrics sends GPU and job metrics
+func (c *GRPCClient) ReportMetrics(ctx context.Context, report *models.MetricsReport) error {
+	if _, err := c.agents.ReportMetrics(ctx, reportToProto(report)); err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+// Assignments returns the jobs pushed to the node and not unassigned
+// since. The assignment stream is opened on the first call, and opened
+// again after it breaks, naming the jobs already known so those that
+// left the node meanwhile are unassigned.
+func (c *GRPCClient) Assignments(ctx context.Context, nodeID string) ([]*models.JobAssignment, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.streaming {
+		jobIDs := make([]string, 0, len(c.assignments))
+		for jobID := range c.assignments {
+			jobIDs = append(jobIDs, jobID)
+		}
+		stream, err := c.agents.ReceiveJobAssignment(ctx)
+		if err != nil {
+			return nil, fromStatus(err)
+		}
+		if err := stream.Send(&agentpb.JobAssignmentRequest{NodeId: nodeID, Status: "ready", JobIds: jobIDs}); err != nil {
+			return nil, fromStatus(err)
+		}
+		c.streaming = true
+		go c.receiveAssignments(stream)
+	}
+
+	assignments := make([]*models.JobAssignment, 0, len(c.assignments))
+	for _, assignment := range c.assignments {
+		assignments = append(assignments, assignment)
+	}
+	return assignments, nil
+}
+
+// AssignmentUpdates signals each change pushed down the assignment stream
+func (c *GRPCClient) AssignmentUpdates() <-chan struct{} {
+	return c.updates
+}
+
+// Close closes the connection and the streams on it
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+// receiveAssignments applies the assignments pushed down the stream until
+// it breaks
+func (c *GRPCClient) receiveAssignments(stream agentpb.AgentService_ReceiveJobAssignmentClient) {
+	for {
+		resp, err := stream.Recv()
+		c.mu.Lock()
+		if err != nil {
+			c.streaming = false
+			c.mu.Unlock()
+			if status.Code(err) != codes.Canceled {
+				utils.Warn("Job assignment stream closed", zap.Error(err))
+			}
+			return
+		}
+		if resp.GetUnassigned() {
+			delete(c.assignments, resp.GetJobId())
+		} else {
+			c.assignments[resp.GetJobId()] = assignmentFromProto(resp)
+		}
+		c.mu.Unlock()
+
+		select {
+		case c.updates <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// fromStatus maps gRPC errors to the ones the REST client returns
+func fromStatus(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return utils.ErrNodeNotFound
+	}
+	return fmt.Errorf("failed to reach scheduler: %w", err)
+}
+
+// The functions below translate between the models the agent works with
+// and AgentService messages
+
+func registrationToProto(reg *models.AgentRegistration) *agentpb.RegisterAgentRequest {
+	req := &agentpb.RegisterAgentRequest{
+		NodeId:        reg.NodeID,
+		Hostname:      reg.Hostname,
+		IpAddress:     reg.IPAddress,
+		TotalGpus:     int32(reg.TotalGPUs),
+		TotalCpuCores: int32(reg.TotalCPUCores),
+		TotalMemoryMb: reg.TotalMemoryMB,
+		Labels:        reg.Labels,
+	}
+	for _, gpu := range reg.GPUs {
+		req.Gpus = append(req.Gpus, &agentpb.GPUInfo{
+			GpuId:             gpu.GPUID,
+			Index:             int32(gpu.Index),
+			Model:             gpu.Model,
+			MemoryTotalMb:     gpu.MemoryTotalMB,
+			ComputeCapability: gpu.ComputeCapability,
+			CudaCores:         int32(gpu.CUDACores),
+			TensorCores:       int32(gpu.TensorCores),
+		})
+	}
+	return req
+}
+
+func heartbeatToProto(heartbeat *models.NodeHeartbeat) *agentpb.HeartbeatRequest {
+	return &agentpb.HeartbeatRequest{
+		NodeId:            heartbeat.NodeID,
+		Timestamp:         timestamppb.New(heartbeat.Timestamp),
+		GpuMetrics:        gpuMetricsToProto(heartbeat.GPUMetrics),
+		CpuUtilization:    heartbeat.CPUUtilization,
+		MemoryUtilization: heartbeat.MemoryUtilization,
+		ActiveJobs:        int32(heartbeat.ActiveJobs),
+	}
+}
+
+func reportToProto(report *models.MetricsReport) *agentpb.ReportMetricsRequest {
+	req := &agentpb.ReportMetricsRequest{
+		NodeId:     report.NodeID,
+		GpuMetrics: gpuMetricsToProto(report.GPUMetrics),
+		Timestamp:  timestamppb.New(report.Timestamp),
+	}
+	for _, job := range report.JobMetrics {
+		metrics := &agentpb.JobMetrics{
+			JobId:          job.JobID,
+			State:          string(job.State),
+			GpuUtilization: job.GPUUtilization,
+			CpuUtilization: job.CPUUtilization,
+			MemoryUsedMb:   job.MemoryUsedMB,
+			ExitCode:       job.ExitCode,
+			ErrorMessage:   job.ErrorMessage,
+		}
+		if job.StartedAt != nil {
+			metrics.StartedAt = timestamppb.New(*job.StartedAt)
+		}
+		req.JobMetrics = append(req.JobMetrics, metrics)
+	}
+	return req
+}
+
+func gpuMetricsToProto(metrics []models.GPUMetrics) []*agentpb.GPUMetrics {
+	samples := make([]*agentpb.GPUMetrics, 0, len(metrics))
+	for _, sample := range metrics {
+		samples = append(samples, &agentpb.GPUMetrics{
+			GpuId:           sample.GPUID,
+			Utilization:     sample.Utilization,
+			Temperature:     sample.Temperature,
+			PowerUsage:      sample.PowerUsage,
+			MemoryUsedMb:    sample.MemoryUsedMB,
+			MemoryFreeMb:    sample.MemoryFreeMB,
+			ThermalThrottle: sample.ThermalThrottle,
+			ErrorCount:      int32(sample.ErrorCount),
+		})
+	}
+	return samples
+}
+
+func assignmentFromProto(resp *agentpb.JobAssignmentResponse) *models.JobAssignment {
+	return &models.JobAssignment{
+		JobID:        resp.GetJobId(),
+		AllocationID: resp.GetAllocationId(),
+		GPUIDs:       resp.GetGpuIds(),
+		CPUCores:     int(resp.GetCpuCores()),
+		MemoryMB:     resp.GetMemoryMb(),
+		Image:        resp.GetImage(),
+		Command:      resp.GetCommand(),
+		Args:         resp.GetArgs(),
+		Environment:  resp.GetEnvironment(),
+		Script:       resp.GetScript(),
+	}
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// hostIP returns the first non-loopback IPv4 address of the host
+func hostIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		if ip := ipNet.IP.To4(); ip != nil {
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+// hostCPUCores returns the number of CPU cores available to jobs
+func hostCPUCores() int {
+	return runtime.NumCPU()
+}
+
+// hostMemory returns the total and available memory in MB from
+// /proc/meminfo. Both are zero where it does not exist.
+func hostMemory() (totalMB, availableMB int64) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			totalMB = kb / 1024
+		case "MemAvailable:":
+			availableMB = kb / 1024
+		}
+	}
+	return totalMB, availableMB
+}
+
+// memoryUtilization returns the percentage of host memory in use
+func memoryUtilization() float64 {
+	total, available := hostMemory()
+	if total == 0 {
+		return 0
+	}
+	return float64(total-available) / float64(total) * 100
+}
+
+// cpuSampler measures CPU utilization between successive samples of
+// /proc/stat
+type cpuSampler struct {
+	idle, total uint64
+}
+
+// sample returns the percentage of CPU time spent busy since the last
+// call. The first call measures since boot.
+func (c *cpuSampler) sample() float64 {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0
+	}
+	line := strings.SplitN(string(data), "\n", 2)[0]
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0
+	}
+
+	var idle, total uint64
+	for i, field := range fields[1:] {
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0
+		}
+		total += v
+		// idle and iowait
+		if i == 3 || i == 4 {
+			idle += v
+		}
+	}
+
+	deltaTotal := total - c.total
+	deltaIdle := idle - c.idle
+	c.idle, c.total = idle, total
+	if deltaTotal == 0 {
+		return 0
+	}
+	return float64(deltaTotal-deltaIdle) / float64(deltaTotal) * 100
+}
@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+)
+
+// Fields queried from nvidia-smi. The throttle reason fields are named
+// clocks_event_reasons by newer drivers, which still accept these names.
+var (
+	nvidiaSMIInventoryFields = []string{"index", "uuid", "name", "memory.total", "compute_cap"}
+	nvidiaSMIMetricsFields   = []string{
+		"uuid", "utilization.gpu", "temperature.gpu", "power.draw", "memory.used", "memory.free",
+		"clocks_throttle_reasons.hw_thermal_slowdown", "clocks_throttle_reasons.sw_thermal_slowdown",
+		"ecc.errors.uncorrected.volatile.total",
+	}
+)
+
+// nvidiaSMI finds and samples NVIDIA GPUs by parsing the CSV output of
+// nvidia-smi --query-gpu. A node without nvidia-smi has no GPUs.
+type nvidiaSMI struct {
+	// run executes nvidia-smi with the given arguments
+	run func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+func newNvidiaSMI() *nvidiaSMI {
+	return &nvidiaSMI{run: runNvidiaSMI}
+}
+
+func runNvidiaSMI(ctx context.Context, args ...string) ([]byte, error) {
+	path, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		return nil, err
+	}
+	out, err := exec.CommandContext(ctx, path, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("nvidia-smi failed: %w", err)
+	}
+	return out, nil
+}
+
+// Discover lists the GPUs nvidia-smi reports
+func (n *nvidiaSMI) Discover(ctx context.Context) ([]models.GPUInfo, error) {
+	rows, err := n.query(ctx, nvidiaSMIInventoryFields)
+	if err != nil {
+		return nil, err
+	}
+
+	gpus := make([]models.GPUInfo, 0, len(rows))
+	for _, row := range rows {
+		index, err := strconv.Atoi(row["index"])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected GPU index %q from nvidia-smi", row["index"])
+		}
+		gpus = append(gpus, models.GPUInfo{
+			GPUID:             row["uuid"],
+			Index:             index,
+			Model:             row["name"],
+			MemoryTotalMB:     int64(csvNumber(row["memory.total"])),
+			ComputeCapability: csvValue(row["compute_cap"]),
+		})
+	}
+	return gpus, nil
+}
+
+// Metrics samples the GPUs nvidia-smi reports
+func (n *nvidiaSMI) Metrics(ctx context.Context) ([]models.GPUMetrics, error) {
+	rows, err := n.query(ctx, nvidiaSMIMetricsFields)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]models.GPUMetrics, 0, len(rows))
+	for _, row := range rows {
+		samples = append(samples, models.GPUMetrics{
+			GPUID:        row["uuid"],
+			Utilization:  csvNumber(row["utilization.gpu"]),
+			Temperature:  csvNumber(row["temperature.gpu"]),
+			PowerUsage:   csvNumber(row["power.draw"]),
+			MemoryUsedMB: int64(csvNumber(row["memory.used"])),
+			MemoryFreeMB: int64(csvNumber(row["memory.free"])),
+			ThermalThrottle: row["clocks_throttle_reasons.hw_thermal_slowdown"] == "Active" ||
+				row["clocks_throttle_reasons.sw_thermal_slowdown"] == "Active",
+			ErrorCount: int(csvNumber(row["ecc.errors.uncorrected.volatile.total"])),
+		})
+	}
+	return samples, nil
+}
+
+// query runs nvidia-smi for the given fields and returns one row per GPU
+func (n *nvidiaSMI) query(ctx context.Context, fields []string) ([]map[string]string, error) {
+	out, err := n.run(ctx, "--query-gpu="+strings.Join(fields, ","), "--format=csv,nounits")
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseNvidiaSMICSV(out)
+}
+
+// parseNvidiaSMICSV parses nvidia-smi --format=csv output into rows keyed
+// by field name. Units in the header, such as "memory.total [MiB]", are
+// dropped.
+func parseNvidiaSMICSV(data []byte) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected nvidia-smi output: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i, name := range header {
+		if j := strings.Index(name, " ["); j >= 0 {
+			name = name[:j]
+		}
+		header[i] = strings.TrimSpace(name)
+	}
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				row[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// csvValue returns a field's value, or an empty string for the
+// placeholders nvidia-smi prints for unsupported fields
+func csvValue(v string) string {
+	if strings.HasPrefix(v, "[") {
+		return ""
+	}
+	return v
+}
+
+// csvNumber parses a numeric field. Unsupported fields, printed as
+// "[N/A]" or "[Not Supported]", read as zero.
+func csvNumber(v string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSuffix(csvValue(v), " %"), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureNvidiaSMI answers nvidia-smi queries with recorded output
+func fixtureNvidiaSMI(t *testing.T) *nvidiaSMI {
+	t.Helper()
+	return &nvidiaSMI{run: func(ctx context.Context, args ...string) ([]byte, error) {
+		fixture := "testdata/nvidia-smi-metrics.csv"
+		if strings.Contains(args[0], "compute_cap") {
+			fixture = "testdata/nvidia-smi-inventory.csv"
+		}
+		return os.ReadFile(fixture)
+	}}
+}
+
+func TestNvidiaSMIDiscover(t *testing.T) {
+	gpus, err := fixtureNvidiaSMI(t).Discover(context.Background())
+	require.NoError(t, err)
+	require.Len(t, gpus, 3)
+
+	assert.Equal(t, "GPU-5f1a3c2e-8a4b-4c1d-9e2f-0a1b2c3d4e5f", gpus[0].GPUID)
+	assert.Equal(t, "NVIDIA A100-SXM4-80GB", gpus[0].Model)
+	assert.Equal(t, int64(81920), gpus[0].MemoryTotalMB)
+	assert.Equal(t, "8.0", gpus[0].ComputeCapability)
+	assert.Equal(t, 2, gpus[2].Index)
+	assert.Equal(t, "Tesla T4", gpus[2].Model)
+	assert.Empty(t, gpus[2].ComputeCapability)
+}
+
+func TestNvidiaSMIMetrics(t *testing.T) {
+	samples, err := fixtureNvidiaSMI(t).Metrics(context.Background())
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+
+	assert.Equal(t, "GPU-5f1a3c2e-8a4b-4c1d-9e2f-0a1b2c3d4e5f", samples[0].GPUID)
+	assert.Equal(t, 97.0, samples[0].Utilization)
+	assert.Equal(t, 71.0, samples[0].Temperature)
+	assert.InDelta(t, 389.42, samples[0].PowerUsage, 0.001)
+	assert.Equal(t, int64(65210), samples[0].MemoryUsedMB)
+	assert.Equal(t, int64(16244), samples[0].MemoryFreeMB)
+	assert.False(t, samples[0].ThermalThrottle)
+
+	assert.True(t, samples[1].ThermalThrottle)
+	assert.Equal(t, 2, samples[1].ErrorCount)
+
+	// Unsupported fields read as zero
+	assert.Zero(t, samples[2].PowerUsage)
+	assert.Zero(t, samples[2].ErrorCount)
+	assert.False(t, samples[2].ThermalThrottle)
+}
+
+func TestParseNvidiaSMICSV(t *testing.T) {
+	rows, err := parseNvidiaSMICSV([]byte("index, memory.total [MiB]\n0, 81920\n"))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "81920", rows[0]["memory.total"])
+
+	rows, err = parseNvidiaSMICSV(nil)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+
+	_, err = parseNvidiaSMICSV([]byte("index, uuid\n0, a, b\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+)
+
+// Provider finds a node's GPUs and samples their telemetry. GPUs are
+// identified by the provider's own IDs in GPUInfo.GPUID and
+// GPUMetrics.GPUID; the agent maps them to the scheduler's GPU IDs.
+type Provider interface {
+	// Discover lists the GPUs the node can currently reach
+	Discover(ctx context.Context) ([]models.GPUInfo, error)
+	// Metrics samples every GPU the node can currently reach
+	Metrics(ctx context.Context) ([]models.GPUMetrics, error)
+}
+
+// JobObserver is implemented by providers whose metrics depend on the
+// jobs running on their GPUs, such as the simulated provider
+type JobObserver interface {
+	JobStarted(jobID string, indexes []int)
+	JobStopped(jobID string)
+}
+
+// Provider names accepted in agent.gpu_provider
+const (
+	ProviderNvidiaSMI = "nvidia-smi"
+	ProviderDCGM      = "dcgm"
+	ProviderSimulated = "simulated"
+)
+
+// NewProvider creates the GPU provider selected by the agent config.
+// Without one, the DCGM exporter is used if DCGM is enabled and
+// nvidia-smi otherwise.
+func NewProvider(config utils.AgentConfig) (Provider, error) {
+	provider := config.GPUProvider
+	if provider == "" {
+		provider = ProviderNvidiaSMI
+		if config.DCGMEnabled {
+			provider = ProviderDCGM
+		}
+	}
+
+	switch provider {
+	case ProviderNvidiaSMI:
+		return newNvidiaSMI(), nil
+	case ProviderDCGM:
+		if config.DCGMHostPort == "" {
+			return nil, fmt.Errorf("%w: agent.dcgm_host_port", utils.ErrMissingConfig)
+		}
+		return newDCGMExporter(config.DCGMHostPort), nil
+	case ProviderSimulated:
+		return NewSimulatedProviderFromConfig(config.Simulation)
+	default:
+		return nil, fmt.Errorf("%w: unknown GPU provider %q", utils.ErrInvalidConfig, config.GPUProvider)
+	}
+}
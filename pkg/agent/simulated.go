@@ -0,0 +1,409 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+)
+
+// gpuSpec holds the datasheet figures the simulator uses for a model
+type gpuSpec struct {
+	memoryMB          int64
+	tdpW              float64
+	computeCapability string
+	cudaCores         int
+	tensorCores       int
+}
+
+var gpuSpecs = map[models.GPUModel]gpuSpec{
+	models.GPUH100:    {memoryMB: 81920, tdpW: 700, computeCapability: "9.0", cudaCores: 16896, tensorCores: 528},
+	models.GPUA100:    {memoryMB: 81920, tdpW: 400, computeCapability: "8.0", cudaCores: 6912, tensorCores: 432},
+	models.GPUV100:    {memoryMB: 32768, tdpW: 300, computeCapability: "7.0", cudaCores: 5120, tensorCores: 640},
+	models.GPUA10:     {memoryMB: 24576, tdpW: 150, computeCapability: "8.6", cudaCores: 9216, tensorCores: 288},
+	models.GPUT4:      {memoryMB: 16384, tdpW: 70, computeCapability: "7.5", cudaCores: 2560, tensorCores: 320},
+	models.GPUL4:      {memoryMB: 24576, tdpW: 72, computeCapability: "8.9", cudaCores: 7424, tensorCores: 240},
+	models.GPURTX4090: {memoryMB: 24576, tdpW: 450, computeCapability: "8.9", cudaCores: 16384, tensorCores: 512},
+}
+
+// Synthetic telemetry model. Temperature moves part of the way towards
+// its target on each sample, so it lags behind load as on real hardware.
+const (
+	simAmbientC         = 35.0
+	simFullLoadRiseC    = 45.0
+	simThrottleC        = 90.0
+	simWarmupRate       = 0.3
+	simIdlePowerShare   = 0.15
+	simDriverMemoryMB   = 512
+	simJobMemoryShare   = 0.7
+	simNVLinkScaling    = 0.95
+	simNUMAScaling      = 0.8
+	simCrossNUMAScaling = 0.7
+)
+
+// Fault kinds the simulated provider can inject
+const (
+	FaultECC             = "ecc"
+	FaultThermalThrottle = "thermal_throttle"
+	FaultFallOffBus      = "fall_off_bus"
+)
+
+// SimulatedGPU describes a simulated GPU. A zero MemoryMB uses the
+// model's usual memory. GPUs sharing an NVLinkDomain are linked by
+// NVLink; others talk over PCIe.
+type SimulatedGPU struct {
+	Model        models.GPUModel
+	MemoryMB     int64
+	NUMANode     int
+	NVLinkDomain string
+}
+
+// SimulatedFault is a fault injected into a GPU once After has passed
+// since the provider was created
+type SimulatedFault struct {
+	GPU   int
+	Kind  string
+	After time.Duration
+	Count int
+}
+
+type simGPU struct {
+	SimulatedGPU
+	index       int
+	spec        gpuSpec
+	temperature float64
+	eccErrors   int
+	throttled   bool
+	lost        bool
+}
+
+// SimulatedProvider is a Provider backed by simulated GPUs, for nodes
+// and CI machines without any. Utilization, temperature, power and
+// memory follow the jobs it is told about through JobObserver. Jobs whose
+// GPUs share an NVLink domain scale better than jobs spanning PCIe or
+// NUMA nodes. Faults can be injected directly or scheduled.
+type SimulatedProvider struct {
+	mu      sync.Mutex
+	gpus    []*simGPU
+	jobs    map[string][]int
+	faults  []SimulatedFault
+	started time.Time
+	rand    *rand.Rand
+	now     func() time.Time
+}
+
+// NewSimulatedProvider creates a provider with the given GPUs. The same
+// seed produces the same metrics for the same jobs.
+func NewSimulatedProvider(gpus []SimulatedGPU, seed int64) (*SimulatedProvider, error) {
+	p := &SimulatedProvider{
+		jobs:    make(map[string][]int),
+		started: time.Now(),
+		rand:    rand.New(rand.NewSource(seed)),
+		now:     time.Now,
+	}
+	for i, gpu := range gpus {
+		spec, ok := gpuSpecs[gpu.Model]
+		if !ok {
+			return nil, fmt.Errorf("%w: no simulated GPU model %q", utils.ErrInvalidConfig, gpu.Model)
+		}
+		if gpu.MemoryMB == 0 {
+			gpu.MemoryMB = spec.memoryMB
+		}
+		p.gpus = append(p.gpus, &simGPU{
+			SimulatedGPU: gpu,
+			index:        i,
+			spec:         spec,
+			temperature:  simAmbientC,
+		})
+	}
+	return p, nil
+}
+
+// NewSimulatedProviderFromConfig creates a provider from the agent's
+// simulation config
+func NewSimulatedProviderFromConfig(config utils.SimulationConfig) (*SimulatedProvider, error) {
+	var gpus []SimulatedGPU
+	for _, entry := range config.GPUs {
+		count := entry.Count
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			gpus = append(gpus, SimulatedGPU{
+				Model:        models.ParseGPUModel(entry.Model),
+				MemoryMB:     entry.MemoryMB,
+				NUMANode:     entry.NUMANode,
+				NVLinkDomain: entry.NVLinkDomain,
+			})
+		}
+	}
+
+	p, err := NewSimulatedProvider(gpus, config.Seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, fault := range config.Faults {
+		err := p.ScheduleFault(SimulatedFault{
+			GPU:   fault.GPU,
+			Kind:  fault.Kind,
+			After: time.Duration(fault.AfterSec) * time.Second,
+			Count: fault.Count,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", utils.ErrInvalidConfig, err)
+		}
+	}
+	return p, nil
+}
+
+// Discover lists the simulated GPUs still on the bus
+func (p *SimulatedProvider) Discover(ctx context.Context) ([]models.GPUInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.applyDueFaults()
+
+	var infos []models.GPUInfo
+	for _, g := range p.gpus {
+		if g.lost {
+			continue
+		}
+		infos = append(infos, models.GPUInfo{
+			GPUID:             simGPUID(g.index),
+			Index:             g.index,
+			Model:             string(g.Model),
+			MemoryTotalMB:     g.MemoryMB,
+			ComputeCapability: g.spec.computeCapability,
+			CUDACores:         g.spec.cudaCores,
+			TensorCores:       g.spec.tensorCores,
+		})
+	}
+	return infos, nil
+}
+
+// Metrics takes a synthetic sample of each GPU still on the bus
+func (p *SimulatedProvider) Metrics(ctx context.Context) ([]models.GPUMetrics, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.applyDueFaults()
+
+	var samples []models.GPUMetrics
+	for _, g := range p.gpus {
+		if g.lost {
+			continue
+		}
+
+		load := p.load(g)
+		utilization := clamp(load*100+p.noise(2), 0, 100)
+		if load == 0 {
+			utilization = 0
+		}
+		target := simAmbientC + load*simFullLoadRiseC
+		if g.throttled {
+			// A throttled GPU runs hot and at reduced clocks
+			utilization /= 2
+			target = math.Max(target, simThrottleC)
+		}
+		g.temperature += (target-g.temperature)*simWarmupRate + p.noise(0.5)
+
+		power := g.spec.tdpW * (simIdlePowerShare + (1-simIdlePowerShare)*utilization/100)
+		used := int64(simDriverMemoryMB)
+		if load > 0 {
+			used += int64(float64(g.MemoryMB-simDriverMemoryMB) * simJobMemoryShare)
+		}
+
+		samples = append(samples, models.GPUMetrics{
+			GPUID:           simGPUID(g.index),
+			Utilization:     round1(utilization),
+			Temperature:     round1(g.temperature),
+			PowerUsage:      round1(power + p.noise(1)),
+			MemoryUsedMB:    used,
+			MemoryFreeMB:    g.MemoryMB - used,
+			ThermalThrottle: g.throttled,
+			ErrorCount:      g.eccErrors,
+		})
+	}
+	return samples, nil
+}
+
+// JobStarted puts load on the GPUs with the given indexes
+func (p *SimulatedProvider) JobStarted(jobID string, indexes []int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.jobs[jobID] = append([]int(nil), indexes...)
+}
+
+// JobStopped takes a job's load off its GPUs
+func (p *SimulatedProvider) JobStopped(jobID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.jobs, jobID)
+}
+
+// InjectECCErrors adds uncorrectable ECC errors to a GPU
+func (p *SimulatedProvider) InjectECCErrors(index, count int) error {
+	return p.inject(SimulatedFault{GPU: index, Kind: FaultECC, Count: count})
+}
+
+// SetThermalThrottle makes a GPU throttle, or stop throttling
+func (p *SimulatedProvider) SetThermalThrottle(index int, throttled bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	g, err := p.gpu(index)
+	if err != nil {
+		return err
+	}
+	g.throttled = throttled
+	return nil
+}
+
+// FallOffBus makes a GPU disappear from discovery and metrics, as when
+// the driver reports it has fallen off the bus
+func (p *SimulatedProvider) FallOffBus(index int) error {
+	return p.inject(SimulatedFault{GPU: index, Kind: FaultFallOffBus})
+}
+
+// ScheduleFault injects a fault once its delay has passed
+func (p *SimulatedProvider) ScheduleFault(fault SimulatedFault) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.gpu(fault.GPU); err != nil {
+		return err
+	}
+	switch fault.Kind {
+	case FaultECC, FaultThermalThrottle, FaultFallOffBus:
+	default:
+		return fmt.Errorf("unknown fault kind %q", fault.Kind)
+	}
+	p.faults = append(p.faults, fault)
+	return nil
+}
+
+func (p *SimulatedProvider) inject(fault SimulatedFault) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.applyFault(fault)
+}
+
+// applyDueFaults injects the scheduled faults whose time has come. The
+// caller holds mu.
+func (p *SimulatedProvider) applyDueFaults() {
+	elapsed := p.now().Sub(p.started)
+	pending := p.faults[:0]
+	for _, fault := range p.faults {
+		if fault.After > elapsed {
+			pending = append(pending, fault)
+			continue
+		}
+		p.applyFault(fault)
+	}
+	p.faults = pending
+}
+
+// applyFault injects a fault. The caller holds mu.
+func (p *SimulatedProvider) applyFault(fault SimulatedFault) error {
+	g, err := p.gpu(fault.GPU)
+	if err != nil {
+		return err
+	}
+	switch fault.Kind {
+	case FaultECC:
+		count := fault.Count
+		if count == 0 {
+			count = 1
+		}
+		g.eccErrors += count
+	case FaultThermalThrottle:
+		g.throttled = true
+	case FaultFallOffBus:
+		g.lost = true
+	default:
+		return fmt.Errorf("unknown fault kind %q", fault.Kind)
+	}
+	return nil
+}
+
+func (p *SimulatedProvider) gpu(index int) (*simGPU, error) {
+	if index < 0 || index >= len(p.gpus) {
+		return nil, fmt.Errorf("no simulated GPU %d", index)
+	}
+	return p.gpus[index], nil
+}
+
+// load returns how busy a GPU is, from 0 to 1. Each job running on it
+// adds load according to how well its GPUs are connected. The caller
+// holds mu.
+func (p *SimulatedProvider) load(g *simGPU) float64 {
+	load := 0.0
+	for _, indexes := range p.jobs {
+		if !containsInt(indexes, g.index) {
+			continue
+		}
+		load += p.scaling(indexes)
+	}
+	return math.Min(load, 1)
+}
+
+// scaling returns how efficiently a job uses its GPUs given the links
+// between them
+func (p *SimulatedProvider) scaling(indexes []int) float64 {
+	if len(indexes) <= 1 {
+		return simNVLinkScaling
+	}
+
+	first, err := p.gpu(indexes[0])
+	if err != nil {
+		return simCrossNUMAScaling
+	}
+	nvlink, numa := first.NVLinkDomain != "", true
+	for _, index := range indexes[1:] {
+		g, err := p.gpu(index)
+		if err != nil {
+			return simCrossNUMAScaling
+		}
+		if g.NVLinkDomain != first.NVLinkDomain {
+			nvlink = false
+		}
+		if g.NUMANode != first.NUMANode {
+			numa = false
+		}
+	}
+	switch {
+	case nvlink:
+		return simNVLinkScaling
+	case numa:
+		return simNUMAScaling
+	default:
+		return simCrossNUMAScaling
+	}
+}
+
+// noise returns a random value within ±amplitude
+func (p *SimulatedProvider) noise(amplitude float64) float64 {
+	return (p.rand.Float64()*2 - 1) * amplitude
+}
+
+func simGPUID(index int) string {
+	return fmt.Sprintf("sim-gpu-%d", index)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
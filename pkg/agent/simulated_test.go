@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSimulatedNode creates a provider with two NVLinked A100s on NUMA
+// node 0 and two T4s on NUMA node 1
+func newSimulatedNode(t *testing.T) *SimulatedProvider {
+	t.Helper()
+	p, err := NewSimulatedProviderFromConfig(utils.SimulationConfig{
+		Seed: 1,
+		GPUs: []utils.SimulatedGPUConfig{
+			{Model: "A100", Count: 2, NVLinkDomain: "nvl0"},
+			{Model: "NVIDIA T4", Count: 2, MemoryMB: 15360, NUMANode: 1},
+		},
+	})
+	require.NoError(t, err)
+	return p
+}
+
+// sampleN samples the provider n times and returns the last sample
+func sampleN(t *testing.T, p *SimulatedProvider, n int) map[string]models.GPUMetrics {
+	t.Helper()
+	var samples []models.GPUMetrics
+	for i := 0; i < n; i++ {
+		var err error
+		samples, err = p.Metrics(context.Background())
+		require.NoError(t, err)
+	}
+	byID := make(map[string]models.GPUMetrics, len(samples))
+	for _, sample := range samples {
+		byID[sample.GPUID] = sample
+	}
+	return byID
+}
+
+func TestSimulatedProviderInventory(t *testing.T) {
+	p := newSimulatedNode(t)
+
+	gpus, err := p.Discover(context.Background())
+	require.NoError(t, err)
+	require.Len(t, gpus, 4)
+
+	assert.Equal(t, "A100", gpus[0].Model)
+	assert.Equal(t, int64(81920), gpus[0].MemoryTotalMB)
+	assert.Equal(t, "8.0", gpus[0].ComputeCapability)
+	assert.Equal(t, "T4", gpus[2].Model)
+	assert.Equal(t, int64(15360), gpus[2].MemoryTotalMB)
+	assert.Equal(t, 3, gpus[3].Index)
+
+	_, err = NewSimulatedProviderFromConfig(utils.SimulationConfig{
+		GPUs: []utils.SimulatedGPUConfig{{Model: "Voodoo5"}},
+	})
+	assert.True(t, errors.Is(err, utils.ErrInvalidConfig))
+
+	_, err = NewSimulatedProviderFromConfig(utils.SimulationConfig{
+		GPUs:   []utils.SimulatedGPUConfig{{Model: "T4"}},
+		Faults: []utils.SimulatedFaultConfig{{GPU: 3, Kind: FaultECC}},
+	})
+	assert.True(t, errors.Is(err, utils.ErrInvalidConfig))
+}
+
+func TestSimulatedMetricsFollowJobs(t *testing.T) {
+	p := newSimulatedNode(t)
+
+	idle := sampleN(t, p, 1)
+	assert.Zero(t, idle["sim-gpu-0"].Utilization)
+	assert.InDelta(t, 60, idle["sim-gpu-0"].PowerUsage, 2)
+	assert.InDelta(t, simAmbientC, idle["sim-gpu-0"].Temperature, 1)
+
+	p.JobStarted("nvlinked", []int{0, 1})
+	p.JobStarted("over-pcie", []int{2, 3})
+	busy := sampleN(t, p, 20)
+
+	a100 := busy["sim-gpu-0"]
+	assert.InDelta(t, 95, a100.Utilization, 3)
+	assert.Greater(t, a100.PowerUsage, 350.0)
+	assert.Greater(t, a100.Temperature, 70.0)
+	assert.Greater(t, a100.MemoryUsedMB, int64(50000))
+	assert.Equal(t, a100.MemoryUsedMB+a100.MemoryFreeMB, int64(81920))
+
+	// GPUs on different NUMA nodes without NVLink scale worse
+	p.JobStopped("over-pcie")
+	p.JobStarted("cross-numa", []int{1, 2})
+	busy = sampleN(t, p, 1)
+	assert.InDelta(t, 70, busy["sim-gpu-2"].Utilization, 3)
+
+	p.JobStopped("nvlinked")
+	p.JobStopped("cross-numa")
+	cooled := sampleN(t, p, 20)
+	assert.Zero(t, cooled["sim-gpu-0"].Utilization)
+	assert.Less(t, cooled["sim-gpu-0"].Temperature, 40.0)
+}
+
+func TestSimulatedFaults(t *testing.T) {
+	p := newSimulatedNode(t)
+
+	require.NoError(t, p.InjectECCErrors(0, 12))
+	require.NoError(t, p.SetThermalThrottle(1, true))
+	require.NoError(t, p.FallOffBus(3))
+	assert.Error(t, p.FallOffBus(7))
+
+	samples := sampleN(t, p, 20)
+	require.Len(t, samples, 3)
+	assert.Equal(t, 12, samples["sim-gpu-0"].ErrorCount)
+	assert.True(t, samples["sim-gpu-1"].ThermalThrottle)
+	assert.Greater(t, samples["sim-gpu-1"].Temperature, 85.0)
+
+	gpus, err := p.Discover(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, gpus, 3)
+
+	// The scheduler's health rules treat the samples as failures
+	gpu := &models.GPU{}
+	gpu.ApplyMetrics(samples["sim-gpu-0"])
+	assert.Equal(t, models.HealthUnhealthy, gpu.Health)
+	gpu = &models.GPU{}
+	gpu.ApplyMetrics(samples["sim-gpu-1"])
+	assert.False(t, gpu.IsAvailable())
+}
+
+func TestSimulatedScheduledFaults(t *testing.T) {
+	p := newSimulatedNode(t)
+	now := p.started
+	p.now = func() time.Time { return now }
+
+	require.NoError(t, p.ScheduleFault(SimulatedFault{GPU: 2, Kind: FaultFallOffBus, After: time.Minute}))
+	assert.Error(t, p.ScheduleFault(SimulatedFault{GPU: 2, Kind: "meltdown"}))
+
+	gpus, _ := p.Discover(context.Background())
+	assert.Len(t, gpus, 4)
+
+	now = now.Add(2 * time.Minute)
+	gpus, _ = p.Discover(context.Background())
+	assert.Len(t, gpus, 3)
+	for _, gpu := range gpus {
+		assert.NotEqual(t, 2, gpu.Index)
+	}
+}
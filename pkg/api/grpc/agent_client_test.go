@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/agent"
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/scheduler/core"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAgentOverGRPC runs a node agent against the AgentService over TCP:
+// it registers, sends heartbeats and runs the jobs pushed to it until
+// they leave the node
+func TestAgentOverGRPC(t *testing.T) {
+	repo := newMemoryRepository()
+	scheduler := core.NewScheduler(&utils.SchedulerConfig{MaxQueueSize: 10}, repo)
+	server, err := NewServer(NewAgentServer(scheduler, 10*time.Millisecond), NewSchedulerServer(scheduler), &utils.APIConfig{})
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	provider, err := agent.NewSimulatedProviderFromConfig(utils.SimulationConfig{
+		Seed: 1,
+		GPUs: []utils.SimulatedGPUConfig{{Model: "A100", Count: 2}},
+	})
+	require.NoError(t, err)
+	nodeAgent, err := agent.NewWithProvider(utils.AgentConfig{
+		NodeID:               "node-1",
+		Protocol:             agent.ProtocolGRPC,
+		SchedulerGRPCAddress: listener.Addr().String(),
+		HeartbeatInterval:    20,
+		MetricsInterval:      50,
+	}, provider)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, nodeAgent.Run(ctx))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	require.Eventually(t, func() bool {
+		node, err := repo.GetNode(ctx, "node-1")
+		return err == nil && node.TotalGPUs == 2 && !node.LastHeartbeat.IsZero()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The job is pushed to the agent, which runs it on its GPU
+	log := filepath.Join(t.TempDir(), "log")
+	repo.startScript("train", "node-1", "echo $CUDA_VISIBLE_DEVICES >> "+log+
+		"; trap 'echo stopped >> "+log+"; exit 0' TERM; sleep 30 & wait")
+	lines := func() []string {
+		data, _ := os.ReadFile(log)
+		return strings.Fields(string(data))
+	}
+	require.Eventually(t, func() bool {
+		return len(lines()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"0"}, lines())
+
+	// The job is stopped once it leaves the node
+	repo.stopJob("train")
+	require.Eventually(t, func() bool {
+		return len(lines()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"0", "stopped"}, lines())
+
+	gpu, err := repo.GetGPU(ctx, "node-1-gpu-0")
+	require.NoError(t, err)
+	assert.Equal(t, models.GPUA100, gpu.Model)
+}
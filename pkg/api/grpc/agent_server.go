@@ -0,0 +1,230 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	schedulerpb "github.com/azizbahloul/gpu-scheduler/pkg/api/grpc/generated"
+	agentpb "github.com/azizbahloul/gpu-scheduler/pkg/api/grpc/generated/agent"
+	"github.com/azizbahloul/gpu-scheduler/pkg/scheduler/core"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// AgentServer serves the AgentService node agents use to register,
+// send heartbeats and metrics, and receive the jobs placed on their node
+type AgentServer struct {
+	agentpb.UnimplementedAgentServiceServer
+	scheduler *core.Scheduler
+	// assignmentInterval is how often assignment streams check for newly
+	// started jobs
+	assignmentInterval time.Duration
+}
+
+// NewAgentServer creates an AgentService backed by the scheduler
+func NewAgentServer(scheduler *core.Scheduler, assignmentInterval time.Duration) *AgentServer {
+	if assignmentInterval <= 0 {
+		assignmentInterval = time.Second
+	}
+	return &AgentServer{
+		scheduler:          scheduler,
+		assignmentInterval: assignmentInterval,
+	}
+}
+
+// NewServer creates a gRPC server serving the AgentService and the
+// SchedulerService, with TLS when the API config enables it
+func NewServer(agents *AgentServer, schedulerService *SchedulerServer, config *utils.APIConfig) (*grpc.Server, error) {
+	var opts []grpc.ServerOption
+	if config.EnableTLS {
+		creds, err := credentials.NewServerTLSFromFile(config.TLSCertPath, config.TLSKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to load TLS certificate: %v", utils.ErrInvalidConfig, err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	server := grpc.NewServer(opts...)
+	agentpb.RegisterAgentServiceServer(server, agents)
+	schedulerpb.RegisterSchedulerServiceServer(server, schedulerService)
+	return server, nil
+}
+
+// RegisterAgent adds the agent's node and GPUs, or refreshes them when
+// the node is already known
+func (s *AgentServer) RegisterAgent(ctx context.Context, req *agentpb.RegisterAgentRequest) (*agentpb.RegisterAgentResponse, error) {
+	node, err := s.scheduler.RegisterAgent(ctx, registrationFromProto(req))
+	if err != nil {
+		utils.Error("Failed to register agent", zap.String("node_id", req.GetNodeId()), zap.Error(err))
+		return nil, toStatus(err, "failed to register agent")
+	}
+
+	return &agentpb.RegisterAgentResponse{
+		Success: true,
+		Message: fmt.Sprintf("registered %d GPUs", node.TotalGPUs),
+		AgentId: node.ID,
+	}, nil
+}
+
+// Heartbeat answers each heartbeat on the stream with the commands for
+// the agent. Unknown nodes end the stream with NotFound and must
+// register again.
+func (s *AgentServer) Heartbeat(stream agentpb.AgentService_HeartbeatServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		ack, err := s.scheduler.AgentHeartbeat(stream.Context(), heartbeatFromProto(req))
+		if err != nil {
+			return toStatus(err, "failed to record heartbeat")
+		}
+		if err := stream.Send(&agentpb.HeartbeatResponse{
+			Acknowledged: ack.Acknowledged,
+			Commands:     ack.Commands,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// ReportMetrics applies GPU and job metrics reported by the agent
+func (s *AgentServer) ReportMetrics(ctx context.Context, req *agentpb.ReportMetricsRequest) (*agentpb.ReportMetricsResponse, error) {
+	if err := s.scheduler.ReportAgentMetrics(ctx, reportFromProto(req)); err != nil {
+		return nil, toStatus(err, "failed to apply metrics")
+	}
+	return &agentpb.ReportMetricsResponse{Success: true}, nil
+}
+
+// ReceiveJobAssignment pushes the running jobs placed on the agent's node
+// down the stream, each job once and again whenever its GPUs on the node
+// change, and once more marked unassigned when it leaves the node. The
+// agent names its node and the jobs it already runs in the first
+// request; any later request, such as one sent after a job exits, makes
+// the server check for new jobs right away.
+func (s *AgentServer) ReceiveJobAssignment(stream agentpb.AgentService_ReceiveJobAssignmentServer) error {
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	nodeID := first.GetNodeId()
+	if nodeID == "" {
+		return status.Error(codes.InvalidArgument, "node ID is required")
+	}
+
+	wake := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err == nil && req.GetNodeId() != "" && req.GetNodeId() != nodeID {
+				err = status.Errorf(codes.InvalidArgument, "stream belongs to node %s", nodeID)
+			}
+			if err != nil {
+				done <- err
+				return
+			}
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(s.assignmentInterval)
+	defer ticker.Stop()
+
+	// Jobs the agent runs are sent again, or unassigned if they left
+	sent := make(map[string]string)
+	for _, jobID := range first.GetJobIds() {
+		sent[jobID] = unknownGPUs
+	}
+	for {
+		if err := s.pushAssignments(stream, nodeID, sent); err != nil {
+			return err
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case err := <-done:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// unknownGPUs marks jobs the agent runs whose GPUs were not sent on the
+// stream
+const unknownGPUs = "?"
+
+// pushAssignments sends the node's assignments that were not sent on the
+// stream yet, or whose GPUs changed since. sent maps the jobs already
+// sent to their GPUs; jobs no longer assigned are sent as unassigned and
+// forgotten.
+func (s *AgentServer) pushAssignments(stream agentpb.AgentService_ReceiveJobAssignmentServer, nodeID string, sent map[string]string) error {
+	assignments, err := s.scheduler.JobAssignments(stream.Context(), nodeID)
+	if err != nil {
+		return toStatus(err, "failed to list assignments")
+	}
+
+	current := make(map[string]bool, len(assignments))
+	for _, assignment := range assignments {
+		current[assignment.JobID] = true
+		gpus := strings.Join(assignment.GPUIDs, ",")
+		if previous, ok := sent[assignment.JobID]; ok && previous == gpus {
+			continue
+		}
+		if err := stream.Send(assignmentToProto(assignment)); err != nil {
+			return err
+		}
+		sent[assignment.JobID] = gpus
+		utils.Debug("Pushed job assignment",
+			zap.String("node_id", nodeID),
+			zap.String("job_id", assignment.JobID))
+	}
+	for id := range sent {
+		if current[id] {
+			continue
+		}
+		if err := stream.Send(&agentpb.JobAssignmentResponse{JobId: id, Unassigned: true}); err != nil {
+			return err
+		}
+		delete(sent, id)
+	}
+	return nil
+}
+
+// toStatus maps scheduler errors to gRPC status codes. Unexpected errors
+// are reported with the given message instead of their details.
+func toStatus(err error, message string) error {
+	switch {
+	case errors.Is(err, utils.ErrNodeNotFound):
+		return status.Error(codes.NotFound, "node not found")
+	case errors.Is(err, utils.ErrTenantNotFound):
+		return status.Error(codes.NotFound, "tenant not found")
+	case errors.Is(err, utils.ErrInvalidAgentRequest), errors.Is(err, utils.ErrInvalidTenant):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, message)
+	}
+}
@@ -0,0 +1,383 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	agentpb "github.com/azizbahloul/gpu-scheduler/pkg/api/grpc/generated/agent"
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/scheduler/core"
+	"github.com/azizbahloul/gpu-scheduler/pkg/storage"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// memoryRepository keeps the records the gRPC services touch in memory.
+// Other repository methods are not implemented.
+type memoryRepository struct {
+	storage.Repository
+	mu          sync.Mutex
+	nodes       map[string]*models.Node
+	gpus        map[string]*models.GPU
+	jobs        map[string]*models.Job
+	allocations map[string]*models.Allocation
+	tenants     map[string]*models.Tenant
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		nodes:       make(map[string]*models.Node),
+		gpus:        make(map[string]*models.GPU),
+		jobs:        make(map[string]*models.Job),
+		allocations: make(map[string]*models.Allocation),
+		tenants:     make(map[string]*models.Tenant),
+	}
+}
+
+func (r *memoryRepository) CreateNode(ctx context.Context, node *models.Node) error {
+	return r.UpdateNode(ctx, node)
+}
+
+func (r *memoryRepository) GetNode(ctx context.Context, nodeID string) (*models.Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	node, ok := r.nodes[nodeID]
+	if !ok {
+		return nil, utils.ErrNodeNotFound
+	}
+	copied := *node
+	return &copied, nil
+}
+
+func (r *memoryRepository) UpdateNode(ctx context.Context, node *models.Node) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *node
+	r.nodes[node.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) CreateGPU(ctx context.Context, gpu *models.GPU) error {
+	return r.UpdateGPU(ctx, gpu)
+}
+
+func (r *memoryRepository) GetGPU(ctx context.Context, gpuID string) (*models.GPU, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	gpu, ok := r.gpus[gpuID]
+	if !ok {
+		return nil, utils.ErrGPUNotFound
+	}
+	copied := *gpu
+	return &copied, nil
+}
+
+func (r *memoryRepository) UpdateGPU(ctx context.Context, gpu *models.GPU) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *gpu
+	r.gpus[gpu.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) ListGPUsByNode(ctx context.Context, nodeID string) ([]*models.GPU, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var gpus []*models.GPU
+	for _, gpu := range r.gpus {
+		if gpu.NodeID == nodeID {
+			copied := *gpu
+			gpus = append(gpus, &copied)
+		}
+	}
+	return gpus, nil
+}
+
+func (r *memoryRepository) GetJob(ctx context.Context, jobID string) (*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[jobID]
+	if !ok {
+		return nil, utils.ErrJobNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (r *memoryRepository) ListActiveAllocations(ctx context.Context) ([]*models.Allocation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var allocations []*models.Allocation
+	for _, alloc := range r.allocations {
+		if alloc.State == models.AllocationActive {
+			copied := *alloc
+			allocations = append(allocations, &copied)
+		}
+	}
+	return allocations, nil
+}
+
+func (r *memoryRepository) GetJobAllocations(ctx context.Context, jobID string) ([]*models.Allocation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var allocations []*models.Allocation
+	for _, alloc := range r.allocations {
+		if alloc.JobID == jobID {
+			copied := *alloc
+			allocations = append(allocations, &copied)
+		}
+	}
+	return allocations, nil
+}
+
+func (r *memoryRepository) UpdateAllocation(ctx context.Context, allocation *models.Allocation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *allocation
+	r.allocations[allocation.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tenant, ok := r.tenants[tenantID]
+	if !ok {
+		return nil, utils.ErrTenantNotFound
+	}
+	copied := *tenant
+	return &copied, nil
+}
+
+func (r *memoryRepository) UpdateTenant(ctx context.Context, tenant *models.Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *tenant
+	r.tenants[tenant.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) CreateEvent(ctx context.Context, event *models.Event) error {
+	return nil
+}
+
+// startJob records a job running on the node's first GPU
+func (r *memoryRepository) startJob(jobID, nodeID string) {
+	r.startScript(jobID, nodeID, "train.sh")
+}
+
+// startScript records a job running the script on the node's first GPU
+func (r *memoryRepository) startScript(jobID, nodeID, script string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[jobID] = &models.Job{ID: jobID, State: models.JobStateRunning, Script: script}
+	r.allocations["alloc-"+jobID] = &models.Allocation{
+		ID:          "alloc-" + jobID,
+		JobID:       jobID,
+		NodeID:      nodeID,
+		GPUIDs:      []string{nodeID + "-gpu-0"},
+		State:       models.AllocationActive,
+		AllocatedAt: time.Now(),
+	}
+}
+
+// growJob gives a running job another GPU on the node
+func (r *memoryRepository) growJob(jobID, nodeID, gpuID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.allocations["alloc-grow-"+jobID] = &models.Allocation{
+		ID:          "alloc-grow-" + jobID,
+		JobID:       jobID,
+		NodeID:      nodeID,
+		GPUIDs:      []string{gpuID},
+		State:       models.AllocationActive,
+		AllocatedAt: time.Now(),
+	}
+}
+
+// stopJob completes a job's allocations
+func (r *memoryRepository) stopJob(jobID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, alloc := range r.allocations {
+		if alloc.JobID == jobID {
+			alloc.State = models.AllocationCompleted
+		}
+	}
+}
+
+// startServices serves the gRPC services over an in-memory connection
+// and returns a connection to them
+func startServices(t *testing.T) (*grpc.ClientConn, *memoryRepository) {
+	t.Helper()
+	repo := newMemoryRepository()
+	scheduler := core.NewScheduler(&utils.SchedulerConfig{MaxQueueSize: 10}, repo)
+	server, err := NewServer(NewAgentServer(scheduler, 10*time.Millisecond), NewSchedulerServer(scheduler), &utils.APIConfig{})
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, repo
+}
+
+// startAgentService returns an AgentService client for startServices
+func startAgentService(t *testing.T) (agentpb.AgentServiceClient, *memoryRepository) {
+	t.Helper()
+	conn, repo := startServices(t)
+	return agentpb.NewAgentServiceClient(conn), repo
+}
+
+func registerNode(t *testing.T, client agentpb.AgentServiceClient, nodeID string) {
+	t.Helper()
+	resp, err := client.RegisterAgent(context.Background(), &agentpb.RegisterAgentRequest{
+		NodeId:        nodeID,
+		Hostname:      nodeID + ".cluster",
+		TotalCpuCores: 32,
+		TotalMemoryMb: 256000,
+		Gpus: []*agentpb.GPUInfo{
+			{Index: 0, Model: "NVIDIA A100-SXM4-80GB", MemoryTotalMb: 81920},
+			{Index: 1, Model: "NVIDIA A100-SXM4-80GB", MemoryTotalMb: 81920},
+		},
+		Labels: map[string]string{"zone": "a"},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	assert.Equal(t, nodeID, resp.AgentId)
+}
+
+func TestAgentServiceRegisterAndHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	client, repo := startAgentService(t)
+	registerNode(t, client, "node-1")
+
+	node, err := repo.GetNode(ctx, "node-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, node.TotalGPUs)
+	assert.Equal(t, "a", node.Labels["zone"])
+	gpu, err := repo.GetGPU(ctx, "node-1-gpu-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.GPUA100, gpu.Model)
+
+	_, err = client.RegisterAgent(ctx, &agentpb.RegisterAgentRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	stream, err := client.Heartbeat(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&agentpb.HeartbeatRequest{
+		NodeId:         "node-1",
+		CpuUtilization: 40,
+		GpuMetrics: []*agentpb.GPUMetrics{
+			{GpuId: "node-1-gpu-0", Utilization: 93, Temperature: 78, PowerUsage: 380},
+		},
+	}))
+	ack, err := stream.Recv()
+	require.NoError(t, err)
+	assert.True(t, ack.Acknowledged)
+
+	node, _ = repo.GetNode(ctx, "node-1")
+	assert.Equal(t, 40.0, node.CPUUtilization)
+	assert.WithinDuration(t, time.Now(), node.LastHeartbeat, time.Second)
+	gpu, _ = repo.GetGPU(ctx, "node-1-gpu-0")
+	assert.Equal(t, 93.0, gpu.Utilization)
+	assert.Equal(t, models.HealthDegraded, gpu.Health)
+
+	// An unknown node ends the stream and must register again
+	require.NoError(t, stream.Send(&agentpb.HeartbeatRequest{NodeId: "node-9"}))
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAgentServicePushesAssignments(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, repo := startAgentService(t)
+	registerNode(t, client, "node-1")
+	registerNode(t, client, "node-2")
+	repo.startJob("job-1", "node-1")
+
+	stream, err := client.ReceiveJobAssignment(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&agentpb.JobAssignmentRequest{NodeId: "node-1", Status: "ready"}))
+
+	assignment, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "job-1", assignment.JobId)
+	assert.Equal(t, "alloc-job-1", assignment.AllocationId)
+	assert.Equal(t, []string{"node-1-gpu-0"}, assignment.GpuIds)
+	assert.Equal(t, "train.sh", assignment.Script)
+
+	// Jobs started later are pushed to their node only, once each
+	repo.startJob("job-2", "node-2")
+	repo.startJob("job-3", "node-1")
+	assignment, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "job-3", assignment.JobId)
+
+	// A job grown on the node is pushed again with all of its GPUs there
+	repo.growJob("job-1", "node-1", "node-1-gpu-1")
+	assignment, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "job-1", assignment.JobId)
+	assert.Equal(t, "alloc-job-1", assignment.AllocationId)
+	assert.ElementsMatch(t, []string{"node-1-gpu-0", "node-1-gpu-1"}, assignment.GpuIds)
+
+	// A job leaving the node is pushed once more as unassigned
+	repo.stopJob("job-3")
+	assignment, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "job-3", assignment.JobId)
+	assert.True(t, assignment.Unassigned)
+
+	_, err = client.ReportMetrics(ctx, &agentpb.ReportMetricsRequest{
+		NodeId:     "node-1",
+		GpuMetrics: []*agentpb.GPUMetrics{{GpuId: "node-1-gpu-1", ErrorCount: 12}},
+	})
+	require.NoError(t, err)
+	gpu, _ := repo.GetGPU(ctx, "node-1-gpu-1")
+	assert.Equal(t, models.HealthUnhealthy, gpu.Health)
+
+	_, err = client.ReportMetrics(ctx, &agentpb.ReportMetricsRequest{NodeId: "node-9"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAgentServiceUnassignsJobsLeftWithoutStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, repo := startAgentService(t)
+	registerNode(t, client, "node-1")
+	repo.startJob("job-1", "node-1")
+
+	// The agent still runs job-2, which left the node before it connected
+	stream, err := client.ReceiveJobAssignment(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&agentpb.JobAssignmentRequest{NodeId: "node-1", JobIds: []string{"job-1", "job-2"}}))
+
+	assignment, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "job-1", assignment.JobId)
+	assert.False(t, assignment.Unassigned)
+	assert.Equal(t, []string{"node-1-gpu-0"}, assignment.GpuIds)
+
+	assignment, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "job-2", assignment.JobId)
+	assert.True(t, assignment.Unassigned)
+}
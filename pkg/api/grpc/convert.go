@@ -0,0 +1,112 @@
+package grpc
+
+import (
+	"time"
+
+	agentpb "github.com/azizbahloul/gpu-scheduler/pkg/api/grpc/generated/agent"
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The functions below translate between AgentService messages and the
+// models the scheduler works with
+
+func registrationFromProto(req *agentpb.RegisterAgentRequest) *models.AgentRegistration {
+	reg := &models.AgentRegistration{
+		NodeID:        req.GetNodeId(),
+		Hostname:      req.GetHostname(),
+		IPAddress:     req.GetIpAddress(),
+		TotalGPUs:     int(req.GetTotalGpus()),
+		TotalCPUCores: int(req.GetTotalCpuCores()),
+		TotalMemoryMB: req.GetTotalMemoryMb(),
+		Labels:        req.GetLabels(),
+	}
+	for _, gpu := range req.GetGpus() {
+		reg.GPUs = append(reg.GPUs, models.GPUInfo{
+			GPUID:             gpu.GetGpuId(),
+			Index:             int(gpu.GetIndex()),
+			Model:             gpu.GetModel(),
+			MemoryTotalMB:     gpu.GetMemoryTotalMb(),
+			ComputeCapability: gpu.GetComputeCapability(),
+			CUDACores:         int(gpu.GetCudaCores()),
+			TensorCores:       int(gpu.GetTensorCores()),
+		})
+	}
+	return reg
+}
+
+func heartbeatFromProto(req *agentpb.HeartbeatRequest) *models.NodeHeartbeat {
+	return &models.NodeHeartbeat{
+		NodeID:            req.GetNodeId(),
+		Timestamp:         timeFromProto(req.GetTimestamp()),
+		GPUMetrics:        gpuMetricsFromProto(req.GetGpuMetrics()),
+		CPUUtilization:    req.GetCpuUtilization(),
+		MemoryUtilization: req.GetMemoryUtilization(),
+		ActiveJobs:        int(req.GetActiveJobs()),
+	}
+}
+
+func reportFromProto(req *agentpb.ReportMetricsRequest) *models.MetricsReport {
+	report := &models.MetricsReport{
+		NodeID:     req.GetNodeId(),
+		GPUMetrics: gpuMetricsFromProto(req.GetGpuMetrics()),
+		Timestamp:  timeFromProto(req.GetTimestamp()),
+	}
+	for _, job := range req.GetJobMetrics() {
+		metrics := models.JobMetrics{
+			JobID:          job.GetJobId(),
+			State:          models.JobState(job.GetState()),
+			GPUUtilization: job.GetGpuUtilization(),
+			CPUUtilization: job.GetCpuUtilization(),
+			MemoryUsedMB:   job.GetMemoryUsedMb(),
+			ExitCode:       job.GetExitCode(),
+			ErrorMessage:   job.GetErrorMessage(),
+		}
+		if job.GetStartedAt() != nil {
+			startedAt := job.GetStartedAt().AsTime()
+			metrics.StartedAt = &startedAt
+		}
+		report.JobMetrics = append(report.JobMetrics, metrics)
+	}
+	return report
+}
+
+func gpuMetricsFromProto(samples []*agentpb.GPUMetrics) []models.GPUMetrics {
+	metrics := make([]models.GPUMetrics, 0, len(samples))
+	for _, sample := range samples {
+		metrics = append(metrics, models.GPUMetrics{
+			GPUID:           sample.GetGpuId(),
+			Utilization:     sample.GetUtilization(),
+			Temperature:     sample.GetTemperature(),
+			PowerUsage:      sample.GetPowerUsage(),
+			MemoryUsedMB:    sample.GetMemoryUsedMb(),
+			MemoryFreeMB:    sample.GetMemoryFreeMb(),
+			ThermalThrottle: sample.GetThermalThrottle(),
+			ErrorCount:      int(sample.GetErrorCount()),
+		})
+	}
+	return metrics
+}
+
+func assignmentToProto(a *models.JobAssignment) *agentpb.JobAssignmentResponse {
+	return &agentpb.JobAssignmentResponse{
+		JobId:        a.JobID,
+		AllocationId: a.AllocationID,
+		GpuIds:       a.GPUIDs,
+		CpuCores:     int32(a.CPUCores),
+		MemoryMb:     a.MemoryMB,
+		Image:        a.Image,
+		Command:      a.Command,
+		Args:         a.Args,
+		Environment:  a.Environment,
+		Script:       a.Script,
+	}
+}
+
+// timeFromProto returns the zero time for a missing timestamp
+func timeFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
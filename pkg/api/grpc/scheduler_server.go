@@ -0,0 +1,66 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	schedulerpb "github.com/azizbahloul/gpu-scheduler/pkg/api/grpc/generated"
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/scheduler/core"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SchedulerServer serves the SchedulerService. Only UpdateTenantQuota is
+// served; jobs and cluster status go through the REST API, and the other
+// RPCs return Unimplemented.
+type SchedulerServer struct {
+	schedulerpb.UnimplementedSchedulerServiceServer
+	scheduler *core.Scheduler
+}
+
+// NewSchedulerServer creates a SchedulerService backed by the scheduler
+func NewSchedulerServer(scheduler *core.Scheduler) *SchedulerServer {
+	return &SchedulerServer{scheduler: scheduler}
+}
+
+// UpdateTenantQuota changes a tenant's limits. Proto3 cannot tell an
+// unset limit from zero, so limits left at zero are unchanged.
+func (s *SchedulerServer) UpdateTenantQuota(ctx context.Context, req *schedulerpb.UpdateTenantQuotaRequest) (*schedulerpb.UpdateTenantQuotaResponse, error) {
+	if req.GetTenantId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "tenant_id is required")
+	}
+
+	tenant, err := s.scheduler.UpdateTenant(ctx, req.GetTenantId(), quotaUpdateFromProto(req))
+	if err != nil {
+		utils.Error("Failed to update tenant quota", zap.String("tenant_id", req.GetTenantId()), zap.Error(err))
+		return nil, toStatus(err, "failed to update tenant quota")
+	}
+
+	return &schedulerpb.UpdateTenantQuotaResponse{
+		Success: true,
+		Message: fmt.Sprintf("tenant %s may use %d GPUs and run %d jobs", tenant.ID, tenant.MaxGPUs, tenant.MaxConcurrentJobs),
+	}, nil
+}
+
+func quotaUpdateFromProto(req *schedulerpb.UpdateTenantQuotaRequest) *models.TenantUpdate {
+	update := &models.TenantUpdate{}
+	if v := int(req.GetMaxGpus()); v != 0 {
+		update.MaxGPUs = &v
+	}
+	if v := req.GetMaxGpuMemoryMb(); v != 0 {
+		update.MaxGPUMemoryMB = &v
+	}
+	if v := int(req.GetMaxCpuCores()); v != 0 {
+		update.MaxCPUCores = &v
+	}
+	if v := req.GetMaxMemoryMb(); v != 0 {
+		update.MaxMemoryMB = &v
+	}
+	if v := int(req.GetMaxConcurrentJobs()); v != 0 {
+		update.MaxConcurrentJobs = &v
+	}
+	return update
+}
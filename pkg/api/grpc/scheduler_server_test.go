@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	schedulerpb "github.com/azizbahloul/gpu-scheduler/pkg/api/grpc/generated"
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateTenantQuota(t *testing.T) {
+	ctx := context.Background()
+	conn, repo := startServices(t)
+	client := schedulerpb.NewSchedulerServiceClient(conn)
+	require.NoError(t, repo.UpdateTenant(ctx, &models.Tenant{
+		ID: "tenant-1", MaxGPUs: 4, MaxCPUCores: 32, MaxMemoryMB: 128000, MaxConcurrentJobs: 5, Active: true,
+	}))
+
+	// Limits left at zero keep their value
+	resp, err := client.UpdateTenantQuota(ctx, &schedulerpb.UpdateTenantQuotaRequest{
+		TenantId: "tenant-1", MaxGpus: 8, MaxConcurrentJobs: 10,
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	tenant, _ := repo.GetTenant(ctx, "tenant-1")
+	assert.Equal(t, 8, tenant.MaxGPUs)
+	assert.Equal(t, 10, tenant.MaxConcurrentJobs)
+	assert.Equal(t, 32, tenant.MaxCPUCores)
+	assert.Equal(t, int64(128000), tenant.MaxMemoryMB)
+
+	_, err = client.UpdateTenantQuota(ctx, &schedulerpb.UpdateTenantQuotaRequest{TenantId: "tenant-1", MaxGpus: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.UpdateTenantQuota(ctx, &schedulerpb.UpdateTenantQuotaRequest{MaxGpus: 8})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.UpdateTenantQuota(ctx, &schedulerpb.UpdateTenantQuotaRequest{TenantId: "tenant-9", MaxGpus: 8})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Job management is left to the REST API
+	_, err = client.SubmitJob(ctx, &schedulerpb.SubmitJobRequest{TenantId: "tenant-1"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
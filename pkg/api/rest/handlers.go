@@ -2,6 +2,7 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -29,36 +30,46 @@ func NewHandlers(scheduler *core.Scheduler, storage storage.Repository) *Handler
 	}
 }
 
-// SubmitJobHandler handles job submissions
-func (h *Handlers) SubmitJobHandler(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		TenantID         string            `json:"tenant_id"`
-		Name             string            `json:"name"`
-		Priority         int               `json:"priority"`
-		GPUCount         int               `json:"gpu_count"`
-		GPUMemoryMB      int64             `json:"gpu_memory_mb"`
-		CPUCores         int               `json:"cpu_cores"`
-		MemoryMB         int64             `json:"memory_mb"`
-		Script           string            `json:"script"`
-		Environment      map[string]string `json:"environment"`
-		Image            string            `json:"image"`
-		Command          []string          `json:"command"`
-		Args             []string          `json:"args"`
-		GangScheduling   bool              `json:"gang_scheduling"`
-		MaxRuntimeMinutes int              `json:"max_runtime_minutes"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
+// jobRequest is the job description accepted by the submit endpoints
+type jobRequest struct {
+	TenantID         string            `json:"tenant_id"`
+	Name             string            `json:"name"`
+	Priority         int               `json:"priority"`
+	GPUCount         int               `json:"gpu_count"`
+	MinGPUs          int               `json:"min_gpus"`
+	MaxGPUs          int               `json:"max_gpus"`
+	GPUMemoryMB      int64             `json:"gpu_memory_mb"`
+	CPUCores         int               `json:"cpu_cores"`
+	MemoryMB         int64             `json:"memory_mb"`
+	Script           string            `json:"script"`
+	Environment      map[string]string `json:"environment"`
+	Image            string            `json:"image"`
+	Command          []string          `json:"command"`
+	Args             []string          `json:"args"`
+	GangScheduling   bool              `json:"gang_scheduling"`
+	MaxRuntimeMinutes int              `json:"max_runtime_minutes"`
+	EstimatedDurationMinutes int       `json:"estimated_duration_minutes"`
+	Deadline         *time.Time        `json:"deadline"`
+	Labels           map[string]string `json:"labels"`
+	Affinity         *models.Affinity  `json:"affinity"`
+	ExpectedPowerW   float64           `json:"expected_power_w"`
+	GPUSlice         *models.SliceRequest `json:"gpu_slice"`
+	Dependencies     []models.JobDependency `json:"dependencies"`
+	Array            string            `json:"array"`
+	RetryPolicy      *models.RetryPolicy `json:"retry_policy"`
+	ReservationID    string            `json:"reservation_id"`
+	Queue            string            `json:"queue"`
+}
 
+func (req *jobRequest) toJob() (*models.Job, error) {
 	job := &models.Job{
 		ID:          generateJobID(),
 		TenantID:    req.TenantID,
 		Name:        req.Name,
 		Priority:    req.Priority,
 		GPUCount:    req.GPUCount,
+		MinGPUs:     req.MinGPUs,
+		MaxGPUs:     req.MaxGPUs,
 		GPUMemoryMB: req.GPUMemoryMB,
 		CPUCores:    req.CPUCores,
 		MemoryMB:    req.MemoryMB,
@@ -69,6 +80,42 @@ func (h *Handlers) SubmitJobHandler(w http.ResponseWriter, r *http.Request) {
 		Args:        req.Args,
 		GangScheduling: req.GangScheduling,
 		MaxRuntime:  time.Duration(req.MaxRuntimeMinutes) * time.Minute,
+		EstimatedDuration: time.Duration(req.EstimatedDurationMinutes) * time.Minute,
+		Deadline:    req.Deadline,
+		Labels:      req.Labels,
+		Affinity:    req.Affinity,
+		ExpectedPowerW: req.ExpectedPowerW,
+		GPUSlice:    req.GPUSlice,
+		Dependencies: req.Dependencies,
+		RetryPolicy: req.RetryPolicy,
+		ReservationID: req.ReservationID,
+		Queue:       req.Queue,
+	}
+
+	// Arrays use the "start-end%max" form, e.g. "0-499%20"
+	if req.Array != "" {
+		spec, err := models.ParseArraySpec(req.Array)
+		if err != nil {
+			return nil, err
+		}
+		job.Array = spec
+	}
+
+	return job, nil
+}
+
+// SubmitJobHandler handles job submissions
+func (h *Handlers) SubmitJobHandler(w http.ResponseWriter, r *http.Request) {
+	var req jobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	job, err := req.toJob()
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
 	}
 
 	if err := h.scheduler.SubmitJob(r.Context(), job); err != nil {
@@ -78,6 +125,16 @@ func (h *Handlers) SubmitJobHandler(w http.ResponseWriter, r *http.Request) {
 			respondJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
 			return
 		}
+
+		if errors.Is(err, utils.ErrQueueAccessDenied) || errors.Is(err, utils.ErrTenantInactive) {
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, utils.ErrInvalidDependency) || errors.Is(err, utils.ErrReservationNotFound) ||
+			errors.Is(err, utils.ErrReservationEnded) || errors.Is(err, utils.ErrQueueNotFound) || errors.Is(err, utils.ErrQueueLimitExceeded) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
 		
 		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to submit job"})
 		return
@@ -86,10 +143,68 @@ func (h *Handlers) SubmitJobHandler(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusCreated, map[string]interface{}{
 		"job_id":  job.ID,
 		"status":  "submitted",
+		"state":   job.State,
 		"message": "Job submitted successfully",
 	})
 }
 
+// SubmitWorkflowHandler submits a DAG of jobs in one request
+func (h *Handlers) SubmitWorkflowHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TenantID string       `json:"tenant_id"`
+		Name     string       `json:"name"`
+		Jobs     []jobRequest `json:"jobs"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	workflow := &models.Workflow{
+		ID:       generateWorkflowID(),
+		TenantID: req.TenantID,
+		Name:     req.Name,
+	}
+	for i := range req.Jobs {
+		job, err := req.Jobs[i].toJob()
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		workflow.Jobs = append(workflow.Jobs, job)
+	}
+
+	if err := h.scheduler.SubmitWorkflow(r.Context(), workflow); err != nil {
+		utils.Error("Failed to submit workflow", zap.Error(err))
+
+		if utils.IsQuotaExceeded(err) {
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+			return
+		}
+
+		if errors.Is(err, utils.ErrInvalidDependency) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to submit workflow"})
+		return
+	}
+
+	jobs := make(map[string]string, len(workflow.Jobs))
+	for _, job := range workflow.Jobs {
+		jobs[job.Name] = job.ID
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"workflow_id": workflow.ID,
+		"jobs":        jobs,
+		"status":      "submitted",
+		"message":     "Workflow submitted successfully",
+	})
+}
+
 // GetJobStatusHandler returns job status
 func (h *Handlers) GetJobStatusHandler(w http.ResponseWriter, r *http.Request) {
 	jobID := chi.URLParam(r, "jobID")
@@ -167,6 +282,80 @@ func (h *Handlers) CancelJobHandler(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Job cancelled successfully"})
 }
 
+// ExtendJobHandler extends the max runtime of a running job
+func (h *Handlers) ExtendJobHandler(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
+
+	var req struct {
+		Minutes int `json:"minutes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	alloc, err := h.scheduler.ExtendJob(r.Context(), jobID, time.Duration(req.Minutes)*time.Minute)
+	if err != nil {
+		if utils.IsNotFound(err) {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, utils.ErrExtensionDenied) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to extend job", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"job_id":         jobID,
+		"deadline":       alloc.Deadline(),
+		"extended_count": alloc.ExtendedCount,
+	})
+}
+
+// ReportFailureHandler records that the current attempt of a running job
+// failed. The job is retried if its retry policy covers the failure.
+func (h *Handlers) ReportFailureHandler(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
+
+	var req struct {
+		Class    models.FailureClass `json:"class"`
+		Reason   string              `json:"reason"`
+		ExitCode int                 `json:"exit_code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !req.Class.IsValid() {
+		http.Error(w, fmt.Sprintf("Unknown failure class: %s", req.Class), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.scheduler.FailJob(r.Context(), jobID, req.Class, req.Reason, req.ExitCode); err != nil {
+		if utils.IsNotFound(err) {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, utils.ErrInvalidJobState) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to record job failure", http.StatusInternalServerError)
+		return
+	}
+
+	status, err := h.scheduler.GetJobStatus(r.Context(), jobID)
+	if err != nil {
+		http.Error(w, "Failed to get job status", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
+
 // GetClusterStatusHandler returns cluster status
 func (h *Handlers) GetClusterStatusHandler(w http.ResponseWriter, r *http.Request) {
 	nodes, err := h.storage.ListNodes(r.Context())
@@ -189,25 +378,47 @@ func (h *Handlers) GetClusterStatusHandler(w http.ResponseWriter, r *http.Reques
 
 	allJobs, _ := h.storage.ListJobs(r.Context(), 10000, 0)
 	pendingCount := 0
+	waitingCount := 0
 	runningCount := 0
 
 	for _, job := range allJobs {
 		if job.State == models.JobStatePending {
 			pendingCount++
+		} else if job.State == models.JobStateWaiting {
+			waitingCount++
 		} else if job.State == models.JobStateRunning {
 			runningCount++
 		}
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
+	status := map[string]interface{}{
 		"total_gpus":      totalGPUs,
 		"available_gpus":  availableGPUs,
 		"total_nodes":     len(nodes),
 		"online_nodes":    onlineNodes,
 		"total_jobs":      len(allJobs),
 		"pending_jobs":    pendingCount,
+		"waiting_jobs":    waitingCount,
 		"running_jobs":    runningCount,
-	})
+	}
+
+	if h.scheduler != nil {
+		power, err := h.scheduler.PowerStatus(r.Context())
+		if err != nil {
+			http.Error(w, "Failed to get power status", http.StatusInternalServerError)
+			return
+		}
+		status["power"] = power
+
+		queues, err := h.scheduler.QueueStatus(r.Context())
+		if err != nil {
+			http.Error(w, "Failed to get queue status", http.StatusInternalServerError)
+			return
+		}
+		status["queues"] = queues
+	}
+
+	respondJSON(w, http.StatusOK, status)
 }
 
 // CreateTenantHandler creates a new tenant
@@ -218,10 +429,25 @@ func (h *Handlers) CreateTenantHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := tenant.Validate(); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if tenant.QuotaGroupID != "" {
+		if _, err := h.storage.GetQuotaGroup(r.Context(), tenant.QuotaGroupID); err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
 	tenant.ID = generateTenantID()
 	tenant.Active = true
 	tenant.CreatedAt = time.Now()
 	tenant.UpdatedAt = time.Now()
+	if tenant.Budget != nil {
+		tenant.Budget.Reset(tenant.CreatedAt)
+	}
 
 	if err := h.storage.CreateTenant(r.Context(), &tenant); err != nil {
 		http.Error(w, "Failed to create tenant", http.StatusInternalServerError)
@@ -231,24 +457,782 @@ func (h *Handlers) CreateTenantHandler(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusCreated, tenant)
 }
 
-// HealthCheckHandler returns health status
-func (h *Handlers) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	if err := h.storage.Ping(r.Context()); err != nil {
-		http.Error(w, "Unhealthy", http.StatusServiceUnavailable)
+// ListTenantsHandler returns all tenants
+func (h *Handlers) ListTenantsHandler(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.scheduler.ListTenants(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to list tenants", http.StatusInternalServerError)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{"status": "healthy"})
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"tenants": tenants,
+		"total":   len(tenants),
+	})
 }
 
-func respondJSON(w http.ResponseWriter, code int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(data)
+// GetTenantHandler returns a tenant with its limits and current usage
+func (h *Handlers) GetTenantHandler(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.scheduler.GetTenant(r.Context(), chi.URLParam(r, "tenantID"))
+	if err != nil {
+		if errors.Is(err, utils.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get tenant", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tenant)
 }
 
-func generateJobID() string {
-	return fmt.Sprintf("job-%d", time.Now().UnixNano())
+// UpdateTenantHandler changes the tenant settings present in the body
+func (h *Handlers) UpdateTenantHandler(w http.ResponseWriter, r *http.Request) {
+	var update models.TenantUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tenant, err := h.scheduler.UpdateTenant(r.Context(), chi.URLParam(r, "tenantID"), &update)
+	if err != nil {
+		utils.Error("Failed to update tenant", zap.Error(err))
+
+		if errors.Is(err, utils.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, utils.ErrInvalidTenant) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to update tenant"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tenant)
+}
+
+// DeactivateTenantHandler stops a tenant from submitting jobs. With
+// drain=true its active jobs are cancelled as well.
+func (h *Handlers) DeactivateTenantHandler(w http.ResponseWriter, r *http.Request) {
+	drain := false
+	if v := r.URL.Query().Get("drain"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "Invalid drain flag", http.StatusBadRequest)
+			return
+		}
+		drain = parsed
+	}
+
+	tenant, cancelled, err := h.scheduler.DeactivateTenant(r.Context(), chi.URLParam(r, "tenantID"), drain)
+	if err != nil {
+		if errors.Is(err, utils.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to deactivate tenant", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"tenant":         tenant,
+		"cancelled_jobs": cancelled,
+	})
+}
+
+// ActivateTenantHandler lets an inactive tenant submit and run jobs again
+func (h *Handlers) ActivateTenantHandler(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.scheduler.ActivateTenant(r.Context(), chi.URLParam(r, "tenantID"))
+	if err != nil {
+		if errors.Is(err, utils.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to activate tenant", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tenant)
+}
+
+// DeleteTenantHandler removes a tenant without active jobs, cron jobs or
+// reservations
+func (h *Handlers) DeleteTenantHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.scheduler.DeleteTenant(r.Context(), chi.URLParam(r, "tenantID")); err != nil {
+		if errors.Is(err, utils.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, utils.ErrTenantInUse) {
+			respondJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
+		http.Error(w, "Failed to delete tenant", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Tenant deleted successfully"})
+}
+
+// CreateReservationHandler reserves GPUs for a tenant over a time window
+func (h *Handlers) CreateReservationHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TenantID  string          `json:"tenant_id"`
+		Name      string          `json:"name"`
+		GPUCount  int             `json:"gpu_count"`
+		NodeIDs   []string        `json:"node_ids"`
+		GPUModel  models.GPUModel `json:"gpu_model"`
+		StartTime time.Time       `json:"start_time"`
+		EndTime   time.Time       `json:"end_time"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	reservation := &models.Reservation{
+		TenantID:  req.TenantID,
+		Name:      req.Name,
+		GPUCount:  req.GPUCount,
+		NodeIDs:   req.NodeIDs,
+		GPUModel:  req.GPUModel,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}
+	if err := reservation.Validate(); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.scheduler.CreateReservation(r.Context(), reservation); err != nil {
+		utils.Error("Failed to create reservation", zap.Error(err))
+
+		if utils.IsNotFound(err) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, utils.ErrReservationConflict) {
+			respondJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to create reservation"})
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, reservation)
+}
+
+// ListReservationsHandler lists reservations, optionally for one tenant
+func (h *Handlers) ListReservationsHandler(w http.ResponseWriter, r *http.Request) {
+	reservations, err := h.scheduler.ListReservations(r.Context(), r.URL.Query().Get("tenant_id"))
+	if err != nil {
+		http.Error(w, "Failed to list reservations", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"reservations": reservations,
+		"total":        len(reservations),
+	})
+}
+
+// GetReservationHandler returns a reservation
+func (h *Handlers) GetReservationHandler(w http.ResponseWriter, r *http.Request) {
+	reservation, err := h.scheduler.GetReservation(r.Context(), chi.URLParam(r, "reservationID"))
+	if err != nil {
+		if utils.IsNotFound(err) {
+			http.Error(w, "Reservation not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get reservation", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, reservation)
+}
+
+// DeleteReservationHandler releases a reservation
+func (h *Handlers) DeleteReservationHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.scheduler.DeleteReservation(r.Context(), chi.URLParam(r, "reservationID")); err != nil {
+		if utils.IsNotFound(err) {
+			http.Error(w, "Reservation not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete reservation", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Reservation deleted successfully"})
+}
+
+// CreateCronJobHandler creates a job template submitted on a cron schedule
+func (h *Handlers) CreateCronJobHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TenantID          string                   `json:"tenant_id"`
+		Name              string                   `json:"name"`
+		Schedule          string                   `json:"schedule"`
+		Timezone          string                   `json:"timezone"`
+		ConcurrencyPolicy models.ConcurrencyPolicy `json:"concurrency_policy"`
+		MissedRunPolicy   models.MissedRunPolicy   `json:"missed_run_policy"`
+		Job               jobRequest               `json:"job"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	template, err := req.Job.toJob()
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	// Each run gets its own ID when it is submitted
+	template.ID = ""
+
+	cronJob := &models.CronJob{
+		TenantID:          req.TenantID,
+		Name:              req.Name,
+		Schedule:          req.Schedule,
+		Timezone:          req.Timezone,
+		ConcurrencyPolicy: req.ConcurrencyPolicy,
+		MissedRunPolicy:   req.MissedRunPolicy,
+		Template:          template,
+	}
+	if err := cronJob.Validate(); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.scheduler.CreateCronJob(r.Context(), cronJob); err != nil {
+		utils.Error("Failed to create cron job", zap.Error(err))
+
+		if errors.Is(err, utils.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, cronJob)
+}
+
+// ListCronJobsHandler lists cron jobs, optionally for one tenant
+func (h *Handlers) ListCronJobsHandler(w http.ResponseWriter, r *http.Request) {
+	cronJobs, err := h.scheduler.ListCronJobs(r.Context(), r.URL.Query().Get("tenant_id"))
+	if err != nil {
+		http.Error(w, "Failed to list cron jobs", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"cron_jobs": cronJobs,
+		"total":     len(cronJobs),
+	})
+}
+
+// GetCronJobHandler returns a cron job
+func (h *Handlers) GetCronJobHandler(w http.ResponseWriter, r *http.Request) {
+	cronJob, err := h.scheduler.GetCronJob(r.Context(), chi.URLParam(r, "cronJobID"))
+	if err != nil {
+		if utils.IsNotFound(err) {
+			http.Error(w, "Cron job not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get cron job", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, cronJob)
+}
+
+// DeleteCronJobHandler stops a cron job
+func (h *Handlers) DeleteCronJobHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.scheduler.DeleteCronJob(r.Context(), chi.URLParam(r, "cronJobID")); err != nil {
+		if utils.IsNotFound(err) {
+			http.Error(w, "Cron job not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete cron job", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Cron job deleted successfully"})
+}
+
+// ListCronRunsHandler returns a cron job's run history, most recent first
+func (h *Handlers) ListCronRunsHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	runs, err := h.scheduler.ListCronRuns(r.Context(), chi.URLParam(r, "cronJobID"), limit)
+	if err != nil {
+		if utils.IsNotFound(err) {
+			http.Error(w, "Cron job not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to list cron runs", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"runs":  runs,
+		"total": len(runs),
+	})
+}
+
+// quotaGroupRequest carries a quota group's name, place in the tree and
+// limits
+type quotaGroupRequest struct {
+	Name              string                `json:"name"`
+	Kind              models.QuotaGroupKind `json:"kind"`
+	ParentID          string                `json:"parent_id"`
+	MaxGPUs           int                   `json:"max_gpus"`
+	MaxGPUMemoryMB    int64                 `json:"max_gpu_memory_mb"`
+	MaxCPUCores       int                   `json:"max_cpu_cores"`
+	MaxMemoryMB       int64                 `json:"max_memory_mb"`
+	MaxConcurrentJobs int                   `json:"max_concurrent_jobs"`
+}
+
+func (req *quotaGroupRequest) toQuotaGroup() *models.QuotaGroup {
+	return &models.QuotaGroup{
+		Name:              req.Name,
+		Kind:              req.Kind,
+		ParentID:          req.ParentID,
+		MaxGPUs:           req.MaxGPUs,
+		MaxGPUMemoryMB:    req.MaxGPUMemoryMB,
+		MaxCPUCores:       req.MaxCPUCores,
+		MaxMemoryMB:       req.MaxMemoryMB,
+		MaxConcurrentJobs: req.MaxConcurrentJobs,
+	}
+}
+
+// CreateQuotaGroupHandler adds an organization, team or project to the
+// quota tree
+func (h *Handlers) CreateQuotaGroupHandler(w http.ResponseWriter, r *http.Request) {
+	var req quotaGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	group := req.toQuotaGroup()
+	if err := h.scheduler.CreateQuotaGroup(r.Context(), group); err != nil {
+		utils.Error("Failed to create quota group", zap.Error(err))
+
+		if errors.Is(err, utils.ErrInvalidQuotaGroup) || utils.IsNotFound(err) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to create quota group"})
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, group)
+}
+
+// ListQuotaGroupsHandler returns the quota tree with the usage of every
+// group
+func (h *Handlers) ListQuotaGroupsHandler(w http.ResponseWriter, r *http.Request) {
+	roots, err := h.scheduler.QuotaTree(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to list quota groups", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"quota_groups": roots,
+		"total":        len(roots),
+	})
+}
+
+// GetQuotaGroupHandler returns a quota group with its subtree and usage
+func (h *Handlers) GetQuotaGroupHandler(w http.ResponseWriter, r *http.Request) {
+	group, err := h.scheduler.GetQuotaGroup(r.Context(), chi.URLParam(r, "groupID"))
+	if err != nil {
+		if utils.IsNotFound(err) {
+			http.Error(w, "Quota group not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get quota group", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+// UpdateQuotaGroupHandler changes a quota group's name and limits
+func (h *Handlers) UpdateQuotaGroupHandler(w http.ResponseWriter, r *http.Request) {
+	var req quotaGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	update := req.toQuotaGroup()
+	update.ID = chi.URLParam(r, "groupID")
+	group, err := h.scheduler.UpdateQuotaGroup(r.Context(), update)
+	if err != nil {
+		utils.Error("Failed to update quota group", zap.Error(err))
+
+		if utils.IsNotFound(err) {
+			http.Error(w, "Quota group not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, utils.ErrInvalidQuotaGroup) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to update quota group"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+// DeleteQuotaGroupHandler removes a quota group without members
+func (h *Handlers) DeleteQuotaGroupHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.scheduler.DeleteQuotaGroup(r.Context(), chi.URLParam(r, "groupID")); err != nil {
+		if utils.IsNotFound(err) {
+			http.Error(w, "Quota group not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, utils.ErrQuotaGroupInUse) {
+			respondJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
+		http.Error(w, "Failed to delete quota group", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Quota group deleted successfully"})
+}
+
+// AssignQuotaGroupHandler moves a tenant into a quota group, or out of
+// the tree when quota_group_id is empty
+func (h *Handlers) AssignQuotaGroupHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		QuotaGroupID string `json:"quota_group_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tenant, err := h.scheduler.AssignQuotaGroup(r.Context(), chi.URLParam(r, "tenantID"), req.QuotaGroupID)
+	if err != nil {
+		if errors.Is(err, utils.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, utils.ErrQuotaGroupNotFound) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		http.Error(w, "Failed to assign quota group", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tenant)
+}
+
+// GetTenantBudgetHandler reports a tenant's remaining GPU-hour budget
+// and the limits in effect now
+func (h *Handlers) GetTenantBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := h.scheduler.TenantBudget(r.Context(), chi.URLParam(r, "tenantID"))
+	if err != nil {
+		if errors.Is(err, utils.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get tenant budget", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
+
+// SetTenantBudgetHandler replaces a tenant's GPU-hour budget and quota
+// profiles
+func (h *Handlers) SetTenantBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Budget        *models.GPUHourBudget `json:"budget"`
+		QuotaProfiles []models.QuotaProfile `json:"quota_profiles"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tenantID := chi.URLParam(r, "tenantID")
+	if _, err := h.scheduler.SetTenantBudget(r.Context(), tenantID, req.Budget, req.QuotaProfiles); err != nil {
+		if errors.Is(err, utils.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, utils.ErrInvalidBudget) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		http.Error(w, "Failed to set tenant budget", http.StatusInternalServerError)
+		return
+	}
+
+	h.GetTenantBudgetHandler(w, r)
+}
+
+// GetInvoiceHandler exports a tenant's invoice for a period as JSON or,
+// with format=csv, as CSV line items. The period defaults to the current
+// month.
+func (h *Handlers) GetInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseInvoicePeriod(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		http.Error(w, "Format must be json or csv", http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := h.scheduler.Invoice(r.Context(), chi.URLParam(r, "tenantID"), from, to)
+	if err != nil {
+		if errors.Is(err, utils.ErrTenantNotFound) {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, utils.ErrInvalidInvoicePeriod) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		http.Error(w, "Failed to build invoice", http.StatusInternalServerError)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"invoice-%s-%s.csv\"",
+			invoice.TenantID, from.Format("2006-01-02")))
+		if err := invoice.WriteCSV(w); err != nil {
+			utils.Error("Failed to write invoice", zap.Error(err))
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, invoice)
+}
+
+// parseInvoicePeriod reads the invoice period from either month
+// (YYYY-MM) or from and to (RFC3339 or YYYY-MM-DD), defaulting to the
+// current month
+func parseInvoicePeriod(r *http.Request) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+	if month := query.Get("month"); month != "" {
+		from, err := time.Parse("2006-01", month)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("month must be YYYY-MM")
+		}
+		return from, from.AddDate(0, 1, 0), nil
+	}
+
+	from := models.BudgetMonthly.Start(time.Now())
+	to := models.BudgetMonthly.Next(from)
+	for _, bound := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := query.Get(bound.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			if parsed, err = time.Parse("2006-01-02", raw); err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("%s must be RFC3339 or YYYY-MM-DD", bound.name)
+			}
+		}
+		*bound.value = parsed
+	}
+	return from, to, nil
+}
+
+// ListNodesHandler returns the online nodes with their GPUs
+func (h *Handlers) ListNodesHandler(w http.ResponseWriter, r *http.Request) {
+	nodes, err := h.storage.ListNodes(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to list nodes", http.StatusInternalServerError)
+		return
+	}
+
+	type nodeResponse struct {
+		*models.Node
+		GPUs []*models.GPU `json:"gpus"`
+	}
+	resp := make([]nodeResponse, 0, len(nodes))
+	for _, node := range nodes {
+		gpus, err := h.storage.ListGPUsByNode(r.Context(), node.ID)
+		if err != nil {
+			http.Error(w, "Failed to list node GPUs", http.StatusInternalServerError)
+			return
+		}
+		resp = append(resp, nodeResponse{Node: node, GPUs: gpus})
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"nodes": resp,
+		"total": len(resp),
+	})
+}
+
+// RegisterAgentHandler adds or refreshes a node and its GPUs on behalf
+// of the node's agent
+func (h *Handlers) RegisterAgentHandler(w http.ResponseWriter, r *http.Request) {
+	var reg models.AgentRegistration
+	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	node, err := h.scheduler.RegisterAgent(r.Context(), &reg)
+	if err != nil {
+		utils.Error("Failed to register agent", zap.String("node_id", reg.NodeID), zap.Error(err))
+
+		if errors.Is(err, utils.ErrInvalidAgentRequest) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to register agent"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.AgentRegistrationResult{
+		Success: true,
+		Message: fmt.Sprintf("registered %d GPUs", node.TotalGPUs),
+		AgentID: node.ID,
+	})
+}
+
+// AgentHeartbeatHandler records a heartbeat from a node's agent. Unknown
+// nodes get 404 and must register again.
+func (h *Handlers) AgentHeartbeatHandler(w http.ResponseWriter, r *http.Request) {
+	var heartbeat models.NodeHeartbeat
+	if err := json.NewDecoder(r.Body).Decode(&heartbeat); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	heartbeat.NodeID = chi.URLParam(r, "nodeID")
+
+	ack, err := h.scheduler.AgentHeartbeat(r.Context(), &heartbeat)
+	if err != nil {
+		if errors.Is(err, utils.ErrNodeNotFound) {
+			http.Error(w, "Node not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to record heartbeat", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ack)
+}
+
+// AgentMetricsHandler applies GPU and job metrics reported by a node's
+// agent
+func (h *Handlers) AgentMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	var report models.MetricsReport
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	report.NodeID = chi.URLParam(r, "nodeID")
+
+	if err := h.scheduler.ReportAgentMetrics(r.Context(), &report); err != nil {
+		if errors.Is(err, utils.ErrNodeNotFound) {
+			http.Error(w, "Node not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to record metrics", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// AgentAssignmentsHandler returns the jobs a node's agent should be
+// running
+func (h *Handlers) AgentAssignmentsHandler(w http.ResponseWriter, r *http.Request) {
+	assignments, err := h.scheduler.JobAssignments(r.Context(), chi.URLParam(r, "nodeID"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNodeNotFound) {
+			http.Error(w, "Node not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to list assignments", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"assignments": assignments,
+		"total":       len(assignments),
+	})
+}
+
+// ListEventsHandler lists recorded scheduling events
+func (h *Handlers) ListEventsHandler(w http.ResponseWriter, r *http.Request) {
+	filter := models.EventFilter{
+		Type:   models.EventType(r.URL.Query().Get("type")),
+		JobID:  r.URL.Query().Get("job_id"),
+		NodeID: r.URL.Query().Get("node_id"),
+		Limit:  100,
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			filter.Limit = l
+		}
+	}
+
+	events, err := h.storage.ListEvents(r.Context(), filter)
+	if err != nil {
+		http.Error(w, "Failed to list events", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"events": events,
+		"total":  len(events),
+	})
+}
+
+// HealthCheckHandler returns health status
+func (h *Handlers) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.storage.Ping(r.Context()); err != nil {
+		http.Error(w, "Unhealthy", http.StatusServiceUnavailable)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "healthy"})
+}
+
+func respondJSON(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(data)
+}
+
+func generateJobID() string {
+	return fmt.Sprintf("job-%d", time.Now().UnixNano())
+}
+
+func generateWorkflowID() string {
+	return fmt.Sprintf("wf-%d", time.Now().UnixNano())
 }
 
 func generateTenantID() string {
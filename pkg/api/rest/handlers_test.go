@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/azizbahloul/gpu-scheduler/pkg/models"
 	"github.com/azizbahloul/gpu-scheduler/pkg/scheduler/core"
@@ -59,6 +60,11 @@ func (m *MockStorage) ListJobsByState(ctx context.Context, state models.JobState
 	return args.Get(0).([]*models.Job), args.Error(1)
 }
 
+func (m *MockStorage) ListArrayJobs(ctx context.Context, parentID string) ([]*models.Job, error) {
+	args := m.Called(ctx, parentID)
+	return args.Get(0).([]*models.Job), args.Error(1)
+}
+
 func (m *MockStorage) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
 	args := m.Called(ctx, tenant)
 	return args.Error(0)
@@ -87,6 +93,34 @@ func (m *MockStorage) ListTenants(ctx context.Context) ([]*models.Tenant, error)
 	return args.Get(0).([]*models.Tenant), args.Error(1)
 }
 
+func (m *MockStorage) CreateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetQuotaGroup(ctx context.Context, groupID string) (*models.QuotaGroup, error) {
+	args := m.Called(ctx, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QuotaGroup), args.Error(1)
+}
+
+func (m *MockStorage) UpdateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteQuotaGroup(ctx context.Context, groupID string) error {
+	args := m.Called(ctx, groupID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ListQuotaGroups(ctx context.Context) ([]*models.QuotaGroup, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.QuotaGroup), args.Error(1)
+}
+
 func (m *MockStorage) CreateGPU(ctx context.Context, gpu *models.GPU) error {
 	return nil
 }
@@ -160,6 +194,85 @@ func (m *MockStorage) ListActiveAllocations(ctx context.Context) ([]*models.Allo
 	return []*models.Allocation{}, nil
 }
 
+func (m *MockStorage) CreateReservation(ctx context.Context, reservation *models.Reservation) error {
+	args := m.Called(ctx, reservation)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetReservation(ctx context.Context, reservationID string) (*models.Reservation, error) {
+	args := m.Called(ctx, reservationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reservation), args.Error(1)
+}
+
+func (m *MockStorage) DeleteReservation(ctx context.Context, reservationID string) error {
+	args := m.Called(ctx, reservationID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ListReservations(ctx context.Context) ([]*models.Reservation, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.Reservation), args.Error(1)
+}
+
+func (m *MockStorage) CreateCronJob(ctx context.Context, cronJob *models.CronJob) error {
+	args := m.Called(ctx, cronJob)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetCronJob(ctx context.Context, cronJobID string) (*models.CronJob, error) {
+	args := m.Called(ctx, cronJobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CronJob), args.Error(1)
+}
+
+func (m *MockStorage) UpdateCronJob(ctx context.Context, cronJob *models.CronJob) error {
+	args := m.Called(ctx, cronJob)
+	return args.Error(0)
+}
+
+func (m *MockStorage) DeleteCronJob(ctx context.Context, cronJobID string) error {
+	args := m.Called(ctx, cronJobID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ListCronJobs(ctx context.Context) ([]*models.CronJob, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.CronJob), args.Error(1)
+}
+
+func (m *MockStorage) CreateCronRun(ctx context.Context, run *models.CronRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ListCronRuns(ctx context.Context, cronJobID string, limit int) ([]*models.CronRun, error) {
+	args := m.Called(ctx, cronJobID, limit)
+	return args.Get(0).([]*models.CronRun), args.Error(1)
+}
+
+func (m *MockStorage) CreateEvent(ctx context.Context, event *models.Event) error {
+	return nil
+}
+
+func (m *MockStorage) ListEvents(ctx context.Context, filter models.EventFilter) ([]*models.Event, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*models.Event), args.Error(1)
+}
+
+func (m *MockStorage) CreateLedgerEntry(ctx context.Context, entry *models.LedgerEntry) error {
+	return nil
+}
+
+func (m *MockStorage) ListLedgerEntries(ctx context.Context, tenantID string, from, to time.Time) ([]*models.LedgerEntry, error) {
+	args := m.Called(ctx, tenantID, from, to)
+	return args.Get(0).([]*models.LedgerEntry), args.Error(1)
+}
+
 func (m *MockStorage) Ping(ctx context.Context) error {
 	return nil
 }
@@ -317,6 +430,7 @@ func TestGetClusterStatusHandler(t *testing.T) {
 
 	mockStorage.On("ListNodes", mock.Anything).Return(nodes, nil)
 	mockStorage.On("ListJobs", mock.Anything, 10000, 0).Return([]*models.Job{}, nil)
+	mockStorage.On("ListJobsByState", mock.Anything, models.JobStateRunning).Return([]*models.Job{}, nil)
 
 	req := httptest.NewRequest("GET", "/api/v1/cluster/status", nil)
 	w := httptest.NewRecorder()
@@ -330,6 +444,11 @@ func TestGetClusterStatusHandler(t *testing.T) {
 	assert.Equal(t, float64(16), response["total_gpus"])
 	assert.Equal(t, float64(8), response["available_gpus"])
 	assert.Equal(t, float64(2), response["total_nodes"])
+
+	// Without configured queues everything goes through the default one
+	queues := response["queues"].([]interface{})
+	assert.Len(t, queues, 1)
+	assert.Equal(t, "default", queues[0].(map[string]interface{})["name"])
 }
 
 func TestCreateTenantHandler(t *testing.T) {
@@ -353,3 +472,257 @@ func TestCreateTenantHandler(t *testing.T) {
 
 	assert.Equal(t, http.StatusCreated, w.Code)
 }
+
+func TestSubmitWorkflowHandler(t *testing.T) {
+	mockStorage := new(MockStorage)
+	scheduler := core.NewScheduler(&utils.SchedulerConfig{MaxQueueSize: 100}, mockStorage)
+	handlers := NewHandlers(scheduler, mockStorage)
+
+	tenant := &models.Tenant{ID: "tenant-1", MaxGPUs: 10, MaxConcurrentJobs: 20, Active: true}
+	mockStorage.On("GetTenant", mock.Anything, "tenant-1").Return(tenant, nil)
+	mockStorage.On("CreateJob", mock.Anything, mock.AnythingOfType("*models.Job")).Return(nil)
+	mockStorage.On("GetJob", mock.Anything, mock.Anything).Return(&models.Job{State: models.JobStatePending}, nil)
+
+	requestBody := map[string]interface{}{
+		"tenant_id": "tenant-1",
+		"name":      "pipeline",
+		"jobs": []map[string]interface{}{
+			{"name": "preprocess", "gpu_count": 1},
+			{"name": "train", "gpu_count": 2, "dependencies": []map[string]string{{"job_id": "preprocess", "condition": "afterok"}}},
+		},
+	}
+
+	body, _ := json.Marshal(requestBody)
+	req := httptest.NewRequest("POST", "/api/v1/workflows", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handlers.SubmitWorkflowHandler(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NotEmpty(t, response["workflow_id"])
+	assert.Len(t, response["jobs"], 2)
+
+	// A cycle is rejected before anything is stored
+	requestBody["jobs"] = []map[string]interface{}{
+		{"name": "a", "gpu_count": 1, "dependencies": []map[string]string{{"job_id": "b"}}},
+		{"name": "b", "gpu_count": 1, "dependencies": []map[string]string{{"job_id": "a"}}},
+	}
+	body, _ = json.Marshal(requestBody)
+	req = httptest.NewRequest("POST", "/api/v1/workflows", bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+
+	handlers.SubmitWorkflowHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockStorage.AssertNumberOfCalls(t, "CreateJob", 2)
+}
+
+func TestListEventsHandler(t *testing.T) {
+	mockStorage := new(MockStorage)
+	handlers := NewHandlers(nil, mockStorage)
+
+	events := []*models.Event{
+		{ID: "evt-1", Type: models.EventThermalSkip, GPUID: "gpu-3", NodeID: "node-1"},
+	}
+	filter := models.EventFilter{Type: models.EventThermalSkip, NodeID: "node-1", Limit: 100}
+	mockStorage.On("ListEvents", mock.Anything, filter).Return(events, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/events?type=thermal_skip&node_id=node-1", nil)
+	w := httptest.NewRecorder()
+
+	handlers.ListEventsHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(1), response["total"])
+}
+
+func TestCreateReservationHandler(t *testing.T) {
+	mockStorage := new(MockStorage)
+	scheduler := core.NewScheduler(&utils.SchedulerConfig{MaxQueueSize: 100}, mockStorage)
+	handlers := NewHandlers(scheduler, mockStorage)
+
+	tenant := &models.Tenant{ID: "tenant-1", MaxGPUs: 10, Active: true}
+	mockStorage.On("GetTenant", mock.Anything, "tenant-1").Return(tenant, nil)
+	mockStorage.On("ListReservations", mock.Anything).Return([]*models.Reservation{}, nil)
+	mockStorage.On("ListNodes", mock.Anything).Return([]*models.Node{}, nil)
+
+	start := time.Now().Add(time.Hour)
+	requestBody := map[string]interface{}{
+		"tenant_id":  "tenant-1",
+		"gpu_count":  4,
+		"start_time": start,
+		"end_time":   start.Add(2 * time.Hour),
+	}
+
+	// No node has GPUs to set aside
+	body, _ := json.Marshal(requestBody)
+	req := httptest.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handlers.CreateReservationHandler(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// A window that ends before it starts is rejected up front
+	requestBody["end_time"] = start.Add(-time.Hour)
+	body, _ = json.Marshal(requestBody)
+	req = httptest.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+
+	handlers.CreateReservationHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockStorage.AssertNotCalled(t, "CreateReservation", mock.Anything, mock.Anything)
+}
+
+func TestCreateCronJobHandler(t *testing.T) {
+	mockStorage := new(MockStorage)
+	scheduler := core.NewScheduler(&utils.SchedulerConfig{MaxQueueSize: 100}, mockStorage)
+	handlers := NewHandlers(scheduler, mockStorage)
+
+	tenant := &models.Tenant{ID: "tenant-1", MaxGPUs: 10, MaxCPUCores: 64, MaxMemoryMB: 512000, MaxConcurrentJobs: 10, Active: true}
+	mockStorage.On("GetTenant", mock.Anything, "tenant-1").Return(tenant, nil)
+	mockStorage.On("CreateCronJob", mock.Anything, mock.AnythingOfType("*models.CronJob")).Return(nil)
+
+	requestBody := map[string]interface{}{
+		"tenant_id": "tenant-1",
+		"name":      "nightly-eval",
+		"schedule":  "0 2 * * *",
+		"timezone":  "Europe/Paris",
+		"job": map[string]interface{}{
+			"gpu_count": 2,
+			"priority":  100,
+		},
+	}
+
+	body, _ := json.Marshal(requestBody)
+	req := httptest.NewRequest("POST", "/api/v1/cronjobs", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handlers.CreateCronJobHandler(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var cronJob models.CronJob
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &cronJob))
+	assert.Equal(t, models.ConcurrencyAllow, cronJob.ConcurrencyPolicy)
+	assert.Equal(t, models.MissedRunOnce, cronJob.MissedRunPolicy)
+	assert.NotNil(t, cronJob.NextRunAt)
+
+	// A malformed schedule is rejected before anything is stored
+	requestBody["schedule"] = "0 25 * * *"
+	body, _ = json.Marshal(requestBody)
+	req = httptest.NewRequest("POST", "/api/v1/cronjobs", bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+
+	handlers.CreateCronJobHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockStorage.AssertNumberOfCalls(t, "CreateCronJob", 1)
+}
+
+func TestCreateQuotaGroupHandler(t *testing.T) {
+	mockStorage := new(MockStorage)
+	scheduler := core.NewScheduler(&utils.SchedulerConfig{MaxQueueSize: 100}, mockStorage)
+	handlers := NewHandlers(scheduler, mockStorage)
+
+	org := &models.QuotaGroup{ID: "acme", Name: "acme", Kind: models.QuotaOrganization, MaxGPUs: 16}
+	mockStorage.On("GetQuotaGroup", mock.Anything, "acme").Return(org, nil)
+	mockStorage.On("CreateQuotaGroup", mock.Anything, mock.AnythingOfType("*models.QuotaGroup")).Return(nil)
+
+	requestBody := map[string]interface{}{
+		"name":      "ml",
+		"kind":      "team",
+		"parent_id": "acme",
+		"max_gpus":  8,
+	}
+
+	body, _ := json.Marshal(requestBody)
+	req := httptest.NewRequest("POST", "/api/v1/quota-groups", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handlers.CreateQuotaGroupHandler(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var group models.QuotaGroup
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &group))
+	assert.NotEmpty(t, group.ID)
+	assert.Equal(t, "acme", group.ParentID)
+
+	// A team may not allow more than its organization
+	requestBody["max_gpus"] = 32
+	body, _ = json.Marshal(requestBody)
+	req = httptest.NewRequest("POST", "/api/v1/quota-groups", bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+
+	handlers.CreateQuotaGroupHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockStorage.AssertNumberOfCalls(t, "CreateQuotaGroup", 1)
+}
+
+func TestDeleteTenantHandler(t *testing.T) {
+	mockStorage := new(MockStorage)
+	scheduler := core.NewScheduler(&utils.SchedulerConfig{MaxQueueSize: 100}, mockStorage)
+	handlers := NewHandlers(scheduler, mockStorage)
+
+	tenant := &models.Tenant{ID: "tenant-1", Active: true}
+	running := &models.Job{ID: "job-1", TenantID: "tenant-1", State: models.JobStateRunning}
+	mockStorage.On("GetTenant", mock.Anything, "tenant-1").Return(tenant, nil)
+	mockStorage.On("ListJobsByTenant", mock.Anything, "tenant-1").Return([]*models.Job{running}, nil)
+	nightly := &models.CronJob{ID: "cron-1", TenantID: "tenant-1"}
+	mockStorage.On("ListCronJobs", mock.Anything).Return([]*models.CronJob{nightly}, nil)
+	mockStorage.On("ListReservations", mock.Anything).Return([]*models.Reservation{}, nil)
+
+	deleteTenant := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/api/v1/tenants/tenant-1", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("tenantID", "tenant-1")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		handlers.DeleteTenantHandler(w, req)
+		return w
+	}
+
+	// Tenants with running jobs are kept
+	assert.Equal(t, http.StatusConflict, deleteTenant().Code)
+	mockStorage.AssertNotCalled(t, "DeleteTenant", mock.Anything, "tenant-1")
+
+	// So are tenants owning cron jobs
+	running.State = models.JobStateCompleted
+	assert.Equal(t, http.StatusConflict, deleteTenant().Code)
+	mockStorage.AssertNotCalled(t, "DeleteTenant", mock.Anything, "tenant-1")
+
+	nightly.TenantID = "tenant-2"
+	mockStorage.On("DeleteTenant", mock.Anything, "tenant-1").Return(nil)
+	assert.Equal(t, http.StatusOK, deleteTenant().Code)
+	mockStorage.AssertCalled(t, "DeleteTenant", mock.Anything, "tenant-1")
+}
+
+func TestRegisterAgentHandler(t *testing.T) {
+	mockStorage := new(MockStorage)
+	scheduler := core.NewScheduler(&utils.SchedulerConfig{MaxQueueSize: 100}, mockStorage)
+	handlers := NewHandlers(scheduler, mockStorage)
+
+	register := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/agents/register", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		handlers.RegisterAgentHandler(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, register(`not json`).Code)
+
+	// Agents must identify their node
+	w := register(`{"hostname": "gpu-node-1", "gpus": []}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "node ID is required")
+}
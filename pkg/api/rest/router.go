@@ -30,12 +30,57 @@ func NewRouter(handlers *Handlers) http.Handler {
 		r.Get("/jobs", handlers.ListJobsHandler)
 		r.Get("/jobs/{jobID}", handlers.GetJobStatusHandler)
 		r.Delete("/jobs/{jobID}", handlers.CancelJobHandler)
+		r.Post("/jobs/{jobID}/extend", handlers.ExtendJobHandler)
+		r.Post("/jobs/{jobID}/failure", handlers.ReportFailureHandler)
+
+		// Workflows
+		r.Post("/workflows", handlers.SubmitWorkflowHandler)
 
 		// Tenants
 		r.Post("/tenants", handlers.CreateTenantHandler)
+		r.Get("/tenants", handlers.ListTenantsHandler)
+		r.Get("/tenants/{tenantID}", handlers.GetTenantHandler)
+		r.Patch("/tenants/{tenantID}", handlers.UpdateTenantHandler)
+		r.Delete("/tenants/{tenantID}", handlers.DeleteTenantHandler)
+		r.Post("/tenants/{tenantID}/deactivate", handlers.DeactivateTenantHandler)
+		r.Post("/tenants/{tenantID}/activate", handlers.ActivateTenantHandler)
+		r.Put("/tenants/{tenantID}/quota-group", handlers.AssignQuotaGroupHandler)
+		r.Get("/tenants/{tenantID}/budget", handlers.GetTenantBudgetHandler)
+		r.Put("/tenants/{tenantID}/budget", handlers.SetTenantBudgetHandler)
+		r.Get("/tenants/{tenantID}/invoice", handlers.GetInvoiceHandler)
+
+		// Quota groups
+		r.Post("/quota-groups", handlers.CreateQuotaGroupHandler)
+		r.Get("/quota-groups", handlers.ListQuotaGroupsHandler)
+		r.Get("/quota-groups/{groupID}", handlers.GetQuotaGroupHandler)
+		r.Put("/quota-groups/{groupID}", handlers.UpdateQuotaGroupHandler)
+		r.Delete("/quota-groups/{groupID}", handlers.DeleteQuotaGroupHandler)
+
+		// Reservations
+		r.Post("/reservations", handlers.CreateReservationHandler)
+		r.Get("/reservations", handlers.ListReservationsHandler)
+		r.Get("/reservations/{reservationID}", handlers.GetReservationHandler)
+		r.Delete("/reservations/{reservationID}", handlers.DeleteReservationHandler)
+
+		// Cron jobs
+		r.Post("/cronjobs", handlers.CreateCronJobHandler)
+		r.Get("/cronjobs", handlers.ListCronJobsHandler)
+		r.Get("/cronjobs/{cronJobID}", handlers.GetCronJobHandler)
+		r.Delete("/cronjobs/{cronJobID}", handlers.DeleteCronJobHandler)
+		r.Get("/cronjobs/{cronJobID}/runs", handlers.ListCronRunsHandler)
 
 		// Cluster
 		r.Get("/cluster/status", handlers.GetClusterStatusHandler)
+		r.Get("/nodes", handlers.ListNodesHandler)
+
+		// Node agents
+		r.Post("/agents/register", handlers.RegisterAgentHandler)
+		r.Post("/agents/{nodeID}/heartbeat", handlers.AgentHeartbeatHandler)
+		r.Post("/agents/{nodeID}/metrics", handlers.AgentMetricsHandler)
+		r.Get("/agents/{nodeID}/assignments", handlers.AgentAssignmentsHandler)
+
+		// Events
+		r.Get("/events", handlers.ListEventsHandler)
 	})
 
 	return r
@@ -45,7 +90,7 @@ func NewRouter(handlers *Handlers) http.Handler {
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if r.Method == "OPTIONS" {
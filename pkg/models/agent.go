@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+)
+
+// The types below are exchanged between node agents and the scheduler.
+// They mirror the messages of the AgentService in agent.proto.
+
+// AgentRegistration announces a node and its GPUs to the scheduler
+type AgentRegistration struct {
+	NodeID        string            `json:"node_id"`
+	Hostname      string            `json:"hostname"`
+	IPAddress     string            `json:"ip_address"`
+	TotalGPUs     int               `json:"total_gpus"`
+	TotalCPUCores int               `json:"total_cpu_cores"`
+	TotalMemoryMB int64             `json:"total_memory_mb"`
+	GPUs          []GPUInfo         `json:"gpus"`
+	Labels        map[string]string `json:"labels"`
+}
+
+// AgentRegistrationResult answers an agent's registration
+type AgentRegistrationResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	AgentID string `json:"agent_id"`
+}
+
+// GPUInfo describes a GPU found by an agent
+type GPUInfo struct {
+	GPUID             string `json:"gpu_id"`
+	Index             int    `json:"index"`
+	Model             string `json:"model"`
+	MemoryTotalMB     int64  `json:"memory_total_mb"`
+	ComputeCapability string `json:"compute_capability"`
+	CUDACores         int    `json:"cuda_cores"`
+	TensorCores       int    `json:"tensor_cores"`
+}
+
+// GPUMetrics is a sample of a GPU's telemetry taken by an agent
+type GPUMetrics struct {
+	GPUID           string  `json:"gpu_id"`
+	Utilization     float64 `json:"utilization"`
+	Temperature     float64 `json:"temperature"`
+	PowerUsage      float64 `json:"power_usage"`
+	MemoryUsedMB    int64   `json:"memory_used_mb"`
+	MemoryFreeMB    int64   `json:"memory_free_mb"`
+	ThermalThrottle bool    `json:"thermal_throttle"`
+	ErrorCount      int     `json:"error_count"`
+}
+
+// NodeHeartbeat tells the scheduler an agent is alive
+type NodeHeartbeat struct {
+	NodeID            string       `json:"node_id"`
+	Timestamp         time.Time    `json:"timestamp"`
+	GPUMetrics        []GPUMetrics `json:"gpu_metrics"`
+	CPUUtilization    float64      `json:"cpu_utilization"`
+	MemoryUtilization float64      `json:"memory_utilization"`
+	ActiveJobs        int          `json:"active_jobs"`
+}
+
+// AgentCommandTerminate asks an agent to stop a job. The job ID follows
+// the command, separated by a space.
+const AgentCommandTerminate = "terminate"
+
+// HeartbeatAck answers a heartbeat
+type HeartbeatAck struct {
+	Acknowledged bool     `json:"acknowledged"`
+	Commands     []string `json:"commands"`
+}
+
+// JobMetrics reports the state of a job an agent runs. Agents report
+// the states running, completed and failed.
+type JobMetrics struct {
+	JobID          string     `json:"job_id"`
+	State          JobState   `json:"state"`
+	GPUUtilization float64    `json:"gpu_utilization"`
+	CPUUtilization float64    `json:"cpu_utilization"`
+	MemoryUsedMB   int64      `json:"memory_used_mb"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	ExitCode       string     `json:"exit_code"`
+	ErrorMessage   string     `json:"error_message"`
+}
+
+// MetricsReport carries an agent's GPU and job metrics
+type MetricsReport struct {
+	NodeID     string       `json:"node_id"`
+	GPUMetrics []GPUMetrics `json:"gpu_metrics"`
+	JobMetrics []JobMetrics `json:"job_metrics"`
+	Timestamp  time.Time    `json:"timestamp"`
+}
+
+// JobAssignment tells an agent to run a job on some of its GPUs
+type JobAssignment struct {
+	JobID        string            `json:"job_id"`
+	AllocationID string            `json:"allocation_id"`
+	GPUIDs       []string          `json:"gpu_ids"`
+	CPUCores     int               `json:"cpu_cores"`
+	MemoryMB     int64             `json:"memory_mb"`
+	Image        string            `json:"image"`
+	Command      []string          `json:"command"`
+	Args         []string          `json:"args"`
+	Environment  map[string]string `json:"environment"`
+	Script       string            `json:"script"`
+}
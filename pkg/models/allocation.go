@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
@@ -17,6 +18,31 @@ const (
 	AllocationFailed       AllocationState = "failed"
 )
 
+// PlacementStrategy selects how candidate nodes are ranked
+type PlacementStrategy string
+
+const (
+	// StrategyBestFit packs jobs onto the node left with the fewest idle GPUs
+	StrategyBestFit          PlacementStrategy = "best-fit"
+	// StrategySpread places jobs on the node left with the most idle capacity
+	StrategySpread           PlacementStrategy = "spread"
+	// StrategyMostAllocated favors nodes with the highest combined GPU, CPU
+	// and memory utilization
+	StrategyMostAllocated    PlacementStrategy = "most-allocated"
+	// StrategyMinFragmentation avoids stranding idle GPUs on partially
+	// used nodes, keeping whole nodes free for large jobs
+	StrategyMinFragmentation PlacementStrategy = "min-fragmentation"
+)
+
+// IsValid checks if the strategy is a known placement strategy
+func (s PlacementStrategy) IsValid() bool {
+	switch s {
+	case StrategyBestFit, StrategySpread, StrategyMostAllocated, StrategyMinFragmentation:
+		return true
+	}
+	return false
+}
+
 // Allocation represents a resource allocation for a job
 type Allocation struct {
 	ID                string           `json:"id" gorm:"primaryKey"`
@@ -29,17 +55,23 @@ type Allocation struct {
 	NodeID            string           `json:"node_id"`
 	CPUCores          int              `json:"cpu_cores"`
 	MemoryMB          int64            `json:"memory_mb"`
+	Slice             *GPUSlice        `json:"slice" gorm:"serializer:json"`
 	
 	// Timing
 	AllocatedAt       time.Time        `json:"allocated_at"`
 	PlannedDuration   time.Duration    `json:"planned_duration"`
 	ActualDuration    time.Duration    `json:"actual_duration"`
 	ExtendedCount     int              `json:"extended_count"`
+	RuntimeWarnedAt   *time.Time       `json:"runtime_warned_at"`
+	TerminateSignaledAt *time.Time     `json:"terminate_signaled_at"`
 	
 	// Preemption
 	PreemptedAt       *time.Time       `json:"preempted_at"`
 	PreemptedBy       string           `json:"preempted_by"`
 	PreemptionReason  string           `json:"preemption_reason"`
+	// Reclaimable allocations use GPUs beyond the tenant's guarantee and
+	// are preempted first when another tenant reclaims its guarantee
+	Reclaimable       bool             `json:"reclaimable"`
 	CheckpointSize    int64            `json:"checkpoint_size"`
 	CheckpointPath    string           `json:"checkpoint_path"`
 	
@@ -47,6 +79,7 @@ type Allocation struct {
 	AvgGPUUtilization float64          `json:"avg_gpu_utilization"`
 	PeakGPUUtilization float64         `json:"peak_gpu_utilization"`
 	AvgPowerUsage     float64          `json:"avg_power_usage"`
+	ExpectedPowerW    float64          `json:"expected_power_w"`
 	
 	// Cost
 	CostPerHour       float64          `json:"cost_per_hour"`
@@ -70,6 +103,37 @@ type AllocationRequest struct {
 	PreferredNodes    []string         `json:"preferred_nodes"`
 	RequiredLabels    map[string]string `json:"required_labels"`
 	Affinity          *Affinity        `json:"affinity"`
+	ExpectedPowerW    float64          `json:"expected_power_w"`
+	Strategy          PlacementStrategy `json:"strategy"`
+	GPUSlice          *SliceRequest    `json:"gpu_slice"`
+	MaxRuntime        time.Duration    `json:"max_runtime"`
+	AvoidNodes        []string         `json:"avoid_nodes"`
+	ReservationID     string           `json:"reservation_id"`
+	Queue             string           `json:"queue"`
+	Reclaimable       bool             `json:"reclaimable"`
+}
+
+// Avoids returns true if the request prefers not to land on the node
+func (r *AllocationRequest) Avoids(nodeID string) bool {
+	for _, id := range r.AvoidNodes {
+		if id == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFractional returns true if the request asks for part of a single GPU
+func (r *AllocationRequest) IsFractional() bool {
+	return r.GPUSlice != nil
+}
+
+// WholeGPUs returns the number of whole GPUs the request takes
+func (r *AllocationRequest) WholeGPUs() int {
+	if r.IsFractional() {
+		return 0
+	}
+	return r.GPUCount
 }
 
 // Affinity defines scheduling affinity rules
@@ -93,15 +157,43 @@ type AllocationResult struct {
 	AllocationID      string           `json:"allocation_id"`
 	GPUIDs            []string         `json:"gpu_ids"`
 	NodeID            string           `json:"node_id"`
+	GPUFraction       float64          `json:"gpu_fraction,omitempty"`
 	Message           string           `json:"message"`
 	Timestamp         time.Time        `json:"timestamp"`
 }
 
+// HasJobAffinity returns true if any inter-job constraint is set
+func (a *Affinity) HasJobAffinity() bool {
+	return a != nil && (a.ColocateWithJob != "" || len(a.AntiColocateWith) > 0)
+}
+
+// AntiColocatesWith checks if the given job matches one of the
+// anti-colocation selectors. Selectors are "key=value" label matches;
+// a bare "key" matches any job carrying that label.
+func (a *Affinity) AntiColocatesWith(job *Job) bool {
+	if a == nil || job == nil {
+		return false
+	}
+	for _, selector := range a.AntiColocateWith {
+		key, value, hasValue := strings.Cut(selector, "=")
+		labelValue, ok := job.Labels[key]
+		if ok && (!hasValue || labelValue == value) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsActive returns true if allocation is active
 func (a *Allocation) IsActive() bool {
 	return a.State == AllocationActive
 }
 
+// Deadline returns when the allocation's planned duration runs out
+func (a *Allocation) Deadline() time.Time {
+	return a.AllocatedAt.Add(a.PlannedDuration)
+}
+
 // CalculateDuration updates actual duration
 func (a *Allocation) CalculateDuration() {
 	if a.CompletedAt != nil {
@@ -112,7 +204,15 @@ func (a *Allocation) CalculateDuration() {
 // CalculateCost calculates total cost based on duration
 func (a *Allocation) CalculateCost() {
 	hours := a.ActualDuration.Hours()
-	a.TotalCost = hours * a.CostPerHour * float64(len(a.GPUIDs))
+	a.TotalCost = hours * a.CostPerHour * a.GPUs()
+}
+
+// GPUs returns the number of GPUs held, counting slices fractionally
+func (a *Allocation) GPUs() float64 {
+	if a.Slice != nil {
+		return a.Slice.Fraction
+	}
+	return float64(len(a.GPUIDs))
 }
 
 // UpdateUtilization updates GPU utilization metrics
@@ -128,3 +228,12 @@ func (a *Allocation) UpdateUtilization(current float64) {
 		a.AvgGPUUtilization = (a.AvgGPUUtilization + current) / 2
 	}
 }
+
+// UpdatePowerUsage updates the average per-GPU power draw
+func (a *Allocation) UpdatePowerUsage(current float64) {
+	if a.AvgPowerUsage == 0 {
+		a.AvgPowerUsage = current
+	} else {
+		a.AvgPowerUsage = (a.AvgPowerUsage + current) / 2
+	}
+}
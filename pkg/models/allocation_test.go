@@ -70,3 +70,33 @@ func TestUpdateUtilization(t *testing.T) {
 	assert.Equal(t, 78.75, alloc.AvgGPUUtilization) // (87.5+70)/2
 	assert.Equal(t, 95.0, alloc.PeakGPUUtilization) // Peak doesn't change
 }
+
+func TestAntiColocatesWith(t *testing.T) {
+	affinity := &Affinity{AntiColocateWith: []string{"app=inference", "replica-set"}}
+
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected bool
+	}{
+		{"Matching key and value", map[string]string{"app": "inference"}, true},
+		{"Different value", map[string]string{"app": "training"}, false},
+		{"Bare key selector", map[string]string{"replica-set": "rs-1"}, true},
+		{"No labels", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &Job{Labels: tt.labels}
+			assert.Equal(t, tt.expected, affinity.AntiColocatesWith(job))
+		})
+	}
+}
+
+func TestHasJobAffinity(t *testing.T) {
+	var nilAffinity *Affinity
+	assert.False(t, nilAffinity.HasJobAffinity())
+	assert.False(t, (&Affinity{GPUModel: GPUA100}).HasJobAffinity())
+	assert.True(t, (&Affinity{ColocateWithJob: "job-1"}).HasJobAffinity())
+	assert.True(t, (&Affinity{AntiColocateWith: []string{"app=web"}}).HasJobAffinity())
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxArraySize caps the number of child jobs one array can expand into
+const MaxArraySize = 10000
+
+// ArrayIndexEnv is the environment variable carrying a child's index
+const ArrayIndexEnv = "ARRAY_INDEX"
+
+// ArraySpec describes a job array: one child job per index from Start to
+// End inclusive, with at most MaxConcurrent children queued or running at
+// once (0 means no limit)
+type ArraySpec struct {
+	Start         int `json:"start"`
+	End           int `json:"end"`
+	MaxConcurrent int `json:"max_concurrent,omitempty"`
+}
+
+// ParseArraySpec parses the "start-end%max" form, e.g. "0-499%20". The
+// end and the throttle are optional, so "7" is a single-index array.
+func ParseArraySpec(spec string) (*ArraySpec, error) {
+	a := &ArraySpec{}
+	rng := spec
+
+	if i := strings.Index(spec, "%"); i >= 0 {
+		max, err := strconv.Atoi(spec[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid array throttle in %q", spec)
+		}
+		a.MaxConcurrent = max
+		rng = spec[:i]
+	}
+
+	bounds := strings.SplitN(rng, "-", 2)
+	start, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid array range in %q", spec)
+	}
+	a.Start, a.End = start, start
+	if len(bounds) == 2 {
+		if a.End, err = strconv.Atoi(bounds[1]); err != nil {
+			return nil, fmt.Errorf("invalid array range in %q", spec)
+		}
+	}
+
+	return a, a.Validate()
+}
+
+// Validate checks the range and throttle
+func (a *ArraySpec) Validate() error {
+	if a.Start < 0 || a.End < a.Start {
+		return fmt.Errorf("invalid array range %d-%d", a.Start, a.End)
+	}
+	if a.Size() > MaxArraySize {
+		return fmt.Errorf("array of %d jobs exceeds the limit of %d", a.Size(), MaxArraySize)
+	}
+	if a.MaxConcurrent < 0 {
+		return fmt.Errorf("array throttle cannot be negative")
+	}
+	return nil
+}
+
+// Size returns the number of child jobs
+func (a *ArraySpec) Size() int {
+	return a.End - a.Start + 1
+}
+
+// String formats the spec in the form accepted by ParseArraySpec
+func (a *ArraySpec) String() string {
+	s := fmt.Sprintf("%d-%d", a.Start, a.End)
+	if a.MaxConcurrent > 0 {
+		s += fmt.Sprintf("%%%d", a.MaxConcurrent)
+	}
+	return s
+}
+
+// ArrayCounts tallies an array's children by state
+func ArrayCounts(children []*Job) map[JobState]int {
+	counts := make(map[JobState]int)
+	for _, child := range children {
+		counts[child.State]++
+	}
+	return counts
+}
+
+// AggregateArrayState derives an array parent's state from its children:
+// running while any child runs, pending while any is still to run, and
+// once all have finished, completed only if every child completed.
+// Preempted children that will retry are waiting, so a preempted child
+// has finished.
+func AggregateArrayState(children []*Job) JobState {
+	counts := ArrayCounts(children)
+	switch {
+	case counts[JobStateRunning] > 0:
+		return JobStateRunning
+	case counts[JobStatePending] > 0 || counts[JobStateWaiting] > 0:
+		return JobStatePending
+	case counts[JobStateFailed] > 0:
+		return JobStateFailed
+	case counts[JobStatePreempted] > 0:
+		return JobStatePreempted
+	case counts[JobStateCancelled] > 0:
+		return JobStateCancelled
+	}
+	return JobStateCompleted
+}
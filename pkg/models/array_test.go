@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArraySpec(t *testing.T) {
+	spec, err := ParseArraySpec("0-499%20")
+	require.NoError(t, err)
+	assert.Equal(t, &ArraySpec{Start: 0, End: 499, MaxConcurrent: 20}, spec)
+	assert.Equal(t, 500, spec.Size())
+	assert.Equal(t, "0-499%20", spec.String())
+
+	spec, err = ParseArraySpec("7")
+	require.NoError(t, err)
+	assert.Equal(t, 1, spec.Size())
+
+	for _, invalid := range []string{"", "a-b", "5-1", "0-9%x", "0-20000", "-1-4"} {
+		_, err := ParseArraySpec(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestAggregateArrayState(t *testing.T) {
+	children := func(states ...JobState) []*Job {
+		var jobs []*Job
+		for _, state := range states {
+			jobs = append(jobs, &Job{State: state})
+		}
+		return jobs
+	}
+
+	assert.Equal(t, JobStateRunning, AggregateArrayState(children(JobStateCompleted, JobStateRunning, JobStateWaiting)))
+	assert.Equal(t, JobStatePending, AggregateArrayState(children(JobStateCompleted, JobStateWaiting)))
+	assert.Equal(t, JobStateFailed, AggregateArrayState(children(JobStateCompleted, JobStateFailed, JobStateCancelled)))
+	assert.Equal(t, JobStatePreempted, AggregateArrayState(children(JobStateCompleted, JobStatePreempted, JobStateCancelled)))
+	assert.Equal(t, JobStateCancelled, AggregateArrayState(children(JobStateCompleted, JobStateCancelled)))
+	assert.Equal(t, JobStateCompleted, AggregateArrayState(children(JobStateCompleted, JobStateCompleted)))
+}
@@ -0,0 +1,281 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// BudgetPeriod is the window a GPU-hour budget covers before it resets
+type BudgetPeriod string
+
+const (
+	BudgetDaily   BudgetPeriod = "daily"
+	BudgetWeekly  BudgetPeriod = "weekly"
+	BudgetMonthly BudgetPeriod = "monthly"
+)
+
+// IsValid returns true if the period is a known one
+func (p BudgetPeriod) IsValid() bool {
+	switch p {
+	case BudgetDaily, BudgetWeekly, BudgetMonthly:
+		return true
+	}
+	return false
+}
+
+// Start returns the start of the period containing t. Weeks start on
+// Monday.
+func (p BudgetPeriod) Start(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch p {
+	case BudgetWeekly:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case BudgetMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return day
+}
+
+// Next returns the start of the period after the one starting at start
+func (p BudgetPeriod) Next(start time.Time) time.Time {
+	switch p {
+	case BudgetWeekly:
+		return start.AddDate(0, 0, 7)
+	case BudgetMonthly:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// BudgetState tells whether a budget still admits new jobs
+type BudgetState string
+
+const (
+	BudgetOK        BudgetState = "ok"
+	BudgetWarning   BudgetState = "warning"
+	BudgetExhausted BudgetState = "exhausted"
+)
+
+// Default thresholds, as fractions of the period's allowance
+const (
+	DefaultBudgetSoftThreshold = 0.8
+	DefaultBudgetHardThreshold = 1.0
+)
+
+// GPUHourBudget caps the GPU-hours a tenant may use per period. Past the
+// soft threshold a warning is raised once per period; past the hard
+// threshold new jobs wait until the next period while running jobs are
+// left alone. With Rollover, hours left unused at the end of a period
+// carry into the next, up to one period's worth.
+type GPUHourBudget struct {
+	Period        BudgetPeriod `json:"period"`
+	GPUHours      float64      `json:"gpu_hours"`
+	Rollover      bool         `json:"rollover"`
+	SoftThreshold float64      `json:"soft_threshold"`
+	HardThreshold float64      `json:"hard_threshold"`
+
+	// State of the current period
+	PeriodStart     time.Time  `json:"period_start"`
+	UsedGPUHours    float64    `json:"used_gpu_hours"`
+	CarriedGPUHours float64    `json:"carried_gpu_hours"`
+	WarnedAt        *time.Time `json:"warned_at,omitempty"`
+	ExhaustedAt     *time.Time `json:"exhausted_at,omitempty"`
+}
+
+// Validate checks the budget's period, size and thresholds
+func (b *GPUHourBudget) Validate() error {
+	if !b.Period.IsValid() {
+		return fmt.Errorf("unknown budget period %q", b.Period)
+	}
+	if b.GPUHours <= 0 {
+		return fmt.Errorf("budget GPU hours must be positive")
+	}
+	if b.SoftThreshold < 0 || b.HardThreshold < 0 {
+		return fmt.Errorf("budget thresholds cannot be negative")
+	}
+	if b.SoftThreshold > 0 && b.SoftThreshold > b.hardThreshold() {
+		return fmt.Errorf("budget soft threshold cannot exceed the hard threshold")
+	}
+	return nil
+}
+
+// Roll moves the budget into the period containing now, carrying unused
+// hours over if the budget rolls over. It returns true if a new period
+// began.
+func (b *GPUHourBudget) Roll(now time.Time) bool {
+	start := b.Period.Start(now)
+	if !b.PeriodStart.IsZero() && !start.After(b.PeriodStart) {
+		return false
+	}
+
+	carry := 0.0
+	if b.Rollover && !b.PeriodStart.IsZero() {
+		// A skipped period went unused entirely
+		if b.Period.Next(b.PeriodStart).Before(start) {
+			carry = b.GPUHours
+		} else {
+			carry = b.Allowance() - b.UsedGPUHours
+		}
+		if carry < 0 {
+			carry = 0
+		}
+		if carry > b.GPUHours {
+			carry = b.GPUHours
+		}
+	}
+
+	b.PeriodStart = start
+	b.UsedGPUHours = 0
+	b.CarriedGPUHours = carry
+	b.WarnedAt = nil
+	b.ExhaustedAt = nil
+	return true
+}
+
+// Reset clears the budget's usage and starts the period containing now
+func (b *GPUHourBudget) Reset(now time.Time) {
+	b.PeriodStart = time.Time{}
+	b.Roll(now)
+}
+
+// Charge adds GPU-hours used in the current period
+func (b *GPUHourBudget) Charge(hours float64) {
+	b.UsedGPUHours += hours
+}
+
+// Allowance returns the GPU-hours available this period, including any
+// carried over
+func (b *GPUHourBudget) Allowance() float64 {
+	return b.GPUHours + b.CarriedGPUHours
+}
+
+// Remaining returns the GPU-hours left before the hard threshold
+func (b *GPUHourBudget) Remaining() float64 {
+	remaining := b.Allowance()*b.hardThreshold() - b.UsedGPUHours
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// State returns where the period's usage stands against the thresholds
+func (b *GPUHourBudget) State() BudgetState {
+	switch allowance := b.Allowance(); {
+	case b.UsedGPUHours >= allowance*b.hardThreshold()-1e-9:
+		return BudgetExhausted
+	case b.UsedGPUHours >= allowance*b.softThreshold()-1e-9:
+		return BudgetWarning
+	}
+	return BudgetOK
+}
+
+// PeriodEnd returns when the current period ends
+func (b *GPUHourBudget) PeriodEnd() time.Time {
+	return b.Period.Next(b.PeriodStart)
+}
+
+func (b *GPUHourBudget) softThreshold() float64 {
+	if b.SoftThreshold == 0 {
+		return DefaultBudgetSoftThreshold
+	}
+	return b.SoftThreshold
+}
+
+func (b *GPUHourBudget) hardThreshold() float64 {
+	if b.HardThreshold == 0 {
+		return DefaultBudgetHardThreshold
+	}
+	return b.HardThreshold
+}
+
+// ValidateBudget checks a tenant's budget, which may be nil, and quota
+// profiles
+func ValidateBudget(budget *GPUHourBudget, profiles []QuotaProfile) error {
+	if budget != nil {
+		if err := budget.Validate(); err != nil {
+			return err
+		}
+	}
+	for i := range profiles {
+		if err := profiles[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QuotaProfile overrides a tenant's GPU and concurrent job limits during
+// a recurring time-of-day window, e.g. tighter limits in business hours.
+// Hours are in the scheduler's local time; the window runs from
+// StartHour up to EndHour and wraps past midnight when EndHour is not
+// after StartHour. Days lists the weekdays the window starts on, every
+// day when empty. A zero limit keeps the tenant's own.
+type QuotaProfile struct {
+	Name              string         `json:"name"`
+	Days              []time.Weekday `json:"days,omitempty"`
+	StartHour         int            `json:"start_hour"`
+	EndHour           int            `json:"end_hour"`
+	MaxGPUs           int            `json:"max_gpus"`
+	MaxConcurrentJobs int            `json:"max_concurrent_jobs"`
+}
+
+// Validate checks the profile's window and limits
+func (p *QuotaProfile) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("quota profile name is required")
+	}
+	if p.StartHour < 0 || p.StartHour > 23 || p.EndHour < 0 || p.EndHour > 24 {
+		return fmt.Errorf("quota profile %s hours must be within 0-24", p.Name)
+	}
+	for _, day := range p.Days {
+		if day < time.Sunday || day > time.Saturday {
+			return fmt.Errorf("quota profile %s has unknown weekday %d", p.Name, day)
+		}
+	}
+	if p.MaxGPUs < 0 || p.MaxConcurrentJobs < 0 {
+		return fmt.Errorf("quota profile %s limits cannot be negative", p.Name)
+	}
+	return nil
+}
+
+// Covers returns true if t falls within the profile's window
+func (p *QuotaProfile) Covers(t time.Time) bool {
+	hour := t.Hour()
+	if p.StartHour < p.EndHour {
+		return hour >= p.StartHour && hour < p.EndHour && p.onDay(t.Weekday())
+	}
+	// Windows wrapping past midnight belong to the day they start on
+	if hour >= p.StartHour {
+		return p.onDay(t.Weekday())
+	}
+	return hour < p.EndHour && p.onDay(t.AddDate(0, 0, -1).Weekday())
+}
+
+func (p *QuotaProfile) onDay(day time.Weekday) bool {
+	if len(p.Days) == 0 {
+		return true
+	}
+	for _, d := range p.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// BudgetStatus reports a tenant's GPU-hour budget and the quota profile
+// in effect
+type BudgetStatus struct {
+	TenantID          string        `json:"tenant_id"`
+	Period            BudgetPeriod  `json:"period,omitempty"`
+	PeriodStart       *time.Time    `json:"period_start,omitempty"`
+	PeriodEnd         *time.Time    `json:"period_end,omitempty"`
+	AllowanceGPUHours float64       `json:"allowance_gpu_hours"`
+	UsedGPUHours      float64       `json:"used_gpu_hours"`
+	RemainingGPUHours *float64      `json:"remaining_gpu_hours,omitempty"`
+	State             BudgetState   `json:"state"`
+	TotalGPUHours     float64       `json:"total_gpu_hours"`
+	ActiveProfile     *QuotaProfile `json:"active_profile,omitempty"`
+	MaxGPUs           int           `json:"max_gpus"`
+	MaxConcurrentJobs int           `json:"max_concurrent_jobs"`
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgetPeriodStart(t *testing.T) {
+	// A Thursday afternoon
+	now := time.Date(2026, 3, 12, 15, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC), BudgetDaily.Start(now))
+	assert.Equal(t, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), BudgetWeekly.Start(now))
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), BudgetMonthly.Start(now))
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), BudgetMonthly.Next(BudgetMonthly.Start(now)))
+
+	// Sundays belong to the week that started the Monday before
+	sunday := time.Date(2026, 3, 15, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), BudgetWeekly.Start(sunday))
+}
+
+func TestBudgetRollover(t *testing.T) {
+	march := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+
+	budget := &GPUHourBudget{Period: BudgetMonthly, GPUHours: 100}
+	budget.Reset(march)
+	budget.Charge(70)
+	assert.False(t, budget.Roll(march.Add(time.Hour)))
+	assert.InDelta(t, 70, budget.UsedGPUHours, 1e-9)
+
+	// Without rollover a new period starts from scratch
+	assert.True(t, budget.Roll(march.AddDate(0, 1, 0)))
+	assert.Zero(t, budget.UsedGPUHours)
+	assert.InDelta(t, 100, budget.Allowance(), 1e-9)
+
+	// With rollover the unused hours carry over, up to one period's worth
+	budget = &GPUHourBudget{Period: BudgetMonthly, GPUHours: 100, Rollover: true}
+	budget.Reset(march)
+	budget.Charge(70)
+	budget.Roll(march.AddDate(0, 1, 0))
+	assert.InDelta(t, 130, budget.Allowance(), 1e-9)
+
+	budget.Roll(march.AddDate(0, 3, 0))
+	assert.InDelta(t, 200, budget.Allowance(), 1e-9)
+}
+
+func TestBudgetThresholds(t *testing.T) {
+	budget := &GPUHourBudget{Period: BudgetDaily, GPUHours: 10, SoftThreshold: 0.5, HardThreshold: 0.9}
+	assert.NoError(t, budget.Validate())
+	budget.Reset(time.Now())
+
+	assert.Equal(t, BudgetOK, budget.State())
+	budget.Charge(5)
+	assert.Equal(t, BudgetWarning, budget.State())
+	assert.InDelta(t, 4, budget.Remaining(), 1e-9)
+	budget.Charge(4)
+	assert.Equal(t, BudgetExhausted, budget.State())
+	assert.Zero(t, budget.Remaining())
+
+	// Defaults warn at 80% and block at 100%
+	budget = &GPUHourBudget{Period: BudgetDaily, GPUHours: 10, UsedGPUHours: 8}
+	assert.Equal(t, BudgetWarning, budget.State())
+
+	assert.Error(t, (&GPUHourBudget{Period: "yearly", GPUHours: 10}).Validate())
+	assert.Error(t, (&GPUHourBudget{Period: BudgetDaily}).Validate())
+	assert.Error(t, (&GPUHourBudget{Period: BudgetDaily, GPUHours: 10, SoftThreshold: 1.2}).Validate())
+}
+
+func TestQuotaProfileCovers(t *testing.T) {
+	business := &QuotaProfile{Name: "business", StartHour: 9, EndHour: 18,
+		Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}}
+	night := &QuotaProfile{Name: "night", StartHour: 22, EndHour: 6, Days: []time.Weekday{time.Friday}}
+
+	friday := time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		at       time.Time
+		business bool
+		night    bool
+	}{
+		{"friday morning", friday.Add(10 * time.Hour), true, false},
+		{"friday evening", friday.Add(18 * time.Hour), false, false},
+		{"friday late", friday.Add(23 * time.Hour), false, true},
+		{"saturday early", friday.Add(29 * time.Hour), false, true},
+		{"saturday late morning", friday.Add(34 * time.Hour), false, false},
+		{"thursday early", friday.Add(-2 * time.Hour), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.business, business.Covers(tt.at))
+			assert.Equal(t, tt.night, night.Covers(tt.at))
+		})
+	}
+
+	// A window with equal hours lasts all day
+	assert.True(t, (&QuotaProfile{Name: "always"}).Covers(friday.Add(13*time.Hour)))
+}
+
+func TestTenantAtTime(t *testing.T) {
+	tenant := &Tenant{MaxGPUs: 8, MaxConcurrentJobs: 10, QuotaProfiles: []QuotaProfile{
+		{Name: "business", StartHour: 9, EndHour: 18, MaxGPUs: 2},
+	}}
+
+	day := time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2, tenant.AtTime(day).MaxGPUs)
+	assert.Equal(t, 10, tenant.AtTime(day).MaxConcurrentJobs)
+	assert.Equal(t, 8, tenant.AtTime(day.Add(8*time.Hour)).MaxGPUs)
+	assert.Equal(t, 8, tenant.MaxGPUs)
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2024, 1, 10, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		next time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 1, 10, 10, 15, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, 1, 11, 2, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * MON-FRI", time.Date(2024, 1, 11, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 6 29 FEB *", time.Date(2024, 2, 29, 6, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match
+		{"0 0 15 * FRI", time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{"5/20 10 * * *", time.Date(2024, 1, 10, 10, 25, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.next, schedule.Next(from))
+		})
+	}
+}
+
+func TestCronNextInTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	schedule, err := ParseCron("0 2 * * *")
+	require.NoError(t, err)
+
+	next := schedule.Next(time.Date(2024, 3, 8, 12, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2024, 3, 9, 2, 0, 0, 0, loc), next)
+	assert.Equal(t, 7, next.UTC().Hour())
+
+	// 2am does not exist on the spring-forward day
+	next = schedule.Next(next)
+	assert.Equal(t, time.Date(2024, 3, 11, 2, 0, 0, 0, loc), next)
+	assert.Equal(t, 6, next.UTC().Hour())
+
+	// 1:30am happens twice on the fall-back day but fires once
+	schedule, err = ParseCron("30 1 * * *")
+	require.NoError(t, err)
+	next = schedule.Next(time.Date(2024, 11, 3, 0, 0, 0, 0, loc))
+	assert.Equal(t, 5, next.UTC().Hour())
+	next = schedule.Next(next)
+	assert.Equal(t, time.Date(2024, 11, 4, 1, 30, 0, 0, loc), next)
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "* * * FOO *", "5-1 * * * *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronJobValidate(t *testing.T) {
+	cronJob := &CronJob{TenantID: "tenant-1", Schedule: "@hourly", Template: &Job{GPUCount: 1}}
+	require.NoError(t, cronJob.Validate())
+	assert.Equal(t, "UTC", cronJob.Timezone)
+	assert.Equal(t, ConcurrencyAllow, cronJob.ConcurrencyPolicy)
+	assert.Equal(t, MissedRunOnce, cronJob.MissedRunPolicy)
+
+	assert.Error(t, (&CronJob{TenantID: "tenant-1", Schedule: "@hourly", Template: &Job{}, Timezone: "Mars/Olympus"}).Validate())
+	assert.Error(t, (&CronJob{TenantID: "tenant-1", Schedule: "@hourly", Template: &Job{}, ConcurrencyPolicy: "queue"}).Validate())
+	assert.Error(t, (&CronJob{TenantID: "tenant-1", Schedule: "@hourly"}).Validate())
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// CronScheduledTimeEnv is the environment variable carrying the time a
+// cron run was scheduled for
+const CronScheduledTimeEnv = "CRON_SCHEDULED_TIME"
+
+// ConcurrencyPolicy decides what a cron job does when a run is due while
+// an earlier run is still pending or running
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyAllow starts the new run alongside the earlier ones
+	ConcurrencyAllow ConcurrencyPolicy = "allow"
+	// ConcurrencyForbid skips the new run
+	ConcurrencyForbid ConcurrencyPolicy = "forbid"
+	// ConcurrencyReplace cancels the earlier runs and starts the new one
+	ConcurrencyReplace ConcurrencyPolicy = "replace"
+)
+
+// MissedRunPolicy decides what a cron job does about runs that were due
+// while the scheduler was down
+type MissedRunPolicy string
+
+const (
+	// MissedRunOnce starts a single catch-up run for all missed runs
+	MissedRunOnce MissedRunPolicy = "run_once"
+	// MissedRunSkip drops missed runs and waits for the next one
+	MissedRunSkip MissedRunPolicy = "skip"
+)
+
+// CronRunStatus is the outcome of a due cron run
+type CronRunStatus string
+
+const (
+	CronRunSubmitted CronRunStatus = "submitted"
+	CronRunSkipped   CronRunStatus = "skipped"
+	CronRunFailed    CronRunStatus = "failed"
+)
+
+// CronJob submits a copy of its job template every time its cron
+// expression fires in its timezone
+type CronJob struct {
+	ID                string            `json:"id" gorm:"primaryKey"`
+	Name              string            `json:"name"`
+	TenantID          string            `json:"tenant_id" gorm:"index"`
+	Schedule          string            `json:"schedule"`
+	Timezone          string            `json:"timezone"`
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrency_policy"`
+	MissedRunPolicy   MissedRunPolicy   `json:"missed_run_policy"`
+	Template          *Job              `json:"template" gorm:"serializer:json"`
+
+	NextRunAt *time.Time `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CronRun records one due run of a cron job and the job it submitted
+type CronRun struct {
+	ID           string        `json:"id" gorm:"primaryKey"`
+	CronJobID    string        `json:"cron_job_id" gorm:"index"`
+	JobID        string        `json:"job_id,omitempty"`
+	ScheduledFor time.Time     `json:"scheduled_for"`
+	Status       CronRunStatus `json:"status"`
+	Reason       string        `json:"reason,omitempty"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"index"`
+}
+
+// Validate checks the schedule, timezone and policies, filling in the
+// defaults: UTC, allow and run_once
+func (c *CronJob) Validate() error {
+	if c.TenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	if c.Template == nil {
+		return fmt.Errorf("cron job needs a job template")
+	}
+	if _, err := ParseCron(c.Schedule); err != nil {
+		return err
+	}
+
+	if c.Timezone == "" {
+		c.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", c.Timezone)
+	}
+
+	switch c.ConcurrencyPolicy {
+	case "":
+		c.ConcurrencyPolicy = ConcurrencyAllow
+	case ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace:
+	default:
+		return fmt.Errorf("unknown concurrency policy %q", c.ConcurrencyPolicy)
+	}
+
+	switch c.MissedRunPolicy {
+	case "":
+		c.MissedRunPolicy = MissedRunOnce
+	case MissedRunOnce, MissedRunSkip:
+	default:
+		return fmt.Errorf("unknown missed run policy %q", c.MissedRunPolicy)
+	}
+
+	return nil
+}
+
+// NextAfter returns the first run time after t, or nil if the schedule
+// never fires again
+func (c *CronJob) NextAfter(t time.Time) (*time.Time, error) {
+	schedule, err := ParseCron(c.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	next := schedule.Next(t.In(loc))
+	if next.IsZero() {
+		return nil, nil
+	}
+	return &next, nil
+}
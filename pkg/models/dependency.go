@@ -0,0 +1,51 @@
+package models
+
+// DependencyCondition sets which outcome of another job releases a
+// dependent job
+type DependencyCondition string
+
+const (
+	DependAfterOK    DependencyCondition = "afterok"
+	DependAfterAny   DependencyCondition = "afterany"
+	DependAfterNotOK DependencyCondition = "afternotok"
+)
+
+// IsValid checks if the condition is one of the supported conditions
+func (c DependencyCondition) IsValid() bool {
+	switch c {
+	case DependAfterOK, DependAfterAny, DependAfterNotOK:
+		return true
+	}
+	return false
+}
+
+// JobDependency makes a job wait on the outcome of another job
+type JobDependency struct {
+	JobID     string              `json:"job_id"`
+	Condition DependencyCondition `json:"condition"`
+}
+
+// Evaluate reports whether the dependency is met by the given state of
+// the job it waits on, and whether it can still be met later
+func (d JobDependency) Evaluate(state JobState) (met bool, possible bool) {
+	ok := state == JobStateCompleted
+	notOK := state == JobStateFailed || state == JobStateCancelled
+
+	switch d.Condition {
+	case DependAfterAny:
+		return ok || notOK, true
+	case DependAfterNotOK:
+		return notOK, !ok
+	default:
+		return ok, !notOK
+	}
+}
+
+// Workflow is a DAG of jobs submitted together. Dependencies between
+// steps refer to other steps by name.
+type Workflow struct {
+	ID       string `json:"id"`
+	TenantID string `json:"tenant_id"`
+	Name     string `json:"name"`
+	Jobs     []*Job `json:"jobs"`
+}
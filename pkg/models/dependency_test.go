@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobDependencyEvaluate(t *testing.T) {
+	tests := []struct {
+		condition DependencyCondition
+		state     JobState
+		met       bool
+		possible  bool
+	}{
+		{DependAfterOK, JobStateRunning, false, true},
+		{DependAfterOK, JobStateCompleted, true, true},
+		{DependAfterOK, JobStateFailed, false, false},
+		{DependAfterAny, JobStatePending, false, true},
+		{DependAfterAny, JobStateCancelled, true, true},
+		{DependAfterNotOK, JobStateFailed, true, true},
+		{DependAfterNotOK, JobStateCompleted, false, false},
+		{DependAfterNotOK, JobStatePreempted, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.condition)+"/"+string(tt.state), func(t *testing.T) {
+			met, possible := JobDependency{JobID: "job-1", Condition: tt.condition}.Evaluate(tt.state)
+			assert.Equal(t, tt.met, met)
+			assert.Equal(t, tt.possible, possible)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// EventType categorizes scheduler decisions recorded for operators
+type EventType string
+
+const (
+	EventThermalCooling     EventType = "thermal_cooling"
+	EventThermalSkip        EventType = "thermal_skip"
+	EventAllocationResized  EventType = "allocation_resized"
+	EventRuntimeWarning     EventType = "runtime_warning"
+	EventRuntimeExpired     EventType = "runtime_expired"
+	EventRuntimeExtended    EventType = "runtime_extended"
+	EventJobTimeout         EventType = "job_timeout"
+	EventJobFailed          EventType = "job_failed"
+	EventJobRetry           EventType = "job_retry"
+	EventReservationCreated EventType = "reservation_created"
+	EventReservationDeleted EventType = "reservation_deleted"
+	EventReservationExpired EventType = "reservation_expired"
+	EventQuotaReclaimed     EventType = "quota_reclaimed"
+	EventBudgetWarning      EventType = "budget_warning"
+	EventBudgetExhausted    EventType = "budget_exhausted"
+	EventTenantDeactivated  EventType = "tenant_deactivated"
+	EventTenantActivated    EventType = "tenant_activated"
+	EventNodeRegistered     EventType = "node_registered"
+	EventNodeOffline        EventType = "node_offline"
+)
+
+// Event records a scheduling decision so operators can see why it was made
+type Event struct {
+	ID           string            `json:"id" gorm:"primaryKey"`
+	Type         EventType         `json:"type" gorm:"index"`
+	Reason       string            `json:"reason"`
+	Message      string            `json:"message"`
+	JobID        string            `json:"job_id" gorm:"index"`
+	TenantID     string            `json:"tenant_id"`
+	NodeID       string            `json:"node_id" gorm:"index"`
+	GPUID        string            `json:"gpu_id"`
+	AllocationID string            `json:"allocation_id"`
+	Metadata     map[string]string `json:"metadata" gorm:"serializer:json"`
+	CreatedAt    time.Time         `json:"created_at" gorm:"index"`
+}
+
+// EventFilter narrows an event listing; empty fields match everything
+type EventFilter struct {
+	Type   EventType `json:"type"`
+	JobID  string    `json:"job_id"`
+	NodeID string    `json:"node_id"`
+	Limit  int       `json:"limit"`
+}
+
+// Matches checks if the event satisfies the filter
+func (f EventFilter) Matches(e *Event) bool {
+	return (f.Type == "" || e.Type == f.Type) &&
+		(f.JobID == "" || e.JobID == f.JobID) &&
+		(f.NodeID == "" || e.NodeID == f.NodeID)
+}
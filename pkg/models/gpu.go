@@ -1,7 +1,9 @@
 package models
 
 import (
+	"strings"
 	"time"
+	"unicode"
 )
 
 // GPUModel represents different GPU hardware models
@@ -17,6 +19,34 @@ const (
 	GPURTX4090 GPUModel = "RTX4090"
 )
 
+// ParseGPUModel maps a product name such as "NVIDIA A100-SXM4-80GB" to
+// its model. Names of unknown models are returned unchanged.
+func ParseGPUModel(name string) GPUModel {
+	tokens := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, model := range []GPUModel{GPUH100, GPUA100, GPUV100, GPURTX4090, GPUA10, GPUT4, GPUL4} {
+		for i, token := range tokens {
+			// "RTX 4090" is written with a space
+			candidates := []string{token}
+			if i+1 < len(tokens) {
+				candidates = append(candidates, token+tokens[i+1])
+			}
+			for _, c := range candidates {
+				if !strings.HasPrefix(c, string(model)) {
+					continue
+				}
+				// A10 is not an A100, nor L4 an L40
+				if rest := c[len(model):]; rest != "" && unicode.IsDigit(rune(rest[0])) {
+					continue
+				}
+				return model
+			}
+		}
+	}
+	return GPUModel(strings.TrimSpace(name))
+}
+
 // GPUHealth represents the health status of a GPU
 type GPUHealth string
 
@@ -43,6 +73,10 @@ type GPU struct {
 	JobID           string    `json:"job_id"`
 	TenantID        string    `json:"tenant_id"`
 	
+	// Fractional Sharing
+	SharingMode     GPUSharingMode `json:"sharing_mode"`
+	Slices          []GPUSlice `json:"slices" gorm:"serializer:json"`
+	
 	// Performance Metrics
 	Utilization     float64   `json:"utilization"`
 	Temperature     float64   `json:"temperature"`
@@ -109,6 +143,7 @@ type Node struct {
 // IsAvailable checks if GPU is available for allocation
 func (g *GPU) IsAvailable() bool {
 	return !g.Allocated && 
+	       len(g.Slices) == 0 &&
 	       g.Health == HealthHealthy && 
 	       !g.ThermalThrottle &&
 	       time.Since(g.CoolingPeriod) > 0
@@ -119,6 +154,21 @@ func (g *GPU) NeedsCooling(threshold float64) bool {
 	return g.Temperature > threshold
 }
 
+// IsCooling checks if GPU is still inside its cooling period
+func (g *GPU) IsCooling() bool {
+	return time.Now().Before(g.CoolingPeriod)
+}
+
+// StartCooling keeps the GPU out of scheduling for the given period
+func (g *GPU) StartCooling(period time.Duration) {
+	g.CoolingPeriod = time.Now().Add(period)
+}
+
+// ThermalHeadroom returns degrees remaining before the threshold
+func (g *GPU) ThermalHeadroom(threshold float64) float64 {
+	return threshold - g.Temperature
+}
+
 // UpdateMetrics updates GPU metrics
 func (g *GPU) UpdateMetrics(util, temp, power float64) {
 	g.Utilization = util
@@ -135,6 +185,15 @@ func (g *GPU) UpdateMetrics(util, temp, power float64) {
 	g.UpdateHealth()
 }
 
+// ApplyMetrics records a telemetry sample reported by the GPU's agent
+func (g *GPU) ApplyMetrics(m GPUMetrics) {
+	g.MemoryUsedMB = m.MemoryUsedMB
+	g.MemoryFreeMB = m.MemoryFreeMB
+	g.ThermalThrottle = m.ThermalThrottle
+	g.ErrorCount = m.ErrorCount
+	g.UpdateMetrics(m.Utilization, m.Temperature, m.PowerUsage)
+}
+
 // UpdateHealth determines GPU health status
 func (g *GPU) UpdateHealth() {
 	switch {
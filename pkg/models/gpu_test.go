@@ -179,3 +179,34 @@ func TestNodeNotSchedulable(t *testing.T) {
 		})
 	}
 }
+
+func TestStartCooling(t *testing.T) {
+	gpu := &GPU{Health: HealthHealthy}
+	assert.False(t, gpu.IsCooling())
+
+	gpu.StartCooling(10 * time.Minute)
+	assert.True(t, gpu.IsCooling())
+	assert.False(t, gpu.IsAvailable())
+}
+
+func TestThermalHeadroom(t *testing.T) {
+	gpu := &GPU{Temperature: 68.0}
+	assert.Equal(t, 7.0, gpu.ThermalHeadroom(75.0))
+	assert.Equal(t, -3.0, gpu.ThermalHeadroom(65.0))
+}
+
+func TestParseGPUModel(t *testing.T) {
+	tests := map[string]GPUModel{
+		"NVIDIA A100-SXM4-80GB":   GPUA100,
+		"NVIDIA A10":              GPUA10,
+		"NVIDIA H100 80GB HBM3":   GPUH100,
+		"Tesla V100-PCIE-16GB":    GPUV100,
+		"Tesla T4":                GPUT4,
+		"NVIDIA L4":               GPUL4,
+		"NVIDIA GeForce RTX 4090": GPURTX4090,
+		"NVIDIA L40S":             GPUModel("NVIDIA L40S"),
+	}
+	for name, expected := range tests {
+		assert.Equal(t, expected, ParseGPUModel(name), name)
+	}
+}
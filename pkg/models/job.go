@@ -9,6 +9,7 @@ type JobState string
 
 const (
 	JobStatePending    JobState = "pending"
+	JobStateWaiting    JobState = "waiting"
 	JobStateRunning    JobState = "running"
 	JobStateCompleted  JobState = "completed"
 	JobStateFailed     JobState = "failed"
@@ -24,6 +25,8 @@ type Job struct {
 	State             JobState          `json:"state" gorm:"index"`
 	Priority          int               `json:"priority"`
 	GPUCount          int               `json:"gpu_count"`
+	MinGPUs           int               `json:"min_gpus"`
+	MaxGPUs           int               `json:"max_gpus"`
 	GPUMemoryMB       int64             `json:"gpu_memory_mb"`
 	CPUCores          int               `json:"cpu_cores"`
 	MemoryMB          int64             `json:"memory_mb"`
@@ -33,10 +36,36 @@ type Job struct {
 	Command           []string          `json:"command" gorm:"serializer:json"`
 	Args              []string          `json:"args" gorm:"serializer:json"`
 	GangScheduling    bool              `json:"gang_scheduling"`
+	GPUSlice          *SliceRequest     `json:"gpu_slice" gorm:"serializer:json"`
+	GPUFraction       float64           `json:"gpu_fraction"`
 	MaxRuntime        time.Duration     `json:"max_runtime"`
+	Deadline          *time.Time        `json:"deadline,omitempty"`
 	CheckpointEnabled bool              `json:"checkpoint_enabled"`
 	CheckpointPath    string            `json:"checkpoint_path"`
-	
+
+	// Dependencies
+	Dependencies      []JobDependency   `json:"dependencies" gorm:"serializer:json"`
+	WorkflowID        string            `json:"workflow_id" gorm:"index"`
+	CronJobID         string            `json:"cron_job_id,omitempty" gorm:"index"`
+
+	// Job arrays
+	Array             *ArraySpec        `json:"array,omitempty" gorm:"serializer:json"`
+	ArrayParentID     string            `json:"array_parent_id,omitempty" gorm:"index"`
+	ArrayIndex        int               `json:"array_index"`
+
+	// Retries
+	RetryPolicy       *RetryPolicy      `json:"retry_policy,omitempty" gorm:"serializer:json"`
+	Attempts          []JobAttempt      `json:"attempts,omitempty" gorm:"serializer:json"`
+	RetryAt           *time.Time        `json:"retry_at,omitempty"`
+
+	// Placement
+	Queue             string            `json:"queue,omitempty" gorm:"index"`
+	Affinity          *Affinity         `json:"affinity" gorm:"serializer:json"`
+	PendingReason     string            `json:"pending_reason"`
+	FailureReason     string            `json:"failure_reason"`
+	ExpectedPowerW    float64           `json:"expected_power_w"`
+	ReservationID     string            `json:"reservation_id,omitempty" gorm:"index"`
+
 	// Timestamps
 	SubmittedAt       time.Time         `json:"submitted_at"`
 	ScheduledAt       *time.Time        `json:"scheduled_at"`
@@ -77,10 +106,19 @@ type JobStatus struct {
 	Message         string            `json:"message"`
 	AllocatedGPUs   []string          `json:"allocated_gpus"`
 	NodeName        string            `json:"node_name"`
+	Queue           string            `json:"queue,omitempty"`
 	QueuePosition   int               `json:"queue_position"`
 	EstimatedWait   time.Duration     `json:"estimated_wait"`
 	Logs            string            `json:"logs"`
 	Metrics         map[string]float64 `json:"metrics"`
+	ArrayJobs       map[JobState]int  `json:"array_jobs,omitempty"`
+	RuntimeDeadline *time.Time        `json:"runtime_deadline,omitempty"`
+	Attempt         int               `json:"attempt,omitempty"`
+	Attempts        []JobAttempt      `json:"attempts,omitempty"`
+	RetryAt         *time.Time        `json:"retry_at,omitempty"`
+	Deadline        *time.Time        `json:"deadline,omitempty"`
+	DeadlineSlack   *time.Duration    `json:"deadline_slack,omitempty"`
+	DeadlineUnreachable bool          `json:"deadline_unreachable,omitempty"`
 }
 
 // IsTerminal returns true if the job is in a terminal state
@@ -90,6 +128,48 @@ func (j *Job) IsTerminal() bool {
 	       j.State == JobStateCancelled
 }
 
+// IsFractional returns true if the job asks for part of a single GPU
+func (j *Job) IsFractional() bool {
+	return j.GPUSlice != nil
+}
+
+// WholeGPUs returns the number of whole GPUs the job takes
+func (j *Job) WholeGPUs() int {
+	if j.IsFractional() {
+		return 0
+	}
+	return j.GPUCount
+}
+
+// RequestedGPUs returns the GPUs the job counts against quota: its whole
+// GPUs, or the estimated share of a fractional request
+func (j *Job) RequestedGPUs() float64 {
+	if j.IsFractional() {
+		return j.GPUSlice.EstimatedShare()
+	}
+	return float64(j.GPUCount)
+}
+
+// IsArrayParent returns true if the job stands for a job array rather
+// than running itself
+func (j *Job) IsArrayParent() bool {
+	return j.Array != nil
+}
+
+// IsElastic returns true if the job can grow and shrink between
+// MinGPUs and MaxGPUs
+func (j *Job) IsElastic() bool {
+	return j.MaxGPUs > 0
+}
+
+// SurplusGPUs returns the GPUs an elastic job holds above its minimum
+func (j *Job) SurplusGPUs() int {
+	if !j.IsElastic() || j.GPUCount <= j.MinGPUs {
+		return 0
+	}
+	return j.GPUCount - j.MinGPUs
+}
+
 // IsActive returns true if the job is active (running or pending)
 func (j *Job) IsActive() bool {
 	return j.State == JobStateRunning || j.State == JobStatePending
@@ -101,3 +181,33 @@ func (j *Job) CalculateActualDuration() {
 		j.ActualDuration = j.CompletedAt.Sub(*j.StartedAt)
 	}
 }
+
+// ExpectedRuntime returns how long the job is expected to run: its
+// estimated duration, or its max runtime when there is no estimate
+func (j *Job) ExpectedRuntime() time.Duration {
+	if j.EstimatedDuration > 0 {
+		return j.EstimatedDuration
+	}
+	return j.MaxRuntime
+}
+
+// Slack returns how much the job's start can slip at now and still meet
+// its deadline. Running jobs count from when they started. ok is false
+// for jobs without a deadline.
+func (j *Job) Slack(now time.Time) (slack time.Duration, ok bool) {
+	if j.Deadline == nil {
+		return 0, false
+	}
+	start := now
+	if j.State == JobStateRunning && j.StartedAt != nil {
+		start = *j.StartedAt
+	}
+	return j.Deadline.Sub(start.Add(j.ExpectedRuntime())), true
+}
+
+// DeadlineUnreachable returns true if the job can no longer finish by
+// its deadline
+func (j *Job) DeadlineUnreachable(now time.Time) bool {
+	slack, ok := j.Slack(now)
+	return ok && slack < 0
+}
@@ -68,3 +68,33 @@ func TestCalculateActualDurationNilTimes(t *testing.T) {
 	job.CalculateActualDuration()
 	assert.Equal(t, time.Duration(0), job.ActualDuration)
 }
+
+func TestJobSlack(t *testing.T) {
+	now := time.Now()
+	deadline := now.Add(3 * time.Hour)
+	started := now.Add(-30 * time.Minute)
+
+	tests := []struct {
+		name        string
+		job         Job
+		slack       time.Duration
+		unreachable bool
+	}{
+		{"estimate preferred", Job{Deadline: &deadline, EstimatedDuration: time.Hour, MaxRuntime: 2 * time.Hour}, 2 * time.Hour, false},
+		{"max runtime fallback", Job{Deadline: &deadline, MaxRuntime: 2 * time.Hour}, time.Hour, false},
+		{"too long to finish", Job{Deadline: &deadline, MaxRuntime: 4 * time.Hour}, -time.Hour, true},
+		{"running counts from start", Job{Deadline: &deadline, State: JobStateRunning, StartedAt: &started, MaxRuntime: 3 * time.Hour}, 30 * time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack, ok := tt.job.Slack(now)
+			assert.True(t, ok)
+			assert.Equal(t, tt.slack, slack)
+			assert.Equal(t, tt.unreachable, tt.job.DeadlineUnreachable(now))
+		})
+	}
+
+	_, ok := (&Job{}).Slack(now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// LedgerEntryKind tells how the interval a ledger entry bills ended
+type LedgerEntryKind string
+
+const (
+	// LedgerRunning intervals ended with the job completing, failing,
+	// being cancelled or releasing GPUs on its own
+	LedgerRunning LedgerEntryKind = "running"
+	// LedgerPreempted intervals ended with the job being preempted
+	LedgerPreempted LedgerEntryKind = "preempted"
+	// LedgerCheckpointed intervals ended with the job checkpointing before
+	// it was preempted or hit its max runtime
+	LedgerCheckpointed LedgerEntryKind = "checkpointed"
+)
+
+// LedgerEntry is an immutable usage line item for one interval of an
+// allocation. Entries are only ever appended; corrections are new
+// entries.
+type LedgerEntry struct {
+	ID             string          `json:"id" gorm:"primaryKey"`
+	TenantID       string          `json:"tenant_id" gorm:"index"`
+	JobID          string          `json:"job_id" gorm:"index"`
+	AllocationID   string          `json:"allocation_id"`
+	NodeID         string          `json:"node_id"`
+	Queue          string          `json:"queue"`
+	GPUModel       GPUModel        `json:"gpu_model"`
+	Kind           LedgerEntryKind `json:"kind"`
+	GPUs           float64         `json:"gpus"`
+	StartedAt      time.Time       `json:"started_at"`
+	EndedAt        time.Time       `json:"ended_at" gorm:"index"`
+	GPUHours       float64         `json:"gpu_hours"`
+	RatePerGPUHour float64         `json:"rate_per_gpu_hour"`
+	Cost           float64         `json:"cost"`
+	Currency       string          `json:"currency"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// InvoiceLine sums a tenant's usage of one GPU model in one queue at one
+// rate
+type InvoiceLine struct {
+	GPUModel       GPUModel `json:"gpu_model"`
+	Queue          string   `json:"queue"`
+	RatePerGPUHour float64  `json:"rate_per_gpu_hour"`
+	GPUHours       float64  `json:"gpu_hours"`
+	Cost           float64  `json:"cost"`
+}
+
+// Invoice bills a tenant for the ledger entries ending within a period
+type Invoice struct {
+	TenantID      string         `json:"tenant_id"`
+	TenantName    string         `json:"tenant_name"`
+	PeriodStart   time.Time      `json:"period_start"`
+	PeriodEnd     time.Time      `json:"period_end"`
+	Currency      string         `json:"currency"`
+	Lines         []InvoiceLine  `json:"lines"`
+	Entries       []*LedgerEntry `json:"entries"`
+	TotalGPUHours float64        `json:"total_gpu_hours"`
+	TotalCost     float64        `json:"total_cost"`
+}
+
+// BuildInvoice bills the tenant for the entries that ended within
+// [start, end), summed by GPU model, queue and rate
+func BuildInvoice(tenant *Tenant, entries []*LedgerEntry, start, end time.Time, currency string) *Invoice {
+	invoice := &Invoice{
+		TenantID:    tenant.ID,
+		TenantName:  tenant.Name,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Currency:    currency,
+		Lines:       []InvoiceLine{},
+		Entries:     []*LedgerEntry{},
+	}
+
+	type lineKey struct {
+		model GPUModel
+		queue string
+		rate  float64
+	}
+	lines := make(map[lineKey]*InvoiceLine)
+	for _, entry := range entries {
+		if entry.TenantID != tenant.ID || entry.EndedAt.Before(start) || !entry.EndedAt.Before(end) {
+			continue
+		}
+		invoice.Entries = append(invoice.Entries, entry)
+		invoice.TotalGPUHours += entry.GPUHours
+		invoice.TotalCost += entry.Cost
+
+		key := lineKey{entry.GPUModel, entry.Queue, entry.RatePerGPUHour}
+		line, ok := lines[key]
+		if !ok {
+			line = &InvoiceLine{GPUModel: entry.GPUModel, Queue: entry.Queue, RatePerGPUHour: entry.RatePerGPUHour}
+			lines[key] = line
+		}
+		line.GPUHours += entry.GPUHours
+		line.Cost += entry.Cost
+	}
+
+	sort.Slice(invoice.Entries, func(i, j int) bool {
+		return invoice.Entries[i].EndedAt.Before(invoice.Entries[j].EndedAt)
+	})
+	for _, line := range lines {
+		invoice.Lines = append(invoice.Lines, *line)
+	}
+	sort.Slice(invoice.Lines, func(i, j int) bool {
+		a, b := invoice.Lines[i], invoice.Lines[j]
+		if a.GPUModel != b.GPUModel {
+			return a.GPUModel < b.GPUModel
+		}
+		if a.Queue != b.Queue {
+			return a.Queue < b.Queue
+		}
+		return a.RatePerGPUHour < b.RatePerGPUHour
+	})
+	return invoice
+}
+
+// WriteCSV writes the invoice's entries as CSV, one row per entry after a
+// header row
+func (inv *Invoice) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"tenant_id", "job_id", "allocation_id", "node_id", "queue", "gpu_model", "kind",
+		"gpus", "started_at", "ended_at", "gpu_hours", "rate_per_gpu_hour", "cost", "currency",
+	}); err != nil {
+		return err
+	}
+
+	for _, entry := range inv.Entries {
+		if err := writer.Write([]string{
+			entry.TenantID,
+			entry.JobID,
+			entry.AllocationID,
+			entry.NodeID,
+			entry.Queue,
+			string(entry.GPUModel),
+			string(entry.Kind),
+			strconv.FormatFloat(entry.GPUs, 'f', -1, 64),
+			entry.StartedAt.UTC().Format(time.RFC3339),
+			entry.EndedAt.UTC().Format(time.RFC3339),
+			fmt.Sprintf("%.4f", entry.GPUHours),
+			fmt.Sprintf("%.4f", entry.RatePerGPUHour),
+			fmt.Sprintf("%.2f", entry.Cost),
+			entry.Currency,
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInvoice(t *testing.T) {
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	april := march.AddDate(0, 1, 0)
+	entry := func(jobID string, model GPUModel, queue string, endedAt time.Time, hours, rate float64) *LedgerEntry {
+		return &LedgerEntry{
+			TenantID: "tenant-1", JobID: jobID, GPUModel: model, Queue: queue, Kind: LedgerRunning,
+			GPUs: 1, StartedAt: endedAt.Add(-time.Hour), EndedAt: endedAt,
+			GPUHours: hours, RatePerGPUHour: rate, Cost: hours * rate, Currency: "USD",
+		}
+	}
+
+	invoice := BuildInvoice(&Tenant{ID: "tenant-1", Name: "research"}, []*LedgerEntry{
+		entry("late", GPUH100, "batch", march.AddDate(0, 0, 20), 2, 5),
+		entry("early", GPUH100, "batch", march.AddDate(0, 0, 1), 3, 5),
+		entry("a100", GPUA100, "batch", march.AddDate(0, 0, 2), 4, 3),
+		entry("before", GPUH100, "batch", march.Add(-time.Minute), 10, 5),
+		entry("after", GPUH100, "batch", april, 10, 5),
+	}, march, april, "USD")
+
+	require.Len(t, invoice.Entries, 3)
+	assert.Equal(t, "early", invoice.Entries[0].JobID)
+	assert.Equal(t, []InvoiceLine{
+		{GPUModel: GPUA100, Queue: "batch", RatePerGPUHour: 3, GPUHours: 4, Cost: 12},
+		{GPUModel: GPUH100, Queue: "batch", RatePerGPUHour: 5, GPUHours: 5, Cost: 25},
+	}, invoice.Lines)
+	assert.InDelta(t, 9.0, invoice.TotalGPUHours, 1e-9)
+	assert.InDelta(t, 37.0, invoice.TotalCost, 1e-9)
+
+	var out bytes.Buffer
+	require.NoError(t, invoice.WriteCSV(&out))
+	rows := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, rows, 4)
+	assert.True(t, strings.HasPrefix(rows[0], "tenant_id,job_id,"))
+	assert.Equal(t, "tenant-1,early,,,batch,H100,running,1,2026-03-01T23:00:00Z,2026-03-02T00:00:00Z,3.0000,5.0000,15.00,USD", rows[1])
+}
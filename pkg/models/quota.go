@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuotaGroupKind is a level of the quota tree
+type QuotaGroupKind string
+
+const (
+	QuotaOrganization QuotaGroupKind = "organization"
+	QuotaTeam         QuotaGroupKind = "team"
+	QuotaProject      QuotaGroupKind = "project"
+)
+
+// IsValid returns true if the kind is a known level
+func (k QuotaGroupKind) IsValid() bool {
+	return k.ParentKind() != "" || k == QuotaOrganization
+}
+
+// ParentKind returns the kind a group of this kind must be nested under.
+// Organizations are roots and have none.
+func (k QuotaGroupKind) ParentKind() QuotaGroupKind {
+	switch k {
+	case QuotaTeam:
+		return QuotaOrganization
+	case QuotaProject:
+		return QuotaTeam
+	}
+	return ""
+}
+
+// QuotaGroup is a node of the organization → team → project quota tree.
+// Tenants belong to a group and count against it and every ancestor. A
+// zero limit leaves the resource unlimited at that level, though still
+// bound by the ancestors' limits.
+type QuotaGroup struct {
+	ID       string         `json:"id" gorm:"primaryKey"`
+	Name     string         `json:"name"`
+	Kind     QuotaGroupKind `json:"kind"`
+	ParentID string         `json:"parent_id,omitempty" gorm:"index"`
+
+	// Quotas
+	MaxGPUs           int   `json:"max_gpus"`
+	MaxGPUMemoryMB    int64 `json:"max_gpu_memory_mb"`
+	MaxCPUCores       int   `json:"max_cpu_cores"`
+	MaxMemoryMB       int64 `json:"max_memory_mb"`
+	MaxConcurrentJobs int   `json:"max_concurrent_jobs"`
+
+	// Usage and Children are rolled up from the tenants when the tree is
+	// read
+	Usage    QuotaUsage    `json:"usage" gorm:"-"`
+	Tenants  []string      `json:"tenants,omitempty" gorm:"-"`
+	Children []*QuotaGroup `json:"children,omitempty" gorm:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QuotaUsage is the resource usage of every tenant under a quota group
+type QuotaUsage struct {
+	GPUs        float64 `json:"gpus"`
+	GPUMemoryMB int64   `json:"gpu_memory_mb"`
+	CPUCores    int     `json:"cpu_cores"`
+	MemoryMB    int64   `json:"memory_mb"`
+	Jobs        int     `json:"jobs"`
+}
+
+// Add counts the tenant's current usage
+func (u *QuotaUsage) Add(tenant *Tenant) {
+	u.GPUs += tenant.GPUsInUse()
+	u.GPUMemoryMB += tenant.CurrentGPUMemory
+	u.CPUCores += tenant.CurrentCPUCores
+	u.MemoryMB += tenant.CurrentMemory
+	u.Jobs += tenant.CurrentJobs
+}
+
+// Validate checks the group's name, kind and limits
+func (g *QuotaGroup) Validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("quota group name is required")
+	}
+	if !g.Kind.IsValid() {
+		return fmt.Errorf("unknown quota group kind %q", g.Kind)
+	}
+	if g.Kind == QuotaOrganization && g.ParentID != "" {
+		return fmt.Errorf("an organization cannot have a parent")
+	}
+	if g.Kind != QuotaOrganization && g.ParentID == "" {
+		return fmt.Errorf("a %s needs a parent %s", g.Kind, g.Kind.ParentKind())
+	}
+	if g.MaxGPUs < 0 || g.MaxGPUMemoryMB < 0 || g.MaxCPUCores < 0 || g.MaxMemoryMB < 0 || g.MaxConcurrentJobs < 0 {
+		return fmt.Errorf("quota limits cannot be negative")
+	}
+	return nil
+}
+
+// FitsWithin returns an error if the group cannot be nested under parent,
+// either because of its kind or because a limit exceeds the parent's
+func (g *QuotaGroup) FitsWithin(parent *QuotaGroup) error {
+	if g.Kind.ParentKind() != parent.Kind {
+		return fmt.Errorf("a %s cannot be nested under a %s", g.Kind, parent.Kind)
+	}
+	for _, limit := range []struct {
+		name          string
+		child, parent int64
+	}{
+		{"max_gpus", int64(g.MaxGPUs), int64(parent.MaxGPUs)},
+		{"max_gpu_memory_mb", g.MaxGPUMemoryMB, parent.MaxGPUMemoryMB},
+		{"max_cpu_cores", int64(g.MaxCPUCores), int64(parent.MaxCPUCores)},
+		{"max_memory_mb", g.MaxMemoryMB, parent.MaxMemoryMB},
+		{"max_concurrent_jobs", int64(g.MaxConcurrentJobs), int64(parent.MaxConcurrentJobs)},
+	} {
+		if limit.parent == 0 {
+			continue
+		}
+		if limit.child == 0 || limit.child > limit.parent {
+			return fmt.Errorf("%s of %s %s exceeds the %d allowed by %s %s",
+				limit.name, g.Kind, g.Name, limit.parent, parent.Kind, parent.Name)
+		}
+	}
+	return nil
+}
+
+// HasAvailableQuota checks if the group's usage leaves room for the
+// requested resources and one more job
+func (g *QuotaGroup) HasAvailableQuota(gpus float64, gpuMemory int64, cpus int, memory int64) bool {
+	return (g.MaxGPUs == 0 || g.Usage.GPUs+gpus <= float64(g.MaxGPUs)+1e-9) &&
+		(g.MaxGPUMemoryMB == 0 || g.Usage.GPUMemoryMB+gpuMemory <= g.MaxGPUMemoryMB) &&
+		(g.MaxCPUCores == 0 || g.Usage.CPUCores+cpus <= g.MaxCPUCores) &&
+		(g.MaxMemoryMB == 0 || g.Usage.MemoryMB+memory <= g.MaxMemoryMB) &&
+		(g.MaxConcurrentJobs == 0 || g.Usage.Jobs+1 <= g.MaxConcurrentJobs)
+}
+
+// ExceededLimit returns the first resource whose requested amount alone
+// exceeds the group's limit, or "" if a job of this size can ever run
+func (g *QuotaGroup) ExceededLimit(gpus float64, gpuMemory int64, cpus int, memory int64) string {
+	switch {
+	case g.MaxGPUs > 0 && gpus > float64(g.MaxGPUs)+1e-9:
+		return "GPUs"
+	case g.MaxGPUMemoryMB > 0 && gpuMemory > g.MaxGPUMemoryMB:
+		return "GPU memory"
+	case g.MaxCPUCores > 0 && cpus > g.MaxCPUCores:
+		return "CPU cores"
+	case g.MaxMemoryMB > 0 && memory > g.MaxMemoryMB:
+		return "memory"
+	}
+	return ""
+}
+
+// GPUHeadroom returns the GPUs the group's usage leaves free, and false
+// if the group does not limit GPUs
+func (g *QuotaGroup) GPUHeadroom() (float64, bool) {
+	if g.MaxGPUs == 0 {
+		return 0, false
+	}
+	return float64(g.MaxGPUs) - g.Usage.GPUs, true
+}
+
+// BuildQuotaTree links groups to their children, rolls each tenant's
+// usage up through its group and every ancestor, and returns the roots.
+// Groups whose parent is missing are returned as roots.
+func BuildQuotaTree(groups []*QuotaGroup, tenants []*Tenant) []*QuotaGroup {
+	byID := make(map[string]*QuotaGroup, len(groups))
+	for _, group := range groups {
+		group.Usage = QuotaUsage{}
+		group.Tenants = nil
+		group.Children = nil
+		byID[group.ID] = group
+	}
+
+	var roots []*QuotaGroup
+	for _, group := range groups {
+		if parent, ok := byID[group.ParentID]; ok && group.ParentID != group.ID {
+			parent.Children = append(parent.Children, group)
+		} else {
+			roots = append(roots, group)
+		}
+	}
+
+	for _, tenant := range tenants {
+		group, ok := byID[tenant.QuotaGroupID]
+		if !ok {
+			continue
+		}
+		group.Tenants = append(group.Tenants, tenant.ID)
+		// The tree is at most three levels deep; the bound guards
+		// against cycles in stored data
+		for depth := 0; group != nil && depth < 3; depth++ {
+			group.Usage.Add(tenant)
+			group = byID[group.ParentID]
+		}
+	}
+	return roots
+}
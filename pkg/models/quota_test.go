@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaGroupFitsWithin(t *testing.T) {
+	org := &QuotaGroup{ID: "org", Name: "acme", Kind: QuotaOrganization, MaxGPUs: 16}
+
+	tests := []struct {
+		name  string
+		group *QuotaGroup
+		fits  bool
+	}{
+		{"within the parent", &QuotaGroup{Name: "ml", Kind: QuotaTeam, MaxGPUs: 8}, true},
+		{"equal to the parent", &QuotaGroup{Name: "ml", Kind: QuotaTeam, MaxGPUs: 16}, true},
+		{"above the parent", &QuotaGroup{Name: "ml", Kind: QuotaTeam, MaxGPUs: 32}, false},
+		{"unlimited under a limit", &QuotaGroup{Name: "ml", Kind: QuotaTeam}, false},
+		{"limit the parent leaves open", &QuotaGroup{Name: "ml", Kind: QuotaTeam, MaxGPUs: 8, MaxCPUCores: 64}, true},
+		{"project under an organization", &QuotaGroup{Name: "llm", Kind: QuotaProject, MaxGPUs: 4}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.group.FitsWithin(org)
+			assert.Equal(t, tt.fits, err == nil, "error: %v", err)
+		})
+	}
+}
+
+func TestQuotaGroupValidate(t *testing.T) {
+	assert.NoError(t, (&QuotaGroup{Name: "acme", Kind: QuotaOrganization}).Validate())
+	assert.Error(t, (&QuotaGroup{Name: "acme", Kind: QuotaOrganization, ParentID: "other"}).Validate())
+	assert.Error(t, (&QuotaGroup{Name: "ml", Kind: QuotaTeam}).Validate())
+	assert.Error(t, (&QuotaGroup{Name: "ml", Kind: "division"}).Validate())
+	assert.Error(t, (&QuotaGroup{Name: "acme", Kind: QuotaOrganization, MaxGPUs: -1}).Validate())
+}
+
+func TestBuildQuotaTree(t *testing.T) {
+	org := &QuotaGroup{ID: "org", Kind: QuotaOrganization, MaxGPUs: 8, MaxConcurrentJobs: 3}
+	team := &QuotaGroup{ID: "team", Kind: QuotaTeam, ParentID: "org", MaxGPUs: 6}
+	project := &QuotaGroup{ID: "project", Kind: QuotaProject, ParentID: "team"}
+	tenants := []*Tenant{
+		{ID: "a", QuotaGroupID: "project", CurrentGPUs: 2, CurrentGPUFraction: 0.5, CurrentJobs: 2},
+		{ID: "b", QuotaGroupID: "org", CurrentGPUs: 1, CurrentJobs: 1},
+		{ID: "c", CurrentGPUs: 4, CurrentJobs: 1},
+	}
+
+	roots := BuildQuotaTree([]*QuotaGroup{project, team, org}, tenants)
+
+	require.Len(t, roots, 1)
+	assert.Same(t, org, roots[0])
+	assert.Equal(t, []*QuotaGroup{team}, org.Children)
+	assert.Equal(t, []*QuotaGroup{project}, team.Children)
+	assert.Equal(t, []string{"a"}, project.Tenants)
+
+	assert.InDelta(t, 2.5, project.Usage.GPUs, 1e-9)
+	assert.InDelta(t, 2.5, team.Usage.GPUs, 1e-9)
+	assert.InDelta(t, 3.5, org.Usage.GPUs, 1e-9)
+	assert.Equal(t, 3, org.Usage.Jobs)
+
+	// The team has room for 3.5 more GPUs but the organization's job
+	// limit is reached
+	assert.True(t, team.HasAvailableQuota(3, 0, 0, 0))
+	assert.False(t, team.HasAvailableQuota(4, 0, 0, 0))
+	assert.False(t, org.HasAvailableQuota(1, 0, 0, 0))
+	assert.True(t, project.HasAvailableQuota(100, 0, 0, 0))
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReservationPhase describes where a reservation is relative to its window
+type ReservationPhase string
+
+const (
+	ReservationScheduled ReservationPhase = "scheduled"
+	ReservationActive    ReservationPhase = "active"
+	ReservationEnded     ReservationPhase = "ended"
+)
+
+// Reservation sets GPUs aside for a tenant during a time window. The GPUs
+// are chosen when the reservation is created, optionally restricted to
+// some nodes or a GPU model. Only jobs naming the reservation may run on
+// them during the window.
+type Reservation struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	TenantID  string    `json:"tenant_id" gorm:"index"`
+	GPUCount  int       `json:"gpu_count"`
+	NodeIDs   []string  `json:"node_ids,omitempty" gorm:"serializer:json"`
+	GPUModel  GPUModel  `json:"gpu_model,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+
+	// GPUs set aside for the reservation
+	GPUIDs []string `json:"gpu_ids" gorm:"serializer:json"`
+
+	// Phase is computed when the reservation is read
+	Phase ReservationPhase `json:"phase" gorm:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the GPU count and window
+func (r *Reservation) Validate() error {
+	if r.TenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	if r.GPUCount <= 0 {
+		return fmt.Errorf("reservation needs at least one GPU")
+	}
+	if !r.EndTime.After(r.StartTime) {
+		return fmt.Errorf("reservation must end after it starts")
+	}
+	return nil
+}
+
+// PhaseAt returns the reservation's phase at now
+func (r *Reservation) PhaseAt(now time.Time) ReservationPhase {
+	switch {
+	case now.Before(r.StartTime):
+		return ReservationScheduled
+	case now.Before(r.EndTime):
+		return ReservationActive
+	}
+	return ReservationEnded
+}
+
+// Overlaps returns true if the window intersects [start, end). A zero
+// end means the interval never ends.
+func (r *Reservation) Overlaps(start, end time.Time) bool {
+	if !end.IsZero() && !end.After(r.StartTime) {
+		return false
+	}
+	return start.Before(r.EndTime)
+}
+
+// Matches returns true if the GPU meets the reservation's node and model
+// restrictions
+func (r *Reservation) Matches(gpu *GPU) bool {
+	if r.GPUModel != "" && gpu.Model != r.GPUModel {
+		return false
+	}
+	if len(r.NodeIDs) == 0 {
+		return true
+	}
+	for _, id := range r.NodeIDs {
+		if id == gpu.NodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// Holds returns true if the GPU is set aside for the reservation
+func (r *Reservation) Holds(gpuID string) bool {
+	for _, id := range r.GPUIDs {
+		if id == gpuID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservationWindow(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	reservation := &Reservation{TenantID: "tenant-1", GPUCount: 2, StartTime: start, EndTime: start.Add(2 * time.Hour)}
+
+	assert.Equal(t, ReservationScheduled, reservation.PhaseAt(start.Add(-time.Minute)))
+	assert.Equal(t, ReservationActive, reservation.PhaseAt(start))
+	assert.Equal(t, ReservationEnded, reservation.PhaseAt(start.Add(2*time.Hour)))
+
+	tests := []struct {
+		name     string
+		from     time.Duration
+		to       time.Duration
+		unbound  bool
+		overlaps bool
+	}{
+		{"ends at start", -time.Hour, 0, false, false},
+		{"runs into window", -time.Hour, time.Minute, false, true},
+		{"inside window", 30 * time.Minute, time.Hour, false, true},
+		{"starts at end", 2 * time.Hour, 3 * time.Hour, false, false},
+		{"unbounded before window", -time.Hour, 0, true, true},
+		{"unbounded after window", 2 * time.Hour, 0, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var end time.Time
+			if !tt.unbound {
+				end = start.Add(tt.to)
+			}
+			assert.Equal(t, tt.overlaps, reservation.Overlaps(start.Add(tt.from), end))
+		})
+	}
+}
+
+func TestReservationMatches(t *testing.T) {
+	reservation := &Reservation{NodeIDs: []string{"node-a"}, GPUModel: GPUH100}
+
+	assert.True(t, reservation.Matches(&GPU{NodeID: "node-a", Model: GPUH100}))
+	assert.False(t, reservation.Matches(&GPU{NodeID: "node-b", Model: GPUH100}))
+	assert.False(t, reservation.Matches(&GPU{NodeID: "node-a", Model: GPUA100}))
+	assert.True(t, (&Reservation{}).Matches(&GPU{NodeID: "node-b", Model: GPUA100}))
+}
+
+func TestReservationValidate(t *testing.T) {
+	start := time.Now()
+	assert.NoError(t, (&Reservation{TenantID: "t", GPUCount: 1, StartTime: start, EndTime: start.Add(time.Hour)}).Validate())
+	assert.Error(t, (&Reservation{GPUCount: 1, StartTime: start, EndTime: start.Add(time.Hour)}).Validate())
+	assert.Error(t, (&Reservation{TenantID: "t", StartTime: start, EndTime: start.Add(time.Hour)}).Validate())
+	assert.Error(t, (&Reservation{TenantID: "t", GPUCount: 1, StartTime: start, EndTime: start}).Validate())
+}
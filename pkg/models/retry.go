@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// FailureClass groups the reasons a job attempt can fail
+type FailureClass string
+
+const (
+	FailureNodeFailure FailureClass = "node_failure"
+	FailurePreemption  FailureClass = "preemption"
+	FailureOOM         FailureClass = "oom"
+	FailureNonZeroExit FailureClass = "non_zero_exit"
+	FailureTimeout     FailureClass = "timeout"
+)
+
+// IsValid returns true for a known failure class
+func (c FailureClass) IsValid() bool {
+	switch c {
+	case FailureNodeFailure, FailurePreemption, FailureOOM, FailureNonZeroExit, FailureTimeout:
+		return true
+	}
+	return false
+}
+
+const (
+	// DefaultRetryBackoff is the delay before the first retry when a
+	// policy sets none
+	DefaultRetryBackoff = 30 * time.Second
+	// DefaultMaxRetryBackoff caps the exponential backoff when a policy
+	// sets no cap
+	DefaultMaxRetryBackoff = 10 * time.Minute
+)
+
+// DefaultRetryOn lists the failure classes retried when a policy names
+// none: failures of the infrastructure rather than of the job itself
+var DefaultRetryOn = []FailureClass{FailureNodeFailure, FailurePreemption}
+
+// RetryPolicy controls how a failed job is requeued. MaxAttempts counts
+// every run of the job, including the first. The delay before retry n is
+// BackoffSec * 2^(n-1), capped at MaxBackoffSec.
+type RetryPolicy struct {
+	MaxAttempts   int            `json:"max_attempts"`
+	BackoffSec    int            `json:"backoff_sec,omitempty"`
+	MaxBackoffSec int            `json:"max_backoff_sec,omitempty"`
+	RetryOn       []FailureClass `json:"retry_on,omitempty"`
+}
+
+// Validate checks the attempt count, backoff and failure classes
+func (p *RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("retry policy needs at least one attempt")
+	}
+	if p.BackoffSec < 0 || p.MaxBackoffSec < 0 {
+		return fmt.Errorf("retry backoff cannot be negative")
+	}
+	for _, class := range p.RetryOn {
+		if !class.IsValid() {
+			return fmt.Errorf("unknown failure class %s", class)
+		}
+	}
+	return nil
+}
+
+// Retryable returns true if the policy retries failures of the class
+func (p *RetryPolicy) Retryable(class FailureClass) bool {
+	if p == nil {
+		return false
+	}
+	retryOn := p.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = DefaultRetryOn
+	}
+	for _, c := range retryOn {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldRetry returns true if a job that has run attempts times and last
+// failed with class gets another attempt
+func (p *RetryPolicy) ShouldRetry(class FailureClass, attempts int) bool {
+	return p.Retryable(class) && attempts < p.MaxAttempts
+}
+
+// Backoff returns the delay before the given retry, counting from 1
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	base := DefaultRetryBackoff
+	if p.BackoffSec > 0 {
+		base = time.Duration(p.BackoffSec) * time.Second
+	}
+	limit := DefaultMaxRetryBackoff
+	if p.MaxBackoffSec > 0 {
+		limit = time.Duration(p.MaxBackoffSec) * time.Second
+	}
+
+	delay := base
+	for i := 1; i < retry && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+// JobAttempt records one run of a job that did not complete
+type JobAttempt struct {
+	Attempt      int          `json:"attempt"`
+	NodeID       string       `json:"node_id"`
+	StartedAt    *time.Time   `json:"started_at"`
+	EndedAt      time.Time    `json:"ended_at"`
+	FailureClass FailureClass `json:"failure_class"`
+	Reason       string       `json:"reason"`
+	ExitCode     int          `json:"exit_code,omitempty"`
+}
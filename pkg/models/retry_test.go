@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 10, BackoffSec: 10, MaxBackoffSec: 60}
+
+	assert.Equal(t, 10*time.Second, policy.Backoff(1))
+	assert.Equal(t, 20*time.Second, policy.Backoff(2))
+	assert.Equal(t, 40*time.Second, policy.Backoff(3))
+	assert.Equal(t, 60*time.Second, policy.Backoff(4))
+	assert.Equal(t, 60*time.Second, policy.Backoff(9))
+
+	defaults := &RetryPolicy{MaxAttempts: 3}
+	assert.Equal(t, DefaultRetryBackoff, defaults.Backoff(1))
+	assert.Equal(t, DefaultMaxRetryBackoff, defaults.Backoff(20))
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *RetryPolicy
+		class    FailureClass
+		attempts int
+		retry    bool
+	}{
+		{"no policy", nil, FailureNodeFailure, 1, false},
+		{"default classes", &RetryPolicy{MaxAttempts: 3}, FailurePreemption, 1, true},
+		{"default skips exit codes", &RetryPolicy{MaxAttempts: 3}, FailureNonZeroExit, 1, false},
+		{"listed class", &RetryPolicy{MaxAttempts: 3, RetryOn: []FailureClass{FailureOOM}}, FailureOOM, 2, true},
+		{"unlisted class", &RetryPolicy{MaxAttempts: 3, RetryOn: []FailureClass{FailureOOM}}, FailureNodeFailure, 1, false},
+		{"attempts used up", &RetryPolicy{MaxAttempts: 3}, FailureNodeFailure, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retry, tt.policy.ShouldRetry(tt.class, tt.attempts))
+		})
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	assert.NoError(t, (&RetryPolicy{MaxAttempts: 1}).Validate())
+	assert.Error(t, (&RetryPolicy{}).Validate())
+	assert.Error(t, (&RetryPolicy{MaxAttempts: 2, BackoffSec: -1}).Validate())
+	assert.Error(t, (&RetryPolicy{MaxAttempts: 2, RetryOn: []FailureClass{"segfault"}}).Validate())
+}
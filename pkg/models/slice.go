@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+)
+
+// GPUSharingMode controls whether a GPU can be split between jobs
+type GPUSharingMode string
+
+const (
+	SharingExclusive GPUSharingMode = "exclusive"
+	SharingMIG       GPUSharingMode = "mig"
+	SharingTimeSlice GPUSharingMode = "time-slice"
+	SharingMPS       GPUSharingMode = "mps"
+)
+
+// MIGComputeSlices is the number of compute slices on a MIG-capable GPU
+const MIGComputeSlices = 7
+
+// MIGProfile describes a Multi-Instance GPU partition
+type MIGProfile struct {
+	Name          string `json:"name"`
+	ComputeSlices int    `json:"compute_slices"`
+	MemoryMB      int64  `json:"memory_mb"`
+}
+
+// MIGProfiles lists the partitions supported on A100 (40 GB and 80 GB)
+// and H100 GPUs
+var MIGProfiles = []MIGProfile{
+	{Name: "1g.5gb", ComputeSlices: 1, MemoryMB: 5120},
+	{Name: "2g.10gb", ComputeSlices: 2, MemoryMB: 10240},
+	{Name: "3g.20gb", ComputeSlices: 3, MemoryMB: 20480},
+	{Name: "4g.20gb", ComputeSlices: 4, MemoryMB: 20480},
+	{Name: "7g.40gb", ComputeSlices: 7, MemoryMB: 40960},
+	{Name: "1g.10gb", ComputeSlices: 1, MemoryMB: 10240},
+	{Name: "1g.20gb", ComputeSlices: 1, MemoryMB: 20480},
+	{Name: "2g.20gb", ComputeSlices: 2, MemoryMB: 20480},
+	{Name: "3g.40gb", ComputeSlices: 3, MemoryMB: 40960},
+	{Name: "4g.40gb", ComputeSlices: 4, MemoryMB: 40960},
+	{Name: "7g.80gb", ComputeSlices: 7, MemoryMB: 81920},
+}
+
+// LookupMIGProfile finds a MIG profile by name
+func LookupMIGProfile(name string) (MIGProfile, bool) {
+	for _, profile := range MIGProfiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return MIGProfile{}, false
+}
+
+// SupportsMIG checks if a GPU model can be partitioned with MIG
+func SupportsMIG(model GPUModel) bool {
+	return model == GPUA100 || model == GPUH100
+}
+
+// SliceRequest asks for part of a single GPU instead of whole GPUs.
+// Set MIGProfile for a MIG partition, or MemoryMB for a time-sliced or
+// MPS share.
+type SliceRequest struct {
+	MIGProfile string `json:"mig_profile,omitempty"`
+	MemoryMB   int64  `json:"memory_mb,omitempty"`
+}
+
+// Validate checks that exactly one kind of slice is requested
+func (r *SliceRequest) Validate() error {
+	if r.MIGProfile != "" {
+		if r.MemoryMB != 0 {
+			return fmt.Errorf("slice request cannot set both mig_profile and memory_mb")
+		}
+		if _, ok := LookupMIGProfile(r.MIGProfile); !ok {
+			return fmt.Errorf("unknown MIG profile %s", r.MIGProfile)
+		}
+		return nil
+	}
+	if r.MemoryMB <= 0 {
+		return fmt.Errorf("slice request needs a mig_profile or a positive memory_mb")
+	}
+	return nil
+}
+
+// EstimatedShare returns the GPU fraction known before placement. MIG
+// shares are fixed by the profile; memory shares depend on the GPU picked
+// and are only known once placed, so they report zero.
+func (r *SliceRequest) EstimatedShare() float64 {
+	if profile, ok := LookupMIGProfile(r.MIGProfile); ok {
+		return float64(profile.ComputeSlices) / MIGComputeSlices
+	}
+	return 0
+}
+
+// GPUSlice is a share of a GPU held by one allocation
+type GPUSlice struct {
+	AllocationID  string  `json:"allocation_id"`
+	JobID         string  `json:"job_id"`
+	TenantID      string  `json:"tenant_id"`
+	Profile       string  `json:"profile,omitempty"`
+	ComputeSlices int     `json:"compute_slices,omitempty"`
+	MemoryMB      int64   `json:"memory_mb"`
+	Fraction      float64 `json:"fraction"`
+}
+
+// IsShared checks if the GPU is configured for fractional allocation
+func (g *GPU) IsShared() bool {
+	return g.SharingMode != "" && g.SharingMode != SharingExclusive
+}
+
+// InUse checks if the GPU is held whole or by at least one slice
+func (g *GPU) InUse() bool {
+	return g.Allocated || len(g.Slices) > 0
+}
+
+// SliceUsage returns the compute slices and memory held by slices
+func (g *GPU) SliceUsage() (int, int64) {
+	compute, memory := 0, int64(0)
+	for _, slice := range g.Slices {
+		compute += slice.ComputeSlices
+		memory += slice.MemoryMB
+	}
+	return compute, memory
+}
+
+// SharedFraction returns the fraction of the GPU held by slices
+func (g *GPU) SharedFraction() float64 {
+	total := 0.0
+	for _, slice := range g.Slices {
+		total += slice.Fraction
+	}
+	return total
+}
+
+// FitSlice returns the slice the request would take on this GPU, or
+// false if the GPU cannot host it
+func (g *GPU) FitSlice(request *SliceRequest) (GPUSlice, bool) {
+	if g.Allocated || !g.IsShared() ||
+		g.Health != HealthHealthy || g.ThermalThrottle || g.IsCooling() {
+		return GPUSlice{}, false
+	}
+
+	usedCompute, usedMemory := g.SliceUsage()
+
+	if request.MIGProfile != "" {
+		profile, ok := LookupMIGProfile(request.MIGProfile)
+		if !ok || g.SharingMode != SharingMIG || !SupportsMIG(g.Model) {
+			return GPUSlice{}, false
+		}
+		if usedCompute+profile.ComputeSlices > MIGComputeSlices ||
+			usedMemory+profile.MemoryMB > g.MemoryTotalMB {
+			return GPUSlice{}, false
+		}
+		return GPUSlice{
+			Profile:       profile.Name,
+			ComputeSlices: profile.ComputeSlices,
+			MemoryMB:      profile.MemoryMB,
+			Fraction:      float64(profile.ComputeSlices) / MIGComputeSlices,
+		}, true
+	}
+
+	if g.SharingMode == SharingMIG || request.MemoryMB <= 0 || g.MemoryTotalMB <= 0 ||
+		usedMemory+request.MemoryMB > g.MemoryTotalMB {
+		return GPUSlice{}, false
+	}
+	return GPUSlice{
+		MemoryMB: request.MemoryMB,
+		Fraction: float64(request.MemoryMB) / float64(g.MemoryTotalMB),
+	}, true
+}
+
+// Release drops whatever the allocation holds on this GPU and reports
+// whether the GPU became fully idle as a result
+func (g *GPU) Release(allocationID string) bool {
+	if g.Allocated {
+		if g.AllocationID != allocationID {
+			return false
+		}
+		g.Allocated = false
+		g.AllocationID = ""
+		g.JobID = ""
+		g.TenantID = ""
+		return true
+	}
+
+	remaining := g.Slices[:0]
+	released := false
+	for _, slice := range g.Slices {
+		if slice.AllocationID == allocationID {
+			released = true
+			continue
+		}
+		remaining = append(remaining, slice)
+	}
+	g.Slices = remaining
+
+	return released && len(g.Slices) == 0
+}
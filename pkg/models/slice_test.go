@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSliceRequestValidate(t *testing.T) {
+	assert.NoError(t, (&SliceRequest{MIGProfile: "3g.20gb"}).Validate())
+	assert.NoError(t, (&SliceRequest{MemoryMB: 8192}).Validate())
+	assert.Error(t, (&SliceRequest{MIGProfile: "9g.90gb"}).Validate())
+	assert.Error(t, (&SliceRequest{MIGProfile: "1g.5gb", MemoryMB: 4096}).Validate())
+	assert.Error(t, (&SliceRequest{}).Validate())
+}
+
+func TestFitSliceMIG(t *testing.T) {
+	gpu := &GPU{Model: GPUA100, MemoryTotalMB: 40960, Health: HealthHealthy, SharingMode: SharingMIG}
+
+	slice, ok := gpu.FitSlice(&SliceRequest{MIGProfile: "4g.20gb"})
+	require.True(t, ok)
+	assert.Equal(t, 4, slice.ComputeSlices)
+	assert.InDelta(t, 4.0/7.0, slice.Fraction, 1e-9)
+	slice.AllocationID = "alloc-1"
+	gpu.Slices = append(gpu.Slices, slice)
+
+	// 4 + 3 compute slices fill the GPU; a further slice does not fit
+	slice, ok = gpu.FitSlice(&SliceRequest{MIGProfile: "3g.20gb"})
+	require.True(t, ok)
+	slice.AllocationID = "alloc-2"
+	gpu.Slices = append(gpu.Slices, slice)
+
+	_, ok = gpu.FitSlice(&SliceRequest{MIGProfile: "1g.5gb"})
+	assert.False(t, ok)
+	assert.InDelta(t, 1.0, gpu.SharedFraction(), 1e-9)
+
+	// MIG GPUs do not take memory slices, and non-MIG models reject profiles
+	_, ok = (&GPU{Model: GPUA100, MemoryTotalMB: 40960, Health: HealthHealthy, SharingMode: SharingMIG}).
+		FitSlice(&SliceRequest{MemoryMB: 1024})
+	assert.False(t, ok)
+	_, ok = (&GPU{Model: GPUV100, MemoryTotalMB: 32768, Health: HealthHealthy, SharingMode: SharingMIG}).
+		FitSlice(&SliceRequest{MIGProfile: "1g.5gb"})
+	assert.False(t, ok)
+}
+
+func TestFitSliceTimeSliced(t *testing.T) {
+	gpu := &GPU{Model: GPUT4, MemoryTotalMB: 16384, Health: HealthHealthy, SharingMode: SharingTimeSlice}
+
+	slice, ok := gpu.FitSlice(&SliceRequest{MemoryMB: 12288})
+	require.True(t, ok)
+	assert.InDelta(t, 0.75, slice.Fraction, 1e-9)
+	gpu.Slices = append(gpu.Slices, slice)
+
+	_, ok = gpu.FitSlice(&SliceRequest{MemoryMB: 8192})
+	assert.False(t, ok)
+	_, ok = gpu.FitSlice(&SliceRequest{MemoryMB: 4096})
+	assert.True(t, ok)
+
+	// Exclusive GPUs never take slices
+	_, ok = (&GPU{Model: GPUT4, MemoryTotalMB: 16384, Health: HealthHealthy}).FitSlice(&SliceRequest{MemoryMB: 1024})
+	assert.False(t, ok)
+}
+
+func TestGPURelease(t *testing.T) {
+	gpu := &GPU{
+		SharingMode: SharingMIG,
+		Slices: []GPUSlice{
+			{AllocationID: "alloc-1", ComputeSlices: 3},
+			{AllocationID: "alloc-2", ComputeSlices: 2},
+		},
+	}
+	assert.False(t, gpu.IsAvailable())
+
+	assert.False(t, gpu.Release("alloc-1"))
+	assert.Len(t, gpu.Slices, 1)
+	assert.False(t, gpu.Release("alloc-3"))
+	assert.True(t, gpu.Release("alloc-2"))
+	assert.False(t, gpu.InUse())
+
+	whole := &GPU{Allocated: true, AllocationID: "alloc-4", JobID: "job-4"}
+	assert.False(t, whole.Release("alloc-5"))
+	assert.True(t, whole.Release("alloc-4"))
+	assert.Empty(t, whole.JobID)
+}
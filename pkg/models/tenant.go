@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -20,16 +21,25 @@ type Tenant struct {
 	Name              string        `json:"name"`
 	Email             string        `json:"email"`
 	Organization      string        `json:"organization"`
+	QuotaGroupID      string        `json:"quota_group_id,omitempty" gorm:"index"`
 	
-	// Quotas
+	// Quotas. GuaranteedGPUs are reserved for the tenant; beyond them it
+	// borrows idle capacity up to MaxGPUs.
 	MaxGPUs           int           `json:"max_gpus"`
+	GuaranteedGPUs    int           `json:"guaranteed_gpus"`
 	MaxGPUMemoryMB    int64         `json:"max_gpu_memory_mb"`
 	MaxCPUCores       int           `json:"max_cpu_cores"`
 	MaxMemoryMB       int64         `json:"max_memory_mb"`
 	MaxConcurrentJobs int           `json:"max_concurrent_jobs"`
+	// QuotaProfiles override the GPU and job limits during recurring
+	// time-of-day windows; the first profile covering the time wins
+	QuotaProfiles     []QuotaProfile `json:"quota_profiles,omitempty" gorm:"serializer:json"`
+	// Budget caps the GPU-hours used per period, when set
+	Budget            *GPUHourBudget `json:"budget,omitempty" gorm:"serializer:json"`
 	
 	// Current Usage
 	CurrentGPUs       int           `json:"current_gpus"`
+	CurrentGPUFraction float64      `json:"current_gpu_fraction"`
 	CurrentGPUMemory  int64         `json:"current_gpu_memory"`
 	CurrentCPUCores   int           `json:"current_cpu_cores"`
 	CurrentMemory     int64         `json:"current_memory"`
@@ -50,6 +60,7 @@ type Tenant struct {
 	AllowPreemption   bool          `json:"allow_preemption"`
 	CanPreemptOthers  bool          `json:"can_preempt_others"`
 	MaxPreemptions    int           `json:"max_preemptions"`
+	PlacementStrategy PlacementStrategy `json:"placement_strategy"`
 	
 	// Billing
 	BillingEnabled    bool          `json:"billing_enabled"`
@@ -68,15 +79,148 @@ type Tenant struct {
 	UpdatedAt         time.Time     `json:"updated_at"`
 }
 
+// TenantUpdate carries the tenant settings to change; nil fields are
+// left as they are
+type TenantUpdate struct {
+	Name              *string            `json:"name,omitempty"`
+	Email             *string            `json:"email,omitempty"`
+	Organization      *string            `json:"organization,omitempty"`
+	MaxGPUs           *int               `json:"max_gpus,omitempty"`
+	GuaranteedGPUs    *int               `json:"guaranteed_gpus,omitempty"`
+	MaxGPUMemoryMB    *int64             `json:"max_gpu_memory_mb,omitempty"`
+	MaxCPUCores       *int               `json:"max_cpu_cores,omitempty"`
+	MaxMemoryMB       *int64             `json:"max_memory_mb,omitempty"`
+	MaxConcurrentJobs *int               `json:"max_concurrent_jobs,omitempty"`
+	PriorityTier      *PriorityTier      `json:"priority_tier,omitempty"`
+	FairShareWeight   *float64           `json:"fair_share_weight,omitempty"`
+	AllowPreemption   *bool              `json:"allow_preemption,omitempty"`
+	CanPreemptOthers  *bool              `json:"can_preempt_others,omitempty"`
+	MaxPreemptions    *int               `json:"max_preemptions,omitempty"`
+	PlacementStrategy *PlacementStrategy `json:"placement_strategy,omitempty"`
+	BillingEnabled    *bool              `json:"billing_enabled,omitempty"`
+	CostPerGPUHour    *float64           `json:"cost_per_gpu_hour,omitempty"`
+	NotifyOnStart     *bool              `json:"notify_on_start,omitempty"`
+	NotifyOnComplete  *bool              `json:"notify_on_complete,omitempty"`
+	NotifyOnFailure   *bool              `json:"notify_on_failure,omitempty"`
+	NotifyEmail       *string            `json:"notify_email,omitempty"`
+}
+
+// Apply copies the fields set in the update onto the tenant
+func (u *TenantUpdate) Apply(t *Tenant) {
+	setString(&t.Name, u.Name)
+	setString(&t.Email, u.Email)
+	setString(&t.Organization, u.Organization)
+	setInt(&t.MaxGPUs, u.MaxGPUs)
+	setInt(&t.GuaranteedGPUs, u.GuaranteedGPUs)
+	setInt64(&t.MaxGPUMemoryMB, u.MaxGPUMemoryMB)
+	setInt(&t.MaxCPUCores, u.MaxCPUCores)
+	setInt64(&t.MaxMemoryMB, u.MaxMemoryMB)
+	setInt(&t.MaxConcurrentJobs, u.MaxConcurrentJobs)
+	if u.PriorityTier != nil {
+		t.PriorityTier = *u.PriorityTier
+	}
+	setFloat(&t.FairShareWeight, u.FairShareWeight)
+	setBool(&t.AllowPreemption, u.AllowPreemption)
+	setBool(&t.CanPreemptOthers, u.CanPreemptOthers)
+	setInt(&t.MaxPreemptions, u.MaxPreemptions)
+	if u.PlacementStrategy != nil {
+		t.PlacementStrategy = *u.PlacementStrategy
+	}
+	setBool(&t.BillingEnabled, u.BillingEnabled)
+	setFloat(&t.CostPerGPUHour, u.CostPerGPUHour)
+	setBool(&t.NotifyOnStart, u.NotifyOnStart)
+	setBool(&t.NotifyOnComplete, u.NotifyOnComplete)
+	setBool(&t.NotifyOnFailure, u.NotifyOnFailure)
+	setString(&t.NotifyEmail, u.NotifyEmail)
+}
+
+func setString(dst *string, v *string) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setInt(dst *int, v *int) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setInt64(dst *int64, v *int64) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setFloat(dst *float64, v *float64) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setBool(dst *bool, v *bool) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// IsValid returns true for the known priority tiers
+func (p PriorityTier) IsValid() bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityCritical:
+		return true
+	}
+	return false
+}
+
+// Validate checks the tenant's limits and policies
+func (t *Tenant) Validate() error {
+	if t.MaxGPUs < 0 || t.MaxGPUMemoryMB < 0 || t.MaxCPUCores < 0 || t.MaxMemoryMB < 0 ||
+		t.MaxConcurrentJobs < 0 || t.MaxPreemptions < 0 {
+		return fmt.Errorf("tenant limits cannot be negative")
+	}
+	if t.GuaranteedGPUs < 0 || t.GuaranteedGPUs > t.MaxGPUs {
+		return fmt.Errorf("guaranteed GPUs must be between 0 and max GPUs")
+	}
+	if t.PriorityTier != "" && !t.PriorityTier.IsValid() {
+		return fmt.Errorf("unknown priority tier %q", t.PriorityTier)
+	}
+	if t.PlacementStrategy != "" && !t.PlacementStrategy.IsValid() {
+		return fmt.Errorf("unknown placement strategy %q", t.PlacementStrategy)
+	}
+	if t.FairShareWeight < 0 || t.CostPerGPUHour < 0 {
+		return fmt.Errorf("fair share weight and cost per GPU-hour cannot be negative")
+	}
+	return ValidateBudget(t.Budget, t.QuotaProfiles)
+}
+
 // HasAvailableQuota checks if tenant has quota for the requested resources
 func (t *Tenant) HasAvailableQuota(gpus int, gpuMemory int64, cpus int, memory int64) bool {
-	return t.CurrentGPUs+gpus <= t.MaxGPUs &&
+	return t.GPUsInUse()+float64(gpus) <= float64(t.MaxGPUs) &&
 		t.CurrentGPUMemory+gpuMemory <= t.MaxGPUMemoryMB &&
 		t.CurrentCPUCores+cpus <= t.MaxCPUCores &&
 		t.CurrentMemory+memory <= t.MaxMemoryMB &&
 		t.CurrentJobs+1 <= t.MaxConcurrentJobs
 }
 
+// ExceededLimit returns the first resource whose requested amount alone
+// exceeds the tenant's limit, or "" if a job of this size can ever run
+func (t *Tenant) ExceededLimit(gpus float64, gpuMemory int64, cpus int, memory int64) string {
+	switch {
+	case gpus > float64(t.MaxGPUs)+1e-9:
+		return "GPUs"
+	case gpuMemory > t.MaxGPUMemoryMB:
+		return "GPU memory"
+	case cpus > t.MaxCPUCores:
+		return "CPU cores"
+	case memory > t.MaxMemoryMB:
+		return "memory"
+	case t.MaxConcurrentJobs < 1:
+		return "concurrent jobs"
+	}
+	return ""
+}
+
 // UpdateUsage updates current resource usage
 func (t *Tenant) UpdateUsage(gpuDelta int, gpuMemDelta int64, cpuDelta int, memDelta int64, jobDelta int) {
 	t.CurrentGPUs += gpuDelta
@@ -86,12 +230,91 @@ func (t *Tenant) UpdateUsage(gpuDelta int, gpuMemDelta int64, cpuDelta int, memD
 	t.CurrentJobs += jobDelta
 }
 
+// ActiveQuotaProfile returns the quota profile in effect at now, or nil
+func (t *Tenant) ActiveQuotaProfile(now time.Time) *QuotaProfile {
+	for i := range t.QuotaProfiles {
+		if t.QuotaProfiles[i].Covers(now) {
+			return &t.QuotaProfiles[i]
+		}
+	}
+	return nil
+}
+
+// AtTime returns a copy of the tenant carrying the GPU and job limits of
+// the quota profile in effect at now
+func (t *Tenant) AtTime(now time.Time) *Tenant {
+	limited := *t
+	if profile := t.ActiveQuotaProfile(now); profile != nil {
+		if profile.MaxGPUs > 0 {
+			limited.MaxGPUs = profile.MaxGPUs
+		}
+		if profile.MaxConcurrentJobs > 0 {
+			limited.MaxConcurrentJobs = profile.MaxConcurrentJobs
+		}
+	}
+	return &limited
+}
+
+// ChargeGPUHours adds GPU-hours to the tenant's lifetime total and to
+// its budget's period containing now
+func (t *Tenant) ChargeGPUHours(hours float64, now time.Time) {
+	t.TotalGPUHours += hours
+	if t.Budget != nil {
+		t.Budget.Roll(now)
+		t.Budget.Charge(hours)
+	}
+}
+
+// BudgetExhausted returns true if the tenant's budget has passed its
+// hard threshold in the period containing now
+func (t *Tenant) BudgetExhausted(now time.Time) bool {
+	if t.Budget == nil {
+		return false
+	}
+	budget := *t.Budget
+	budget.Roll(now)
+	return budget.State() == BudgetExhausted
+}
+
+// GPUsInUse returns whole and fractional GPUs held by the tenant
+func (t *Tenant) GPUsInUse() float64 {
+	return float64(t.CurrentGPUs) + t.CurrentGPUFraction
+}
+
+// HasAvailableGPUShare checks if tenant has quota for a fractional GPU.
+// A zero share only requires some GPU quota to be left.
+func (t *Tenant) HasAvailableGPUShare(share float64) bool {
+	if share <= 0 {
+		return t.GPUsInUse() < float64(t.MaxGPUs)
+	}
+	return t.GPUsInUse()+share <= float64(t.MaxGPUs)+1e-9
+}
+
+// UpdateFractionalUsage adjusts the fractional GPUs held by the tenant
+func (t *Tenant) UpdateFractionalUsage(delta float64) {
+	t.CurrentGPUFraction += delta
+	if t.CurrentGPUFraction < 1e-9 {
+		t.CurrentGPUFraction = 0
+	}
+}
+
+// WithinGuarantee checks if the tenant's guaranteed GPUs cover its usage
+// plus gpus more
+func (t *Tenant) WithinGuarantee(gpus float64) bool {
+	return t.GPUsInUse()+gpus <= float64(t.GuaranteedGPUs)+1e-9
+}
+
+// IsBorrowing returns true if the tenant uses more than its guarantee
+func (t *Tenant) IsBorrowing() bool {
+	return t.GPUsInUse() > float64(t.GuaranteedGPUs)+1e-9
+}
+
 // CalculateFairShare calculates fair share ratio based on usage
 func (t *Tenant) CalculateFairShare() float64 {
 	if t.MaxGPUs == 0 {
 		return 0
 	}
-	return float64(t.CurrentGPUs) / float64(t.MaxGPUs)
+	return t.GPUsInUse() / float64(t.MaxGPUs)
 }
 
 // GetPriorityScore returns priority score for scheduling
@@ -143,3 +143,61 @@ func TestGetPriorityScore(t *testing.T) {
 		})
 	}
 }
+
+func TestFractionalGPUUsage(t *testing.T) {
+	tenant := &Tenant{MaxGPUs: 2, MaxConcurrentJobs: 5, CurrentGPUs: 1}
+
+	assert.True(t, tenant.HasAvailableGPUShare(0.5))
+	tenant.UpdateFractionalUsage(0.75)
+	assert.InDelta(t, 1.75, tenant.GPUsInUse(), 1e-9)
+	assert.False(t, tenant.HasAvailableGPUShare(0.5))
+	assert.False(t, tenant.HasAvailableQuota(1, 0, 0, 0))
+
+	tenant.UpdateFractionalUsage(-0.75)
+	assert.InDelta(t, 1.0, tenant.GPUsInUse(), 1e-9)
+	assert.True(t, tenant.HasAvailableQuota(1, 0, 0, 0))
+}
+
+func TestGuaranteedGPUs(t *testing.T) {
+	tenant := &Tenant{MaxGPUs: 8, GuaranteedGPUs: 4, CurrentGPUs: 3}
+
+	assert.True(t, tenant.WithinGuarantee(1))
+	assert.False(t, tenant.WithinGuarantee(1.5))
+	assert.False(t, tenant.IsBorrowing())
+
+	tenant.UpdateFractionalUsage(1.5)
+	assert.True(t, tenant.IsBorrowing())
+
+	// Without a guarantee every GPU is borrowed
+	assert.False(t, (&Tenant{MaxGPUs: 8}).WithinGuarantee(1))
+}
+
+func TestExceededLimit(t *testing.T) {
+	tenant := &Tenant{MaxGPUs: 4, MaxGPUMemoryMB: 80000, MaxCPUCores: 32, MaxMemoryMB: 128000, MaxConcurrentJobs: 1, CurrentGPUs: 4, CurrentJobs: 1}
+
+	// Current usage does not matter, only the limits
+	assert.Empty(t, tenant.ExceededLimit(4, 80000, 32, 128000))
+	assert.Equal(t, "GPUs", tenant.ExceededLimit(5, 0, 0, 0))
+	assert.Equal(t, "CPU cores", tenant.ExceededLimit(1, 0, 64, 0))
+	assert.Equal(t, "concurrent jobs", (&Tenant{MaxGPUs: 4}).ExceededLimit(1, 0, 0, 0))
+}
+
+func TestTenantValidate(t *testing.T) {
+	assert.NoError(t, (&Tenant{MaxGPUs: 8, GuaranteedGPUs: 4, PriorityTier: PriorityHigh}).Validate())
+	assert.Error(t, (&Tenant{MaxGPUs: 4, GuaranteedGPUs: 5}).Validate())
+	assert.Error(t, (&Tenant{MaxGPUs: -1}).Validate())
+	assert.Error(t, (&Tenant{PriorityTier: "urgent"}).Validate())
+	assert.Error(t, (&Tenant{PlacementStrategy: "random"}).Validate())
+}
+
+func TestTenantUpdateApply(t *testing.T) {
+	tenant := &Tenant{Name: "research", MaxGPUs: 4, MaxConcurrentJobs: 10, BillingEnabled: true}
+
+	maxGPUs, billing := 8, false
+	(&TenantUpdate{MaxGPUs: &maxGPUs, BillingEnabled: &billing}).Apply(tenant)
+
+	assert.Equal(t, 8, tenant.MaxGPUs)
+	assert.False(t, tenant.BillingEnabled)
+	assert.Equal(t, "research", tenant.Name)
+	assert.Equal(t, 10, tenant.MaxConcurrentJobs)
+}
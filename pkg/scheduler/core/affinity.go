@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"strings"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+)
+
+// jobAffinityPlugin enforces the inter-job colocation and anti-colocation
+// rules of a request. Constraints are evaluated against active
+// allocations only, so a job whose colocation target is not running yet
+// stays pending instead of failing.
+type jobAffinityPlugin struct {
+	allocator *Allocator
+}
+
+// jobAffinityState records the nodes allowed and excluded by the rules
+type jobAffinityState struct {
+	targetNodes map[string]bool
+	excluded    map[string]bool
+}
+
+const jobAffinityStateKey = "JobAffinity/state"
+
+func newJobAffinityPlugin(a *Allocator) (Plugin, error) {
+	return &jobAffinityPlugin{allocator: a}, nil
+}
+
+func (p *jobAffinityPlugin) Name() string { return PluginJobAffinity }
+
+// PreFilter resolves the nodes hosting colocation targets and the nodes
+// hosting jobs matched by anti-colocation selectors
+func (p *jobAffinityPlugin) PreFilter(ctx context.Context, state *CycleState, request *models.AllocationRequest) *Status {
+	affinity := request.Affinity
+	if !affinity.HasJobAffinity() {
+		return nil
+	}
+
+	allocations, err := p.allocator.storage.ListActiveAllocations(ctx)
+	if err != nil {
+		return NewInsufficientStatus("failed to list active allocations: %v", err)
+	}
+
+	affinityState := &jobAffinityState{excluded: make(map[string]bool)}
+
+	// Colocation: restrict to nodes currently hosting the target job
+	if affinity.ColocateWithJob != "" {
+		affinityState.targetNodes = make(map[string]bool)
+		for _, alloc := range allocations {
+			if alloc.JobID == affinity.ColocateWithJob {
+				affinityState.targetNodes[alloc.NodeID] = true
+			}
+		}
+
+		if len(affinityState.targetNodes) == 0 {
+			return NewUnschedulableStatus("waiting for colocation target job %s to be running", affinity.ColocateWithJob)
+		}
+	}
+
+	// Anti-colocation: exclude nodes hosting jobs with matching labels
+	if len(affinity.AntiColocateWith) > 0 {
+		for _, alloc := range allocations {
+			if alloc.JobID == request.JobID || affinityState.excluded[alloc.NodeID] {
+				continue
+			}
+
+			job, err := p.allocator.storage.GetJob(ctx, alloc.JobID)
+			if err != nil {
+				continue
+			}
+
+			if affinity.AntiColocatesWith(job) {
+				affinityState.excluded[alloc.NodeID] = true
+			}
+		}
+
+		if len(affinityState.excluded) > 0 {
+			nodes, err := p.allocator.storage.ListNodes(ctx)
+			if err != nil {
+				return NewInsufficientStatus("failed to list nodes: %v", err)
+			}
+			allExcluded := true
+			for _, node := range nodes {
+				if !affinityState.excluded[node.ID] {
+					allExcluded = false
+					break
+				}
+			}
+			if allExcluded {
+				return NewUnschedulableStatus("anti-colocation with [%s] excludes every node",
+					strings.Join(affinity.AntiColocateWith, ", "))
+			}
+		}
+	}
+
+	state.Write(jobAffinityStateKey, affinityState)
+	return nil
+}
+
+func (p *jobAffinityPlugin) Filter(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status {
+	value, ok := state.Read(jobAffinityStateKey)
+	if !ok {
+		return nil
+	}
+	affinityState := value.(*jobAffinityState)
+
+	// The target is running, so freeing resources on its nodes can make
+	// room for the job
+	if affinityState.targetNodes != nil && !affinityState.targetNodes[info.Node.ID] {
+		return NewInsufficientStatus("node %s does not run colocation target job %s",
+			info.Node.ID, request.Affinity.ColocateWithJob)
+	}
+
+	// Other nodes may still be freed for the job, so an excluded node is
+	// rejected like one lacking resources
+	if affinityState.excluded[info.Node.ID] {
+		return NewInsufficientStatus("node %s runs a job matching anti-colocation [%s]",
+			info.Node.ID, strings.Join(request.Affinity.AntiColocateWith, ", "))
+	}
+
+	return nil
+}
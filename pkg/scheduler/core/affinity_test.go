@@ -0,0 +1,168 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runJob places an active allocation for a job on the given node
+func runJob(t *testing.T, repo *fakeRepository, job *models.Job, nodeID string) {
+	t.Helper()
+	ctx := context.Background()
+
+	job.State = models.JobStateRunning
+	require.NoError(t, repo.CreateJob(ctx, job))
+	require.NoError(t, repo.CreateAllocation(ctx, &models.Allocation{
+		ID:          "alloc-" + job.ID,
+		JobID:       job.ID,
+		State:       models.AllocationActive,
+		NodeID:      nodeID,
+		AllocatedAt: time.Now(),
+	}))
+}
+
+func TestColocateWithRunningJob(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 4)
+	repo.addNode("node-b", 4)
+	runJob(t, repo, &models.Job{ID: "trainer"}, "node-b")
+
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{})
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "sidecar",
+		GPUCount: 1,
+		Affinity: &models.Affinity{ColocateWithJob: "trainer"},
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "node-b", result.NodeID)
+}
+
+func TestColocateWithPendingJobStaysUnschedulable(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 4)
+
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{})
+	_, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "sidecar",
+		GPUCount: 1,
+		Affinity: &models.Affinity{ColocateWithJob: "trainer"},
+	})
+
+	assert.True(t, utils.IsUnschedulable(err))
+	assert.Contains(t, utils.UnschedulableReason(err), "trainer")
+}
+
+func TestColocationTargetNodeFullTriggersPreemption(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a", "node-b"), withPreemption())
+
+	// Best-fit packs both jobs onto one node, leaving the other idle
+	trainer := &models.Job{ID: "trainer", TenantID: "tenant-1", Priority: 500, GPUCount: 2}
+	filler := &models.Job{ID: "filler", TenantID: "tenant-1", Priority: 10, GPUCount: 2}
+	for _, job := range []*models.Job{trainer, filler} {
+		require.NoError(t, scheduler.SubmitJob(ctx, job))
+		require.NoError(t, scheduler.schedulingCycle(ctx))
+	}
+	trainerNode := scheduler.primaryAllocation(ctx, "trainer").NodeID
+	require.Equal(t, trainerNode, scheduler.primaryAllocation(ctx, "filler").NodeID)
+
+	sidecar := &models.Job{ID: "sidecar", TenantID: "tenant-1", Priority: 100, GPUCount: 2,
+		Affinity: &models.Affinity{ColocateWithJob: "trainer"}}
+	require.NoError(t, scheduler.SubmitJob(ctx, sidecar))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePreempted, filler.State)
+	assert.Equal(t, models.JobStateRunning, sidecar.State)
+	assert.Equal(t, trainerNode, scheduler.primaryAllocation(ctx, "sidecar").NodeID)
+}
+
+func TestAntiColocateSpreadsReplicas(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 4)
+	repo.addNode("node-b", 4)
+	runJob(t, repo, &models.Job{ID: "replica-0", Labels: map[string]string{"app": "serving"}}, "node-a")
+
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{})
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "replica-1",
+		GPUCount: 1,
+		Affinity: &models.Affinity{AntiColocateWith: []string{"app=serving"}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", result.NodeID)
+
+	// Both nodes now host a replica
+	runJob(t, repo, &models.Job{ID: "replica-1", Labels: map[string]string{"app": "serving"}}, "node-b")
+	_, err = allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "replica-2",
+		GPUCount: 1,
+		Affinity: &models.Affinity{AntiColocateWith: []string{"app=serving"}},
+	})
+	assert.True(t, utils.IsUnschedulable(err))
+	assert.Equal(t, "anti-colocation with [app=serving] excludes every node", utils.UnschedulableReason(err))
+}
+
+func TestAntiColocationLeavesPreemptionToOtherNodes(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a", "node-b"), withPreemption())
+
+	// A replica lands on node-a and low priority work fills node-b
+	serving := map[string]string{"app": "serving"}
+	replica := &models.Job{ID: "replica-0", TenantID: "tenant-1", Priority: 500, GPUCount: 2, Labels: serving}
+	filler := &models.Job{ID: "filler", TenantID: "tenant-1", Priority: 10, GPUCount: 4}
+	for _, job := range []*models.Job{replica, filler} {
+		require.NoError(t, scheduler.SubmitJob(ctx, job))
+		require.NoError(t, scheduler.schedulingCycle(ctx))
+	}
+	require.Equal(t, "node-a", scheduler.primaryAllocation(ctx, "replica-0").NodeID)
+	require.Equal(t, "node-b", scheduler.primaryAllocation(ctx, "filler").NodeID)
+
+	next := &models.Job{ID: "replica-1", TenantID: "tenant-1", Priority: 500, GPUCount: 2, Labels: serving,
+		Affinity: &models.Affinity{AntiColocateWith: []string{"app=serving"}}}
+	require.NoError(t, scheduler.SubmitJob(ctx, next))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePreempted, filler.State)
+	require.Equal(t, models.JobStateRunning, next.State)
+	assert.Equal(t, "node-b", scheduler.primaryAllocation(ctx, "replica-1").NodeID)
+}
+
+func TestUnschedulableJobDoesNotBlockQueue(t *testing.T) {
+	scheduler, repo := newTestScheduler(t, withNodes(4, "node-a"))
+
+	blocked := &models.Job{
+		ID:       "blocked",
+		TenantID: "tenant-1",
+		Priority: 1000,
+		GPUCount: 1,
+		State:    models.JobStatePending,
+		Affinity: &models.Affinity{ColocateWithJob: "missing"},
+	}
+	ready := &models.Job{
+		ID:       "ready",
+		TenantID: "tenant-1",
+		Priority: 100,
+		GPUCount: 1,
+		State:    models.JobStatePending,
+	}
+	for _, job := range []*models.Job{blocked, ready} {
+		require.NoError(t, repo.CreateJob(context.Background(), job))
+		require.NoError(t, scheduler.queue.Enqueue(job))
+	}
+
+	require.NoError(t, scheduler.schedulingCycle(context.Background()))
+
+	assert.Equal(t, models.JobStateRunning, ready.State)
+	assert.Equal(t, models.JobStatePending, blocked.State)
+	assert.Contains(t, blocked.PendingReason, "missing")
+	assert.Equal(t, 1, scheduler.queue.Size())
+}
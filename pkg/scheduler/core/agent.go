@@ -0,0 +1,464 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// Node and GPU records are also written by the allocator, which runs
+// under quotaMu. Agent updates take the same lock so a heartbeat cannot
+// overwrite a GPU that is being allocated.
+
+// RegisterAgent adds the agent's node and GPUs, or refreshes them when the
+// node is already known. Allocations and scheduling settings such as
+// draining are kept. GPUs the agent no longer reports are marked
+// unhealthy so the jobs on them fail over.
+func (s *Scheduler) RegisterAgent(ctx context.Context, reg *models.AgentRegistration) (*models.Node, error) {
+	if reg.NodeID == "" {
+		return nil, fmt.Errorf("%w: node ID is required", utils.ErrInvalidAgentRequest)
+	}
+
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	now := time.Now()
+	node, err := s.storage.GetNode(ctx, reg.NodeID)
+	isNew := errors.Is(err, utils.ErrNodeNotFound)
+	if err != nil && !isNew {
+		return nil, err
+	}
+	if isNew {
+		node = &models.Node{
+			ID:                reg.NodeID,
+			AvailableGPUs:     len(reg.GPUs),
+			AvailableCPUCores: reg.TotalCPUCores,
+			AvailableMemoryMB: reg.TotalMemoryMB,
+			Schedulable:       true,
+			CreatedAt:         now,
+		}
+	} else {
+		// Capacity added or removed since the last registration changes
+		// what is free by the same amount
+		node.AvailableGPUs = maxInt(0, node.AvailableGPUs+len(reg.GPUs)-node.TotalGPUs)
+		node.AvailableCPUCores = maxInt(0, node.AvailableCPUCores+reg.TotalCPUCores-node.TotalCPUCores)
+		node.AvailableMemoryMB += reg.TotalMemoryMB - node.TotalMemoryMB
+		if node.AvailableMemoryMB < 0 {
+			node.AvailableMemoryMB = 0
+		}
+	}
+
+	node.Name = reg.Hostname
+	if node.Name == "" {
+		node.Name = reg.NodeID
+	}
+	node.Hostname = reg.Hostname
+	node.IPAddress = reg.IPAddress
+	node.TotalGPUs = len(reg.GPUs)
+	node.TotalCPUCores = reg.TotalCPUCores
+	node.TotalMemoryMB = reg.TotalMemoryMB
+	node.Labels = reg.Labels
+	node.Online = true
+	node.LastHeartbeat = now
+	node.UpdatedAt = now
+
+	if isNew {
+		err = s.storage.CreateNode(ctx, node)
+	} else {
+		err = s.storage.UpdateNode(ctx, node)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save node: %w", err)
+	}
+
+	if err := s.registerGPUs(ctx, node, reg.GPUs, now); err != nil {
+		return nil, err
+	}
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:    models.EventNodeRegistered,
+		NodeID:  node.ID,
+		Message: fmt.Sprintf("agent registered %d GPUs", len(reg.GPUs)),
+	})
+	utils.Info("Agent registered",
+		zap.String("node_id", node.ID),
+		zap.String("hostname", node.Hostname),
+		zap.Int("gpus", len(reg.GPUs)),
+		zap.Bool("new", isNew))
+	return node, nil
+}
+
+// registerGPUs saves the GPUs an agent reported for its node
+func (s *Scheduler) registerGPUs(ctx context.Context, node *models.Node, infos []models.GPUInfo, now time.Time) error {
+	known, err := s.storage.ListGPUsByNode(ctx, node.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list node GPUs: %w", err)
+	}
+	existing := make(map[string]*models.GPU, len(known))
+	for _, gpu := range known {
+		existing[gpu.ID] = gpu
+	}
+
+	for _, info := range infos {
+		id := info.GPUID
+		if id == "" {
+			id = fmt.Sprintf("%s-gpu-%d", node.ID, info.Index)
+		}
+
+		gpu, ok := existing[id]
+		delete(existing, id)
+		if !ok {
+			gpu = &models.GPU{
+				ID:           id,
+				MemoryFreeMB: info.MemoryTotalMB,
+				Health:       models.HealthHealthy,
+				CreatedAt:    now,
+			}
+		}
+		gpu.NodeID = node.ID
+		gpu.Index = info.Index
+		gpu.Model = models.ParseGPUModel(info.Model)
+		gpu.MemoryTotalMB = info.MemoryTotalMB
+		gpu.ComputeCapability = info.ComputeCapability
+		gpu.CUDACores = info.CUDACores
+		gpu.TensorCores = info.TensorCores
+		gpu.LastHeartbeat = now
+		gpu.UpdatedAt = now
+		if ok && gpu.LastError == "not reported by agent" {
+			gpu.LastError = ""
+			gpu.UpdateHealth()
+		}
+
+		if ok {
+			err = s.storage.UpdateGPU(ctx, gpu)
+		} else {
+			err = s.storage.CreateGPU(ctx, gpu)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save GPU %s: %w", id, err)
+		}
+	}
+
+	for _, gpu := range existing {
+		gpu.Health = models.HealthUnhealthy
+		gpu.LastError = "not reported by agent"
+		gpu.UpdatedAt = now
+		if err := s.storage.UpdateGPU(ctx, gpu); err != nil {
+			return fmt.Errorf("failed to save GPU %s: %w", gpu.ID, err)
+		}
+		utils.Warn("GPU missing from agent registration",
+			zap.String("node_id", node.ID),
+			zap.String("gpu_id", gpu.ID))
+	}
+	return nil
+}
+
+// AgentHeartbeat records that a node's agent is alive along with its
+// latest utilization. Unknown nodes get utils.ErrNodeNotFound and must
+// register again. The reply asks the agent to terminate jobs that have
+// run past their max runtime.
+func (s *Scheduler) AgentHeartbeat(ctx context.Context, heartbeat *models.NodeHeartbeat) (*models.HeartbeatAck, error) {
+	s.quotaMu.Lock()
+	node, err := s.storage.GetNode(ctx, heartbeat.NodeID)
+	if err != nil {
+		s.quotaMu.Unlock()
+		return nil, err
+	}
+
+	node.Online = true
+	node.LastHeartbeat = time.Now()
+	node.CPUUtilization = heartbeat.CPUUtilization
+	node.MemoryUtilization = heartbeat.MemoryUtilization
+	node.UpdatedAt = node.LastHeartbeat
+	if err := s.storage.UpdateNode(ctx, node); err != nil {
+		s.quotaMu.Unlock()
+		return nil, fmt.Errorf("failed to update node: %w", err)
+	}
+	s.applyGPUMetrics(ctx, node.ID, heartbeat.GPUMetrics)
+	s.quotaMu.Unlock()
+
+	ack := &models.HeartbeatAck{Acknowledged: true}
+	allocations, err := s.nodeAllocations(ctx, node.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, alloc := range allocations {
+		if alloc.TerminateSignaledAt != nil {
+			ack.Commands = append(ack.Commands, models.AgentCommandTerminate+" "+alloc.JobID)
+		}
+	}
+	return ack, nil
+}
+
+// ReportAgentMetrics applies GPU telemetry from a node's agent and ends
+// the jobs it reports as completed or failed
+func (s *Scheduler) ReportAgentMetrics(ctx context.Context, report *models.MetricsReport) error {
+	if _, err := s.storage.GetNode(ctx, report.NodeID); err != nil {
+		return err
+	}
+
+	s.quotaMu.Lock()
+	s.applyGPUMetrics(ctx, report.NodeID, report.GPUMetrics)
+	s.quotaMu.Unlock()
+
+	for _, metrics := range report.JobMetrics {
+		if err := s.applyJobMetrics(ctx, report.NodeID, metrics); err != nil {
+			utils.Error("Failed to apply job metrics",
+				zap.String("node_id", report.NodeID),
+				zap.String("job_id", metrics.JobID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// applyGPUMetrics updates the node's GPUs from an agent's samples. The
+// caller holds quotaMu.
+func (s *Scheduler) applyGPUMetrics(ctx context.Context, nodeID string, samples []models.GPUMetrics) {
+	for _, sample := range samples {
+		gpu, err := s.storage.GetGPU(ctx, sample.GPUID)
+		if err != nil || gpu.NodeID != nodeID {
+			continue
+		}
+		gpu.ApplyMetrics(sample)
+		gpu.UpdatedAt = gpu.LastHeartbeat
+		if err := s.storage.UpdateGPU(ctx, gpu); err != nil {
+			utils.Error("Failed to update GPU metrics", zap.String("gpu_id", gpu.ID), zap.Error(err))
+		}
+
+		// A GPU starts cooling as soon as a reading crosses the threshold,
+		// whether or not a job is placed on its node
+		if s.allocator.thermalAware() && gpu.NeedsCooling(s.config.ThermalThreshold) {
+			s.allocator.startCooling(ctx, gpu)
+		}
+	}
+	s.recordAllocationMetrics(ctx, nodeID, samples)
+}
+
+// recordAllocationMetrics folds the samples of each allocation's GPUs on
+// the node into its average utilization and per-GPU power draw, which
+// later runs of the same job use to estimate their power. Slices are
+// charged their share of the GPU's draw.
+func (s *Scheduler) recordAllocationMetrics(ctx context.Context, nodeID string, samples []models.GPUMetrics) {
+	if len(samples) == 0 {
+		return
+	}
+	byGPU := make(map[string]models.GPUMetrics, len(samples))
+	for _, sample := range samples {
+		byGPU[sample.GPUID] = sample
+	}
+
+	allocations, err := s.nodeAllocations(ctx, nodeID)
+	if err != nil {
+		return
+	}
+	for _, alloc := range allocations {
+		utilization, power, count := 0.0, 0.0, 0
+		for _, gpuID := range alloc.GPUIDs {
+			sample, ok := byGPU[gpuID]
+			if !ok {
+				continue
+			}
+			utilization += sample.Utilization
+			power += sample.PowerUsage
+			count++
+		}
+		if count == 0 {
+			continue
+		}
+
+		power /= float64(count)
+		if alloc.Slice != nil {
+			power *= alloc.Slice.Fraction
+		}
+		alloc.UpdateUtilization(utilization / float64(count))
+		alloc.UpdatePowerUsage(power)
+		alloc.UpdatedAt = time.Now()
+		if err := s.storage.UpdateAllocation(ctx, alloc); err != nil {
+			utils.Error("Failed to update allocation metrics", zap.String("allocation_id", alloc.ID), zap.Error(err))
+		}
+	}
+}
+
+// applyJobMetrics ends a job its agent reports as completed or failed.
+// Reports about jobs no longer running on the node are stale and ignored.
+func (s *Scheduler) applyJobMetrics(ctx context.Context, nodeID string, metrics models.JobMetrics) error {
+	job, err := s.storage.GetJob(ctx, metrics.JobID)
+	if err != nil {
+		return err
+	}
+	if job.State != models.JobStateRunning || !s.runsOn(ctx, job.ID, nodeID) {
+		return nil
+	}
+
+	switch metrics.State {
+	case models.JobStateCompleted:
+		return s.CompleteJob(ctx, job.ID)
+	case models.JobStateFailed:
+		exitCode, _ := strconv.Atoi(metrics.ExitCode)
+		reason := metrics.ErrorMessage
+		if reason == "" {
+			reason = fmt.Sprintf("exited with code %d on node %s", exitCode, nodeID)
+		}
+		return s.FailJob(ctx, job.ID, models.FailureNonZeroExit, reason, exitCode)
+	}
+	return nil
+}
+
+// runsOn checks if the job holds an allocation on the node
+func (s *Scheduler) runsOn(ctx context.Context, jobID, nodeID string) bool {
+	for _, alloc := range s.activeAllocations(ctx, jobID) {
+		if alloc.NodeID == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// CompleteJob records that a running job finished successfully and
+// frees its resources
+func (s *Scheduler) CompleteJob(ctx context.Context, jobID string) error {
+	job, err := s.storage.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.State != models.JobStateRunning {
+		return fmt.Errorf("%w: cannot complete job in state %s", utils.ErrInvalidJobState, job.State)
+	}
+
+	if err := s.freeJobResources(ctx, job); err != nil {
+		utils.Error("Failed to free job resources", zap.String("job_id", job.ID), zap.Error(err))
+	}
+
+	now := time.Now()
+	job.State = models.JobStateCompleted
+	job.CompletedAt = &now
+	if job.StartedAt != nil {
+		job.ActualDuration = now.Sub(*job.StartedAt)
+	}
+	if err := s.storage.UpdateJob(ctx, job); err != nil {
+		return err
+	}
+
+	utils.Info("Job completed", zap.String("job_id", job.ID), zap.Duration("duration", job.ActualDuration))
+	return nil
+}
+
+// JobAssignments returns the running jobs placed on a node, oldest
+// first. Agents start the ones they are not running yet and stop the
+// ones that are no longer listed. An elastic job grown onto the node
+// again gets one assignment covering all of its GPUs there, named after
+// its oldest allocation on the node.
+func (s *Scheduler) JobAssignments(ctx context.Context, nodeID string) ([]*models.JobAssignment, error) {
+	if _, err := s.storage.GetNode(ctx, nodeID); err != nil {
+		return nil, err
+	}
+	allocations, err := s.nodeAllocations(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := []*models.JobAssignment{}
+	byJob := make(map[string]*models.JobAssignment)
+	for _, alloc := range allocations {
+		if assignment, ok := byJob[alloc.JobID]; ok {
+			assignment.GPUIDs = append(assignment.GPUIDs, alloc.GPUIDs...)
+			assignment.CPUCores += alloc.CPUCores
+			assignment.MemoryMB += alloc.MemoryMB
+			continue
+		}
+
+		job, err := s.storage.GetJob(ctx, alloc.JobID)
+		if err != nil || job.State != models.JobStateRunning {
+			continue
+		}
+		assignment := &models.JobAssignment{
+			JobID:        job.ID,
+			AllocationID: alloc.ID,
+			GPUIDs:       append([]string(nil), alloc.GPUIDs...),
+			CPUCores:     alloc.CPUCores,
+			MemoryMB:     alloc.MemoryMB,
+			Image:        job.Image,
+			Command:      job.Command,
+			Args:         job.Args,
+			Environment:  job.Environment,
+			Script:       job.Script,
+		}
+		byJob[job.ID] = assignment
+		assignments = append(assignments, assignment)
+	}
+	return assignments, nil
+}
+
+// nodeAllocations returns the active allocations on a node, oldest first
+func (s *Scheduler) nodeAllocations(ctx context.Context, nodeID string) ([]*models.Allocation, error) {
+	allocations, err := s.storage.ListActiveAllocations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list allocations: %w", err)
+	}
+
+	var onNode []*models.Allocation
+	for _, alloc := range allocations {
+		if alloc.NodeID == nodeID {
+			onNode = append(onNode, alloc)
+		}
+	}
+	sort.SliceStable(onNode, func(i, j int) bool {
+		return onNode[i].AllocatedAt.Before(onNode[j].AllocatedAt)
+	})
+	return onNode, nil
+}
+
+// expireAgents marks nodes offline whose agent has not sent a heartbeat
+// within the node timeout. Nodes that never had an agent are left alone.
+func (s *Scheduler) expireAgents(ctx context.Context, now time.Time) {
+	timeout := time.Duration(s.config.NodeTimeoutSec) * time.Second
+	if timeout <= 0 {
+		return
+	}
+
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	nodes, err := s.storage.ListNodes(ctx)
+	if err != nil {
+		utils.Error("Failed to list nodes", zap.Error(err))
+		return
+	}
+
+	for _, node := range nodes {
+		if !node.Online || node.LastHeartbeat.IsZero() || now.Sub(node.LastHeartbeat) <= timeout {
+			continue
+		}
+
+		node.Online = false
+		node.UpdatedAt = now
+		if err := s.storage.UpdateNode(ctx, node); err != nil {
+			utils.Error("Failed to mark node offline", zap.String("node_id", node.ID), zap.Error(err))
+			continue
+		}
+
+		utils.Warn("Node missed its heartbeats",
+			zap.String("node_id", node.ID),
+			zap.Time("last_heartbeat", node.LastHeartbeat))
+		recordEvent(ctx, s.storage, &models.Event{
+			Type:    models.EventNodeOffline,
+			NodeID:  node.ID,
+			Message: fmt.Sprintf("no heartbeat since %s", node.LastHeartbeat.Format(time.RFC3339)),
+		})
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
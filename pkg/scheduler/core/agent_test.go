@@ -0,0 +1,279 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func agentRegistration(nodeID string, gpus int) *models.AgentRegistration {
+	reg := &models.AgentRegistration{
+		NodeID:        nodeID,
+		Hostname:      nodeID + ".cluster.local",
+		IPAddress:     "10.0.0.5",
+		TotalCPUCores: 64,
+		TotalMemoryMB: 512000,
+		Labels:        map[string]string{"zone": "a"},
+	}
+	for i := 0; i < gpus; i++ {
+		reg.GPUs = append(reg.GPUs, models.GPUInfo{
+			Index:             i,
+			Model:             "NVIDIA A100-SXM4-80GB",
+			MemoryTotalMB:     81920,
+			ComputeCapability: "8.0",
+		})
+	}
+	return reg
+}
+
+func TestRegisterAgentCreatesNodeAndGPUs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t)
+
+	node, err := scheduler.RegisterAgent(ctx, agentRegistration("node-1", 2))
+	require.NoError(t, err)
+	assert.True(t, node.Online)
+	assert.True(t, node.Schedulable)
+	assert.Equal(t, 2, node.AvailableGPUs)
+	assert.Equal(t, "node-1.cluster.local", node.Name)
+
+	nodes, err := repo.ListNodes(ctx)
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+
+	gpus, err := repo.ListGPUsByNode(ctx, "node-1")
+	require.NoError(t, err)
+	require.Len(t, gpus, 2)
+	assert.Equal(t, models.GPUA100, gpus[0].Model)
+	assert.Equal(t, models.HealthHealthy, gpus[0].Health)
+	assert.True(t, gpus[0].IsAvailable())
+
+	events, err := repo.ListEvents(ctx, models.EventFilter{Type: models.EventNodeRegistered})
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	_, err = scheduler.RegisterAgent(ctx, &models.AgentRegistration{})
+	assert.True(t, errors.Is(err, utils.ErrInvalidAgentRequest))
+}
+
+func TestRegisterAgentAgainKeepsAllocations(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t)
+
+	_, err := scheduler.RegisterAgent(ctx, agentRegistration("node-1", 2))
+	require.NoError(t, err)
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "train", TenantID: "tenant-1", GPUCount: 1}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	// The agent restarts and finds only one GPU
+	node, err := scheduler.RegisterAgent(ctx, agentRegistration("node-1", 1))
+	require.NoError(t, err)
+	assert.Equal(t, 1, node.TotalGPUs)
+	assert.Equal(t, 0, node.AvailableGPUs)
+
+	gpu, err := repo.GetGPU(ctx, "node-1-gpu-0")
+	require.NoError(t, err)
+	assert.True(t, gpu.Allocated)
+	assert.Equal(t, "train", gpu.JobID)
+
+	missing, err := repo.GetGPU(ctx, "node-1-gpu-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.HealthUnhealthy, missing.Health)
+}
+
+func TestAgentHeartbeatUpdatesMetrics(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t)
+
+	_, err := scheduler.AgentHeartbeat(ctx, &models.NodeHeartbeat{NodeID: "node-1"})
+	assert.True(t, errors.Is(err, utils.ErrNodeNotFound))
+
+	_, err = scheduler.RegisterAgent(ctx, agentRegistration("node-1", 1))
+	require.NoError(t, err)
+
+	ack, err := scheduler.AgentHeartbeat(ctx, &models.NodeHeartbeat{
+		NodeID:         "node-1",
+		CPUUtilization: 40,
+		GPUMetrics: []models.GPUMetrics{
+			{GPUID: "node-1-gpu-0", Utilization: 90, Temperature: 80, PowerUsage: 300, MemoryUsedMB: 1024, MemoryFreeMB: 80896},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, ack.Acknowledged)
+	assert.Empty(t, ack.Commands)
+
+	node, _ := repo.GetNode(ctx, "node-1")
+	assert.Equal(t, 40.0, node.CPUUtilization)
+
+	gpu, _ := repo.GetGPU(ctx, "node-1-gpu-0")
+	assert.Equal(t, 90.0, gpu.Utilization)
+	assert.Equal(t, int64(1024), gpu.MemoryUsedMB)
+	assert.Equal(t, models.HealthDegraded, gpu.Health)
+}
+
+func TestAgentRunsAssignedJobToCompletion(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t)
+
+	_, err := scheduler.RegisterAgent(ctx, agentRegistration("node-1", 2))
+	require.NoError(t, err)
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{
+		ID:       "train",
+		TenantID: "tenant-1",
+		GPUCount: 2,
+		Image:    "pytorch:latest",
+		Command:  []string{"python", "train.py"},
+	}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assignments, err := scheduler.JobAssignments(ctx, "node-1")
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+	assert.Equal(t, "train", assignments[0].JobID)
+	assert.ElementsMatch(t, []string{"node-1-gpu-0", "node-1-gpu-1"}, assignments[0].GPUIDs)
+	assert.Equal(t, "pytorch:latest", assignments[0].Image)
+
+	// Reports from other nodes are ignored
+	_, err = scheduler.RegisterAgent(ctx, agentRegistration("node-2", 1))
+	require.NoError(t, err)
+	require.NoError(t, scheduler.ReportAgentMetrics(ctx, &models.MetricsReport{
+		NodeID:     "node-2",
+		JobMetrics: []models.JobMetrics{{JobID: "train", State: models.JobStateCompleted}},
+	}))
+	job, _ := repo.GetJob(ctx, "train")
+	assert.Equal(t, models.JobStateRunning, job.State)
+
+	require.NoError(t, scheduler.ReportAgentMetrics(ctx, &models.MetricsReport{
+		NodeID:     "node-1",
+		JobMetrics: []models.JobMetrics{{JobID: "train", State: models.JobStateCompleted, ExitCode: "0"}},
+	}))
+	job, _ = repo.GetJob(ctx, "train")
+	assert.Equal(t, models.JobStateCompleted, job.State)
+	assert.NotNil(t, job.CompletedAt)
+
+	gpu, _ := repo.GetGPU(ctx, "node-1-gpu-0")
+	assert.False(t, gpu.Allocated)
+	assignments, err = scheduler.JobAssignments(ctx, "node-1")
+	require.NoError(t, err)
+	assert.Empty(t, assignments)
+}
+
+func TestGrownJobGetsOneAssignmentPerNode(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t)
+
+	_, err := scheduler.RegisterAgent(ctx, agentRegistration("node-1", 4))
+	require.NoError(t, err)
+	job := &models.Job{ID: "train", TenantID: "tenant-1", MinGPUs: 2, MaxGPUs: 4, CPUCores: 8, MemoryMB: 16000, Script: "train.sh"}
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, 4, job.GPUCount)
+	require.Len(t, scheduler.activeAllocations(ctx, "train"), 2)
+
+	assignments, err := scheduler.JobAssignments(ctx, "node-1")
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+	assert.Equal(t, scheduler.primaryAllocation(ctx, "train").ID, assignments[0].AllocationID)
+	assert.ElementsMatch(t, []string{"node-1-gpu-0", "node-1-gpu-1", "node-1-gpu-2", "node-1-gpu-3"}, assignments[0].GPUIDs)
+	assert.Equal(t, 16, assignments[0].CPUCores)
+	assert.Equal(t, int64(32000), assignments[0].MemoryMB)
+}
+
+func TestAgentReportsFailedJob(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t)
+
+	_, err := scheduler.RegisterAgent(ctx, agentRegistration("node-1", 1))
+	require.NoError(t, err)
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "train", TenantID: "tenant-1", GPUCount: 1}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	require.NoError(t, scheduler.ReportAgentMetrics(ctx, &models.MetricsReport{
+		NodeID: "node-1",
+		JobMetrics: []models.JobMetrics{
+			{JobID: "train", State: models.JobStateFailed, ExitCode: "3", ErrorMessage: "exited with code 3"},
+		},
+	}))
+	job, _ := repo.GetJob(ctx, "train")
+	assert.Equal(t, models.JobStateFailed, job.State)
+	require.Len(t, job.Attempts, 1)
+	assert.Equal(t, 3, job.Attempts[0].ExitCode)
+}
+
+func TestMissedHeartbeatsTakeNodeOffline(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withConfig(func(config *utils.SchedulerConfig) {
+		config.NodeTimeoutSec = 30
+	}))
+
+	_, err := scheduler.RegisterAgent(ctx, agentRegistration("node-1", 1))
+	require.NoError(t, err)
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "train", TenantID: "tenant-1", GPUCount: 1}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	node, _ := repo.GetNode(ctx, "node-1")
+	scheduler.expireAgents(ctx, node.LastHeartbeat.Add(10*time.Second))
+	assert.True(t, node.Online)
+
+	scheduler.expireAgents(ctx, node.LastHeartbeat.Add(time.Minute))
+	assert.False(t, node.Online)
+
+	events, err := repo.ListEvents(ctx, models.EventFilter{Type: models.EventNodeOffline})
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	// The job on the lost node fails over
+	scheduler.detectNodeFailures(ctx)
+	job, _ := repo.GetJob(ctx, "train")
+	assert.NotEqual(t, models.JobStateRunning, job.State)
+
+	// A heartbeat brings the node back
+	_, err = scheduler.AgentHeartbeat(ctx, &models.NodeHeartbeat{NodeID: "node-1"})
+	require.NoError(t, err)
+	node, _ = repo.GetNode(ctx, "node-1")
+	assert.True(t, node.Online)
+}
+
+func TestAgentPowerSamplesFeedPowerEstimates(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withConfig(func(config *utils.SchedulerConfig) {
+		config.Power.Enabled = true
+	}))
+
+	_, err := scheduler.RegisterAgent(ctx, agentRegistration("node-1", 2))
+	require.NoError(t, err)
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "run-1", TenantID: "tenant-1", Name: "resnet", GPUCount: 2}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	_, err = scheduler.AgentHeartbeat(ctx, &models.NodeHeartbeat{
+		NodeID: "node-1",
+		GPUMetrics: []models.GPUMetrics{
+			{GPUID: "node-1-gpu-0", Utilization: 90, PowerUsage: 300},
+			{GPUID: "node-1-gpu-1", Utilization: 70, PowerUsage: 340},
+		},
+	})
+	require.NoError(t, err)
+	alloc := scheduler.primaryAllocation(ctx, "run-1")
+	assert.Equal(t, 320.0, alloc.AvgPowerUsage)
+	assert.Equal(t, 80.0, alloc.AvgGPUUtilization)
+
+	require.NoError(t, scheduler.ReportAgentMetrics(ctx, &models.MetricsReport{
+		NodeID: "node-1",
+		GPUMetrics: []models.GPUMetrics{
+			{GPUID: "node-1-gpu-0", PowerUsage: 280},
+			{GPUID: "node-1-gpu-1", PowerUsage: 280},
+		},
+		JobMetrics: []models.JobMetrics{{JobID: "run-1", State: models.JobStateCompleted, ExitCode: "0"}},
+	}))
+
+	// The next run is estimated from the draw the agent measured
+	next := &models.Job{ID: "run-2", TenantID: "tenant-1", Name: "resnet", GPUCount: 2}
+	require.NoError(t, scheduler.SubmitJob(ctx, next))
+	assert.Equal(t, 600.0, next.ExpectedPowerW)
+}
@@ -13,127 +13,41 @@ import (
 
 // Allocator handles resource allocation
 type Allocator struct {
-	storage storage.Repository
+	storage   storage.Repository
+	config    *utils.SchedulerConfig
+	framework *framework
 }
 
-// NewAllocator creates a new allocator
-func NewAllocator(storage storage.Repository) *Allocator {
-	return &Allocator{
+// NewAllocator creates a new allocator. Plugins that fail to load are
+// logged and the default plugin set is used instead.
+func NewAllocator(storage storage.Repository, config *utils.SchedulerConfig) *Allocator {
+	a := &Allocator{
 		storage: storage,
-	}
-}
-
-// Allocate attempts to allocate resources for a job
-func (a *Allocator) Allocate(ctx context.Context, request *models.AllocationRequest) (*models.AllocationResult, error) {
-	utils.Debug("Attempting allocation", 
-		zap.String("job_id", request.JobID),
-		zap.Int("gpu_count", request.GPUCount))
-
-	// Get available nodes
-	nodes, err := a.storage.ListNodes(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list nodes: %w", err)
+		config:  config,
 	}
 
-	// Filter schedulable nodes
-	var availableNodes []*models.Node
-	for _, node := range nodes {
-		if node.HasCapacity(request.GPUCount, request.CPUCores, request.MemoryMB) {
-			availableNodes = append(availableNodes, node)
-		}
+	var plugins utils.PluginsConfig
+	if config != nil {
+		plugins = config.Plugins
 	}
 
-	if len(availableNodes) == 0 {
-		return &models.AllocationResult{
-			Success: false,
-			Message: "no nodes with sufficient capacity",
-		}, utils.ErrInsufficientResources
-	}
-
-	// Try gang scheduling if requested
-	if request.GangScheduling {
-		return a.gangSchedule(ctx, request, availableNodes)
-	}
-
-	// Try to allocate on best-fit node
-	return a.bestFitSchedule(ctx, request, availableNodes)
-}
-
-// bestFitSchedule uses best-fit algorithm
-func (a *Allocator) bestFitSchedule(ctx context.Context, request *models.AllocationRequest, nodes []*models.Node) (*models.AllocationResult, error) {
-	var bestNode *models.Node
-	var bestGPUs []*models.GPU
-
-	// Find node with least fragmentation
-	minWaste := int64(999999)
-
-	for _, node := range nodes {
-		gpus, err := a.storage.ListGPUsByNode(ctx, node.ID)
-		if err != nil {
-			continue
-		}
-
-		// Find available GPUs
-		var availGPUs []*models.GPU
-		for _, gpu := range gpus {
-			if gpu.IsAvailable() {
-				availGPUs = append(availGPUs, gpu)
-			}
-		}
-
-		if len(availGPUs) >= request.GPUCount {
-			waste := int64(len(availGPUs) - request.GPUCount)
-			if waste < minWaste {
-				minWaste = waste
-				bestNode = node
-				bestGPUs = availGPUs[:request.GPUCount]
-			}
-		}
-	}
-
-	if bestNode == nil {
-		return &models.AllocationResult{
-			Success: false,
-			Message: "no suitable node found",
-		}, utils.ErrInsufficientResources
+	f, err := newFramework(a, plugins)
+	if err != nil {
+		utils.Error("Invalid scheduling plugin configuration, using defaults", zap.Error(err))
+		f, _ = newFramework(a, DefaultPlugins())
 	}
+	a.framework = f
 
-	// Create allocation
-	return a.createAllocation(ctx, request, bestNode, bestGPUs)
+	return a
 }
 
-// gangSchedule allocates all resources atomically
-func (a *Allocator) gangSchedule(ctx context.Context, request *models.AllocationRequest, nodes []*models.Node) (*models.AllocationResult, error) {
-	// For simplicity, try to allocate on a single node
-	// Production version would support multi-node gang scheduling
-	
-	for _, node := range nodes {
-		if node.AvailableGPUs >= request.GPUCount {
-			gpus, err := a.storage.ListGPUsByNode(ctx, node.ID)
-			if err != nil {
-				continue
-			}
-
-			var availGPUs []*models.GPU
-			for _, gpu := range gpus {
-				if gpu.IsAvailable() {
-					availGPUs = append(availGPUs, gpu)
-					if len(availGPUs) == request.GPUCount {
-						break
-					}
-				}
-			}
-
-			if len(availGPUs) == request.GPUCount {
-				return a.createAllocation(ctx, request, node, availGPUs)
-			}
-		}
-	}
+// Allocate attempts to allocate resources for a job
+func (a *Allocator) Allocate(ctx context.Context, request *models.AllocationRequest) (*models.AllocationResult, error) {
+	utils.Debug("Attempting allocation", 
+		zap.String("job_id", request.JobID),
+		zap.Int("gpu_count", request.GPUCount))
 
-	return &models.AllocationResult{
-		Success: false,
-		Message: "gang scheduling failed - insufficient resources on single node",
-	}, utils.ErrGangSchedulingFailed
+	return a.framework.schedule(ctx, a, request)
 }
 
 // createAllocation creates and persists an allocation
@@ -143,6 +57,12 @@ func (a *Allocator) createAllocation(ctx context.Context, request *models.Alloca
 		gpuIDs[i] = gpu.ID
 	}
 
+	// Jobs run for their MaxRuntime, or an hour if they set none
+	planned := 1 * time.Hour
+	if request.MaxRuntime > 0 {
+		planned = request.MaxRuntime
+	}
+
 	allocation := &models.Allocation{
 		ID:             generateAllocationID(),
 		JobID:          request.JobID,
@@ -153,7 +73,21 @@ func (a *Allocator) createAllocation(ctx context.Context, request *models.Alloca
 		CPUCores:       request.CPUCores,
 		MemoryMB:       request.MemoryMB,
 		AllocatedAt:    time.Now(),
-		PlannedDuration: 1 * time.Hour, // Default
+		PlannedDuration: planned,
+		ExpectedPowerW: a.expectedDraw(request, gpus),
+		Reclaimable:    request.Reclaimable,
+	}
+
+	// Fractional requests take a slice of a single GPU
+	if request.IsFractional() {
+		slice, ok := gpus[0].FitSlice(request.GPUSlice)
+		if !ok {
+			return nil, fmt.Errorf("GPU %s can no longer host the requested slice", gpus[0].ID)
+		}
+		slice.AllocationID = allocation.ID
+		slice.JobID = request.JobID
+		slice.TenantID = request.TenantID
+		allocation.Slice = &slice
 	}
 
 	// Save allocation
@@ -161,12 +95,21 @@ func (a *Allocator) createAllocation(ctx context.Context, request *models.Alloca
 		return nil, fmt.Errorf("failed to create allocation: %w", err)
 	}
 
-	// Mark GPUs as allocated
+	// Mark GPUs as allocated, counting those that stop being idle
+	taken := 0
 	for _, gpu := range gpus {
-		gpu.Allocated = true
-		gpu.AllocationID = allocation.ID
-		gpu.JobID = request.JobID
-		gpu.TenantID = request.TenantID
+		if !gpu.InUse() {
+			taken++
+		}
+
+		if allocation.Slice != nil {
+			gpu.Slices = append(gpu.Slices, *allocation.Slice)
+		} else {
+			gpu.Allocated = true
+			gpu.AllocationID = allocation.ID
+			gpu.JobID = request.JobID
+			gpu.TenantID = request.TenantID
+		}
 		
 		if err := a.storage.UpdateGPU(ctx, gpu); err != nil {
 			utils.Error("Failed to update GPU", zap.String("gpu_id", gpu.ID), zap.Error(err))
@@ -174,7 +117,7 @@ func (a *Allocator) createAllocation(ctx context.Context, request *models.Alloca
 	}
 
 	// Update node capacity
-	node.AvailableGPUs -= len(gpus)
+	node.AvailableGPUs -= taken
 	node.AvailableCPUCores -= request.CPUCores
 	node.AvailableMemoryMB -= request.MemoryMB
 	
@@ -188,13 +131,17 @@ func (a *Allocator) createAllocation(ctx context.Context, request *models.Alloca
 		zap.String("node_id", node.ID),
 		zap.Int("gpus", len(gpus)))
 
-	return &models.AllocationResult{
+	result := &models.AllocationResult{
 		Success:      true,
 		AllocationID: allocation.ID,
 		GPUIDs:       gpuIDs,
 		NodeID:       node.ID,
 		Timestamp:    time.Now(),
-	}, nil
+	}
+	if allocation.Slice != nil {
+		result.GPUFraction = allocation.Slice.Fraction
+	}
+	return result, nil
 }
 
 // Free releases an allocation
@@ -213,17 +160,17 @@ func (a *Allocator) Free(ctx context.Context, allocationID string) error {
 		return err
 	}
 
-	// Free GPUs
+	// Free GPUs, or the slice held on a shared GPU
+	freed := 0
 	for _, gpuID := range allocation.GPUIDs {
 		gpu, err := a.storage.GetGPU(ctx, gpuID)
 		if err != nil {
 			continue
 		}
 
-		gpu.Allocated = false
-		gpu.AllocationID = ""
-		gpu.JobID = ""
-		gpu.TenantID = ""
+		if gpu.Release(allocation.ID) {
+			freed++
+		}
 
 		if err := a.storage.UpdateGPU(ctx, gpu); err != nil {
 			utils.Error("Failed to free GPU", zap.String("gpu_id", gpuID), zap.Error(err))
@@ -236,7 +183,7 @@ func (a *Allocator) Free(ctx context.Context, allocationID string) error {
 		return err
 	}
 
-	node.AvailableGPUs += len(allocation.GPUIDs)
+	node.AvailableGPUs += freed
 	node.AvailableCPUCores += allocation.CPUCores
 	node.AvailableMemoryMB += allocation.MemoryMB
 
@@ -251,6 +198,73 @@ func (a *Allocator) Free(ctx context.Context, allocationID string) error {
 	return nil
 }
 
+// Shrink releases the last count GPUs of an allocation along with their
+// share of its CPU, memory and expected power. Releasing every GPU frees
+// the allocation.
+func (a *Allocator) Shrink(ctx context.Context, allocationID string, count int) error {
+	allocation, err := a.storage.GetAllocation(ctx, allocationID)
+	if err != nil {
+		return err
+	}
+
+	total := len(allocation.GPUIDs)
+	if count >= total {
+		return a.Free(ctx, allocationID)
+	}
+	if count <= 0 || allocation.Slice != nil {
+		return fmt.Errorf("cannot release %d GPUs from allocation %s", count, allocationID)
+	}
+
+	released := allocation.GPUIDs[total-count:]
+	cpus := allocation.CPUCores * count / total
+	memory := allocation.MemoryMB * int64(count) / int64(total)
+
+	allocation.GPUIDs = allocation.GPUIDs[:total-count]
+	allocation.CPUCores -= cpus
+	allocation.MemoryMB -= memory
+	allocation.ExpectedPowerW -= allocation.ExpectedPowerW * float64(count) / float64(total)
+
+	if err := a.storage.UpdateAllocation(ctx, allocation); err != nil {
+		return err
+	}
+
+	freed := 0
+	for _, gpuID := range released {
+		gpu, err := a.storage.GetGPU(ctx, gpuID)
+		if err != nil {
+			continue
+		}
+
+		if gpu.Release(allocation.ID) {
+			freed++
+		}
+
+		if err := a.storage.UpdateGPU(ctx, gpu); err != nil {
+			utils.Error("Failed to free GPU", zap.String("gpu_id", gpuID), zap.Error(err))
+		}
+	}
+
+	node, err := a.storage.GetNode(ctx, allocation.NodeID)
+	if err != nil {
+		return err
+	}
+
+	node.AvailableGPUs += freed
+	node.AvailableCPUCores += cpus
+	node.AvailableMemoryMB += memory
+
+	if err := a.storage.UpdateNode(ctx, node); err != nil {
+		return err
+	}
+
+	utils.Info("Allocation shrunk",
+		zap.String("allocation_id", allocationID),
+		zap.String("job_id", allocation.JobID),
+		zap.Int("released", count))
+
+	return nil
+}
+
 func generateAllocationID() string {
 	return fmt.Sprintf("alloc-%d", time.Now().UnixNano())
 }
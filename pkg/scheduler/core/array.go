@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// submitArray stores an array parent and expands it into one child job
+// per index. The parent is never queued; it stays waiting until every
+// child has finished. Children inherit the parent's dependencies, and
+// those beyond the array's throttle wait outside the queue.
+func (s *Scheduler) submitArray(ctx context.Context, parent *models.Job) error {
+	spec := parent.Array
+	blocked := parent.State == models.JobStateWaiting
+	blockedReason := parent.PendingReason
+
+	parent.State = models.JobStateWaiting
+	if err := s.storage.CreateJob(ctx, parent); err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	queued := 0
+	for index := spec.Start; index <= spec.End; index++ {
+		child := *parent
+		child.ID = fmt.Sprintf("%s-%d", parent.ID, index)
+		child.Array = nil
+		child.ArrayParentID = parent.ID
+		child.ArrayIndex = index
+
+		child.Environment = make(map[string]string, len(parent.Environment)+1)
+		for k, v := range parent.Environment {
+			child.Environment[k] = v
+		}
+		child.Environment[models.ArrayIndexEnv] = strconv.Itoa(index)
+
+		switch {
+		case blocked:
+			child.State = models.JobStateWaiting
+			child.PendingReason = blockedReason
+		case spec.MaxConcurrent > 0 && queued >= spec.MaxConcurrent:
+			child.State = models.JobStateWaiting
+			child.PendingReason = throttleReason(spec)
+		default:
+			child.State = models.JobStatePending
+			child.PendingReason = ""
+		}
+
+		if err := s.storage.CreateJob(ctx, &child); err != nil {
+			return fmt.Errorf("failed to create array job %s: %w", child.ID, err)
+		}
+
+		if child.State != models.JobStatePending {
+			continue
+		}
+
+		// Children that do not fit in the queue are released later
+		if err := s.jobPartition(&child).queue.Enqueue(&child); err != nil {
+			child.State = models.JobStateWaiting
+			child.PendingReason = queueFullReason
+			if err := s.storage.UpdateJob(ctx, &child); err != nil {
+				utils.Error("Failed to update array job",
+					zap.String("job_id", child.ID),
+					zap.Error(err))
+			}
+			continue
+		}
+		queued++
+	}
+
+	utils.Info("Job array submitted",
+		zap.String("job_id", parent.ID),
+		zap.String("array", spec.String()),
+		zap.Int("queued", queued))
+
+	return nil
+}
+
+func throttleReason(spec *models.ArraySpec) string {
+	return fmt.Sprintf("waiting for array throttle (%d at a time)", spec.MaxConcurrent)
+}
+
+// arraySlot is the room an array has left for queued or running
+// children during one release pass
+type arraySlot struct {
+	room   int
+	reason string
+}
+
+// admitArrayChild checks the array throttle for a waiting child whose
+// dependencies are met. slots caches each array's room for one pass.
+func (s *Scheduler) admitArrayChild(ctx context.Context, slots map[string]*arraySlot, child *models.Job) (bool, string) {
+	slot, ok := slots[child.ArrayParentID]
+	if !ok {
+		slot = &arraySlot{room: math.MaxInt}
+		parent, err := s.storage.GetJob(ctx, child.ArrayParentID)
+		if err == nil && parent.Array != nil && parent.Array.MaxConcurrent > 0 {
+			children, err := s.storage.ListArrayJobs(ctx, parent.ID)
+			if err != nil {
+				return false, child.PendingReason
+			}
+			counts := models.ArrayCounts(children)
+			slot.room = parent.Array.MaxConcurrent - counts[models.JobStatePending] - counts[models.JobStateRunning]
+			slot.reason = throttleReason(parent.Array)
+		}
+		slots[child.ArrayParentID] = slot
+	}
+
+	if slot.room <= 0 {
+		return false, slot.reason
+	}
+	slot.room--
+	return true, ""
+}
+
+// finishArray moves an array parent to its final state once every child
+// has finished, and reports whether it did
+func (s *Scheduler) finishArray(ctx context.Context, parent *models.Job) bool {
+	children, err := s.storage.ListArrayJobs(ctx, parent.ID)
+	if err != nil {
+		utils.Error("Failed to list array jobs", zap.String("job_id", parent.ID), zap.Error(err))
+		return false
+	}
+
+	state := models.AggregateArrayState(children)
+	if state == models.JobStateRunning || state == models.JobStatePending {
+		return false
+	}
+
+	parent.State = state
+	parent.PendingReason = ""
+	parent.CompletedAt = timePtr(time.Now())
+	if err := s.storage.UpdateJob(ctx, parent); err != nil {
+		utils.Error("Failed to update array job", zap.String("job_id", parent.ID), zap.Error(err))
+	}
+
+	utils.Info("Job array finished",
+		zap.String("job_id", parent.ID),
+		zap.String("state", string(state)))
+
+	return true
+}
+
+// cancelArray cancels every unfinished child of an array and the parent
+func (s *Scheduler) cancelArray(ctx context.Context, parent *models.Job) error {
+	if parent.State != models.JobStateWaiting {
+		return fmt.Errorf("cannot cancel job in state: %s", parent.State)
+	}
+
+	children, err := s.storage.ListArrayJobs(ctx, parent.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if child.IsTerminal() || child.State == models.JobStatePreempted {
+			continue
+		}
+		if err := s.CancelJob(ctx, child.ID); err != nil {
+			utils.Error("Failed to cancel array job",
+				zap.String("job_id", child.ID),
+				zap.Error(err))
+		}
+	}
+
+	parent.State = models.JobStateCancelled
+	parent.PendingReason = ""
+	parent.CompletedAt = timePtr(time.Now())
+	if err := s.storage.UpdateJob(ctx, parent); err != nil {
+		return err
+	}
+
+	utils.Info("Job array cancelled", zap.String("job_id", parent.ID))
+	return nil
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func submitSweep(t *testing.T, scheduler *Scheduler, spec string) *models.Job {
+	t.Helper()
+	array, err := models.ParseArraySpec(spec)
+	require.NoError(t, err)
+
+	parent := &models.Job{
+		ID:          "sweep",
+		TenantID:    "tenant-1",
+		Name:        "sweep",
+		GPUCount:    1,
+		Environment: map[string]string{"LR": "0.1"},
+		Array:       array,
+	}
+	require.NoError(t, scheduler.SubmitJob(context.Background(), parent))
+	return parent
+}
+
+func TestJobArrayExpandsWithThrottle(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-a"))
+
+	parent := submitSweep(t, scheduler, "0-4%2")
+	assert.Equal(t, models.JobStateWaiting, parent.State)
+
+	children, err := repo.ListArrayJobs(ctx, "sweep")
+	require.NoError(t, err)
+	require.Len(t, children, 5)
+	assert.Equal(t, "sweep-3", children[3].ID)
+	assert.Equal(t, "3", children[3].Environment[models.ArrayIndexEnv])
+	assert.Equal(t, "0.1", children[3].Environment["LR"])
+	assert.NotContains(t, parent.Environment, models.ArrayIndexEnv)
+	assert.Equal(t, 2, scheduler.queue.Size())
+
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	counts := models.ArrayCounts(children)
+	assert.Equal(t, 2, counts[models.JobStateRunning])
+	assert.Equal(t, 3, counts[models.JobStateWaiting])
+	assert.Contains(t, children[2].PendingReason, "array throttle")
+
+	status, err := scheduler.GetJobStatus(ctx, "sweep")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStateRunning, status.State)
+	assert.Equal(t, 2, status.ArrayJobs[models.JobStateRunning])
+
+	// A finished child frees a slot for the next index
+	children[0].State = models.JobStateCompleted
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateRunning, children[2].State)
+	assert.Equal(t, models.JobStateWaiting, children[3].State)
+
+	for _, child := range children {
+		child.State = models.JobStateCompleted
+	}
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateCompleted, parent.State)
+}
+
+func TestCancelJobArray(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-a"))
+
+	submitSweep(t, scheduler, "0-9%3")
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	require.NoError(t, scheduler.CancelJob(ctx, "sweep"))
+
+	parent, _ := repo.GetJob(ctx, "sweep")
+	assert.Equal(t, models.JobStateCancelled, parent.State)
+
+	children, _ := repo.ListArrayJobs(ctx, "sweep")
+	assert.Equal(t, 10, models.ArrayCounts(children)[models.JobStateCancelled])
+	assert.Equal(t, 0, scheduler.queue.Size())
+
+	tenant, _ := repo.GetTenant(ctx, "tenant-1")
+	assert.Equal(t, 0, tenant.CurrentGPUs)
+	assert.Equal(t, 8, repo.nodes["node-a"].AvailableGPUs)
+}
+
+func TestPreemptedArrayChildFinishesArray(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(1, "node-a"), withPreemption())
+
+	parent := submitSweep(t, scheduler, "0-0")
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	child, _ := repo.GetJob(ctx, "sweep-0")
+	require.Equal(t, models.JobStateRunning, child.State)
+
+	// Without a retry policy the preempted child is not run again
+	urgent := &models.Job{ID: "urgent", TenantID: "tenant-1", GPUCount: 1, Priority: 900}
+	require.NoError(t, scheduler.SubmitJob(ctx, urgent))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStatePreempted, child.State)
+
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStatePreempted, parent.State)
+	assert.NotNil(t, parent.CompletedAt)
+
+	status, err := scheduler.GetJobStatus(ctx, "sweep")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatePreempted, status.State)
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var ledgerSequence uint64
+
+// Invoice bills a tenant for the ledger entries that ended within
+// [from, to)
+func (s *Scheduler) Invoice(ctx context.Context, tenantID string, from, to time.Time) (*models.Invoice, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: invoice period must end after it starts", utils.ErrInvalidInvoicePeriod)
+	}
+	tenant, err := s.storage.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.storage.ListLedgerEntries(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger entries: %w", err)
+	}
+	return models.BuildInvoice(tenant, entries, from, to, s.currency()), nil
+}
+
+// billInterval appends a ledger entry for gpus GPUs the allocation held
+// from its start until end, and adds the cost to the allocation and the
+// cost and GPU-hours to the job's tenant and its budget
+func (s *Scheduler) billInterval(ctx context.Context, job *models.Job, allocationID string, gpus float64, end time.Time, kind models.LedgerEntryKind) {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	alloc, err := s.storage.GetAllocation(ctx, allocationID)
+	if err != nil || gpus <= 0 || !end.After(alloc.AllocatedAt) {
+		return
+	}
+
+	tenant, err := s.storage.GetTenant(ctx, job.TenantID)
+	if err != nil {
+		tenant = nil
+	}
+	model := s.allocationGPUModel(ctx, alloc)
+	rate := s.gpuHourRate(tenant, model, job.Queue)
+	hours := gpus * end.Sub(alloc.AllocatedAt).Hours()
+
+	entry := &models.LedgerEntry{
+		ID:             fmt.Sprintf("ledger-%d-%d", time.Now().UnixNano(), atomic.AddUint64(&ledgerSequence, 1)),
+		TenantID:       job.TenantID,
+		JobID:          job.ID,
+		AllocationID:   alloc.ID,
+		NodeID:         alloc.NodeID,
+		Queue:          job.Queue,
+		GPUModel:       model,
+		Kind:           kind,
+		GPUs:           gpus,
+		StartedAt:      alloc.AllocatedAt,
+		EndedAt:        end,
+		GPUHours:       hours,
+		RatePerGPUHour: rate,
+		Cost:           hours * rate,
+		Currency:       s.currency(),
+		CreatedAt:      time.Now(),
+	}
+	if err := s.storage.CreateLedgerEntry(ctx, entry); err != nil {
+		utils.Error("Failed to record ledger entry",
+			zap.String("job_id", job.ID),
+			zap.String("allocation_id", alloc.ID),
+			zap.Error(err))
+		return
+	}
+
+	alloc.CostPerHour = rate
+	alloc.TotalCost += entry.Cost
+	if err := s.storage.UpdateAllocation(ctx, alloc); err != nil {
+		utils.Error("Failed to update allocation cost", zap.String("allocation_id", alloc.ID), zap.Error(err))
+	}
+
+	if tenant != nil {
+		tenant.ChargeGPUHours(hours, end)
+		tenant.TotalCost += entry.Cost
+		if err := s.storage.UpdateTenant(ctx, tenant); err != nil {
+			utils.Error("Failed to update tenant cost", zap.String("tenant_id", tenant.ID), zap.Error(err))
+		}
+	}
+}
+
+// endKind tells how an allocation interval ended. Jobs that checkpoint
+// do so before they are preempted or killed at their max runtime.
+func endKind(job *models.Job, alloc *models.Allocation, preempted bool) models.LedgerEntryKind {
+	switch {
+	case job.CheckpointEnabled && (preempted || alloc.TerminateSignaledAt != nil):
+		return models.LedgerCheckpointed
+	case preempted:
+		return models.LedgerPreempted
+	}
+	return models.LedgerRunning
+}
+
+// gpuHourRate prices a GPU-hour of the model in the queue for the
+// tenant. Tenants without billing enabled are not charged.
+func (s *Scheduler) gpuHourRate(tenant *models.Tenant, model models.GPUModel, queue string) float64 {
+	if tenant != nil && !tenant.BillingEnabled {
+		return 0
+	}
+	billing := s.config.Billing
+
+	rate := billing.DefaultGPUHourRate
+	if modelRate, ok := lookupRate(billing.GPUModelRates, string(model)); ok {
+		rate = modelRate
+	}
+	if tenant != nil && tenant.CostPerGPUHour > 0 {
+		rate = tenant.CostPerGPUHour
+	}
+	if multiplier, ok := lookupRate(billing.QueueMultipliers, queue); ok {
+		rate *= multiplier
+	}
+	return rate
+}
+
+// lookupRate finds key in rates ignoring case, as configuration keys may
+// have been lowercased when loaded
+func lookupRate(rates map[string]float64, key string) (float64, bool) {
+	if rate, ok := rates[key]; ok {
+		return rate, true
+	}
+	for name, rate := range rates {
+		if strings.EqualFold(name, key) {
+			return rate, true
+		}
+	}
+	return 0, false
+}
+
+// allocationGPUModel returns the model of the allocation's GPUs
+func (s *Scheduler) allocationGPUModel(ctx context.Context, alloc *models.Allocation) models.GPUModel {
+	for _, gpuID := range alloc.GPUIDs {
+		if gpu, err := s.storage.GetGPU(ctx, gpuID); err == nil {
+			return gpu.Model
+		}
+	}
+	return ""
+}
+
+func (s *Scheduler) currency() string {
+	if s.config.Billing.Currency == "" {
+		return "USD"
+	}
+	return s.config.Billing.Currency
+}
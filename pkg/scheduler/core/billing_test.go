@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// billingSetup bills A100s at 3 per GPU-hour on an 8 GPU node shared by
+// two billed tenants
+func billingSetup() []testOption {
+	return []testOption{
+		withNodes(8, "node-1"),
+		withTenants("tenant-a", "tenant-b"),
+		withTenantSettings(func(tenant *models.Tenant) { tenant.BillingEnabled = true }),
+		withPreemption(),
+		withConfig(func(config *utils.SchedulerConfig) {
+			config.Billing = utils.BillingConfig{
+				Currency:           "EUR",
+				DefaultGPUHourRate: 1,
+				GPUModelRates:      map[string]float64{"a100": 3},
+			}
+		}),
+	}
+}
+
+// backdate moves the start of every active allocation of the job back
+func backdate(repo *fakeRepository, jobID string, by time.Duration) {
+	for _, alloc := range repo.allocations {
+		if alloc.JobID == jobID && alloc.IsActive() {
+			alloc.AllocatedAt = alloc.AllocatedAt.Add(-by)
+		}
+	}
+}
+
+func TestFinishedAllocationIsBilled(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, billingSetup()...)
+
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "train", TenantID: "tenant-a", GPUCount: 2}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	backdate(repo, "train", 2*time.Hour)
+	require.NoError(t, scheduler.CancelJob(ctx, "train"))
+
+	require.Len(t, repo.ledger, 1)
+	entry := repo.ledger[0]
+	assert.Equal(t, models.LedgerRunning, entry.Kind)
+	assert.Equal(t, models.GPUA100, entry.GPUModel)
+	assert.Equal(t, "EUR", entry.Currency)
+	assert.InDelta(t, 4.0, entry.GPUHours, 0.01)
+	assert.InDelta(t, 3.0, entry.RatePerGPUHour, 1e-9)
+	assert.InDelta(t, 12.0, entry.Cost, 0.05)
+
+	assert.InDelta(t, 12.0, repo.tenants["tenant-a"].TotalCost, 0.05)
+	assert.InDelta(t, 12.0, repo.allocations[entry.AllocationID].TotalCost, 0.05)
+	assert.InDelta(t, 3.0, repo.allocations[entry.AllocationID].CostPerHour, 1e-9)
+
+	invoice, err := scheduler.Invoice(ctx, "tenant-a", time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, invoice.Lines, 1)
+	assert.InDelta(t, 4.0, invoice.Lines[0].GPUHours, 0.01)
+	assert.InDelta(t, 12.0, invoice.TotalCost, 0.05)
+
+	_, err = scheduler.Invoice(ctx, "tenant-a", time.Now(), time.Now().Add(-time.Hour))
+	assert.ErrorIs(t, err, utils.ErrInvalidInvoicePeriod)
+}
+
+func TestPreemptedAllocationIsBilled(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, billingSetup()...)
+
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "plain", TenantID: "tenant-b", GPUCount: 4, Priority: 10}))
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "ckpt", TenantID: "tenant-b", GPUCount: 4, Priority: 20, CheckpointEnabled: true}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	backdate(repo, "plain", time.Hour)
+	backdate(repo, "ckpt", time.Hour)
+
+	// Each high-priority job preempts one of tenant B's
+	for _, id := range []string{"urgent-1", "urgent-2"} {
+		require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: id, TenantID: "tenant-a", GPUCount: 4, Priority: 500}))
+		require.NoError(t, scheduler.schedulingCycle(ctx))
+	}
+
+	kinds := map[string]models.LedgerEntryKind{}
+	for _, entry := range repo.ledger {
+		kinds[entry.JobID] = entry.Kind
+		assert.InDelta(t, 4.0, entry.GPUHours, 0.01)
+	}
+	assert.Equal(t, map[string]models.LedgerEntryKind{
+		"plain": models.LedgerPreempted,
+		"ckpt":  models.LedgerCheckpointed,
+	}, kinds)
+}
+
+func TestGPUHourRate(t *testing.T) {
+	scheduler := NewScheduler(&utils.SchedulerConfig{Billing: utils.BillingConfig{
+		DefaultGPUHourRate: 2,
+		GPUModelRates:      map[string]float64{"H100": 5},
+		QueueMultipliers:   map[string]float64{"interactive": 1.5},
+	}}, newFakeRepository())
+	billed := &models.Tenant{BillingEnabled: true}
+
+	assert.InDelta(t, 2.0, scheduler.gpuHourRate(billed, models.GPUA100, "batch"), 1e-9)
+	assert.InDelta(t, 5.0, scheduler.gpuHourRate(billed, models.GPUH100, "batch"), 1e-9)
+	assert.InDelta(t, 7.5, scheduler.gpuHourRate(billed, models.GPUH100, "interactive"), 1e-9)
+
+	// A tenant's negotiated rate replaces the model's, queues still scale it
+	negotiated := &models.Tenant{BillingEnabled: true, CostPerGPUHour: 4}
+	assert.InDelta(t, 6.0, scheduler.gpuHourRate(negotiated, models.GPUH100, "interactive"), 1e-9)
+
+	assert.Zero(t, scheduler.gpuHourRate(&models.Tenant{}, models.GPUH100, "batch"))
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// SetTenantBudget replaces a tenant's GPU-hour budget and quota
+// profiles. A nil budget removes it. Usage already charged to the
+// current period is kept when the period does not change.
+func (s *Scheduler) SetTenantBudget(ctx context.Context, tenantID string, budget *models.GPUHourBudget, profiles []models.QuotaProfile) (*models.Tenant, error) {
+	if err := models.ValidateBudget(budget, profiles); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidBudget, err)
+	}
+
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	tenant, err := s.storage.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if budget != nil {
+		budget.Reset(now)
+		if previous := tenant.Budget; previous != nil && previous.Period == budget.Period {
+			previous.Roll(now)
+			budget.UsedGPUHours = previous.UsedGPUHours
+			budget.CarriedGPUHours = previous.CarriedGPUHours
+		}
+	}
+
+	tenant.Budget = budget
+	tenant.QuotaProfiles = profiles
+	tenant.UpdatedAt = now
+	if err := s.storage.UpdateTenant(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	utils.Info("Tenant budget updated",
+		zap.String("tenant_id", tenantID),
+		zap.Bool("budget", budget != nil),
+		zap.Int("quota_profiles", len(profiles)))
+	return tenant, nil
+}
+
+// TenantBudget reports the tenant's GPU-hour budget for the current
+// period and the limits in effect now
+func (s *Scheduler) TenantBudget(ctx context.Context, tenantID string) (*models.BudgetStatus, error) {
+	tenant, err := s.storage.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	limits := tenant.AtTime(now)
+	status := &models.BudgetStatus{
+		TenantID:          tenant.ID,
+		State:             models.BudgetOK,
+		TotalGPUHours:     tenant.TotalGPUHours,
+		ActiveProfile:     tenant.ActiveQuotaProfile(now),
+		MaxGPUs:           limits.MaxGPUs,
+		MaxConcurrentJobs: limits.MaxConcurrentJobs,
+	}
+
+	if tenant.Budget != nil {
+		budget := *tenant.Budget
+		budget.Roll(now)
+		start, end := budget.PeriodStart, budget.PeriodEnd()
+		remaining := budget.Remaining()
+		status.Period = budget.Period
+		status.PeriodStart = &start
+		status.PeriodEnd = &end
+		status.AllowanceGPUHours = budget.Allowance()
+		status.UsedGPUHours = budget.UsedGPUHours
+		status.RemainingGPUHours = &remaining
+		status.State = budget.State()
+	}
+	return status, nil
+}
+
+// checkBudgets moves tenant budgets into the current period and records
+// an event the first time a period's usage crosses the soft or hard
+// threshold
+func (s *Scheduler) checkBudgets(ctx context.Context, now time.Time) {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	tenants, err := s.storage.ListTenants(ctx)
+	if err != nil {
+		utils.Error("Failed to list tenants", zap.Error(err))
+		return
+	}
+
+	for _, tenant := range tenants {
+		budget := tenant.Budget
+		if budget == nil {
+			continue
+		}
+
+		changed := budget.Roll(now)
+		switch state := budget.State(); {
+		case state == models.BudgetExhausted && budget.ExhaustedAt == nil:
+			budget.ExhaustedAt = &now
+			if budget.WarnedAt == nil {
+				budget.WarnedAt = &now
+			}
+			changed = true
+			s.recordBudgetEvent(ctx, tenant, models.EventBudgetExhausted,
+				"GPU-hour budget exhausted, new jobs wait for the next period")
+		case state == models.BudgetWarning && budget.WarnedAt == nil:
+			budget.WarnedAt = &now
+			changed = true
+			s.recordBudgetEvent(ctx, tenant, models.EventBudgetWarning,
+				"GPU-hour budget passed its soft threshold")
+		}
+
+		if !changed {
+			continue
+		}
+		if err := s.storage.UpdateTenant(ctx, tenant); err != nil {
+			utils.Error("Failed to update tenant budget",
+				zap.String("tenant_id", tenant.ID),
+				zap.Error(err))
+		}
+	}
+}
+
+// recordBudgetEvent records a threshold crossing of the tenant's budget
+func (s *Scheduler) recordBudgetEvent(ctx context.Context, tenant *models.Tenant, eventType models.EventType, message string) {
+	budget := tenant.Budget
+	utils.Warn("Tenant budget threshold crossed",
+		zap.String("tenant_id", tenant.ID),
+		zap.String("state", string(budget.State())),
+		zap.Float64("used_gpu_hours", budget.UsedGPUHours),
+		zap.Float64("allowance_gpu_hours", budget.Allowance()))
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:     eventType,
+		Reason:   string(budget.Period),
+		TenantID: tenant.ID,
+		Message:  message,
+		Metadata: map[string]string{
+			"used_gpu_hours":      fmt.Sprintf("%.2f", budget.UsedGPUHours),
+			"allowance_gpu_hours": fmt.Sprintf("%.2f", budget.Allowance()),
+			"period_end":          budget.PeriodEnd().Format(time.RFC3339),
+		},
+	})
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setBudget gives tenant-1 a budget of 10 GPU-hours a month
+func setBudget(t *testing.T, scheduler *Scheduler, repo *fakeRepository) *models.Tenant {
+	t.Helper()
+	_, err := scheduler.SetTenantBudget(context.Background(), "tenant-1",
+		&models.GPUHourBudget{Period: models.BudgetMonthly, GPUHours: 10}, nil)
+	require.NoError(t, err)
+	return repo.tenants["tenant-1"]
+}
+
+func budgetEvents(repo *fakeRepository, eventType models.EventType) []*models.Event {
+	var events []*models.Event
+	for _, event := range repo.events {
+		if event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestFinishedJobsChargeBudget(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-1"))
+	tenant := setBudget(t, scheduler, repo)
+
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "train", TenantID: tenant.ID, GPUCount: 2}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, repo.jobs["train"].State)
+
+	// Two GPUs for four and a half hours
+	for _, alloc := range repo.allocations {
+		alloc.AllocatedAt = time.Now().Add(-270 * time.Minute)
+	}
+	require.NoError(t, scheduler.CancelJob(ctx, "train"))
+
+	assert.InDelta(t, 9.0, tenant.TotalGPUHours, 0.01)
+	status, err := scheduler.TenantBudget(ctx, tenant.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BudgetWarning, status.State)
+	assert.InDelta(t, 9.0, status.UsedGPUHours, 0.01)
+	require.NotNil(t, status.RemainingGPUHours)
+	assert.InDelta(t, 1.0, *status.RemainingGPUHours, 0.01)
+
+	// The soft threshold warns once
+	scheduler.checkBudgets(ctx, time.Now())
+	scheduler.checkBudgets(ctx, time.Now())
+	assert.Len(t, budgetEvents(repo, models.EventBudgetWarning), 1)
+	assert.Empty(t, budgetEvents(repo, models.EventBudgetExhausted))
+}
+
+func TestShrunkGPUsChargeBudget(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-1"))
+	tenant := setBudget(t, scheduler, repo)
+
+	job := &models.Job{ID: "train", TenantID: tenant.ID, MinGPUs: 2, MaxGPUs: 8}
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, 8, job.GPUCount)
+
+	// Eight GPUs for an hour, then six are given back and the rest run
+	// for another hour
+	backdate(repo, "train", time.Hour)
+	require.Equal(t, 6, scheduler.shrinkJob(ctx, job, "", 6))
+	backdate(repo, "train", time.Hour)
+	require.NoError(t, scheduler.CancelJob(ctx, "train"))
+
+	ledgerHours := 0.0
+	for _, entry := range repo.ledger {
+		ledgerHours += entry.GPUHours
+	}
+	assert.InDelta(t, 10.0, ledgerHours, 0.01)
+	assert.InDelta(t, ledgerHours, tenant.Budget.UsedGPUHours, 1e-9)
+	assert.InDelta(t, ledgerHours, tenant.TotalGPUHours, 1e-9)
+}
+
+func TestExhaustedBudgetKeepsJobsPending(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-1"))
+	tenant := setBudget(t, scheduler, repo)
+	tenant.Budget.Charge(10)
+
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "train", TenantID: tenant.ID, GPUCount: 1}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePending, repo.jobs["train"].State)
+	assert.Equal(t, "waiting for GPU-hour budget of tenant tenant-1", repo.jobs["train"].PendingReason)
+	require.Len(t, budgetEvents(repo, models.EventBudgetExhausted), 1)
+
+	// A new period lets the job run
+	tenant.Budget.PeriodStart = tenant.Budget.PeriodStart.AddDate(0, -1, 0)
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateRunning, repo.jobs["train"].State)
+	assert.Zero(t, tenant.Budget.UsedGPUHours)
+}
+
+func TestQuotaProfileLimitsJobs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-1"))
+	tenant := setBudget(t, scheduler, repo)
+
+	// A profile covering every hour stands in for business hours
+	_, err := scheduler.SetTenantBudget(ctx, tenant.ID, nil, []models.QuotaProfile{
+		{Name: "business", StartHour: 9, EndHour: 9, MaxGPUs: 2},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "first", TenantID: tenant.ID, GPUCount: 2, Priority: 200}))
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "second", TenantID: tenant.ID, GPUCount: 2, Priority: 100}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStateRunning, repo.jobs["first"].State)
+	assert.Equal(t, models.JobStatePending, repo.jobs["second"].State)
+	assert.Equal(t, "waiting for quota of tenant tenant-1", repo.jobs["second"].PendingReason)
+
+	status, err := scheduler.TenantBudget(ctx, tenant.ID)
+	require.NoError(t, err)
+	require.NotNil(t, status.ActiveProfile)
+	assert.Equal(t, "business", status.ActiveProfile.Name)
+	assert.Equal(t, 2, status.MaxGPUs)
+	assert.Nil(t, status.RemainingGPUHours)
+
+	_, err = scheduler.SetTenantBudget(ctx, tenant.ID, nil, []models.QuotaProfile{{Name: "bad", StartHour: 25}})
+	assert.ErrorIs(t, err, utils.ErrInvalidBudget)
+}
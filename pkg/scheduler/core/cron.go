@@ -0,0 +1,276 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// defaultStartingDeadline is how late a cron run may start when the
+// configuration sets no limit
+const defaultStartingDeadline = 5 * time.Minute
+
+// CreateCronJob validates a cron job and its template and schedules its
+// first run
+func (s *Scheduler) CreateCronJob(ctx context.Context, cronJob *models.CronJob) error {
+	if err := cronJob.Validate(); err != nil {
+		return fmt.Errorf("cron job validation failed: %w", err)
+	}
+	if _, err := s.storage.GetTenant(ctx, cronJob.TenantID); err != nil {
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	// Check the template the way every run will be checked on submission
+	cronJob.Template.TenantID = cronJob.TenantID
+	template := *cronJob.Template
+	if err := s.validateJob(ctx, &template); err != nil {
+		return fmt.Errorf("cron job validation failed: %w", err)
+	}
+
+	next, err := cronJob.NextAfter(time.Now())
+	if err != nil {
+		return fmt.Errorf("cron job validation failed: %w", err)
+	}
+	if next == nil {
+		return fmt.Errorf("cron job validation failed: schedule %q never fires", cronJob.Schedule)
+	}
+	cronJob.NextRunAt = next
+
+	if cronJob.ID == "" {
+		cronJob.ID = generateCronJobID()
+	}
+	if err := s.storage.CreateCronJob(ctx, cronJob); err != nil {
+		return fmt.Errorf("failed to create cron job: %w", err)
+	}
+
+	utils.Info("Cron job created",
+		zap.String("cron_job_id", cronJob.ID),
+		zap.String("schedule", cronJob.Schedule),
+		zap.String("timezone", cronJob.Timezone),
+		zap.Time("next_run_at", *next))
+
+	return nil
+}
+
+// GetCronJob returns a cron job
+func (s *Scheduler) GetCronJob(ctx context.Context, cronJobID string) (*models.CronJob, error) {
+	return s.storage.GetCronJob(ctx, cronJobID)
+}
+
+// ListCronJobs returns cron jobs, limited to one tenant unless tenantID
+// is empty
+func (s *Scheduler) ListCronJobs(ctx context.Context, tenantID string) ([]*models.CronJob, error) {
+	cronJobs, err := s.storage.ListCronJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.CronJob, 0, len(cronJobs))
+	for _, cronJob := range cronJobs {
+		if tenantID == "" || cronJob.TenantID == tenantID {
+			result = append(result, cronJob)
+		}
+	}
+	return result, nil
+}
+
+// DeleteCronJob stops a cron job. Jobs it already submitted are left to
+// finish.
+func (s *Scheduler) DeleteCronJob(ctx context.Context, cronJobID string) error {
+	if _, err := s.storage.GetCronJob(ctx, cronJobID); err != nil {
+		return err
+	}
+	if err := s.storage.DeleteCronJob(ctx, cronJobID); err != nil {
+		return fmt.Errorf("failed to delete cron job: %w", err)
+	}
+
+	utils.Info("Cron job deleted", zap.String("cron_job_id", cronJobID))
+	return nil
+}
+
+// ListCronRuns returns a cron job's run history, most recent first
+func (s *Scheduler) ListCronRuns(ctx context.Context, cronJobID string, limit int) ([]*models.CronRun, error) {
+	if _, err := s.storage.GetCronJob(ctx, cronJobID); err != nil {
+		return nil, err
+	}
+	return s.storage.ListCronRuns(ctx, cronJobID, limit)
+}
+
+// runCronJobs submits the runs of every cron job that has come due
+func (s *Scheduler) runCronJobs(ctx context.Context, now time.Time) {
+	cronJobs, err := s.storage.ListCronJobs(ctx)
+	if err != nil {
+		utils.Error("Failed to list cron jobs", zap.Error(err))
+		return
+	}
+
+	for _, cronJob := range cronJobs {
+		if cronJob.NextRunAt == nil || cronJob.NextRunAt.After(now) {
+			continue
+		}
+		s.fireCronJob(ctx, cronJob, now)
+	}
+}
+
+// fireCronJob handles a cron job whose next run has come due. Runs that
+// came due while the scheduler was down collapse into the latest one,
+// which is skipped if it is too late and the missed run policy says so.
+func (s *Scheduler) fireCronJob(ctx context.Context, cronJob *models.CronJob, now time.Time) {
+	due := *cronJob.NextRunAt
+	missed := 0
+	for {
+		next, err := cronJob.NextAfter(due)
+		if err != nil || next == nil || next.After(now) {
+			break
+		}
+		due = *next
+		missed++
+	}
+
+	run := &models.CronRun{
+		ID:           fmt.Sprintf("%s-%d", cronJob.ID, due.Unix()),
+		CronJobID:    cronJob.ID,
+		ScheduledFor: due,
+		CreatedAt:    now,
+	}
+
+	// The latest run counts as missed too if it is too late to start
+	late := now.Sub(due) > s.startingDeadline()
+	if late {
+		missed++
+	}
+
+	switch {
+	case late && cronJob.MissedRunPolicy == models.MissedRunSkip:
+		run.Status = models.CronRunSkipped
+		run.Reason = fmt.Sprintf("skipped %d missed runs", missed)
+	case missed > 0 && cronJob.MissedRunPolicy == models.MissedRunSkip:
+		run.Reason = fmt.Sprintf("skipped %d missed runs", missed)
+		s.startCronRun(ctx, cronJob, run)
+	case missed > 0:
+		run.Reason = fmt.Sprintf("catching up on %d missed runs", missed)
+		s.startCronRun(ctx, cronJob, run)
+	default:
+		s.startCronRun(ctx, cronJob, run)
+	}
+
+	s.recordCronRun(ctx, run)
+
+	cronJob.LastRunAt = &due
+	next, err := cronJob.NextAfter(now)
+	if err != nil {
+		utils.Error("Failed to compute next cron run", zap.String("cron_job_id", cronJob.ID), zap.Error(err))
+	}
+	cronJob.NextRunAt = next
+	if err := s.storage.UpdateCronJob(ctx, cronJob); err != nil {
+		utils.Error("Failed to update cron job", zap.String("cron_job_id", cronJob.ID), zap.Error(err))
+	}
+}
+
+// startCronRun applies the concurrency policy and submits the run's job
+func (s *Scheduler) startCronRun(ctx context.Context, cronJob *models.CronJob, run *models.CronRun) {
+	active, err := s.activeCronJobs(ctx, cronJob)
+	if err != nil {
+		run.Status = models.CronRunFailed
+		run.Reason = fmt.Sprintf("failed to list earlier runs: %v", err)
+		return
+	}
+
+	if len(active) > 0 {
+		switch cronJob.ConcurrencyPolicy {
+		case models.ConcurrencyForbid:
+			run.Status = models.CronRunSkipped
+			run.Reason = fmt.Sprintf("earlier run %s is still %s", active[0].ID, active[0].State)
+			return
+
+		case models.ConcurrencyReplace:
+			for _, job := range active {
+				if err := s.CancelJob(ctx, job.ID); err != nil {
+					utils.Error("Failed to cancel replaced cron run",
+						zap.String("cron_job_id", cronJob.ID),
+						zap.String("job_id", job.ID),
+						zap.Error(err))
+				}
+			}
+		}
+	}
+
+	job := newCronRunJob(cronJob, run.ScheduledFor)
+	if err := s.SubmitJob(ctx, job); err != nil {
+		run.Status = models.CronRunFailed
+		run.Reason = err.Error()
+		return
+	}
+
+	run.JobID = job.ID
+	run.Status = models.CronRunSubmitted
+}
+
+// activeCronJobs returns the jobs a cron job submitted that have not
+// finished yet
+func (s *Scheduler) activeCronJobs(ctx context.Context, cronJob *models.CronJob) ([]*models.Job, error) {
+	jobs, err := s.storage.ListJobsByTenant(ctx, cronJob.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []*models.Job
+	for _, job := range jobs {
+		if job.CronJobID != cronJob.ID || job.ArrayParentID != "" {
+			continue
+		}
+		if job.IsTerminal() || job.State == models.JobStatePreempted {
+			continue
+		}
+		active = append(active, job)
+	}
+	return active, nil
+}
+
+func (s *Scheduler) recordCronRun(ctx context.Context, run *models.CronRun) {
+	utils.Info("Cron run",
+		zap.String("cron_job_id", run.CronJobID),
+		zap.Time("scheduled_for", run.ScheduledFor),
+		zap.String("status", string(run.Status)),
+		zap.String("job_id", run.JobID),
+		zap.String("reason", run.Reason))
+
+	if err := s.storage.CreateCronRun(ctx, run); err != nil {
+		utils.Error("Failed to record cron run", zap.String("run_id", run.ID), zap.Error(err))
+	}
+}
+
+func (s *Scheduler) startingDeadline() time.Duration {
+	if s.config.Cron.StartingDeadlineSec > 0 {
+		return time.Duration(s.config.Cron.StartingDeadlineSec) * time.Second
+	}
+	return defaultStartingDeadline
+}
+
+// newCronRunJob instantiates a cron job's template for one run. The run
+// time is passed to the job in CRON_SCHEDULED_TIME.
+func newCronRunJob(cronJob *models.CronJob, due time.Time) *models.Job {
+	job := *cronJob.Template
+	job.ID = fmt.Sprintf("%s-%d", cronJob.ID, due.Unix())
+	job.TenantID = cronJob.TenantID
+	job.CronJobID = cronJob.ID
+	if job.Name == "" {
+		job.Name = cronJob.Name
+	}
+
+	job.Environment = make(map[string]string, len(cronJob.Template.Environment)+1)
+	for k, v := range cronJob.Template.Environment {
+		job.Environment[k] = v
+	}
+	job.Environment[models.CronScheduledTimeEnv] = due.Format(time.RFC3339)
+
+	return &job
+}
+
+func generateCronJobID() string {
+	return fmt.Sprintf("cron-%d", time.Now().UnixNano())
+}
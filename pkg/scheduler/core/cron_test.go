@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cronSetup runs cron jobs on a 4 GPU node and still starts runs up to
+// five minutes late
+func cronSetup() []testOption {
+	return []testOption{
+		withNodes(4, "node-a"),
+		withConfig(func(config *utils.SchedulerConfig) {
+			config.Cron.StartingDeadlineSec = 300
+		}),
+	}
+}
+
+func createCronJob(t *testing.T, scheduler *Scheduler, policy models.ConcurrencyPolicy, missed models.MissedRunPolicy) *models.CronJob {
+	t.Helper()
+	cronJob := &models.CronJob{
+		Name:              "nightly-eval",
+		TenantID:          "tenant-1",
+		Schedule:          "0 2 * * *",
+		Timezone:          "Europe/Paris",
+		ConcurrencyPolicy: policy,
+		MissedRunPolicy:   missed,
+		Template:          &models.Job{GPUCount: 2, Priority: 100},
+	}
+	require.NoError(t, scheduler.CreateCronJob(context.Background(), cronJob))
+	return cronJob
+}
+
+// dueAt moves a cron job's next run to at
+func dueAt(cronJob *models.CronJob, at time.Time) {
+	cronJob.NextRunAt = &at
+}
+
+func TestCronJobSubmitsRuns(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, cronSetup()...)
+
+	cronJob := createCronJob(t, scheduler, "", "")
+	require.NotNil(t, cronJob.NextRunAt)
+	assert.Equal(t, 2, cronJob.NextRunAt.In(mustLoad(t, "Europe/Paris")).Hour())
+
+	// Nothing happens before the run is due
+	scheduler.runCronJobs(ctx, cronJob.NextRunAt.Add(-time.Minute))
+	assert.Empty(t, repo.cronRuns)
+
+	due := *cronJob.NextRunAt
+	scheduler.runCronJobs(ctx, due.Add(time.Second))
+
+	runs, err := scheduler.ListCronRuns(ctx, cronJob.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.CronRunSubmitted, runs[0].Status)
+	assert.Equal(t, due, runs[0].ScheduledFor)
+
+	job := repo.jobs[runs[0].JobID]
+	require.NotNil(t, job)
+	assert.Equal(t, models.JobStatePending, job.State)
+	assert.Equal(t, cronJob.ID, job.CronJobID)
+	assert.Equal(t, "nightly-eval", job.Name)
+	assert.Equal(t, due.Format(time.RFC3339), job.Environment[models.CronScheduledTimeEnv])
+
+	assert.Equal(t, due, *cronJob.LastRunAt)
+	assert.Equal(t, due.AddDate(0, 0, 1), *cronJob.NextRunAt)
+}
+
+func TestCronConcurrencyPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("forbid", func(t *testing.T) {
+		scheduler, repo := newTestScheduler(t, cronSetup()...)
+		cronJob := createCronJob(t, scheduler, models.ConcurrencyForbid, "")
+
+		now := time.Now()
+		dueAt(cronJob, now)
+		scheduler.runCronJobs(ctx, now)
+		dueAt(cronJob, now.Add(time.Minute))
+		scheduler.runCronJobs(ctx, now.Add(time.Minute))
+
+		require.Len(t, repo.cronRuns, 2)
+		assert.Equal(t, models.CronRunSubmitted, repo.cronRuns[0].Status)
+		assert.Equal(t, models.CronRunSkipped, repo.cronRuns[1].Status)
+		assert.Contains(t, repo.cronRuns[1].Reason, "still pending")
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		scheduler, repo := newTestScheduler(t, cronSetup()...)
+		cronJob := createCronJob(t, scheduler, models.ConcurrencyReplace, "")
+
+		now := time.Now()
+		dueAt(cronJob, now)
+		scheduler.runCronJobs(ctx, now)
+		require.NoError(t, scheduler.schedulingCycle(ctx))
+		first := repo.jobs[repo.cronRuns[0].JobID]
+		require.Equal(t, models.JobStateRunning, first.State)
+
+		dueAt(cronJob, now.Add(time.Minute))
+		scheduler.runCronJobs(ctx, now.Add(time.Minute))
+
+		assert.Equal(t, models.JobStateCancelled, first.State)
+		require.Len(t, repo.cronRuns, 2)
+		assert.Equal(t, models.CronRunSubmitted, repo.cronRuns[1].Status)
+	})
+
+	t.Run("allow", func(t *testing.T) {
+		scheduler, repo := newTestScheduler(t, cronSetup()...)
+		cronJob := createCronJob(t, scheduler, models.ConcurrencyAllow, "")
+
+		now := time.Now()
+		dueAt(cronJob, now)
+		scheduler.runCronJobs(ctx, now)
+		dueAt(cronJob, now.Add(time.Minute))
+		scheduler.runCronJobs(ctx, now.Add(time.Minute))
+
+		active, err := scheduler.activeCronJobs(ctx, cronJob)
+		require.NoError(t, err)
+		assert.Len(t, active, 2)
+		assert.Len(t, repo.cronRuns, 2)
+	})
+}
+
+func TestCronMissedRuns(t *testing.T) {
+	ctx := context.Background()
+
+	// The scheduler comes back three days and an hour after a run was due
+	for _, tt := range []struct {
+		policy models.MissedRunPolicy
+		status models.CronRunStatus
+	}{
+		{models.MissedRunOnce, models.CronRunSubmitted},
+		{models.MissedRunSkip, models.CronRunSkipped},
+	} {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			scheduler, repo := newTestScheduler(t, cronSetup()...)
+			cronJob := createCronJob(t, scheduler, "", tt.policy)
+
+			due := *cronJob.NextRunAt
+			now := due.AddDate(0, 0, 3).Add(time.Hour)
+			scheduler.runCronJobs(ctx, now)
+
+			require.Len(t, repo.cronRuns, 1)
+			run := repo.cronRuns[0]
+			assert.Equal(t, tt.status, run.Status)
+			assert.Equal(t, due.AddDate(0, 0, 3), run.ScheduledFor)
+			assert.Contains(t, run.Reason, "4 missed runs")
+			assert.Equal(t, due.AddDate(0, 0, 4), *cronJob.NextRunAt)
+		})
+	}
+}
+
+func TestCreateCronJobValidatesTemplate(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, cronSetup()...)
+
+	err := scheduler.CreateCronJob(ctx, &models.CronJob{
+		TenantID: "tenant-1",
+		Schedule: "@hourly",
+		Template: &models.Job{GPUCount: 16},
+	})
+	assert.Error(t, err)
+
+	err = scheduler.CreateCronJob(ctx, &models.CronJob{
+		TenantID: "tenant-1",
+		Schedule: "0 0 31 2 *",
+		Template: &models.Job{GPUCount: 1},
+	})
+	assert.ErrorContains(t, err, "never fires")
+}
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
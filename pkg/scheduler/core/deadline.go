@@ -0,0 +1,36 @@
+package core
+
+import (
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+)
+
+// boostDeadlines raises queued jobs whose deadline slack is running out
+func (s *Scheduler) boostDeadlines(now time.Time) {
+	window := time.Duration(s.config.Deadline.BoostWindowSec) * time.Second
+	for _, part := range s.partitions {
+		part.queue.ApplyDeadlineBoost(now, window, s.config.Deadline.MaxBoost)
+	}
+}
+
+// deadlineCritical returns true if the job can still meet its deadline
+// but has less slack left than the critical threshold
+func (s *Scheduler) deadlineCritical(job *models.Job, now time.Time) bool {
+	slack, ok := job.Slack(now)
+	critical := time.Duration(s.config.Deadline.CriticalSlackSec) * time.Second
+	return ok && slack >= 0 && slack < critical
+}
+
+// canPreempt returns true if the job may preempt lower-priority work:
+// never when its queue disables preemption, always when preemption is
+// enabled, otherwise only to meet a critical deadline
+func (s *Scheduler) canPreempt(job *models.Job) bool {
+	if s.jobPartition(job).config.Preemption == PreemptDisabled {
+		return false
+	}
+	if s.config.EnablePreemption {
+		return true
+	}
+	return s.config.Deadline.PreemptForCritical && s.deadlineCritical(job, time.Now())
+}
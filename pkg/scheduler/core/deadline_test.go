@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadlineSetup boosts jobs within an hour of their deadline and lets
+// critical ones preempt on a 4 GPU node
+func deadlineSetup() []testOption {
+	return []testOption{
+		withNodes(4, "node-a"),
+		withTenantSettings(func(tenant *models.Tenant) { tenant.AllowPreemption = true }),
+		withConfig(func(config *utils.SchedulerConfig) {
+			config.Deadline = utils.DeadlineConfig{
+				BoostWindowSec:     3600,
+				MaxBoost:           1000,
+				CriticalSlackSec:   600,
+				PreemptForCritical: true,
+			}
+		}),
+	}
+}
+
+func TestCriticalDeadlinePreemptsLowerPriorityWork(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, deadlineSetup()...)
+
+	batch := &models.Job{ID: "batch", TenantID: "tenant-1", GPUCount: 4, Priority: 100}
+	require.NoError(t, scheduler.SubmitJob(ctx, batch))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, batch.State)
+
+	// Plenty of slack: preemption is disabled, so the job waits
+	relaxed := time.Now().Add(5 * time.Hour)
+	refresh := &models.Job{ID: "refresh", TenantID: "tenant-1", GPUCount: 4, Priority: 500,
+		Deadline: &relaxed, MaxRuntime: time.Hour}
+	require.NoError(t, scheduler.SubmitJob(ctx, refresh))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStatePending, refresh.State)
+	assert.Equal(t, models.JobStateRunning, batch.State)
+
+	// Five minutes of slack left makes the deadline critical
+	tight := time.Now().Add(65 * time.Minute)
+	refresh.Deadline = &tight
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateRunning, refresh.State)
+	assert.Equal(t, models.JobStatePreempted, batch.State)
+}
+
+func TestUnreachableDeadlineFlaggedInStatus(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, deadlineSetup()...)
+
+	deadline := time.Now().Add(30 * time.Minute)
+	job := &models.Job{ID: "refresh", TenantID: "tenant-1", GPUCount: 1,
+		Deadline: &deadline, EstimatedDuration: time.Hour}
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+
+	status, err := scheduler.GetJobStatus(ctx, "refresh")
+	require.NoError(t, err)
+	assert.True(t, status.DeadlineUnreachable)
+	require.NotNil(t, status.DeadlineSlack)
+	assert.True(t, *status.DeadlineSlack < 0)
+
+	// A job with room to spare is not flagged
+	later := time.Now().Add(3 * time.Hour)
+	job.Deadline = &later
+	status, err = scheduler.GetJobStatus(ctx, "refresh")
+	require.NoError(t, err)
+	assert.False(t, status.DeadlineUnreachable)
+}
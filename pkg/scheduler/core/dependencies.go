@@ -0,0 +1,230 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// queueFullReason is reported for released jobs the queue has no room for
+const queueFullReason = "waiting for queue space"
+
+// validateDependencies checks dependency conditions without looking up
+// the jobs they refer to. An empty condition means afterok.
+func validateDependencies(job *models.Job) error {
+	for i := range job.Dependencies {
+		dep := &job.Dependencies[i]
+		if dep.Condition == "" {
+			dep.Condition = models.DependAfterOK
+		}
+		if !dep.Condition.IsValid() {
+			return fmt.Errorf("%w: unknown condition %s", utils.ErrInvalidDependency, dep.Condition)
+		}
+		if dep.JobID == "" || dep.JobID == job.ID {
+			return fmt.Errorf("%w: job cannot depend on %q", utils.ErrInvalidDependency, dep.JobID)
+		}
+	}
+	return nil
+}
+
+// dependencyStatus evaluates a job's dependencies. It returns whether all
+// are met, and otherwise a reason the job is still waiting or, when a
+// dependency can no longer be met, why it never will run.
+func (s *Scheduler) dependencyStatus(ctx context.Context, job *models.Job) (met bool, waiting string, failed string) {
+	var pending []string
+	for _, dep := range job.Dependencies {
+		target, err := s.storage.GetJob(ctx, dep.JobID)
+		if err != nil {
+			return false, "", fmt.Sprintf("dependency %s not found", dep.JobID)
+		}
+
+		ok, possible := dep.Evaluate(target.State)
+		if !possible {
+			return false, "", fmt.Sprintf("dependency %s ended %s, %s can no longer be met",
+				dep.JobID, target.State, dep.Condition)
+		}
+		if !ok {
+			pending = append(pending, fmt.Sprintf("%s (%s)", dep.JobID, dep.Condition))
+		}
+	}
+
+	if len(pending) > 0 {
+		return false, "waiting for dependencies: " + strings.Join(pending, ", "), ""
+	}
+	return true, "", ""
+}
+
+// releaseWaitingJobs moves waiting jobs whose dependencies are met into
+// the queue and cancels those whose dependencies can no longer be met.
+// Array children are also held to their array's throttle, lowest index
+// first, and array parents finish once all their children have. Passes
+// repeat until nothing changes so cancellations cascade down a workflow
+// within one cycle.
+func (s *Scheduler) releaseWaitingJobs(ctx context.Context) {
+	for {
+		waiting, err := s.storage.ListJobsByState(ctx, models.JobStateWaiting)
+		if err != nil {
+			utils.Error("Failed to list waiting jobs", zap.Error(err))
+			return
+		}
+		sort.SliceStable(waiting, func(i, j int) bool {
+			return waiting[i].ArrayIndex < waiting[j].ArrayIndex
+		})
+
+		changed := false
+		slots := make(map[string]*arraySlot)
+		for _, job := range waiting {
+			if job.IsArrayParent() {
+				if s.finishArray(ctx, job) {
+					changed = true
+				}
+				continue
+			}
+
+			// Retried jobs wait out their backoff first
+			if job.RetryAt != nil && time.Now().Before(*job.RetryAt) {
+				continue
+			}
+
+			met, reason, failed := s.dependencyStatus(ctx, job)
+			if met && job.ArrayParentID != "" {
+				met, reason = s.admitArrayChild(ctx, slots, job)
+			}
+
+			switch {
+			case failed != "":
+				job.State = models.JobStateCancelled
+				job.CompletedAt = timePtr(time.Now())
+				job.PendingReason = failed
+				changed = true
+
+				utils.Info("Job cancelled, dependency unsatisfiable",
+					zap.String("job_id", job.ID),
+					zap.String("reason", failed))
+
+			case met:
+				job.State = models.JobStatePending
+				if err := s.jobPartition(job).queue.Enqueue(job); err != nil {
+					utils.Error("Failed to enqueue released job",
+						zap.String("job_id", job.ID),
+						zap.Error(err))
+					job.State = models.JobStateWaiting
+					if job.PendingReason == queueFullReason {
+						continue
+					}
+					job.PendingReason = queueFullReason
+					break
+				}
+				job.PendingReason = ""
+				job.RetryAt = nil
+				changed = true
+
+			case reason != job.PendingReason:
+				job.PendingReason = reason
+
+			default:
+				continue
+			}
+
+			if err := s.storage.UpdateJob(ctx, job); err != nil {
+				utils.Error("Failed to update waiting job",
+					zap.String("job_id", job.ID),
+					zap.Error(err))
+			}
+		}
+
+		if !changed {
+			return
+		}
+	}
+}
+
+// SubmitWorkflow submits a DAG of jobs. Steps are named, and a
+// dependency naming another step is rewritten to that step's job ID;
+// any other dependency must name an existing job. The workflow is
+// rejected if it has a cycle, and steps already submitted are cancelled
+// if a later one fails.
+func (s *Scheduler) SubmitWorkflow(ctx context.Context, workflow *models.Workflow) error {
+	if workflow.TenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	if len(workflow.Jobs) == 0 {
+		return fmt.Errorf("workflow has no jobs")
+	}
+
+	steps := make(map[string]*models.Job, len(workflow.Jobs))
+	for _, job := range workflow.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("%w: every workflow step needs a name", utils.ErrInvalidDependency)
+		}
+		if _, ok := steps[job.Name]; ok {
+			return fmt.Errorf("%w: duplicate step %s", utils.ErrInvalidDependency, job.Name)
+		}
+		steps[job.Name] = job
+
+		job.ID = fmt.Sprintf("%s-%s", workflow.ID, job.Name)
+		job.TenantID = workflow.TenantID
+		job.WorkflowID = workflow.ID
+	}
+
+	// Resolve step names and count the in-workflow edges of each step
+	indegree := make(map[string]int, len(workflow.Jobs))
+	dependents := make(map[string][]string)
+	for _, job := range workflow.Jobs {
+		for i := range job.Dependencies {
+			dep := &job.Dependencies[i]
+			if parent, ok := steps[dep.JobID]; ok {
+				dep.JobID = parent.ID
+				indegree[job.Name]++
+				dependents[parent.Name] = append(dependents[parent.Name], job.Name)
+			}
+		}
+		if err := s.validateJob(ctx, job); err != nil {
+			return fmt.Errorf("invalid step %s: %w", job.Name, err)
+		}
+	}
+
+	// Order steps so each is submitted after the steps it depends on
+	var order []*models.Job
+	for _, job := range workflow.Jobs {
+		if indegree[job.Name] == 0 {
+			order = append(order, job)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		for _, name := range dependents[order[i].Name] {
+			indegree[name]--
+			if indegree[name] == 0 {
+				order = append(order, steps[name])
+			}
+		}
+	}
+	if len(order) != len(workflow.Jobs) {
+		return fmt.Errorf("%w: workflow contains a cycle", utils.ErrInvalidDependency)
+	}
+
+	for i, job := range order {
+		if err := s.SubmitJob(ctx, job); err != nil {
+			for _, submitted := range order[:i] {
+				if cancelErr := s.CancelJob(ctx, submitted.ID); cancelErr != nil {
+					utils.Error("Failed to roll back workflow step",
+						zap.String("job_id", submitted.ID),
+						zap.Error(cancelErr))
+				}
+			}
+			return fmt.Errorf("failed to submit step %s: %w", job.Name, err)
+		}
+	}
+
+	utils.Info("Workflow submitted",
+		zap.String("workflow_id", workflow.ID),
+		zap.Int("jobs", len(order)))
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dependentJob(id string, deps ...models.JobDependency) *models.Job {
+	return &models.Job{ID: id, TenantID: "tenant-1", GPUCount: 1, Dependencies: deps}
+}
+
+func TestDependentJobWaitsOutsideQueue(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(8, "node-a"))
+
+	parent := dependentJob("preprocess")
+	require.NoError(t, scheduler.SubmitJob(ctx, parent))
+	child := dependentJob("train", models.JobDependency{JobID: "preprocess"})
+	require.NoError(t, scheduler.SubmitJob(ctx, child))
+
+	assert.Equal(t, models.JobStateWaiting, child.State)
+	assert.Equal(t, models.DependAfterOK, child.Dependencies[0].Condition)
+	assert.Equal(t, 1, scheduler.queue.Size())
+
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateRunning, parent.State)
+	assert.Equal(t, models.JobStateWaiting, child.State)
+	assert.Contains(t, child.PendingReason, "preprocess (afterok)")
+
+	parent.State = models.JobStateCompleted
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateRunning, child.State)
+}
+
+func TestUnsatisfiableDependencyCancelsDownstream(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(8, "node-a"))
+
+	parent := dependentJob("preprocess")
+	require.NoError(t, scheduler.SubmitJob(ctx, parent))
+	train := dependentJob("train", models.JobDependency{JobID: "preprocess", Condition: models.DependAfterOK})
+	cleanup := dependentJob("cleanup", models.JobDependency{JobID: "preprocess", Condition: models.DependAfterNotOK})
+	evaluate := dependentJob("evaluate", models.JobDependency{JobID: "train"})
+	for _, job := range []*models.Job{train, cleanup, evaluate} {
+		require.NoError(t, scheduler.SubmitJob(ctx, job))
+	}
+
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	parent.State = models.JobStateFailed
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	// The failure cascades through train to evaluate in a single cycle
+	assert.Equal(t, models.JobStateCancelled, train.State)
+	assert.Equal(t, models.JobStateCancelled, evaluate.State)
+	assert.Contains(t, evaluate.PendingReason, "dependency train ended cancelled")
+	assert.Equal(t, models.JobStateRunning, cleanup.State)
+
+	// Depending on a job that already failed is rejected outright
+	err := scheduler.SubmitJob(ctx, dependentJob("retry", models.JobDependency{JobID: "preprocess"}))
+	assert.True(t, errors.Is(err, utils.ErrInvalidDependency))
+	err = scheduler.SubmitJob(ctx, dependentJob("orphan", models.JobDependency{JobID: "missing"}))
+	assert.True(t, errors.Is(err, utils.ErrInvalidDependency))
+}
+
+func TestSubmitWorkflow(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-a"))
+
+	workflow := &models.Workflow{
+		ID:       "wf-1",
+		TenantID: "tenant-1",
+		Jobs: []*models.Job{
+			{Name: "evaluate", GPUCount: 1, Dependencies: []models.JobDependency{{JobID: "train"}}},
+			{Name: "train", GPUCount: 1, Dependencies: []models.JobDependency{{JobID: "preprocess"}}},
+			{Name: "preprocess", GPUCount: 1},
+		},
+	}
+	require.NoError(t, scheduler.SubmitWorkflow(ctx, workflow))
+
+	preprocess, err := repo.GetJob(ctx, "wf-1-preprocess")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatePending, preprocess.State)
+	assert.Equal(t, "wf-1", preprocess.WorkflowID)
+
+	evaluate, err := repo.GetJob(ctx, "wf-1-evaluate")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStateWaiting, evaluate.State)
+	assert.Equal(t, "wf-1-train", evaluate.Dependencies[0].JobID)
+}
+
+func TestSubmitWorkflowRejectsCycle(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-a"))
+
+	err := scheduler.SubmitWorkflow(ctx, &models.Workflow{
+		ID:       "wf-1",
+		TenantID: "tenant-1",
+		Jobs: []*models.Job{
+			{Name: "a", GPUCount: 1, Dependencies: []models.JobDependency{{JobID: "b"}}},
+			{Name: "b", GPUCount: 1, Dependencies: []models.JobDependency{{JobID: "a"}}},
+			{Name: "c", GPUCount: 1},
+		},
+	})
+	assert.True(t, errors.Is(err, utils.ErrInvalidDependency))
+	assert.Empty(t, repo.jobs)
+}
@@ -0,0 +1,303 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// elasticJobs returns running elastic jobs other than exclude that match keep
+func (s *Scheduler) elasticJobs(ctx context.Context, exclude string, keep func(*models.Job) bool) []*models.Job {
+	running, err := s.storage.ListJobsByState(ctx, models.JobStateRunning)
+	if err != nil {
+		utils.Error("Failed to list running jobs", zap.Error(err))
+		return nil
+	}
+
+	var jobs []*models.Job
+	for _, job := range running {
+		if job.ID != exclude && job.IsElastic() && keep(job) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+// growElasticJobs hands idle GPUs to running elastic jobs, highest
+// priority first, up to each job's maximum and its tenant's GPU quota
+func (s *Scheduler) growElasticJobs(ctx context.Context) {
+	jobs := s.elasticJobs(ctx, "", func(job *models.Job) bool {
+		return job.GPUCount < job.MaxGPUs
+	})
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].Priority != jobs[j].Priority {
+			return jobs[i].Priority > jobs[j].Priority
+		}
+		return jobs[i].SubmittedAt.Before(jobs[j].SubmittedAt)
+	})
+
+	for _, job := range jobs {
+		s.growJob(ctx, job)
+	}
+}
+
+// growJob adds one allocation to an elastic job, asking for as many of
+// its missing GPUs as a single node can provide
+func (s *Scheduler) growJob(ctx context.Context, job *models.Job) {
+	// Headroom is checked and charged under one lock so no release or
+	// other charge can slip in between
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	tenant, err := s.storage.GetTenant(ctx, job.TenantID)
+	if err != nil {
+		return
+	}
+
+	// Inactive tenants and tenants past their GPU-hour budget do not grow
+	now := time.Now()
+	if !tenant.Active || tenant.BudgetExhausted(now) {
+		return
+	}
+
+	want := job.MaxGPUs - job.GPUCount
+	if headroom := int(float64(tenant.AtTime(now).MaxGPUs) - tenant.GPUsInUse()); headroom < want {
+		want = headroom
+	}
+	if headroom, limited := s.quotaGroupHeadroom(ctx, tenant); limited && int(headroom) < want {
+		want = int(headroom)
+	}
+
+	// Growth shares the deadline of the job's first allocation
+	var remaining time.Duration
+	if hasDeadline(job) {
+		primary := s.primaryAllocation(ctx, job.ID)
+		if primary == nil {
+			return
+		}
+		if remaining = time.Until(primary.Deadline()); remaining <= 0 {
+			return
+		}
+	}
+
+	for count := want; count > 0; count-- {
+		request := &models.AllocationRequest{
+			JobID:         job.ID,
+			TenantID:      job.TenantID,
+			GPUCount:      count,
+			CPUCores:      job.CPUCores * count / job.GPUCount,
+			MemoryMB:      job.MemoryMB * int64(count) / int64(job.GPUCount),
+			Affinity:      job.Affinity,
+			Strategy:      s.placementStrategy(job, tenant),
+			MaxRuntime:    remaining,
+			ReservationID: job.ReservationID,
+			Queue:         job.Queue,
+			Reclaimable:   !tenant.WithinGuarantee(float64(count)),
+		}
+		if job.ExpectedPowerW > 0 {
+			request.ExpectedPowerW = job.ExpectedPowerW * float64(count) / float64(job.GPUCount)
+		}
+
+		result, err := s.allocator.Allocate(ctx, request)
+		if err != nil {
+			continue
+		}
+
+		s.recordResize(ctx, job, "grow", result.NodeID, result.AllocationID)
+		return
+	}
+}
+
+// tryShrink releases GPUs that running elastic jobs hold above their
+// minimum so the pending job can fit, lowest priority first. Only jobs
+// the pending job's queue may preempt are shrunk, and only on a node
+// where the released GPUs and the idle ones together cover the request.
+// It reports whether anything was released.
+func (s *Scheduler) tryShrink(ctx context.Context, job *models.Job) bool {
+	if s.jobPartition(job).config.Preemption == PreemptDisabled {
+		return false
+	}
+	jobs := s.elasticJobs(ctx, job.ID, func(victim *models.Job) bool {
+		return victim.SurplusGPUs() > 0 && s.mayPreempt(ctx, job, victim)
+	})
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].Priority < jobs[j].Priority
+	})
+
+	nodeID, needed := s.shrinkTarget(ctx, job, jobs)
+	released := 0
+	for _, victim := range jobs {
+		if released >= needed {
+			break
+		}
+
+		count := victim.SurplusGPUs()
+		if count > needed-released {
+			count = needed - released
+		}
+		released += s.shrinkJob(ctx, victim, nodeID, count)
+	}
+
+	return released > 0
+}
+
+// shrinkTarget returns the first node in the job's queue where the idle
+// GPUs plus those the elastic jobs could give back cover the job, with
+// the number of GPUs to release there. It returns zero GPUs when no node
+// qualifies, so that no job is shrunk for nothing.
+func (s *Scheduler) shrinkTarget(ctx context.Context, job *models.Job, jobs []*models.Job) (string, int) {
+	nodes, err := s.storage.ListNodes(ctx)
+	if err != nil {
+		utils.Error("Failed to list nodes", zap.Error(err))
+		return "", 0
+	}
+
+	selector := s.jobPartition(job).config.NodeSelector
+	for _, node := range nodes {
+		if !matchesSelector(selector, node.Labels) {
+			continue
+		}
+		gpus, err := s.storage.ListGPUsByNode(ctx, node.ID)
+		if err != nil {
+			continue
+		}
+
+		// A node with enough idle GPUs turned the job down for another
+		// reason that shrinking would not fix
+		needed := job.GPUCount
+		for _, gpu := range gpus {
+			if gpu.IsAvailable() {
+				needed--
+			}
+		}
+		if needed <= 0 {
+			continue
+		}
+
+		surplus := 0
+		for _, victim := range jobs {
+			held := 0
+			for _, alloc := range s.activeAllocations(ctx, victim.ID) {
+				if alloc.NodeID == node.ID {
+					held += len(alloc.GPUIDs)
+				}
+			}
+			if held > victim.SurplusGPUs() {
+				held = victim.SurplusGPUs()
+			}
+			surplus += held
+		}
+		if surplus >= needed {
+			return node.ID, needed
+		}
+	}
+
+	return "", 0
+}
+
+// shrinkJob releases count GPUs from an elastic job, newest allocations
+// first, and returns how many were released. Only allocations on nodeID
+// are shrunk unless it is empty.
+func (s *Scheduler) shrinkJob(ctx context.Context, job *models.Job, nodeID string, count int) int {
+	allocations := s.activeAllocations(ctx, job.ID)
+	sort.SliceStable(allocations, func(i, j int) bool {
+		return allocations[i].AllocatedAt.After(allocations[j].AllocatedAt)
+	})
+
+	released := 0
+	for _, alloc := range allocations {
+		if released >= count {
+			break
+		}
+		if nodeID != "" && alloc.NodeID != nodeID {
+			continue
+		}
+
+		take := len(alloc.GPUIDs)
+		if take > count-released {
+			take = count - released
+		}
+		if err := s.allocator.Shrink(ctx, alloc.ID, take); err != nil {
+			utils.Error("Failed to shrink allocation",
+				zap.String("allocation_id", alloc.ID),
+				zap.Error(err))
+			continue
+		}
+		released += take
+		s.billInterval(ctx, job, alloc.ID, float64(take), time.Now(), models.LedgerRunning)
+
+		s.quotaMu.Lock()
+		s.recordResize(ctx, job, "shrink", alloc.NodeID, alloc.ID)
+		s.quotaMu.Unlock()
+	}
+
+	return released
+}
+
+// recordResize recomputes an elastic job's size from its active
+// allocations, charges the difference to its tenant and records the
+// change. Callers hold quotaMu.
+func (s *Scheduler) recordResize(ctx context.Context, job *models.Job, reason, nodeID, allocationID string) {
+	gpus, cpus, memory := 0, 0, int64(0)
+	for _, alloc := range s.activeAllocations(ctx, job.ID) {
+		gpus += len(alloc.GPUIDs)
+		cpus += alloc.CPUCores
+		memory += alloc.MemoryMB
+	}
+
+	from := job.GPUCount
+	if tenant, err := s.storage.GetTenant(ctx, job.TenantID); err == nil {
+		tenant.UpdateUsage(gpus-job.GPUCount, 0, cpus-job.CPUCores, memory-job.MemoryMB, 0)
+		if err := s.storage.UpdateTenant(ctx, tenant); err != nil {
+			utils.Error("Failed to update tenant usage", zap.String("tenant_id", tenant.ID), zap.Error(err))
+		}
+	}
+
+	job.GPUCount = gpus
+	job.CPUCores = cpus
+	job.MemoryMB = memory
+	if err := s.storage.UpdateJob(ctx, job); err != nil {
+		utils.Error("Failed to update resized job", zap.String("job_id", job.ID), zap.Error(err))
+	}
+
+	utils.Info("Elastic job resized",
+		zap.String("job_id", job.ID),
+		zap.Int("from", from),
+		zap.Int("to", gpus))
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:         models.EventAllocationResized,
+		Reason:       reason,
+		JobID:        job.ID,
+		TenantID:     job.TenantID,
+		NodeID:       nodeID,
+		AllocationID: allocationID,
+		Message:      fmt.Sprintf("Job resized from %d to %d GPUs", from, gpus),
+		Metadata: map[string]string{
+			"from_gpus": strconv.Itoa(from),
+			"to_gpus":   strconv.Itoa(gpus),
+		},
+	})
+}
+
+// activeAllocations returns the job's allocations that still hold resources
+func (s *Scheduler) activeAllocations(ctx context.Context, jobID string) []*models.Allocation {
+	allocations, err := s.storage.GetJobAllocations(ctx, jobID)
+	if err != nil {
+		return nil
+	}
+
+	var active []*models.Allocation
+	for _, alloc := range allocations {
+		if alloc.IsActive() {
+			active = append(active, alloc)
+		}
+	}
+	return active
+}
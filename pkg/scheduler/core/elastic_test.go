@@ -0,0 +1,207 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// elasticSetup runs elastic jobs on an 8 GPU node for a tenant allowed
+// 16 GPUs
+func elasticSetup() []testOption {
+	return []testOption{
+		withNodes(8, "node-a"),
+		withTenantSettings(func(tenant *models.Tenant) { tenant.MaxGPUs = 16 }),
+	}
+}
+
+func TestElasticJobStartsAtMinimumAndGrows(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, elasticSetup()...)
+	node := repo.nodes["node-a"]
+
+	job := &models.Job{ID: "train", TenantID: "tenant-1", MinGPUs: 2, MaxGPUs: 6, CPUCores: 4, MemoryMB: 8000}
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	assert.Equal(t, 2, job.GPUCount)
+
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStateRunning, job.State)
+	assert.Equal(t, 6, job.GPUCount)
+	assert.Equal(t, 12, job.CPUCores)
+	assert.Equal(t, 2, node.AvailableGPUs)
+
+	tenant, _ := repo.GetTenant(ctx, "tenant-1")
+	assert.Equal(t, 6, tenant.CurrentGPUs)
+	assert.Equal(t, 12, tenant.CurrentCPUCores)
+
+	events, _ := repo.ListEvents(ctx, models.EventFilter{Type: models.EventAllocationResized, JobID: "train"})
+	require.Len(t, events, 1)
+	assert.Equal(t, "grow", events[0].Reason)
+	assert.Equal(t, "6", events[0].Metadata["to_gpus"])
+
+	status, err := scheduler.GetJobStatus(ctx, "train")
+	require.NoError(t, err)
+	assert.Len(t, status.AllocatedGPUs, 6)
+}
+
+func TestElasticJobShrinksBeforePreemption(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, elasticSetup()...)
+	node := repo.nodes["node-a"]
+
+	elastic := &models.Job{ID: "train", TenantID: "tenant-1", Priority: 500, MinGPUs: 2, MaxGPUs: 8}
+	require.NoError(t, scheduler.SubmitJob(ctx, elastic))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, 8, elastic.GPUCount)
+
+	// Preemption is disabled, so the pending job only fits once the
+	// elastic job gives back part of its surplus
+	serve := &models.Job{ID: "serve", TenantID: "tenant-1", Priority: 100, GPUCount: 4}
+	require.NoError(t, scheduler.SubmitJob(ctx, serve))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStateRunning, serve.State)
+	assert.Equal(t, models.JobStateRunning, elastic.State)
+	assert.Equal(t, 4, elastic.GPUCount)
+	assert.Equal(t, 0, node.AvailableGPUs)
+
+	tenant, _ := repo.GetTenant(ctx, "tenant-1")
+	assert.Equal(t, 8, tenant.CurrentGPUs)
+
+	events, _ := repo.ListEvents(ctx, models.EventFilter{Type: models.EventAllocationResized, JobID: "train"})
+	require.NotEmpty(t, events)
+	assert.Equal(t, "shrink", events[len(events)-1].Reason)
+
+	// Jobs are never shrunk below their minimum, so a job the surplus
+	// cannot make room for leaves the elastic job alone
+	big := &models.Job{ID: "big", TenantID: "tenant-1", GPUCount: 8}
+	require.NoError(t, scheduler.SubmitJob(ctx, big))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePending, big.State)
+	assert.Equal(t, 4, elastic.GPUCount)
+
+	// Freeing the job releases every remaining allocation exactly once
+	require.Equal(t, 0, node.AvailableGPUs)
+	require.NoError(t, scheduler.freeJobResources(ctx, elastic))
+	assert.Equal(t, 4, node.AvailableGPUs)
+	assert.Equal(t, 64, node.AvailableCPUCores)
+}
+
+func TestShrinkFollowsQueuePreemptionRules(t *testing.T) {
+	ctx := context.Background()
+
+	// Both queues share every node so only the preemption rule decides
+	run := func(t *testing.T, preemption string) (*models.Job, *models.Job) {
+		scheduler, _ := newTestScheduler(t, queueSetup(
+			utils.QueueConfig{Name: "batch"},
+			utils.QueueConfig{Name: "urgent", Preemption: preemption},
+		)...)
+		elastic := &models.Job{ID: "train", TenantID: "tenant-2", Priority: 10, MinGPUs: 2, MaxGPUs: 8}
+		require.NoError(t, scheduler.SubmitJob(ctx, elastic))
+		// One cycle grows the job on each node
+		for cycle := 0; cycle < 2; cycle++ {
+			require.NoError(t, scheduler.schedulingCycle(ctx))
+		}
+		require.Equal(t, 8, elastic.GPUCount)
+
+		high := &models.Job{ID: "high", TenantID: "tenant-1", GPUCount: 4, Priority: 500, Queue: "urgent"}
+		require.NoError(t, scheduler.SubmitJob(ctx, high))
+		require.NoError(t, scheduler.schedulingCycle(ctx))
+		return elastic, high
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		elastic, high := run(t, PreemptEnabled)
+		assert.Equal(t, models.JobStateRunning, high.State)
+		assert.Equal(t, 4, elastic.GPUCount)
+	})
+
+	for _, preemption := range []string{PreemptSameQueue, PreemptDisabled} {
+		t.Run(preemption, func(t *testing.T) {
+			elastic, high := run(t, preemption)
+			assert.Equal(t, models.JobStatePending, high.State)
+			assert.Equal(t, models.JobStateRunning, elastic.State)
+			assert.Equal(t, 8, elastic.GPUCount)
+		})
+	}
+}
+
+func TestShrinkOnlyWhereRequestFits(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t,
+		withNodes(4, "node-a", "node-b"),
+		withTenantSettings(func(tenant *models.Tenant) { tenant.MaxGPUs = 16 }),
+	)
+
+	// Each node holds one elastic job with a single GPU to spare
+	for _, id := range []string{"train-a", "train-b"} {
+		elastic := &models.Job{ID: id, TenantID: "tenant-1", Priority: 10, MinGPUs: 3, MaxGPUs: 4}
+		require.NoError(t, scheduler.SubmitJob(ctx, elastic))
+		require.NoError(t, scheduler.schedulingCycle(ctx))
+		require.Equal(t, 4, elastic.GPUCount)
+	}
+	require.Equal(t, 0, repo.nodes["node-a"].AvailableGPUs)
+	require.Equal(t, 0, repo.nodes["node-b"].AvailableGPUs)
+
+	// Together they could give back two GPUs, but not on the same node
+	serve := &models.Job{ID: "serve", TenantID: "tenant-1", Priority: 100, GPUCount: 2}
+	require.NoError(t, scheduler.SubmitJob(ctx, serve))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePending, serve.State)
+	assert.Len(t, jobGPUs(scheduler, "train-a"), 4)
+	assert.Len(t, jobGPUs(scheduler, "train-b"), 4)
+	events, _ := repo.ListEvents(ctx, models.EventFilter{Type: models.EventAllocationResized})
+	for _, event := range events {
+		assert.NotEqual(t, "shrink", event.Reason)
+	}
+
+}
+
+func TestShrinkReleasesShareOfAllocation(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	node := repo.addNode("node-a", 4)
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{})
+
+	result, err := allocator.Allocate(ctx, &models.AllocationRequest{JobID: "job-1", GPUCount: 4, CPUCores: 16, MemoryMB: 64000})
+	require.NoError(t, err)
+
+	require.NoError(t, allocator.Shrink(ctx, result.AllocationID, 1))
+
+	allocation, _ := repo.GetAllocation(ctx, result.AllocationID)
+	assert.Len(t, allocation.GPUIDs, 3)
+	assert.Equal(t, 12, allocation.CPUCores)
+	assert.Equal(t, int64(48000), allocation.MemoryMB)
+	assert.Equal(t, 1, node.AvailableGPUs)
+	assert.Equal(t, 52, node.AvailableCPUCores)
+
+	gpu, _ := repo.GetGPU(ctx, result.GPUIDs[3])
+	assert.True(t, gpu.IsAvailable())
+
+	require.NoError(t, allocator.Shrink(ctx, result.AllocationID, 3))
+	assert.False(t, allocation.IsActive())
+	assert.Equal(t, 4, node.AvailableGPUs)
+}
+
+func TestValidateElasticJob(t *testing.T) {
+	scheduler := NewScheduler(&utils.SchedulerConfig{MaxQueueSize: 10}, newFakeRepository())
+	ctx := context.Background()
+
+	assert.Error(t, scheduler.validateJob(ctx, &models.Job{TenantID: "t", MinGPUs: 4, MaxGPUs: 2}))
+	assert.Error(t, scheduler.validateJob(ctx, &models.Job{TenantID: "t", MaxGPUs: 4}))
+	assert.Error(t, scheduler.validateJob(ctx, &models.Job{
+		TenantID: "t", MinGPUs: 1, MaxGPUs: 2,
+		GPUSlice: &models.SliceRequest{MemoryMB: 1024},
+	}))
+
+	job := &models.Job{TenantID: "t", GPUCount: 8, MinGPUs: 4, MaxGPUs: 16}
+	require.NoError(t, scheduler.validateJob(ctx, job))
+	assert.Equal(t, 4, job.GPUCount)
+}
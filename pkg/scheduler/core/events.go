@@ -0,0 +1,33 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/storage"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var eventSequence uint64
+
+// recordEvent persists a scheduling event. Failures are logged rather
+// than returned so that bookkeeping never blocks a scheduling decision.
+func recordEvent(ctx context.Context, repo storage.Repository, event *models.Event) {
+	event.ID = generateEventID()
+	event.CreatedAt = time.Now()
+
+	if err := repo.CreateEvent(ctx, event); err != nil {
+		utils.Error("Failed to record event",
+			zap.String("type", string(event.Type)),
+			zap.String("job_id", event.JobID),
+			zap.Error(err))
+	}
+}
+
+func generateEventID() string {
+	return fmt.Sprintf("evt-%d-%d", time.Now().UnixNano(), atomic.AddUint64(&eventSequence, 1))
+}
@@ -0,0 +1,606 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository is an in-memory storage.Repository for scheduler tests
+type fakeRepository struct {
+	mu           sync.Mutex
+	jobs         map[string]*models.Job
+	tenants      map[string]*models.Tenant
+	quotaGroups  map[string]*models.QuotaGroup
+	gpus         map[string]*models.GPU
+	nodes        map[string]*models.Node
+	allocations  map[string]*models.Allocation
+	events       []*models.Event
+	reservations map[string]*models.Reservation
+	cronJobs     map[string]*models.CronJob
+	cronRuns     []*models.CronRun
+	ledger       []*models.LedgerEntry
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		jobs:         make(map[string]*models.Job),
+		tenants:      make(map[string]*models.Tenant),
+		quotaGroups:  make(map[string]*models.QuotaGroup),
+		gpus:         make(map[string]*models.GPU),
+		nodes:        make(map[string]*models.Node),
+		allocations:  make(map[string]*models.Allocation),
+		reservations: make(map[string]*models.Reservation),
+		cronJobs:     make(map[string]*models.CronJob),
+	}
+}
+
+// addNode registers a schedulable node with count healthy GPUs
+func (r *fakeRepository) addNode(id string, gpuCount int) *models.Node {
+	node := &models.Node{
+		ID:                id,
+		Name:              id,
+		TotalGPUs:         gpuCount,
+		AvailableGPUs:     gpuCount,
+		TotalCPUCores:     64,
+		AvailableCPUCores: 64,
+		TotalMemoryMB:     512000,
+		AvailableMemoryMB: 512000,
+		Online:            true,
+		Schedulable:       true,
+		Labels:            map[string]string{},
+	}
+	r.nodes[id] = node
+
+	for i := 0; i < gpuCount; i++ {
+		gpu := &models.GPU{
+			ID:            fmt.Sprintf("%s-gpu-%d", id, i),
+			NodeID:        id,
+			Index:         i,
+			Model:         models.GPUA100,
+			MemoryTotalMB: 81920,
+			MemoryFreeMB:  81920,
+			Health:        models.HealthHealthy,
+		}
+		r.gpus[gpu.ID] = gpu
+	}
+	return node
+}
+
+// testSetup holds what newTestScheduler builds a scheduler from
+type testSetup struct {
+	repo    *fakeRepository
+	config  *utils.SchedulerConfig
+	tenants []*models.Tenant
+}
+
+// testOption customises a scheduler built by newTestScheduler
+type testOption func(*testSetup)
+
+// newTestScheduler builds a scheduler over a fake repository. Without
+// options it has no nodes and one active tenant, tenant-1, with room for
+// 8 GPUs and 10 concurrent jobs.
+func newTestScheduler(t *testing.T, options ...testOption) (*Scheduler, *fakeRepository) {
+	t.Helper()
+	setup := &testSetup{
+		repo:    newFakeRepository(),
+		config:  &utils.SchedulerConfig{MaxQueueSize: 10},
+		tenants: []*models.Tenant{testTenant("tenant-1")},
+	}
+	for _, option := range options {
+		option(setup)
+	}
+
+	for _, tenant := range setup.tenants {
+		require.NoError(t, setup.repo.CreateTenant(context.Background(), tenant))
+	}
+	return NewScheduler(setup.config, setup.repo), setup.repo
+}
+
+// testTenant returns an active tenant with room for 8 GPUs
+func testTenant(id string) *models.Tenant {
+	return &models.Tenant{
+		ID:                id,
+		Name:              id,
+		MaxGPUs:           8,
+		MaxCPUCores:       64,
+		MaxMemoryMB:       512000,
+		MaxConcurrentJobs: 10,
+		Active:            true,
+	}
+}
+
+// withNodes adds nodes with gpuCount GPUs each
+func withNodes(gpuCount int, ids ...string) testOption {
+	return func(setup *testSetup) {
+		for _, id := range ids {
+			setup.repo.addNode(id, gpuCount)
+		}
+	}
+}
+
+// withTenants replaces the default tenant with tenants of the given IDs
+func withTenants(ids ...string) testOption {
+	return func(setup *testSetup) {
+		setup.tenants = nil
+		for _, id := range ids {
+			setup.tenants = append(setup.tenants, testTenant(id))
+		}
+	}
+}
+
+// withTenantSettings applies update to every tenant added so far
+func withTenantSettings(update func(*models.Tenant)) testOption {
+	return func(setup *testSetup) {
+		for _, tenant := range setup.tenants {
+			update(tenant)
+		}
+	}
+}
+
+// withConfig applies update to the scheduler configuration
+func withConfig(update func(*utils.SchedulerConfig)) testOption {
+	return func(setup *testSetup) {
+		update(setup.config)
+	}
+}
+
+// withPreemption enables preemption and lets the jobs of every tenant
+// added so far be preempted
+func withPreemption() testOption {
+	return func(setup *testSetup) {
+		setup.config.EnablePreemption = true
+		for _, tenant := range setup.tenants {
+			tenant.AllowPreemption = true
+		}
+	}
+}
+
+func (r *fakeRepository) CreateJob(ctx context.Context, job *models.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = job
+	return nil
+}
+
+func (r *fakeRepository) GetJob(ctx context.Context, jobID string) (*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job, ok := r.jobs[jobID]; ok {
+		return job, nil
+	}
+	return nil, utils.ErrJobNotFound
+}
+
+func (r *fakeRepository) UpdateJob(ctx context.Context, job *models.Job) error {
+	return r.CreateJob(ctx, job)
+}
+
+func (r *fakeRepository) DeleteJob(ctx context.Context, jobID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.jobs, jobID)
+	return nil
+}
+
+func (r *fakeRepository) ListJobs(ctx context.Context, limit, offset int) ([]*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var jobs []*models.Job
+	for _, job := range r.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (r *fakeRepository) ListJobsByTenant(ctx context.Context, tenantID string) ([]*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var jobs []*models.Job
+	for _, job := range r.jobs {
+		if job.TenantID == tenantID {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (r *fakeRepository) ListJobsByState(ctx context.Context, state models.JobState) ([]*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var jobs []*models.Job
+	for _, job := range r.jobs {
+		if job.State == state {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (r *fakeRepository) ListArrayJobs(ctx context.Context, parentID string) ([]*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var jobs []*models.Job
+	for _, job := range r.jobs {
+		if job.ArrayParentID == parentID {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ArrayIndex < jobs[j].ArrayIndex })
+	return jobs, nil
+}
+
+func (r *fakeRepository) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tenants[tenant.ID] = tenant
+	return nil
+}
+
+func (r *fakeRepository) GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tenant, ok := r.tenants[tenantID]; ok {
+		return tenant, nil
+	}
+	return nil, utils.ErrTenantNotFound
+}
+
+func (r *fakeRepository) UpdateTenant(ctx context.Context, tenant *models.Tenant) error {
+	return r.CreateTenant(ctx, tenant)
+}
+
+func (r *fakeRepository) DeleteTenant(ctx context.Context, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tenants, tenantID)
+	return nil
+}
+
+func (r *fakeRepository) ListTenants(ctx context.Context) ([]*models.Tenant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tenants []*models.Tenant
+	for _, tenant := range r.tenants {
+		tenants = append(tenants, tenant)
+	}
+	return tenants, nil
+}
+
+func (r *fakeRepository) CreateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.quotaGroups[group.ID] = group
+	return nil
+}
+
+func (r *fakeRepository) GetQuotaGroup(ctx context.Context, groupID string) (*models.QuotaGroup, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if group, ok := r.quotaGroups[groupID]; ok {
+		return group, nil
+	}
+	return nil, utils.ErrQuotaGroupNotFound
+}
+
+func (r *fakeRepository) UpdateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error {
+	return r.CreateQuotaGroup(ctx, group)
+}
+
+func (r *fakeRepository) DeleteQuotaGroup(ctx context.Context, groupID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.quotaGroups, groupID)
+	return nil
+}
+
+func (r *fakeRepository) ListQuotaGroups(ctx context.Context) ([]*models.QuotaGroup, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var groups []*models.QuotaGroup
+	for _, group := range r.quotaGroups {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	return groups, nil
+}
+
+func (r *fakeRepository) CreateGPU(ctx context.Context, gpu *models.GPU) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gpus[gpu.ID] = gpu
+	return nil
+}
+
+func (r *fakeRepository) GetGPU(ctx context.Context, gpuID string) (*models.GPU, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if gpu, ok := r.gpus[gpuID]; ok {
+		return gpu, nil
+	}
+	return nil, utils.ErrGPUNotFound
+}
+
+func (r *fakeRepository) UpdateGPU(ctx context.Context, gpu *models.GPU) error {
+	return r.CreateGPU(ctx, gpu)
+}
+
+func (r *fakeRepository) DeleteGPU(ctx context.Context, gpuID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.gpus, gpuID)
+	return nil
+}
+
+func (r *fakeRepository) ListGPUs(ctx context.Context) ([]*models.GPU, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var gpus []*models.GPU
+	for _, gpu := range r.gpus {
+		gpus = append(gpus, gpu)
+	}
+	return gpus, nil
+}
+
+func (r *fakeRepository) ListGPUsByNode(ctx context.Context, nodeID string) ([]*models.GPU, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var gpus []*models.GPU
+	for _, gpu := range r.gpus {
+		if gpu.NodeID == nodeID {
+			gpus = append(gpus, gpu)
+		}
+	}
+	sort.Slice(gpus, func(i, j int) bool { return gpus[i].Index < gpus[j].Index })
+	return gpus, nil
+}
+
+func (r *fakeRepository) ListAvailableGPUs(ctx context.Context) ([]*models.GPU, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var gpus []*models.GPU
+	for _, gpu := range r.gpus {
+		if !gpu.Allocated && gpu.Health == models.HealthHealthy {
+			gpus = append(gpus, gpu)
+		}
+	}
+	return gpus, nil
+}
+
+func (r *fakeRepository) CreateNode(ctx context.Context, node *models.Node) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodes[node.ID] = node
+	return nil
+}
+
+func (r *fakeRepository) GetNode(ctx context.Context, nodeID string) (*models.Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if node, ok := r.nodes[nodeID]; ok {
+		return node, nil
+	}
+	return nil, utils.ErrNodeNotFound
+}
+
+func (r *fakeRepository) UpdateNode(ctx context.Context, node *models.Node) error {
+	return r.CreateNode(ctx, node)
+}
+
+func (r *fakeRepository) DeleteNode(ctx context.Context, nodeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.nodes, nodeID)
+	return nil
+}
+
+func (r *fakeRepository) ListNodes(ctx context.Context) ([]*models.Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var nodes []*models.Node
+	for _, node := range r.nodes {
+		if node.Online {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
+}
+
+func (r *fakeRepository) CreateAllocation(ctx context.Context, allocation *models.Allocation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.allocations[allocation.ID] = allocation
+	return nil
+}
+
+func (r *fakeRepository) GetAllocation(ctx context.Context, allocationID string) (*models.Allocation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if alloc, ok := r.allocations[allocationID]; ok {
+		return alloc, nil
+	}
+	return nil, utils.ErrAllocationNotFound
+}
+
+func (r *fakeRepository) UpdateAllocation(ctx context.Context, allocation *models.Allocation) error {
+	return r.CreateAllocation(ctx, allocation)
+}
+
+func (r *fakeRepository) DeleteAllocation(ctx context.Context, allocationID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.allocations, allocationID)
+	return nil
+}
+
+func (r *fakeRepository) GetJobAllocations(ctx context.Context, jobID string) ([]*models.Allocation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var allocations []*models.Allocation
+	for _, alloc := range r.allocations {
+		if alloc.JobID == jobID {
+			allocations = append(allocations, alloc)
+		}
+	}
+	return allocations, nil
+}
+
+func (r *fakeRepository) ListActiveAllocations(ctx context.Context) ([]*models.Allocation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var allocations []*models.Allocation
+	for _, alloc := range r.allocations {
+		if alloc.State == models.AllocationActive {
+			allocations = append(allocations, alloc)
+		}
+	}
+	return allocations, nil
+}
+
+func (r *fakeRepository) CreateReservation(ctx context.Context, reservation *models.Reservation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reservations[reservation.ID] = reservation
+	return nil
+}
+
+func (r *fakeRepository) GetReservation(ctx context.Context, reservationID string) (*models.Reservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if reservation, ok := r.reservations[reservationID]; ok {
+		return reservation, nil
+	}
+	return nil, utils.ErrReservationNotFound
+}
+
+func (r *fakeRepository) DeleteReservation(ctx context.Context, reservationID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.reservations, reservationID)
+	return nil
+}
+
+func (r *fakeRepository) ListReservations(ctx context.Context) ([]*models.Reservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var reservations []*models.Reservation
+	for _, reservation := range r.reservations {
+		reservations = append(reservations, reservation)
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].StartTime.Before(reservations[j].StartTime)
+	})
+	return reservations, nil
+}
+
+func (r *fakeRepository) CreateCronJob(ctx context.Context, cronJob *models.CronJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cronJobs[cronJob.ID] = cronJob
+	return nil
+}
+
+func (r *fakeRepository) GetCronJob(ctx context.Context, cronJobID string) (*models.CronJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cronJob, ok := r.cronJobs[cronJobID]; ok {
+		return cronJob, nil
+	}
+	return nil, utils.ErrCronJobNotFound
+}
+
+func (r *fakeRepository) UpdateCronJob(ctx context.Context, cronJob *models.CronJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cronJobs[cronJob.ID] = cronJob
+	return nil
+}
+
+func (r *fakeRepository) DeleteCronJob(ctx context.Context, cronJobID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cronJobs, cronJobID)
+	return nil
+}
+
+func (r *fakeRepository) ListCronJobs(ctx context.Context) ([]*models.CronJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var cronJobs []*models.CronJob
+	for _, cronJob := range r.cronJobs {
+		cronJobs = append(cronJobs, cronJob)
+	}
+	sort.Slice(cronJobs, func(i, j int) bool { return cronJobs[i].ID < cronJobs[j].ID })
+	return cronJobs, nil
+}
+
+func (r *fakeRepository) CreateCronRun(ctx context.Context, run *models.CronRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cronRuns = append(r.cronRuns, run)
+	return nil
+}
+
+func (r *fakeRepository) ListCronRuns(ctx context.Context, cronJobID string, limit int) ([]*models.CronRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var runs []*models.CronRun
+	for i := len(r.cronRuns) - 1; i >= 0; i-- {
+		if r.cronRuns[i].CronJobID == cronJobID {
+			runs = append(runs, r.cronRuns[i])
+		}
+	}
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func (r *fakeRepository) CreateEvent(ctx context.Context, event *models.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *fakeRepository) ListEvents(ctx context.Context, filter models.EventFilter) ([]*models.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []*models.Event
+	for _, event := range r.events {
+		if filter.Matches(event) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *fakeRepository) CreateLedgerEntry(ctx context.Context, entry *models.LedgerEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ledger = append(r.ledger, entry)
+	return nil
+}
+
+func (r *fakeRepository) ListLedgerEntries(ctx context.Context, tenantID string, from, to time.Time) ([]*models.LedgerEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []*models.LedgerEntry
+	for _, entry := range r.ledger {
+		if entry.TenantID == tenantID && !entry.EndedAt.Before(from) && entry.EndedAt.Before(to) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *fakeRepository) Ping(ctx context.Context) error { return nil }
+func (r *fakeRepository) Close() error                   { return nil }
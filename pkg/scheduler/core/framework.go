@@ -0,0 +1,468 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/storage"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// Built-in plugin names
+const (
+	PluginNodeResources  = "NodeResources"
+	PluginGangScheduling = "GangScheduling"
+	PluginJobAffinity    = "JobAffinity"
+	PluginThermal        = "Thermal"
+	PluginPowerBudget    = "PowerBudget"
+	PluginPlacement      = "Placement"
+	PluginReservation    = "Reservation"
+	PluginQueue          = "Queue"
+)
+
+// Plugin is the base interface of every scheduling plugin
+type Plugin interface {
+	Name() string
+}
+
+// PreFilterPlugin runs once per placement before any node is filtered.
+// Filter plugins that implement it get the chance to precompute state.
+type PreFilterPlugin interface {
+	Plugin
+	PreFilter(ctx context.Context, state *CycleState, request *models.AllocationRequest) *Status
+}
+
+// FilterPlugin prunes a candidate node, or the GPUs considered on it
+type FilterPlugin interface {
+	Plugin
+	Filter(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status
+}
+
+// ScorePlugin ranks a feasible node; higher scores are preferred
+type ScorePlugin interface {
+	Plugin
+	Score(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) float64
+}
+
+// ReservePlugin runs once a node is chosen, before the allocation is
+// persisted. Unreserve is called if a later step fails.
+type ReservePlugin interface {
+	Plugin
+	Reserve(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) error
+	Unreserve(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo)
+}
+
+// PermitPlugin approves or rejects the chosen placement right before the
+// allocation is persisted
+type PermitPlugin interface {
+	Plugin
+	Permit(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status
+}
+
+// PluginFactory builds a plugin bound to an allocator
+type PluginFactory func(a *Allocator) (Plugin, error)
+
+// Status explains why a plugin rejected a node or a placement.
+// A nil status means success.
+type Status struct {
+	// Unschedulable marks constraint violations that freeing resources
+	// cannot resolve; the job stays pending without blocking the queue
+	Unschedulable bool
+	Reason        string
+}
+
+// NewInsufficientStatus rejects a node for lack of resources
+func NewInsufficientStatus(format string, args ...interface{}) *Status {
+	return &Status{Reason: fmt.Sprintf(format, args...)}
+}
+
+// NewUnschedulableStatus rejects a node for a placement constraint
+func NewUnschedulableStatus(format string, args ...interface{}) *Status {
+	return &Status{Unschedulable: true, Reason: fmt.Sprintf(format, args...)}
+}
+
+// CycleState carries data between plugins during a single placement
+type CycleState struct {
+	data map[string]interface{}
+}
+
+// NewCycleState creates an empty cycle state
+func NewCycleState() *CycleState {
+	return &CycleState{data: make(map[string]interface{})}
+}
+
+// Read returns the value stored under key
+func (c *CycleState) Read(key string) (interface{}, bool) {
+	value, ok := c.data[key]
+	return value, ok
+}
+
+// Write stores a value under key
+func (c *CycleState) Write(key string, value interface{}) {
+	c.data[key] = value
+}
+
+// NodeInfo is a candidate node and the GPUs considered on it
+type NodeInfo struct {
+	Node *models.Node
+	// GPUs holds every GPU on the node
+	GPUs []*models.GPU
+	// Available holds the schedulable GPUs in preference order
+	Available []*models.GPU
+	// Cooling holds idle GPUs held back to cool down
+	Cooling []*models.GPU
+}
+
+// Selection returns the GPUs a request of count GPUs would take on the
+// node, or nil if too few are available
+func (n *NodeInfo) Selection(count int) []*models.GPU {
+	if len(n.Available) < count {
+		return nil
+	}
+	return n.Available[:count]
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]PluginFactory{
+		PluginNodeResources:  newNodeResourcesPlugin,
+		PluginGangScheduling: newGangSchedulingPlugin,
+		PluginJobAffinity:    newJobAffinityPlugin,
+		PluginThermal:        newThermalPlugin,
+		PluginPowerBudget:    newPowerBudgetPlugin,
+		PluginPlacement:      newPlacementPlugin,
+		PluginReservation:    newReservationPlugin,
+		PluginQueue:          newQueuePlugin,
+	}
+)
+
+// RegisterPlugin makes a custom plugin available to the scheduler
+// configuration. It must be called before the allocator is created.
+func RegisterPlugin(name string, factory PluginFactory) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		return fmt.Errorf("plugin %s already registered", name)
+	}
+	registry[name] = factory
+	return nil
+}
+
+// DefaultPlugins returns the plugin configuration used when none is set
+func DefaultPlugins() utils.PluginsConfig {
+	return utils.PluginsConfig{
+		Filter: []string{
+			PluginQueue,
+			PluginNodeResources,
+			PluginGangScheduling,
+			PluginJobAffinity,
+			PluginThermal,
+			PluginReservation,
+			PluginPowerBudget,
+		},
+		Score: []utils.ScorePluginConfig{
+			{Name: PluginPlacement, Weight: 1},
+			{Name: PluginThermal, Weight: 1},
+		},
+		Reserve: []string{PluginThermal},
+		Permit:  []string{},
+	}
+}
+
+type weightedScorePlugin struct {
+	plugin ScorePlugin
+	weight float64
+}
+
+// framework runs the configured plugins at each extension point
+type framework struct {
+	preFilters []PreFilterPlugin
+	filters    []FilterPlugin
+	scores     []weightedScorePlugin
+	reserves   []ReservePlugin
+	permits    []PermitPlugin
+}
+
+// newFramework instantiates the plugins enabled in the configuration.
+// Unset extension points fall back to the defaults.
+func newFramework(a *Allocator, config utils.PluginsConfig) (*framework, error) {
+	defaults := DefaultPlugins()
+	if config.Filter == nil {
+		config.Filter = defaults.Filter
+	}
+	if config.Score == nil {
+		config.Score = defaults.Score
+	}
+	if config.Reserve == nil {
+		config.Reserve = defaults.Reserve
+	}
+	if config.Permit == nil {
+		config.Permit = defaults.Permit
+	}
+
+	// Plugins enabled at several extension points share one instance
+	instances := make(map[string]Plugin)
+	instance := func(name string) (Plugin, error) {
+		if plugin, ok := instances[name]; ok {
+			return plugin, nil
+		}
+
+		registryMu.RLock()
+		factory, ok := registry[name]
+		registryMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown scheduling plugin %s", name)
+		}
+
+		plugin, err := factory(a)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create plugin %s: %w", name, err)
+		}
+		instances[name] = plugin
+		return plugin, nil
+	}
+
+	f := &framework{}
+
+	for _, name := range config.Filter {
+		plugin, err := instance(name)
+		if err != nil {
+			return nil, err
+		}
+		filter, ok := plugin.(FilterPlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s does not implement filter", name)
+		}
+		f.filters = append(f.filters, filter)
+		if preFilter, ok := plugin.(PreFilterPlugin); ok {
+			f.preFilters = append(f.preFilters, preFilter)
+		}
+	}
+
+	for _, cfg := range config.Score {
+		plugin, err := instance(cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		score, ok := plugin.(ScorePlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s does not implement score", cfg.Name)
+		}
+		weight := cfg.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		f.scores = append(f.scores, weightedScorePlugin{plugin: score, weight: weight})
+	}
+
+	for _, name := range config.Reserve {
+		plugin, err := instance(name)
+		if err != nil {
+			return nil, err
+		}
+		reserve, ok := plugin.(ReservePlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s does not implement reserve", name)
+		}
+		f.reserves = append(f.reserves, reserve)
+	}
+
+	for _, name := range config.Permit {
+		plugin, err := instance(name)
+		if err != nil {
+			return nil, err
+		}
+		permit, ok := plugin.(PermitPlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s does not implement permit", name)
+		}
+		f.permits = append(f.permits, permit)
+	}
+
+	return f, nil
+}
+
+// schedule runs the plugin chain and persists the chosen placement
+func (f *framework) schedule(ctx context.Context, a *Allocator, request *models.AllocationRequest) (*models.AllocationResult, error) {
+	state := NewCycleState()
+
+	for _, plugin := range f.preFilters {
+		if status := plugin.PreFilter(ctx, state, request); status != nil {
+			return f.noFit(request, []*Status{status})
+		}
+	}
+
+	nodes, err := a.storage.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var best, fallback *NodeInfo
+	var bestScore, fallbackScore float64
+	var rejections []*Status
+
+	for _, node := range nodes {
+		info, status := f.filter(ctx, a, state, request, node)
+		if status != nil {
+			rejections = append(rejections, status)
+			continue
+		}
+
+		// Ties keep the earlier node. Nodes the job asked to avoid are
+		// only used when no other node fits.
+		score := f.score(ctx, state, request, info)
+		if request.Avoids(node.ID) {
+			if fallback == nil || score > fallbackScore {
+				fallback = info
+				fallbackScore = score
+			}
+			continue
+		}
+		if best == nil || score > bestScore {
+			best = info
+			bestScore = score
+		}
+	}
+
+	if best == nil {
+		best = fallback
+	}
+	if best == nil {
+		return f.noFit(request, rejections)
+	}
+
+	return f.bind(ctx, a, state, request, best)
+}
+
+// filter runs every filter plugin against a node
+func (f *framework) filter(ctx context.Context, a *Allocator, state *CycleState, request *models.AllocationRequest, node *models.Node) (*NodeInfo, *Status) {
+	gpus, err := a.storage.ListGPUsByNode(ctx, node.ID)
+	if err != nil {
+		return nil, NewInsufficientStatus("failed to list GPUs on node %s", node.ID)
+	}
+
+	info := &NodeInfo{Node: node, GPUs: gpus}
+	for _, gpu := range gpus {
+		if candidateGPU(request, gpu) {
+			info.Available = append(info.Available, gpu)
+		}
+	}
+	if request.IsFractional() {
+		packSlices(info.Available)
+	}
+
+	for _, plugin := range f.filters {
+		if status := plugin.Filter(ctx, state, request, info); status != nil {
+			return nil, status
+		}
+	}
+
+	if len(info.Available) < request.GPUCount {
+		return nil, NewInsufficientStatus("node %s has %d of %d GPUs available",
+			node.ID, len(info.Available), request.GPUCount)
+	}
+
+	return info, nil
+}
+
+// score sums the weighted scores of a feasible node
+func (f *framework) score(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) float64 {
+	total := 0.0
+	for _, scorer := range f.scores {
+		total += scorer.weight * scorer.plugin.Score(ctx, state, request, info)
+	}
+	return total
+}
+
+// bind runs the reserve and permit hooks around createAllocation
+func (f *framework) bind(ctx context.Context, a *Allocator, state *CycleState, request *models.AllocationRequest, info *NodeInfo) (*models.AllocationResult, error) {
+	reserved := 0
+	unreserve := func() {
+		for i := reserved - 1; i >= 0; i-- {
+			f.reserves[i].Unreserve(ctx, state, request, info)
+		}
+	}
+
+	for _, plugin := range f.reserves {
+		if err := plugin.Reserve(ctx, state, request, info); err != nil {
+			unreserve()
+			return nil, fmt.Errorf("plugin %s failed to reserve: %w", plugin.Name(), err)
+		}
+		reserved++
+	}
+
+	for _, plugin := range f.permits {
+		if status := plugin.Permit(ctx, state, request, info); status != nil {
+			utils.Debug("Placement rejected by permit plugin",
+				zap.String("plugin", plugin.Name()),
+				zap.String("job_id", request.JobID),
+				zap.String("reason", status.Reason))
+			unreserve()
+			return f.noFit(request, []*Status{status})
+		}
+	}
+
+	result, err := a.createAllocation(ctx, request, info.Node, info.Selection(request.GPUCount))
+	if err != nil {
+		unreserve()
+		return nil, err
+	}
+	return result, nil
+}
+
+// noFit builds the result returned when no node accepts the request.
+// The request is unschedulable only when every node violates a
+// constraint; if any node just lacks resources, freeing them may let the
+// request fit, so the resource shortfall is returned instead.
+func (f *framework) noFit(request *models.AllocationRequest, rejections []*Status) (*models.AllocationResult, error) {
+	var reasons []string
+	seen := make(map[string]bool)
+	for _, status := range rejections {
+		if !status.Unschedulable {
+			reasons = nil
+			break
+		}
+		if !seen[status.Reason] {
+			seen[status.Reason] = true
+			reasons = append(reasons, status.Reason)
+		}
+	}
+
+	if len(reasons) > 0 {
+		err := &utils.UnschedulableError{
+			JobID:  request.JobID,
+			Reason: strings.Join(reasons, "; "),
+		}
+		return &models.AllocationResult{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	if request.GangScheduling {
+		return &models.AllocationResult{
+			Success: false,
+			Message: "gang scheduling failed - insufficient resources on single node",
+		}, utils.ErrGangSchedulingFailed
+	}
+
+	return &models.AllocationResult{
+		Success: false,
+		Message: "no suitable node found",
+	}, utils.ErrInsufficientResources
+}
+
+// Storage exposes the repository to plugins
+func (a *Allocator) Storage() storage.Repository {
+	return a.storage
+}
+
+// Config exposes the scheduler configuration to plugins
+func (a *Allocator) Config() *utils.SchedulerConfig {
+	return a.config
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zonePlugin keeps jobs labelled with a zone on nodes in that zone
+type zonePlugin struct{}
+
+func (p *zonePlugin) Name() string { return "TestZone" }
+
+func (p *zonePlugin) Filter(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status {
+	if info.Node.Labels["zone"] != "us-east" {
+		return NewUnschedulableStatus("node outside zone us-east")
+	}
+	return nil
+}
+
+// mostGPUsPlugin prefers the node with the most idle GPUs
+type mostGPUsPlugin struct{}
+
+func (p *mostGPUsPlugin) Name() string { return "TestMostGPUs" }
+
+func (p *mostGPUsPlugin) Score(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) float64 {
+	return float64(len(info.Available))
+}
+
+// vetoPlugin reserves every placement and then rejects it at permit
+type vetoPlugin struct {
+	reserved   int
+	unreserved int
+}
+
+func (p *vetoPlugin) Name() string { return "TestVeto" }
+
+func (p *vetoPlugin) Reserve(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) error {
+	p.reserved++
+	return nil
+}
+
+func (p *vetoPlugin) Unreserve(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) {
+	p.unreserved++
+}
+
+func (p *vetoPlugin) Permit(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status {
+	return NewUnschedulableStatus("placement vetoed")
+}
+
+var veto = &vetoPlugin{}
+
+func init() {
+	RegisterPlugin("TestZone", func(a *Allocator) (Plugin, error) { return &zonePlugin{}, nil })
+	RegisterPlugin("TestMostGPUs", func(a *Allocator) (Plugin, error) { return &mostGPUsPlugin{}, nil })
+	RegisterPlugin("TestVeto", func(a *Allocator) (Plugin, error) { return veto, nil })
+}
+
+func TestRegisterPluginRejectsDuplicates(t *testing.T) {
+	err := RegisterPlugin(PluginPlacement, newPlacementPlugin)
+	assert.Error(t, err)
+}
+
+func TestCustomFilterPlugin(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 1)
+	repo.addNode("node-b", 4).Labels["zone"] = "us-east"
+
+	config := &utils.SchedulerConfig{
+		Plugins: utils.PluginsConfig{
+			Filter: []string{PluginNodeResources, "TestZone"},
+		},
+	}
+	allocator := NewAllocator(repo, config)
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: 1,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", result.NodeID)
+
+	repo.nodes["node-b"].Labels["zone"] = "eu-west"
+	_, err = allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-2",
+		GPUCount: 1,
+	})
+	assert.True(t, utils.IsUnschedulable(err))
+	assert.Equal(t, "node outside zone us-east", utils.UnschedulableReason(err))
+}
+
+func TestConstraintOnOneNodeLeavesPreemptionToOthers(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(4, "node-a", "node-b"), withPreemption(),
+		withConfig(func(config *utils.SchedulerConfig) {
+			config.Plugins.Filter = []string{PluginNodeResources, "TestZone"}
+		}))
+	repo.nodes["node-b"].Labels["zone"] = "us-east"
+
+	low := &models.Job{ID: "low", TenantID: "tenant-1", Priority: 10, GPUCount: 4}
+	require.NoError(t, scheduler.SubmitJob(ctx, low))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, low.State)
+
+	// node-a is outside the zone and node-b is full, so only the full
+	// node can be made to fit
+	high := &models.Job{ID: "high", TenantID: "tenant-1", Priority: 500, GPUCount: 4}
+	_, err := scheduler.allocator.Allocate(ctx, &models.AllocationRequest{JobID: high.ID, GPUCount: 4})
+	assert.ErrorIs(t, err, utils.ErrInsufficientResources)
+	assert.False(t, utils.IsUnschedulable(err))
+
+	require.NoError(t, scheduler.SubmitJob(ctx, high))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePreempted, low.State)
+	require.Equal(t, models.JobStateRunning, high.State)
+	assert.Equal(t, "node-b", scheduler.primaryAllocation(ctx, "high").NodeID)
+}
+
+func TestScorePluginWeights(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+	repo.addNode("node-b", 8)
+
+	request := &models.AllocationRequest{JobID: "job-1", GPUCount: 2}
+
+	// Best-fit dominates by default
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{})
+	result, err := allocator.Allocate(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "node-a", result.NodeID)
+	require.NoError(t, allocator.Free(context.Background(), result.AllocationID))
+
+	// A heavier custom score plugin overrides it
+	allocator = NewAllocator(repo, &utils.SchedulerConfig{
+		Plugins: utils.PluginsConfig{
+			Score: []utils.ScorePluginConfig{
+				{Name: PluginPlacement, Weight: 1},
+				{Name: "TestMostGPUs", Weight: 2},
+			},
+		},
+	})
+	result, err = allocator.Allocate(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", result.NodeID)
+}
+
+func TestPermitRejectionUnreserves(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{
+		Plugins: utils.PluginsConfig{
+			Reserve: []string{"TestVeto"},
+			Permit:  []string{"TestVeto"},
+		},
+	})
+	_, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: 1,
+	})
+
+	assert.True(t, utils.IsUnschedulable(err))
+	assert.Equal(t, 1, veto.reserved)
+	assert.Equal(t, 1, veto.unreserved)
+	assert.Empty(t, repo.allocations)
+}
+
+func TestInvalidPluginConfigFallsBackToDefaults(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{
+		Plugins: utils.PluginsConfig{
+			Filter: []string{"DoesNotExist"},
+		},
+	})
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: 1,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+}
+
+func TestGangRequestFailsWithoutSingleNodeFit(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+	repo.addNode("node-b", 2)
+
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{})
+	_, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:          "gang",
+		GPUCount:       4,
+		GangScheduling: true,
+	})
+
+	assert.ErrorIs(t, err, utils.ErrGangSchedulingFailed)
+}
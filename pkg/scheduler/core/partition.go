@@ -0,0 +1,309 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// defaultQueueName is the queue of jobs submitted without one when the
+// configuration names none
+const defaultQueueName = "default"
+
+// Queue preemption rules
+const (
+	// PreemptEnabled lets a queue's jobs preempt lower-priority jobs
+	// running on the queue's nodes, whatever their queue
+	PreemptEnabled = "enabled"
+	// PreemptSameQueue limits victims to the queue's own jobs
+	PreemptSameQueue = "same_queue"
+	// PreemptDisabled keeps a queue's jobs from preempting anything
+	PreemptDisabled = "disabled"
+)
+
+// partition is a named queue together with its policy
+type partition struct {
+	config utils.QueueConfig
+	queue  *Queue
+}
+
+// QueueStatus reports a queue's policy and the jobs it holds
+type QueueStatus struct {
+	Name    string `json:"name"`
+	Default bool   `json:"default,omitempty"`
+	// Depth counts the jobs waiting in the queue to be placed
+	Depth        int               `json:"depth"`
+	Running      int               `json:"running"`
+	NodeSelector map[string]string `json:"node_selector,omitempty"`
+	Ordering     QueueOrdering     `json:"ordering"`
+	Preemption   string            `json:"preemption"`
+	Tenants      []string          `json:"tenants,omitempty"`
+}
+
+// newPartitions builds the configured queues in order. The default queue
+// is added without restrictions when it is not configured.
+func newPartitions(config *utils.SchedulerConfig) ([]*partition, *partition) {
+	defaultName := config.DefaultQueue
+	if defaultName == "" {
+		defaultName = defaultQueueName
+	}
+
+	var partitions []*partition
+	var fallback *partition
+	for _, queueConfig := range config.Queues {
+		part := newPartition(queueConfig, config.MaxQueueSize)
+		partitions = append(partitions, part)
+		if part.config.Name == defaultName {
+			fallback = part
+		}
+	}
+
+	if fallback == nil {
+		fallback = newPartition(utils.QueueConfig{Name: defaultName}, config.MaxQueueSize)
+		partitions = append(partitions, fallback)
+	}
+	return partitions, fallback
+}
+
+// newPartition fills in the queue's defaults, falling back on unknown
+// orderings, preemption rules and placement strategies
+func newPartition(config utils.QueueConfig, maxSize int) *partition {
+	ordering := QueueOrdering(config.Ordering)
+	if ordering == "" {
+		ordering = OrderPriority
+	}
+	if !ordering.IsValid() {
+		utils.Warn("Unknown queue ordering, using priority",
+			zap.String("queue", config.Name),
+			zap.String("ordering", config.Ordering))
+		ordering = OrderPriority
+	}
+	config.Ordering = string(ordering)
+
+	switch config.Preemption {
+	case "":
+		config.Preemption = PreemptEnabled
+	case PreemptEnabled, PreemptSameQueue, PreemptDisabled:
+	default:
+		utils.Warn("Unknown queue preemption rule, using enabled",
+			zap.String("queue", config.Name),
+			zap.String("preemption", config.Preemption))
+		config.Preemption = PreemptEnabled
+	}
+
+	if config.PlacementStrategy != "" && !models.PlacementStrategy(config.PlacementStrategy).IsValid() {
+		utils.Warn("Unknown queue placement strategy, using the default",
+			zap.String("queue", config.Name),
+			zap.String("strategy", config.PlacementStrategy))
+		config.PlacementStrategy = ""
+	}
+
+	return &partition{
+		config: config,
+		queue:  NewOrderedQueue(maxSize, ordering),
+	}
+}
+
+// admits returns true if the tenant may submit to the queue
+func (p *partition) admits(tenantID string) bool {
+	if len(p.config.Tenants) == 0 {
+		return true
+	}
+	for _, id := range p.config.Tenants {
+		if id == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *partition) maxRuntime() time.Duration {
+	return time.Duration(p.config.MaxRuntimeSec) * time.Second
+}
+
+// matchesSelector returns true if labels carry every key and value of
+// the selector
+func matchesSelector(selector, labels map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// partition returns the named queue, or nil if it is not configured
+func (s *Scheduler) partition(name string) *partition {
+	for _, part := range s.partitions {
+		if part.config.Name == name {
+			return part
+		}
+	}
+	return nil
+}
+
+// jobPartition returns the job's queue. Jobs whose queue has since been
+// removed from the configuration fall back to the default queue.
+func (s *Scheduler) jobPartition(job *models.Job) *partition {
+	if part := s.partition(job.Queue); part != nil {
+		return part
+	}
+	return s.defaultPartition
+}
+
+// validateQueue assigns jobs without a queue to the default one and
+// checks the queue's access list and limits. Jobs without a max runtime
+// inherit the queue's.
+func (s *Scheduler) validateQueue(job *models.Job) error {
+	if job.Queue == "" {
+		job.Queue = s.defaultPartition.config.Name
+	}
+	part := s.partition(job.Queue)
+	if part == nil {
+		return fmt.Errorf("%w: %s", utils.ErrQueueNotFound, job.Queue)
+	}
+	if !part.admits(job.TenantID) {
+		return fmt.Errorf("%w: %s may not submit to %s", utils.ErrQueueAccessDenied, job.TenantID, part.config.Name)
+	}
+
+	gpus := job.GPUCount
+	if job.IsElastic() {
+		gpus = job.MaxGPUs
+	}
+	if limit := part.config.MaxGPUsPerJob; limit > 0 && gpus > limit {
+		return fmt.Errorf("%w: %s allows at most %d GPUs per job", utils.ErrQueueLimitExceeded, part.config.Name, limit)
+	}
+
+	if limit := part.maxRuntime(); limit > 0 {
+		if job.MaxRuntime > limit {
+			return fmt.Errorf("%w: %s allows a max runtime of at most %s", utils.ErrQueueLimitExceeded, part.config.Name, limit)
+		}
+		if job.MaxRuntime == 0 {
+			job.MaxRuntime = limit
+		}
+	}
+	return nil
+}
+
+// placementStrategy returns the tenant's placement strategy, else the
+// job's queue's. An empty strategy falls back to the global one.
+func (s *Scheduler) placementStrategy(job *models.Job, tenant *models.Tenant) models.PlacementStrategy {
+	if tenant != nil && tenant.PlacementStrategy != "" {
+		return tenant.PlacementStrategy
+	}
+	return models.PlacementStrategy(s.jobPartition(job).config.PlacementStrategy)
+}
+
+// mayPreempt reports whether the job's queue lets it preempt victim.
+// Under same_queue the victim must be in the job's queue; otherwise it
+// must run on a node the job's queue may use.
+func (s *Scheduler) mayPreempt(ctx context.Context, job, victim *models.Job) bool {
+	part := s.jobPartition(job)
+	if part.config.Preemption == PreemptSameQueue {
+		return s.jobPartition(victim) == part
+	}
+	if len(part.config.NodeSelector) == 0 {
+		return true
+	}
+
+	allocations, err := s.storage.GetJobAllocations(ctx, victim.ID)
+	if err != nil {
+		return false
+	}
+	for _, alloc := range allocations {
+		if !alloc.IsActive() {
+			continue
+		}
+		node, err := s.storage.GetNode(ctx, alloc.NodeID)
+		if err == nil && matchesSelector(part.config.NodeSelector, node.Labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueueStatus reports every queue with its depth and running jobs
+func (s *Scheduler) QueueStatus(ctx context.Context) ([]QueueStatus, error) {
+	running, err := s.storage.ListJobsByState(ctx, models.JobStateRunning)
+	if err != nil {
+		return nil, err
+	}
+	runningByQueue := make(map[*partition]int)
+	for _, job := range running {
+		runningByQueue[s.jobPartition(job)]++
+	}
+
+	statuses := make([]QueueStatus, 0, len(s.partitions))
+	for _, part := range s.partitions {
+		statuses = append(statuses, QueueStatus{
+			Name:         part.config.Name,
+			Default:      part == s.defaultPartition,
+			Depth:        part.queue.Size(),
+			Running:      runningByQueue[part],
+			NodeSelector: part.config.NodeSelector,
+			Ordering:     QueueOrdering(part.config.Ordering),
+			Preemption:   part.config.Preemption,
+			Tenants:      part.config.Tenants,
+		})
+	}
+	return statuses, nil
+}
+
+// queuePlugin keeps jobs on the nodes matching their queue's node
+// selector
+type queuePlugin struct {
+	allocator *Allocator
+}
+
+const queueStateKey = "Queue/selector"
+
+func newQueuePlugin(a *Allocator) (Plugin, error) {
+	return &queuePlugin{allocator: a}, nil
+}
+
+func (p *queuePlugin) Name() string { return PluginQueue }
+
+// PreFilter resolves the request's node selector. A queue that no node
+// matches keeps its jobs pending without blocking other queues.
+func (p *queuePlugin) PreFilter(ctx context.Context, state *CycleState, request *models.AllocationRequest) *Status {
+	var selector map[string]string
+	if p.allocator.config != nil {
+		for _, queueConfig := range p.allocator.config.Queues {
+			if queueConfig.Name == request.Queue {
+				selector = queueConfig.NodeSelector
+			}
+		}
+	}
+	if len(selector) == 0 {
+		return nil
+	}
+
+	nodes, err := p.allocator.storage.ListNodes(ctx)
+	if err != nil {
+		return NewInsufficientStatus("failed to list nodes: %v", err)
+	}
+	for _, node := range nodes {
+		if matchesSelector(selector, node.Labels) {
+			state.Write(queueStateKey, selector)
+			return nil
+		}
+	}
+	return NewUnschedulableStatus("no node matches the node selector of queue %s", request.Queue)
+}
+
+// Filter rejects nodes outside the queue as lacking resources, so a job
+// whose queue's nodes are full may still preempt or wait its turn
+func (p *queuePlugin) Filter(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status {
+	value, ok := state.Read(queueStateKey)
+	if !ok {
+		return nil
+	}
+	if !matchesSelector(value.(map[string]string), info.Node.Labels) {
+		return NewInsufficientStatus("node %s is outside queue %s", info.Node.ID, request.Queue)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queueSetup creates an interactive and a batch queue, each bound to its
+// own 4-GPU node, unless other queues are given
+func queueSetup(queues ...utils.QueueConfig) []testOption {
+	if len(queues) == 0 {
+		queues = []utils.QueueConfig{
+			{
+				Name:          "interactive",
+				NodeSelector:  map[string]string{"pool": "interactive"},
+				MaxRuntimeSec: 3600,
+				MaxGPUsPerJob: 2,
+				Tenants:       []string{"tenant-1"},
+			},
+			{
+				Name:         "batch",
+				NodeSelector: map[string]string{"pool": "batch"},
+			},
+		}
+	}
+
+	return []testOption{
+		func(setup *testSetup) {
+			setup.repo.addNode("node-i", 4).Labels["pool"] = "interactive"
+			setup.repo.addNode("node-b", 4).Labels["pool"] = "batch"
+		},
+		withTenants("tenant-1", "tenant-2"),
+		withTenantSettings(func(tenant *models.Tenant) { tenant.MaxGPUs = 16 }),
+		withPreemption(),
+		withConfig(func(config *utils.SchedulerConfig) {
+			config.DefaultQueue = "batch"
+			config.Queues = queues
+		}),
+	}
+}
+
+func TestSubmitChecksQueue(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, queueSetup()...)
+
+	for _, tt := range []struct {
+		name string
+		job  *models.Job
+		want error
+	}{
+		{"unknown queue", &models.Job{TenantID: "tenant-1", GPUCount: 1, Queue: "debug"}, utils.ErrQueueNotFound},
+		{"not on the access list", &models.Job{TenantID: "tenant-2", GPUCount: 1, Queue: "interactive"}, utils.ErrQueueAccessDenied},
+		{"too many GPUs", &models.Job{TenantID: "tenant-1", GPUCount: 4, Queue: "interactive"}, utils.ErrQueueLimitExceeded},
+		{"elastic maximum too large", &models.Job{TenantID: "tenant-1", MinGPUs: 1, MaxGPUs: 4, Queue: "interactive"}, utils.ErrQueueLimitExceeded},
+		{"runtime too long", &models.Job{TenantID: "tenant-1", GPUCount: 1, Queue: "interactive", MaxRuntime: 2 * time.Hour}, utils.ErrQueueLimitExceeded},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.job.ID = "job-" + tt.name
+			assert.ErrorIs(t, scheduler.SubmitJob(ctx, tt.job), tt.want)
+		})
+	}
+
+	// Jobs inherit the queue's max runtime, and land in the default queue
+	// when they name none
+	interactive := &models.Job{ID: "interactive", TenantID: "tenant-1", GPUCount: 1, Queue: "interactive"}
+	require.NoError(t, scheduler.SubmitJob(ctx, interactive))
+	assert.Equal(t, time.Hour, interactive.MaxRuntime)
+
+	batch := &models.Job{ID: "batch", TenantID: "tenant-2", GPUCount: 4}
+	require.NoError(t, scheduler.SubmitJob(ctx, batch))
+	assert.Equal(t, "batch", batch.Queue)
+	assert.Equal(t, 1, scheduler.queue.Size())
+}
+
+func TestQueuesStayOnTheirNodes(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, queueSetup()...)
+
+	first := &models.Job{ID: "first", TenantID: "tenant-1", GPUCount: 2, Queue: "interactive", Priority: 100}
+	second := &models.Job{ID: "second", TenantID: "tenant-1", GPUCount: 2, Queue: "interactive", Priority: 100}
+	third := &models.Job{ID: "third", TenantID: "tenant-1", GPUCount: 2, Queue: "interactive", Priority: 100}
+	batch := &models.Job{ID: "batch", TenantID: "tenant-2", GPUCount: 4, Queue: "batch", Priority: 10}
+	for _, job := range []*models.Job{first, second, third, batch} {
+		require.NoError(t, scheduler.SubmitJob(ctx, job))
+	}
+
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	for _, id := range []string{"first", "second"} {
+		assert.Equal(t, models.JobStateRunning, repo.jobs[id].State)
+		assert.Equal(t, "node-i", scheduler.activeAllocations(ctx, id)[0].NodeID)
+	}
+
+	// The full interactive queue neither spills onto batch nodes nor
+	// holds up the batch queue
+	assert.Equal(t, models.JobStatePending, repo.jobs["third"].State)
+	assert.Equal(t, models.JobStateRunning, repo.jobs["batch"].State)
+	assert.Equal(t, "node-b", scheduler.activeAllocations(ctx, "batch")[0].NodeID)
+
+	statuses, err := scheduler.QueueStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "interactive", statuses[0].Name)
+	assert.Equal(t, 1, statuses[0].Depth)
+	assert.Equal(t, 2, statuses[0].Running)
+	assert.True(t, statuses[1].Default)
+	assert.Equal(t, 0, statuses[1].Depth)
+	assert.Equal(t, 1, statuses[1].Running)
+}
+
+func TestQueueWithoutNodesKeepsJobsPending(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, queueSetup(
+		utils.QueueConfig{Name: "debug", NodeSelector: map[string]string{"pool": "debug"}},
+		utils.QueueConfig{Name: "batch"},
+	)...)
+
+	debug := &models.Job{ID: "debug", TenantID: "tenant-1", GPUCount: 1, Queue: "debug"}
+	batch := &models.Job{ID: "batch", TenantID: "tenant-1", GPUCount: 1}
+	require.NoError(t, scheduler.SubmitJob(ctx, debug))
+	require.NoError(t, scheduler.SubmitJob(ctx, batch))
+
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePending, repo.jobs["debug"].State)
+	assert.Contains(t, repo.jobs["debug"].PendingReason, "no node matches the node selector of queue debug")
+	assert.Equal(t, models.JobStateRunning, repo.jobs["batch"].State)
+}
+
+func TestQueuePreemptionRules(t *testing.T) {
+	ctx := context.Background()
+
+	// Both queues share every node so only the preemption rule decides
+	run := func(t *testing.T, preemption string) *fakeRepository {
+		scheduler, repo := newTestScheduler(t, queueSetup(
+			utils.QueueConfig{Name: "batch"},
+			utils.QueueConfig{Name: "urgent", Preemption: preemption},
+		)...)
+		for _, id := range []string{"low-1", "low-2"} {
+			require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: id, TenantID: "tenant-2", GPUCount: 4, Priority: 10}))
+		}
+		require.NoError(t, scheduler.schedulingCycle(ctx))
+
+		require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "high", TenantID: "tenant-1", GPUCount: 4, Priority: 500, Queue: "urgent"}))
+		require.NoError(t, scheduler.schedulingCycle(ctx))
+		return repo
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		repo := run(t, PreemptEnabled)
+		assert.Equal(t, models.JobStateRunning, repo.jobs["high"].State)
+	})
+
+	t.Run("same_queue", func(t *testing.T) {
+		repo := run(t, PreemptSameQueue)
+		assert.Equal(t, models.JobStatePending, repo.jobs["high"].State)
+		assert.Equal(t, models.JobStateRunning, repo.jobs["low-1"].State)
+		assert.Equal(t, models.JobStateRunning, repo.jobs["low-2"].State)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		repo := run(t, PreemptDisabled)
+		assert.Equal(t, models.JobStatePending, repo.jobs["high"].State)
+	})
+}
+
+func TestPreemptionStaysOnQueueNodes(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, queueSetup()...)
+
+	// A batch job holds the batch node; the interactive queue is full of
+	// low-priority work
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "batch", TenantID: "tenant-2", GPUCount: 4, Priority: 10}))
+	for _, id := range []string{"low-1", "low-2"} {
+		require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: id, TenantID: "tenant-1", GPUCount: 2, Priority: 50, Queue: "interactive"}))
+	}
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	high := &models.Job{ID: "high", TenantID: "tenant-1", GPUCount: 2, Priority: 500, Queue: "interactive"}
+	require.NoError(t, scheduler.SubmitJob(ctx, high))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	// The lower-priority batch job is on a node the interactive queue
+	// cannot use, so an interactive job is preempted instead
+	assert.Equal(t, models.JobStateRunning, repo.jobs["high"].State)
+	assert.Equal(t, models.JobStateRunning, repo.jobs["batch"].State)
+	preempted := 0
+	for _, id := range []string{"low-1", "low-2"} {
+		if repo.jobs[id].State == models.JobStatePreempted {
+			preempted++
+		}
+	}
+	assert.Equal(t, 1, preempted)
+}
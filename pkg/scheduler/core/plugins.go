@@ -0,0 +1,44 @@
+package core
+
+import (
+	"context"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+)
+
+// nodeResourcesPlugin rejects nodes that are offline, draining or short
+// of GPUs, CPU or memory
+type nodeResourcesPlugin struct{}
+
+func newNodeResourcesPlugin(a *Allocator) (Plugin, error) {
+	return &nodeResourcesPlugin{}, nil
+}
+
+func (p *nodeResourcesPlugin) Name() string { return PluginNodeResources }
+
+func (p *nodeResourcesPlugin) Filter(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status {
+	if !info.Node.HasCapacity(request.WholeGPUs(), request.CPUCores, request.MemoryMB) {
+		return NewInsufficientStatus("node %s has insufficient capacity", info.Node.ID)
+	}
+	return nil
+}
+
+// gangSchedulingPlugin places every GPU of a gang request on a single
+// node, all or nothing
+type gangSchedulingPlugin struct{}
+
+func newGangSchedulingPlugin(a *Allocator) (Plugin, error) {
+	return &gangSchedulingPlugin{}, nil
+}
+
+func (p *gangSchedulingPlugin) Name() string { return PluginGangScheduling }
+
+func (p *gangSchedulingPlugin) Filter(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status {
+	if !request.GangScheduling {
+		return nil
+	}
+	if info.Node.AvailableGPUs < request.GPUCount || len(info.Available) < request.GPUCount {
+		return NewInsufficientStatus("node %s cannot host the whole gang of %d GPUs", info.Node.ID, request.GPUCount)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/storage"
+)
+
+// PowerBudgetStatus reports draw against one power budget
+type PowerBudgetStatus struct {
+	Scope      string  `json:"scope"`
+	Name       string  `json:"name"`
+	BudgetW    float64 `json:"budget_w"`
+	CommittedW float64 `json:"committed_w"`
+	HeadroomW  float64 `json:"headroom_w"`
+}
+
+// PowerStatus summarizes power headroom across the cluster
+type PowerStatus struct {
+	Enabled bool                `json:"enabled"`
+	Cluster PowerBudgetStatus   `json:"cluster"`
+	Nodes   []PowerBudgetStatus `json:"nodes"`
+	Groups  []PowerBudgetStatus `json:"groups"`
+}
+
+// powerSnapshot holds committed power draw at the start of a placement.
+// Idle GPUs count at their measured draw; active allocations count at
+// the larger of their expected and measured draw.
+type powerSnapshot struct {
+	nodes       map[string]*models.Node
+	nodeDraw    map[string]float64
+	clusterDraw float64
+}
+
+// powerEnabled reports whether power budgets are enforced
+func (a *Allocator) powerEnabled() bool {
+	return a.config != nil && a.config.Power.Enabled
+}
+
+// buildPowerSnapshot computes committed draw per node
+func (a *Allocator) buildPowerSnapshot(ctx context.Context) (*powerSnapshot, error) {
+	nodes, err := a.storage.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	gpus, err := a.storage.ListGPUs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GPUs: %w", err)
+	}
+
+	allocations, err := a.storage.ListActiveAllocations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active allocations: %w", err)
+	}
+
+	snapshot := &powerSnapshot{
+		nodes:    make(map[string]*models.Node, len(nodes)),
+		nodeDraw: make(map[string]float64, len(nodes)),
+	}
+	for _, node := range nodes {
+		snapshot.nodes[node.ID] = node
+	}
+
+	gpuDraw := make(map[string]float64, len(gpus))
+	for _, gpu := range gpus {
+		if _, online := snapshot.nodes[gpu.NodeID]; !online {
+			continue
+		}
+		gpuDraw[gpu.ID] = gpu.PowerUsage
+		if !gpu.InUse() {
+			snapshot.nodeDraw[gpu.NodeID] += gpu.PowerUsage
+		}
+	}
+
+	for _, alloc := range allocations {
+		if _, online := snapshot.nodes[alloc.NodeID]; !online {
+			continue
+		}
+		measured := 0.0
+		for _, gpuID := range alloc.GPUIDs {
+			measured += gpuDraw[gpuID]
+		}
+		// Slices share the measured draw of their GPU
+		if alloc.Slice != nil {
+			measured *= alloc.Slice.Fraction
+		}
+		snapshot.nodeDraw[alloc.NodeID] += math.Max(alloc.ExpectedPowerW, measured)
+	}
+
+	for _, draw := range snapshot.nodeDraw {
+		snapshot.clusterDraw += draw
+	}
+
+	return snapshot, nil
+}
+
+// groupDraw sums committed draw over nodes carrying label=value
+func (s *powerSnapshot) groupDraw(label, value string) float64 {
+	total := 0.0
+	for nodeID, node := range s.nodes {
+		if node.Labels[label] == value {
+			total += s.nodeDraw[nodeID]
+		}
+	}
+	return total
+}
+
+// nodeBudget returns the power budget of a node
+func (a *Allocator) nodeBudget(nodeID string) float64 {
+	for _, budget := range a.config.Power.NodeBudgets {
+		if budget.NodeID == nodeID {
+			return budget.BudgetW
+		}
+	}
+	return a.config.Power.DefaultNodeBudgetW
+}
+
+// expectedDraw returns the full draw a job is expected to add on the
+// given GPUs: its declared or historical draw, else the GPUs' power limit
+func (a *Allocator) expectedDraw(request *models.AllocationRequest, gpus []*models.GPU) float64 {
+	if request.ExpectedPowerW > 0 {
+		return request.ExpectedPowerW
+	}
+
+	draw := 0.0
+	for _, gpu := range gpus {
+		limit := gpu.PowerLimitW
+		if limit <= 0 && a.config != nil {
+			limit = a.config.Power.DefaultGPUDrawW
+		}
+		draw += limit * sliceFraction(request, gpu)
+	}
+	return draw
+}
+
+// checkPowerBudget rejects placing the request on the given GPUs when it
+// would exceed the node, group or cluster budget. Freeing power on the
+// node makes room under its own budget, so that case is reported like a
+// resource shortfall; group and cluster budgets are constraints.
+func (a *Allocator) checkPowerBudget(snapshot *powerSnapshot, request *models.AllocationRequest, node *models.Node, gpus []*models.GPU) *Status {
+	if snapshot == nil {
+		return nil
+	}
+
+	// The selected GPUs' idle draw is already counted in the snapshot
+	delta := a.expectedDraw(request, gpus)
+	for _, gpu := range gpus {
+		if !gpu.InUse() {
+			delta -= gpu.PowerUsage
+		}
+	}
+	if delta < 0 {
+		delta = 0
+	}
+
+	if budget := a.nodeBudget(node.ID); budget > 0 && snapshot.nodeDraw[node.ID]+delta > budget {
+		return NewInsufficientStatus("waiting for power headroom: node %s power budget %.0fW exceeded (committed %.0fW + %.0fW)",
+			node.ID, budget, snapshot.nodeDraw[node.ID], delta)
+	}
+
+	for _, group := range a.config.Power.GroupBudgets {
+		if group.BudgetW <= 0 || node.Labels[group.Label] != group.Value {
+			continue
+		}
+		draw := snapshot.groupDraw(group.Label, group.Value)
+		if draw+delta > group.BudgetW {
+			return NewUnschedulableStatus("waiting for power headroom: %s=%s power budget %.0fW exceeded (committed %.0fW + %.0fW)",
+				group.Label, group.Value, group.BudgetW, draw, delta)
+		}
+	}
+
+	if budget := a.config.Power.ClusterBudgetW; budget > 0 && snapshot.clusterDraw+delta > budget {
+		return NewUnschedulableStatus("waiting for power headroom: cluster power budget %.0fW exceeded (committed %.0fW + %.0fW)",
+			budget, snapshot.clusterDraw, delta)
+	}
+
+	return nil
+}
+
+// powerBudgetPlugin rejects placements that would exceed a node, group
+// or cluster power budget
+type powerBudgetPlugin struct {
+	allocator *Allocator
+}
+
+const powerSnapshotKey = "PowerBudget/snapshot"
+
+func newPowerBudgetPlugin(a *Allocator) (Plugin, error) {
+	return &powerBudgetPlugin{allocator: a}, nil
+}
+
+func (p *powerBudgetPlugin) Name() string { return PluginPowerBudget }
+
+// PreFilter snapshots committed draw once per placement
+func (p *powerBudgetPlugin) PreFilter(ctx context.Context, state *CycleState, request *models.AllocationRequest) *Status {
+	if !p.allocator.powerEnabled() {
+		return nil
+	}
+	snapshot, err := p.allocator.buildPowerSnapshot(ctx)
+	if err != nil {
+		return NewInsufficientStatus("power snapshot unavailable: %v", err)
+	}
+	state.Write(powerSnapshotKey, snapshot)
+	return nil
+}
+
+func (p *powerBudgetPlugin) Filter(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status {
+	value, ok := state.Read(powerSnapshotKey)
+	if !ok {
+		return nil
+	}
+	selected := info.Selection(request.GPUCount)
+	if selected == nil {
+		return nil
+	}
+	return p.allocator.checkPowerBudget(value.(*powerSnapshot), request, info.Node, selected)
+}
+
+// PowerStatus reports committed draw and headroom for every budget
+func (a *Allocator) PowerStatus(ctx context.Context) (*PowerStatus, error) {
+	status := &PowerStatus{Enabled: a.powerEnabled()}
+	if !status.Enabled {
+		return status, nil
+	}
+
+	snapshot, err := a.buildPowerSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status.Cluster = newBudgetStatus("cluster", "cluster", a.config.Power.ClusterBudgetW, snapshot.clusterDraw)
+
+	for nodeID := range snapshot.nodes {
+		status.Nodes = append(status.Nodes,
+			newBudgetStatus("node", nodeID, a.nodeBudget(nodeID), snapshot.nodeDraw[nodeID]))
+	}
+
+	sort.Slice(status.Nodes, func(i, j int) bool {
+		return status.Nodes[i].Name < status.Nodes[j].Name
+	})
+
+	for _, group := range a.config.Power.GroupBudgets {
+		name := group.Label + "=" + group.Value
+		status.Groups = append(status.Groups,
+			newBudgetStatus("group", name, group.BudgetW, snapshot.groupDraw(group.Label, group.Value)))
+	}
+
+	return status, nil
+}
+
+func newBudgetStatus(scope, name string, budget, committed float64) PowerBudgetStatus {
+	status := PowerBudgetStatus{
+		Scope:      scope,
+		Name:       name,
+		BudgetW:    budget,
+		CommittedW: committed,
+	}
+	// Unlimited budgets report no headroom figure
+	if budget > 0 {
+		status.HeadroomW = budget - committed
+	}
+	return status
+}
+
+// estimatePowerFromHistory averages the per-GPU draw of the tenant's
+// completed runs of the same job name and scales it to the job's GPU count
+func estimatePowerFromHistory(ctx context.Context, repo storage.Repository, job *models.Job) float64 {
+	jobs, err := repo.ListJobsByTenant(ctx, job.TenantID)
+	if err != nil {
+		return 0
+	}
+
+	total, samples := 0.0, 0
+	for _, past := range jobs {
+		if past.ID == job.ID || past.Name != job.Name || past.State != models.JobStateCompleted {
+			continue
+		}
+		allocations, err := repo.GetJobAllocations(ctx, past.ID)
+		if err != nil {
+			continue
+		}
+		for _, alloc := range allocations {
+			if alloc.AvgPowerUsage > 0 {
+				total += alloc.AvgPowerUsage
+				samples++
+			}
+		}
+	}
+
+	if samples == 0 {
+		return 0
+	}
+	return total / float64(samples) * float64(job.GPUCount)
+}
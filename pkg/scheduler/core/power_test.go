@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func powerConfig(power utils.PowerConfig) *utils.SchedulerConfig {
+	power.Enabled = true
+	if power.DefaultGPUDrawW == 0 {
+		power.DefaultGPUDrawW = 300
+	}
+	return &utils.SchedulerConfig{Power: power}
+}
+
+func TestNodePowerBudgetSteersPlacement(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+	repo.addNode("node-b", 4)
+
+	// Best-fit alone would pick node-a (zero waste)
+	allocator := NewAllocator(repo, powerConfig(utils.PowerConfig{
+		NodeBudgets: []utils.NodePowerBudget{{NodeID: "node-a", BudgetW: 500}},
+	}))
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: 2,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", result.NodeID)
+
+	alloc, err := repo.GetAllocation(context.Background(), result.AllocationID)
+	require.NoError(t, err)
+	assert.Equal(t, 600.0, alloc.ExpectedPowerW)
+}
+
+func TestNodePowerBudgetLeavesRoomForPreemption(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a"), withPreemption(),
+		withConfig(func(config *utils.SchedulerConfig) {
+			config.Power = powerConfig(utils.PowerConfig{
+				NodeBudgets: []utils.NodePowerBudget{{NodeID: "node-a", BudgetW: 700}},
+			}).Power
+		}))
+
+	// The node has GPUs for two 2-GPU jobs but power for only one
+	low := &models.Job{ID: "low", TenantID: "tenant-1", Priority: 10, GPUCount: 2}
+	require.NoError(t, scheduler.SubmitJob(ctx, low))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, low.State)
+
+	// Preempting the running job gives its power back
+	high := &models.Job{ID: "high", TenantID: "tenant-1", Priority: 500, GPUCount: 2}
+	require.NoError(t, scheduler.SubmitJob(ctx, high))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePreempted, low.State)
+	assert.Equal(t, models.JobStateRunning, high.State)
+}
+
+func TestGroupPowerBudgetKeepsJobPending(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 4).Labels["rack"] = "r1"
+	repo.addNode("node-b", 4).Labels["rack"] = "r1"
+	runJob(t, repo, &models.Job{ID: "trainer"}, "node-a")
+	repo.allocations["alloc-trainer"].ExpectedPowerW = 800
+
+	allocator := NewAllocator(repo, powerConfig(utils.PowerConfig{
+		GroupBudgets: []utils.GroupPowerBudget{{Label: "rack", Value: "r1", BudgetW: 1000}},
+	}))
+	_, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: 1,
+	})
+
+	assert.True(t, utils.IsUnschedulable(err))
+	assert.Contains(t, utils.UnschedulableReason(err), "rack=r1")
+}
+
+func TestClusterPowerBudgetUsesDeclaredDraw(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 4)
+
+	allocator := NewAllocator(repo, powerConfig(utils.PowerConfig{ClusterBudgetW: 1000}))
+
+	_, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:          "job-big",
+		GPUCount:       2,
+		ExpectedPowerW: 1200,
+	})
+	assert.True(t, utils.IsUnschedulable(err))
+	assert.Contains(t, utils.UnschedulableReason(err), "cluster power budget")
+
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:          "job-small",
+		GPUCount:       2,
+		ExpectedPowerW: 400,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+}
+
+func TestPowerBudgetsDisabledByDefault(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{
+		Power: utils.PowerConfig{ClusterBudgetW: 100},
+	})
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: 2,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+}
+
+func TestSubmitEstimatesPowerFromHistory(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withConfig(func(config *utils.SchedulerConfig) {
+		config.Power.Enabled = true
+	}))
+
+	for i, draw := range []float64{200, 300} {
+		past := &models.Job{
+			ID:       "past-" + string(rune('a'+i)),
+			TenantID: "tenant-1",
+			Name:     "resnet",
+			State:    models.JobStateCompleted,
+		}
+		require.NoError(t, repo.CreateJob(ctx, past))
+		require.NoError(t, repo.CreateAllocation(ctx, &models.Allocation{
+			ID:            "alloc-" + past.ID,
+			JobID:         past.ID,
+			State:         models.AllocationCompleted,
+			AvgPowerUsage: draw,
+			AllocatedAt:   time.Now(),
+		}))
+	}
+
+	job := &models.Job{ID: "next", TenantID: "tenant-1", Name: "resnet", GPUCount: 2}
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	assert.Equal(t, 500.0, job.ExpectedPowerW)
+
+	declared := &models.Job{ID: "declared", TenantID: "tenant-1", Name: "resnet", GPUCount: 2, ExpectedPowerW: 900}
+	require.NoError(t, scheduler.SubmitJob(ctx, declared))
+	assert.Equal(t, 900.0, declared.ExpectedPowerW)
+}
+
+func TestPowerStatusReportsHeadroom(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 4).Labels["rack"] = "r1"
+	repo.addNode("node-b", 4)
+	runJob(t, repo, &models.Job{ID: "trainer"}, "node-a")
+	repo.allocations["alloc-trainer"].ExpectedPowerW = 600
+
+	allocator := NewAllocator(repo, powerConfig(utils.PowerConfig{
+		ClusterBudgetW:     2000,
+		DefaultNodeBudgetW: 1500,
+		GroupBudgets:       []utils.GroupPowerBudget{{Label: "rack", Value: "r1", BudgetW: 1000}},
+	}))
+	status, err := allocator.PowerStatus(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, status.Enabled)
+	assert.Equal(t, 600.0, status.Cluster.CommittedW)
+	assert.Equal(t, 1400.0, status.Cluster.HeadroomW)
+	require.Len(t, status.Nodes, 2)
+	assert.Equal(t, "node-a", status.Nodes[0].Name)
+	assert.Equal(t, 900.0, status.Nodes[0].HeadroomW)
+	require.Len(t, status.Groups, 1)
+	assert.Equal(t, 400.0, status.Groups[0].HeadroomW)
+}
@@ -23,8 +23,9 @@ func NewPreemptor(storage storage.Repository) *Preemptor {
 	}
 }
 
-// SelectVictims selects jobs to preempt
-func (p *Preemptor) SelectVictims(ctx context.Context, requestingJob *models.Job) ([]*models.Job, error) {
+// SelectVictims selects jobs to preempt among the running jobs eligible
+// accepts
+func (p *Preemptor) SelectVictims(ctx context.Context, requestingJob *models.Job, eligible func(*models.Job) bool) ([]*models.Job, error) {
 	// Get all running jobs
 	runningJobs, err := p.storage.ListJobsByState(ctx, models.JobStateRunning)
 	if err != nil {
@@ -35,7 +36,11 @@ func (p *Preemptor) SelectVictims(ctx context.Context, requestingJob *models.Job
 
 	// Find lower priority jobs
 	for _, job := range runningJobs {
-		if job.Priority < requestingJob.Priority {
+		// Reserved GPUs would not go to the requesting job anyway
+		if job.ReservationID != "" && job.ReservationID != requestingJob.ReservationID {
+			continue
+		}
+		if job.Priority < requestingJob.Priority && eligible(job) {
 			// Check if tenant allows preemption
 			tenant, err := p.storage.GetTenant(ctx, job.TenantID)
 			if err != nil {
@@ -52,14 +57,17 @@ func (p *Preemptor) SelectVictims(ctx context.Context, requestingJob *models.Job
 		return nil, nil
 	}
 
-	// Select victims based on cost
-	// For now, select the lowest priority job
+	// Select victims based on cost: borrowed capacity goes first, then
+	// the lowest priority job
 	var victim *models.Job
+	victimBorrowed := false
 	lowestPriority := 999999
 
 	for _, candidate := range candidates {
-		if candidate.Priority < lowestPriority {
+		borrowed := p.isBorrowed(ctx, candidate)
+		if borrowed && !victimBorrowed || borrowed == victimBorrowed && candidate.Priority < lowestPriority {
 			lowestPriority = candidate.Priority
+			victimBorrowed = borrowed
 			victim = candidate
 		}
 	}
@@ -71,11 +79,69 @@ func (p *Preemptor) SelectVictims(ctx context.Context, requestingJob *models.Job
 	return nil, nil
 }
 
+// SelectReclaimVictims selects a job running on borrowed capacity among
+// the running jobs eligible accepts, whatever its priority, so a tenant
+// within its guarantee can take its GPUs back
+func (p *Preemptor) SelectReclaimVictims(ctx context.Context, requestingJob *models.Job, eligible func(*models.Job) bool) ([]*models.Job, error) {
+	runningJobs, err := p.storage.ListJobsByState(ctx, models.JobStateRunning)
+	if err != nil {
+		return nil, err
+	}
+
+	var victim *models.Job
+	for _, job := range runningJobs {
+		if job.ReservationID != "" && job.ReservationID != requestingJob.ReservationID {
+			continue
+		}
+		if !eligible(job) || !p.isBorrowed(ctx, job) {
+			continue
+		}
+		if victim == nil || job.Priority < victim.Priority {
+			victim = job
+		}
+	}
+
+	if victim == nil {
+		return nil, nil
+	}
+	return []*models.Job{victim}, nil
+}
+
+// isBorrowed returns true if the job holds a reclaimable allocation and
+// its tenant still uses more than its guarantee
+func (p *Preemptor) isBorrowed(ctx context.Context, job *models.Job) bool {
+	tenant, err := p.storage.GetTenant(ctx, job.TenantID)
+	if err != nil || !tenant.IsBorrowing() {
+		return false
+	}
+
+	allocations, err := p.storage.GetJobAllocations(ctx, job.ID)
+	if err != nil {
+		return false
+	}
+	for _, alloc := range allocations {
+		if alloc.IsActive() && alloc.Reclaimable {
+			return true
+		}
+	}
+	return false
+}
+
 // Preempt preempts a running job
 func (p *Preemptor) Preempt(ctx context.Context, victim *models.Job, preemptorID string) error {
+	return p.preempt(ctx, victim, preemptorID, "higher priority job")
+}
+
+// Reclaim preempts a job running on borrowed capacity
+func (p *Preemptor) Reclaim(ctx context.Context, victim *models.Job, preemptorID string) error {
+	return p.preempt(ctx, victim, preemptorID, "guaranteed quota reclaimed")
+}
+
+func (p *Preemptor) preempt(ctx context.Context, victim *models.Job, preemptorID, reason string) error {
 	utils.Info("Preempting job", 
 		zap.String("victim_id", victim.ID),
-		zap.String("preemptor_id", preemptorID))
+		zap.String("preemptor_id", preemptorID),
+		zap.String("reason", reason))
 
 	// Update job state
 	victim.State = models.JobStatePreempted
@@ -93,10 +159,15 @@ func (p *Preemptor) Preempt(ctx context.Context, victim *models.Job, preemptorID
 	}
 
 	for _, alloc := range allocations {
+		// Allocations of earlier attempts, or released by an elastic
+		// shrink, are already free
+		if !alloc.IsActive() {
+			continue
+		}
 		alloc.State = models.AllocationPreempted
 		alloc.PreemptedAt = &now
 		alloc.PreemptedBy = preemptorID
-		alloc.PreemptionReason = "higher priority job"
+		alloc.PreemptionReason = reason
 
 		if err := p.storage.UpdateAllocation(ctx, alloc); err != nil {
 			utils.Error("Failed to update allocation", 
@@ -104,17 +175,17 @@ func (p *Preemptor) Preempt(ctx context.Context, victim *models.Job, preemptorID
 				zap.Error(err))
 		}
 
-		// Free the GPUs
+		// Free the GPUs, or the slice held on a shared GPU
+		freed := 0
 		for _, gpuID := range alloc.GPUIDs {
 			gpu, err := p.storage.GetGPU(ctx, gpuID)
 			if err != nil {
 				continue
 			}
 
-			gpu.Allocated = false
-			gpu.AllocationID = ""
-			gpu.JobID = ""
-			gpu.TenantID = ""
+			if gpu.Release(alloc.ID) {
+				freed++
+			}
 
 			if err := p.storage.UpdateGPU(ctx, gpu); err != nil {
 				utils.Error("Failed to free GPU", 
@@ -129,7 +200,7 @@ func (p *Preemptor) Preempt(ctx context.Context, victim *models.Job, preemptorID
 			continue
 		}
 
-		node.AvailableGPUs += len(alloc.GPUIDs)
+		node.AvailableGPUs += freed
 		node.AvailableCPUCores += alloc.CPUCores
 		node.AvailableMemoryMB += alloc.MemoryMB
 
@@ -3,18 +3,42 @@ package core
 import (
 	"container/heap"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/azizbahloul/gpu-scheduler/pkg/models"
 )
 
+// QueueOrdering decides the order in which a queue's jobs are scheduled
+type QueueOrdering string
+
+const (
+	// OrderPriority schedules by effective priority, then earliest
+	// deadline, then submission order
+	OrderPriority QueueOrdering = "priority"
+	// OrderFIFO schedules in submission order
+	OrderFIFO QueueOrdering = "fifo"
+	// OrderDeadline schedules earliest deadline first, then by priority
+	OrderDeadline QueueOrdering = "deadline"
+)
+
+// IsValid returns true if the ordering is known
+func (o QueueOrdering) IsValid() bool {
+	switch o {
+	case OrderPriority, OrderFIFO, OrderDeadline:
+		return true
+	}
+	return false
+}
+
 // Queue manages the scheduling queue with priority
 type Queue struct {
 	mu       sync.RWMutex
 	items    PriorityQueue
 	jobMap   map[string]*QueueItem
 	maxSize  int
+	ordering QueueOrdering
 }
 
 // QueueItem represents a job in the queue
@@ -24,6 +48,56 @@ type QueueItem struct {
 	Index     int
 	EnqueuedAt time.Time
 	AgingBoost int
+	DeadlineBoost int
+
+	ordering QueueOrdering
+}
+
+// rank returns the item's effective priority
+func (item *QueueItem) rank() int {
+	return item.Priority + item.AgingBoost + item.DeadlineBoost
+}
+
+// before reports whether item is scheduled ahead of other under the
+// queue's ordering. By default that is higher effective priority first,
+// then earliest deadline, then FIFO.
+func (item *QueueItem) before(other *QueueItem) bool {
+	switch item.ordering {
+	case OrderFIFO:
+		return item.EnqueuedAt.Before(other.EnqueuedAt)
+
+	case OrderDeadline:
+		if first, ok := item.earlierDeadline(other); ok {
+			return first
+		}
+		if item.rank() != other.rank() {
+			return item.rank() > other.rank()
+		}
+
+	default:
+		if item.rank() != other.rank() {
+			return item.rank() > other.rank()
+		}
+		if first, ok := item.earlierDeadline(other); ok {
+			return first
+		}
+	}
+
+	return item.EnqueuedAt.Before(other.EnqueuedAt)
+}
+
+// earlierDeadline reports whether item's deadline comes before other's.
+// Jobs without a deadline come last; ok is false when neither deadline
+// decides.
+func (item *QueueItem) earlierDeadline(other *QueueItem) (first bool, ok bool) {
+	mine, theirs := item.Job.Deadline, other.Job.Deadline
+	if mine != nil && (theirs == nil || mine.Before(*theirs)) {
+		return true, true
+	}
+	if theirs != nil && (mine == nil || theirs.Before(*mine)) {
+		return false, true
+	}
+	return false, false
 }
 
 // PriorityQueue implements heap.Interface
@@ -33,15 +107,7 @@ func (pq PriorityQueue) Len() int { return len(pq) }
 
 func (pq PriorityQueue) Less(i, j int) bool {
 	// Higher priority first (max heap)
-	totalPriorityI := pq[i].Priority + pq[i].AgingBoost
-	totalPriorityJ := pq[j].Priority + pq[j].AgingBoost
-	
-	if totalPriorityI != totalPriorityJ {
-		return totalPriorityI > totalPriorityJ
-	}
-	
-	// If same priority, FIFO
-	return pq[i].EnqueuedAt.Before(pq[j].EnqueuedAt)
+	return pq[i].before(pq[j])
 }
 
 func (pq PriorityQueue) Swap(i, j int) {
@@ -69,10 +135,16 @@ func (pq *PriorityQueue) Pop() interface{} {
 
 // NewQueue creates a new scheduling queue
 func NewQueue(maxSize int) *Queue {
+	return NewOrderedQueue(maxSize, OrderPriority)
+}
+
+// NewOrderedQueue creates a scheduling queue with the given ordering
+func NewOrderedQueue(maxSize int, ordering QueueOrdering) *Queue {
 	q := &Queue{
-		items:   make(PriorityQueue, 0),
-		jobMap:  make(map[string]*QueueItem),
-		maxSize: maxSize,
+		items:    make(PriorityQueue, 0),
+		jobMap:   make(map[string]*QueueItem),
+		maxSize:  maxSize,
+		ordering: ordering,
 	}
 	heap.Init(&q.items)
 	return q
@@ -96,6 +168,7 @@ func (q *Queue) Enqueue(job *models.Job) error {
 		Priority:   job.Priority,
 		EnqueuedAt: time.Now(),
 		AgingBoost: 0,
+		ordering:   q.ordering,
 	}
 
 	heap.Push(&q.items, item)
@@ -182,6 +255,25 @@ func (q *Queue) List() []*models.Job {
 	return jobs
 }
 
+// Sorted returns all jobs in the queue in scheduling order
+func (q *Queue) Sorted() []*models.Job {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	items := make(PriorityQueue, len(q.items))
+	copy(items, q.items)
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items.Less(i, j)
+	})
+
+	jobs := make([]*models.Job, len(items))
+	for i, item := range items {
+		jobs[i] = item.Job
+	}
+	return jobs
+}
+
 // ApplyAging increases priority of waiting jobs to prevent starvation
 func (q *Queue) ApplyAging(agingFactor int, ageThreshold time.Duration) {
 	q.mu.Lock()
@@ -200,6 +292,26 @@ func (q *Queue) ApplyAging(agingFactor int, ageThreshold time.Duration) {
 	heap.Init(&q.items)
 }
 
+// ApplyDeadlineBoost raises the priority of jobs whose deadline slack
+// is below window, by up to maxBoost as the slack reaches zero. Boosts
+// are recomputed on every call. Jobs that can no longer meet their
+// deadline are not boosted.
+func (q *Queue) ApplyDeadlineBoost(now time.Time, window time.Duration, maxBoost int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, item := range q.items {
+		item.DeadlineBoost = 0
+		slack, ok := item.Job.Slack(now)
+		if !ok || window <= 0 || slack < 0 || slack >= window {
+			continue
+		}
+		item.DeadlineBoost = int(float64(maxBoost) * float64(window-slack) / float64(window))
+	}
+
+	heap.Init(&q.items)
+}
+
 // GetPosition returns the queue position of a job (1-indexed)
 func (q *Queue) GetPosition(jobID string) int {
 	q.mu.RLock()
@@ -214,13 +326,9 @@ func (q *Queue) GetPosition(jobID string) int {
 	position := 1
 	for _, qItem := range q.items {
 		if qItem.Job.ID == jobID {
-			return position
+			continue
 		}
-		totalPriority := qItem.Priority + qItem.AgingBoost
-		itemTotalPriority := item.Priority + item.AgingBoost
-		
-		if totalPriority > itemTotalPriority ||
-			(totalPriority == itemTotalPriority && qItem.EnqueuedAt.Before(item.EnqueuedAt)) {
+		if qItem.before(item) {
 			position++
 		}
 	}
@@ -240,6 +240,71 @@ func TestConcurrentAccess(t *testing.T) {
 	assert.Equal(t, 5, q.Size())
 }
 
+func TestDeadlineBoostOrdering(t *testing.T) {
+	q := NewQueue(10)
+	now := time.Now()
+	soon := now.Add(90 * time.Minute)
+	later := now.Add(10 * time.Hour)
+	missed := now.Add(30 * time.Minute)
+
+	require.NoError(t, q.Enqueue(&models.Job{ID: "normal", Priority: 500}))
+	require.NoError(t, q.Enqueue(&models.Job{ID: "nightly", Priority: 100, Deadline: &soon, MaxRuntime: time.Hour}))
+	require.NoError(t, q.Enqueue(&models.Job{ID: "weekly", Priority: 100, Deadline: &later, MaxRuntime: time.Hour}))
+	require.NoError(t, q.Enqueue(&models.Job{ID: "late", Priority: 100, Deadline: &missed, MaxRuntime: time.Hour}))
+
+	// 30 minutes of slack in a one hour window earns half the boost
+	q.ApplyDeadlineBoost(now, time.Hour, 1000)
+
+	sorted := q.Sorted()
+	assert.Equal(t, "nightly", sorted[0].ID)
+	assert.Equal(t, "normal", sorted[1].ID)
+	assert.Equal(t, 600, q.jobMap["nightly"].rank())
+	assert.Equal(t, 0, q.jobMap["weekly"].DeadlineBoost)
+	assert.Equal(t, 0, q.jobMap["late"].DeadlineBoost)
+	assert.Equal(t, 1, q.GetPosition("nightly"))
+
+	// Equal priorities go earliest deadline first
+	assert.Equal(t, "late", sorted[2].ID)
+	assert.Equal(t, "weekly", sorted[3].ID)
+	assert.Equal(t, 4, q.GetPosition("weekly"))
+}
+
+func TestQueueOrderings(t *testing.T) {
+	now := time.Now()
+	soon := now.Add(time.Hour)
+	later := now.Add(2 * time.Hour)
+
+	jobs := []*models.Job{
+		{ID: "first", Priority: 100},
+		{ID: "urgent", Priority: 100, Deadline: &soon},
+		{ID: "important", Priority: 500},
+		{ID: "weekly", Priority: 300, Deadline: &later},
+	}
+
+	for _, tt := range []struct {
+		ordering QueueOrdering
+		want     []string
+	}{
+		{OrderPriority, []string{"important", "weekly", "urgent", "first"}},
+		{OrderFIFO, []string{"first", "urgent", "important", "weekly"}},
+		{OrderDeadline, []string{"urgent", "weekly", "important", "first"}},
+	} {
+		t.Run(string(tt.ordering), func(t *testing.T) {
+			q := NewOrderedQueue(10, tt.ordering)
+			for _, job := range jobs {
+				require.NoError(t, q.Enqueue(job))
+			}
+
+			var got []string
+			for _, job := range q.Sorted() {
+				got = append(got, job.ID)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want[0], q.Dequeue().ID)
+		})
+	}
+}
+
 func BenchmarkEnqueue(b *testing.B) {
 	q := NewQueue(100000)
 	job := &models.Job{ID: "bench-job", Priority: 100, GPUCount: 1}
@@ -0,0 +1,251 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// CreateQuotaGroup adds a group to the quota tree. Its kind must sit one
+// level below its parent and its limits may not exceed the parent's.
+func (s *Scheduler) CreateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error {
+	if err := group.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", utils.ErrInvalidQuotaGroup, err)
+	}
+	if group.ParentID != "" {
+		parent, err := s.storage.GetQuotaGroup(ctx, group.ParentID)
+		if err != nil {
+			return fmt.Errorf("failed to get parent quota group: %w", err)
+		}
+		if err := group.FitsWithin(parent); err != nil {
+			return fmt.Errorf("%w: %v", utils.ErrInvalidQuotaGroup, err)
+		}
+	}
+
+	if group.ID == "" {
+		group.ID = generateQuotaGroupID()
+	}
+	group.CreatedAt = time.Now()
+	group.UpdatedAt = group.CreatedAt
+	if err := s.storage.CreateQuotaGroup(ctx, group); err != nil {
+		return fmt.Errorf("failed to create quota group: %w", err)
+	}
+
+	utils.Info("Quota group created",
+		zap.String("quota_group_id", group.ID),
+		zap.String("kind", string(group.Kind)),
+		zap.String("parent_id", group.ParentID))
+	return nil
+}
+
+// UpdateQuotaGroup changes a group's name and limits. The new limits must
+// still fit within the parent's and leave room for every child's. A
+// group's kind and parent cannot change.
+func (s *Scheduler) UpdateQuotaGroup(ctx context.Context, update *models.QuotaGroup) (*models.QuotaGroup, error) {
+	stored, err := s.storage.GetQuotaGroup(ctx, update.ID)
+	if err != nil {
+		return nil, err
+	}
+	group := *stored
+	group.Name = update.Name
+	group.MaxGPUs = update.MaxGPUs
+	group.MaxGPUMemoryMB = update.MaxGPUMemoryMB
+	group.MaxCPUCores = update.MaxCPUCores
+	group.MaxMemoryMB = update.MaxMemoryMB
+	group.MaxConcurrentJobs = update.MaxConcurrentJobs
+	if err := group.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidQuotaGroup, err)
+	}
+
+	groups, err := s.storage.ListQuotaGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quota groups: %w", err)
+	}
+	for _, other := range groups {
+		if other.ID == group.ParentID {
+			if err := group.FitsWithin(other); err != nil {
+				return nil, fmt.Errorf("%w: %v", utils.ErrInvalidQuotaGroup, err)
+			}
+		}
+		if other.ParentID == group.ID {
+			if err := other.FitsWithin(&group); err != nil {
+				return nil, fmt.Errorf("%w: %v", utils.ErrInvalidQuotaGroup, err)
+			}
+		}
+	}
+
+	group.UpdatedAt = time.Now()
+	if err := s.storage.UpdateQuotaGroup(ctx, &group); err != nil {
+		return nil, fmt.Errorf("failed to update quota group: %w", err)
+	}
+
+	utils.Info("Quota group updated", zap.String("quota_group_id", group.ID))
+	return s.GetQuotaGroup(ctx, group.ID)
+}
+
+// DeleteQuotaGroup removes a group that has no child groups and no
+// tenants
+func (s *Scheduler) DeleteQuotaGroup(ctx context.Context, groupID string) error {
+	group, err := s.GetQuotaGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if len(group.Children) > 0 || len(group.Tenants) > 0 {
+		return fmt.Errorf("%w: %s has %d child groups and %d tenants",
+			utils.ErrQuotaGroupInUse, group.Name, len(group.Children), len(group.Tenants))
+	}
+	if err := s.storage.DeleteQuotaGroup(ctx, groupID); err != nil {
+		return fmt.Errorf("failed to delete quota group: %w", err)
+	}
+
+	utils.Info("Quota group deleted", zap.String("quota_group_id", groupID))
+	return nil
+}
+
+// QuotaTree returns the organizations with their teams and projects
+// nested below, each carrying the usage of every tenant under it
+func (s *Scheduler) QuotaTree(ctx context.Context) ([]*models.QuotaGroup, error) {
+	roots, _, err := s.quotaTree(ctx)
+	return roots, err
+}
+
+// GetQuotaGroup returns a group with its subtree and rolled-up usage
+func (s *Scheduler) GetQuotaGroup(ctx context.Context, groupID string) (*models.QuotaGroup, error) {
+	_, groups, err := s.quotaTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	group, ok := groups[groupID]
+	if !ok {
+		return nil, utils.ErrQuotaGroupNotFound
+	}
+	return group, nil
+}
+
+// AssignQuotaGroup moves a tenant into a quota group, or out of the tree
+// when groupID is empty
+func (s *Scheduler) AssignQuotaGroup(ctx context.Context, tenantID, groupID string) (*models.Tenant, error) {
+	tenant, err := s.storage.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if groupID != "" {
+		if _, err := s.storage.GetQuotaGroup(ctx, groupID); err != nil {
+			return nil, err
+		}
+	}
+
+	tenant.QuotaGroupID = groupID
+	tenant.UpdatedAt = time.Now()
+	if err := s.storage.UpdateTenant(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	utils.Info("Tenant quota group assigned",
+		zap.String("tenant_id", tenantID),
+		zap.String("quota_group_id", groupID))
+	return tenant, nil
+}
+
+// quotaTree builds the quota tree and indexes its groups by ID
+func (s *Scheduler) quotaTree(ctx context.Context) ([]*models.QuotaGroup, map[string]*models.QuotaGroup, error) {
+	groups, err := s.storage.ListQuotaGroups(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list quota groups: %w", err)
+	}
+	tenants, err := s.storage.ListTenants(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	roots := models.BuildQuotaTree(groups, tenants)
+	byID := make(map[string]*models.QuotaGroup, len(groups))
+	for _, group := range groups {
+		byID[group.ID] = group
+	}
+	return roots, byID, nil
+}
+
+// checkQuotaFits refuses a job that could never run within its tenant's
+// limits or those of any group above it, even with nothing else running
+func (s *Scheduler) checkQuotaFits(ctx context.Context, tenant *models.Tenant, job *models.Job) error {
+	gpus := job.RequestedGPUs()
+	if resource := tenant.ExceededLimit(gpus, job.GPUMemoryMB, job.CPUCores, job.MemoryMB); resource != "" {
+		return fmt.Errorf("%w: job needs more %s than tenant %s allows", utils.ErrQuotaExceeded, resource, tenant.ID)
+	}
+
+	groupID := tenant.QuotaGroupID
+	for depth := 0; groupID != "" && depth < 3; depth++ {
+		group, err := s.storage.GetQuotaGroup(ctx, groupID)
+		if err != nil {
+			return fmt.Errorf("failed to get quota group: %w", err)
+		}
+		if resource := group.ExceededLimit(gpus, job.GPUMemoryMB, job.CPUCores, job.MemoryMB); resource != "" {
+			return fmt.Errorf("%w: job needs more %s than %s %s allows", utils.ErrQuotaExceeded, resource, group.Kind, group.Name)
+		}
+		groupID = group.ParentID
+	}
+	return nil
+}
+
+// quotaShortfall returns what keeps the job from running within its
+// tenant's GPU-hour budget and the current usage of its tenant, under
+// the quota profile in effect, and of every quota group above it, or ""
+// if it fits
+func (s *Scheduler) quotaShortfall(ctx context.Context, tenant *models.Tenant, job *models.Job) (string, error) {
+	now := time.Now()
+	if tenant.BudgetExhausted(now) {
+		return fmt.Sprintf("GPU-hour budget of tenant %s", tenant.ID), nil
+	}
+	limits := tenant.AtTime(now)
+	if !limits.HasAvailableQuota(job.WholeGPUs(), job.GPUMemoryMB, job.CPUCores, job.MemoryMB) ||
+		(job.IsFractional() && !limits.HasAvailableGPUShare(job.GPUSlice.EstimatedShare())) {
+		return fmt.Sprintf("quota of tenant %s", tenant.ID), nil
+	}
+	if tenant.QuotaGroupID == "" {
+		return "", nil
+	}
+
+	_, groups, err := s.quotaTree(ctx)
+	if err != nil {
+		return "", err
+	}
+	group := groups[tenant.QuotaGroupID]
+	for depth := 0; group != nil && depth < 3; depth++ {
+		if !group.HasAvailableQuota(job.RequestedGPUs(), job.GPUMemoryMB, job.CPUCores, job.MemoryMB) {
+			return fmt.Sprintf("quota of %s %s", group.Kind, group.Name), nil
+		}
+		group = groups[group.ParentID]
+	}
+	return "", nil
+}
+
+// quotaGroupHeadroom returns the fewest GPUs left free by the tenant's
+// quota group and its ancestors, and false if none of them limits GPUs
+func (s *Scheduler) quotaGroupHeadroom(ctx context.Context, tenant *models.Tenant) (float64, bool) {
+	if tenant.QuotaGroupID == "" {
+		return 0, false
+	}
+	_, groups, err := s.quotaTree(ctx)
+	if err != nil {
+		return 0, true
+	}
+
+	headroom, limited := 0.0, false
+	group := groups[tenant.QuotaGroupID]
+	for depth := 0; group != nil && depth < 3; depth++ {
+		if free, ok := group.GPUHeadroom(); ok && (!limited || free < headroom) {
+			headroom, limited = free, true
+		}
+		group = groups[group.ParentID]
+	}
+	return headroom, limited
+}
+
+func generateQuotaGroupID() string {
+	return fmt.Sprintf("qgroup-%d", time.Now().UnixNano())
+}
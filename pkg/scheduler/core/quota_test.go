@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createQuotaTree builds an acme → ml → llm quota tree where the
+// organization allows 4 GPUs and the team 3, and places tenant-llm in
+// llm and tenant-ops in acme
+func createQuotaTree(t *testing.T, scheduler *Scheduler, repo *fakeRepository) {
+	t.Helper()
+	for _, group := range []*models.QuotaGroup{
+		{ID: "acme", Name: "acme", Kind: models.QuotaOrganization, MaxGPUs: 4},
+		{ID: "ml", Name: "ml", Kind: models.QuotaTeam, ParentID: "acme", MaxGPUs: 3},
+		{ID: "llm", Name: "llm", Kind: models.QuotaProject, ParentID: "ml", MaxGPUs: 3},
+	} {
+		require.NoError(t, scheduler.CreateQuotaGroup(context.Background(), group))
+	}
+	repo.tenants["tenant-llm"].QuotaGroupID = "llm"
+	repo.tenants["tenant-ops"].QuotaGroupID = "acme"
+}
+
+func TestQuotaTreeLimitsJobs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-1"), withTenants("tenant-llm", "tenant-ops"))
+	createQuotaTree(t, scheduler, repo)
+
+	// The tenant allows 8 GPUs but its project never allows more than 3
+	err := scheduler.SubmitJob(ctx, &models.Job{ID: "too-big", TenantID: "tenant-llm", GPUCount: 4})
+	assert.True(t, utils.IsQuotaExceeded(err))
+	assert.Contains(t, err.Error(), "project llm")
+
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "llm", TenantID: "tenant-llm", GPUCount: 3}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	// Usage rolls up, so the organization has a single GPU left for a
+	// tenant attached to it directly
+	acme, err := scheduler.GetQuotaGroup(ctx, "acme")
+	require.NoError(t, err)
+	assert.InDelta(t, 3.0, acme.Usage.GPUs, 1e-9)
+	assert.Equal(t, 1, acme.Usage.Jobs)
+	assert.Equal(t, []string{"tenant-ops"}, acme.Tenants)
+
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "ops-2", TenantID: "tenant-ops", GPUCount: 2, Priority: 200}))
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "ops-1", TenantID: "tenant-ops", GPUCount: 1, Priority: 100}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePending, repo.jobs["ops-2"].State)
+	assert.Equal(t, "waiting for quota of organization acme", repo.jobs["ops-2"].PendingReason)
+	assert.Equal(t, models.JobStateRunning, repo.jobs["ops-1"].State)
+}
+
+func TestQuotaTreeKeepsChildrenWithinParents(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-1"), withTenants("tenant-llm", "tenant-ops"))
+	createQuotaTree(t, scheduler, repo)
+
+	err := scheduler.CreateQuotaGroup(ctx, &models.QuotaGroup{Name: "cv", Kind: models.QuotaTeam, ParentID: "acme", MaxGPUs: 8})
+	assert.ErrorIs(t, err, utils.ErrInvalidQuotaGroup)
+
+	err = scheduler.CreateQuotaGroup(ctx, &models.QuotaGroup{Name: "cv", Kind: models.QuotaTeam, ParentID: "missing", MaxGPUs: 1})
+	assert.ErrorIs(t, err, utils.ErrQuotaGroupNotFound)
+
+	// Lowering the organization below its team's limit is refused
+	_, err = scheduler.UpdateQuotaGroup(ctx, &models.QuotaGroup{ID: "acme", Name: "acme", MaxGPUs: 2})
+	assert.ErrorIs(t, err, utils.ErrInvalidQuotaGroup)
+	assert.Equal(t, 4, repo.quotaGroups["acme"].MaxGPUs)
+
+	updated, err := scheduler.UpdateQuotaGroup(ctx, &models.QuotaGroup{ID: "acme", Name: "acme-corp", MaxGPUs: 6})
+	require.NoError(t, err)
+	assert.Equal(t, "acme-corp", updated.Name)
+	assert.Equal(t, models.QuotaOrganization, updated.Kind)
+
+	// Groups with children or tenants cannot be deleted
+	assert.ErrorIs(t, scheduler.DeleteQuotaGroup(ctx, "ml"), utils.ErrQuotaGroupInUse)
+	assert.ErrorIs(t, scheduler.DeleteQuotaGroup(ctx, "llm"), utils.ErrQuotaGroupInUse)
+
+	_, err = scheduler.AssignQuotaGroup(ctx, "tenant-llm", "")
+	require.NoError(t, err)
+	require.NoError(t, scheduler.DeleteQuotaGroup(ctx, "llm"))
+
+	roots, err := scheduler.QuotaTree(ctx)
+	require.NoError(t, err)
+	require.Len(t, roots, 1)
+	require.Len(t, roots[0].Children, 1)
+	assert.Empty(t, roots[0].Children[0].Children)
+}
+
+func TestJobsWaitForQuota(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(8, "node-1"), withTenants("tenant-1", "tenant-2"),
+		withTenantSettings(func(tenant *models.Tenant) { tenant.MaxGPUs = 4 }))
+	repo.tenants["tenant-1"].MaxConcurrentJobs = 1
+
+	// A job larger than the tenant's quota can never run
+	err := scheduler.SubmitJob(ctx, &models.Job{ID: "huge", TenantID: "tenant-1", GPUCount: 6})
+	assert.True(t, utils.IsQuotaExceeded(err))
+
+	// A tenant at its concurrent limit can still queue work, which waits
+	// without holding up other tenants
+	for _, job := range []*models.Job{
+		{ID: "first", TenantID: "tenant-1", GPUCount: 1, Priority: 300},
+		{ID: "second", TenantID: "tenant-1", GPUCount: 1, Priority: 200},
+		{ID: "other", TenantID: "tenant-2", GPUCount: 1, Priority: 100},
+	} {
+		require.NoError(t, scheduler.SubmitJob(ctx, job))
+	}
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStateRunning, repo.jobs["first"].State)
+	assert.Equal(t, models.JobStatePending, repo.jobs["second"].State)
+	assert.Equal(t, "waiting for quota of tenant tenant-1", repo.jobs["second"].PendingReason)
+	assert.Equal(t, models.JobStateRunning, repo.jobs["other"].State)
+
+	// Usage is charged as each job is placed, so jobs queued together
+	// cannot overrun the quota
+	for _, id := range []string{"big-1", "big-2"} {
+		require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: id, TenantID: "tenant-2", GPUCount: 3}))
+	}
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateRunning, repo.jobs["big-1"].State)
+	assert.Equal(t, models.JobStatePending, repo.jobs["big-2"].State)
+	assert.Equal(t, 4, repo.tenants["tenant-2"].CurrentGPUs)
+
+	// The waiting job runs once quota is released
+	require.NoError(t, scheduler.CancelJob(ctx, "first"))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateRunning, repo.jobs["second"].State)
+	assert.Empty(t, repo.jobs["second"].PendingReason)
+}
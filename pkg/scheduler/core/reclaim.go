@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// tryReclaim preempts another tenant's job running on borrowed capacity
+// when the job fits within its own tenant's guarantee. Guarantees are
+// honored even when preemption is disabled, except in queues that
+// disable it.
+func (s *Scheduler) tryReclaim(ctx context.Context, job *models.Job) bool {
+	if s.jobPartition(job).config.Preemption == PreemptDisabled {
+		return false
+	}
+	tenant, err := s.storage.GetTenant(ctx, job.TenantID)
+	if err != nil || !tenant.WithinGuarantee(job.RequestedGPUs()) {
+		return false
+	}
+
+	victims, err := s.preemptor.SelectReclaimVictims(ctx, job, func(victim *models.Job) bool {
+		return victim.TenantID != job.TenantID && s.mayPreempt(ctx, job, victim)
+	})
+	if err != nil || len(victims) == 0 {
+		return false
+	}
+
+	utils.Info("Reclaiming guaranteed quota",
+		zap.String("job_id", job.ID),
+		zap.String("tenant_id", job.TenantID),
+		zap.Int("victims", len(victims)))
+
+	if !s.evictVictims(ctx, job, victims, s.preemptor.Reclaim, "guaranteed quota reclaimed by job %s") {
+		return false
+	}
+	for _, victim := range victims {
+		recordEvent(ctx, s.storage, &models.Event{
+			Type:     models.EventQuotaReclaimed,
+			Reason:   "guarantee",
+			JobID:    victim.ID,
+			TenantID: victim.TenantID,
+			Message:  fmt.Sprintf("Borrowed GPUs reclaimed by tenant %s for job %s", job.TenantID, job.ID),
+			Metadata: map[string]string{"reclaimed_by": job.ID},
+		})
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reclaimSetup shares one 8-GPU node between two tenants guaranteed 4
+// GPUs each, with preemption disabled
+func reclaimSetup() []testOption {
+	return []testOption{
+		withNodes(8, "node-1"),
+		withTenants("tenant-a", "tenant-b"),
+		withTenantSettings(func(tenant *models.Tenant) { tenant.GuaranteedGPUs = 4 }),
+	}
+}
+
+func TestBorrowedGPUsAreReclaimed(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, reclaimSetup()...)
+
+	// Tenant B borrows tenant A's idle guarantee
+	for _, id := range []string{"b-1", "b-2"} {
+		require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: id, TenantID: "tenant-b", GPUCount: 4, Priority: 500}))
+	}
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.False(t, scheduler.activeAllocations(ctx, "b-1")[0].Reclaimable)
+	assert.True(t, scheduler.activeAllocations(ctx, "b-2")[0].Reclaimable)
+
+	// Tenant A's lower-priority job takes its guarantee back
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "a-1", TenantID: "tenant-a", GPUCount: 4, Priority: 10}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStateRunning, repo.jobs["a-1"].State)
+	assert.False(t, scheduler.activeAllocations(ctx, "a-1")[0].Reclaimable)
+	assert.Equal(t, models.JobStateRunning, repo.jobs["b-1"].State)
+	assert.Equal(t, models.JobStatePreempted, repo.jobs["b-2"].State)
+
+	allocations, _ := repo.GetJobAllocations(ctx, "b-2")
+	require.Len(t, allocations, 1)
+	assert.Equal(t, "guaranteed quota reclaimed", allocations[0].PreemptionReason)
+	assert.Equal(t, "a-1", allocations[0].PreemptedBy)
+
+	events, _ := repo.ListEvents(ctx, models.EventFilter{Type: models.EventQuotaReclaimed, JobID: "b-2"})
+	require.Len(t, events, 1)
+	assert.Equal(t, "a-1", events[0].Metadata["reclaimed_by"])
+
+	// Beyond its guarantee tenant A borrows like anyone else and cannot
+	// touch tenant B's guaranteed job
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "a-2", TenantID: "tenant-a", GPUCount: 4, Priority: 10}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStatePending, repo.jobs["a-2"].State)
+	assert.Equal(t, models.JobStateRunning, repo.jobs["b-1"].State)
+}
+
+func TestPreemptionTakesBorrowedJobsFirst(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, reclaimSetup()...)
+	scheduler.config.EnablePreemption = true
+	for _, tenant := range repo.tenants {
+		tenant.AllowPreemption = true
+	}
+
+	// b-1 runs within tenant B's guarantee at the lowest priority; b-2
+	// borrows at a higher one
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "b-1", TenantID: "tenant-b", GPUCount: 4, Priority: 10}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "b-2", TenantID: "tenant-b", GPUCount: 4, Priority: 50}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	// A high-priority job beyond tenant A's guarantee preempts the
+	// borrowed job rather than the lowest-priority one
+	tenantA := repo.tenants["tenant-a"]
+	tenantA.GuaranteedGPUs = 0
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "a-1", TenantID: "tenant-a", GPUCount: 4, Priority: 500}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStateRunning, repo.jobs["a-1"].State)
+	assert.Equal(t, models.JobStateRunning, repo.jobs["b-1"].State)
+	assert.Equal(t, models.JobStatePreempted, repo.jobs["b-2"].State)
+}
@@ -0,0 +1,420 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// reservationPlugin keeps jobs off GPUs reserved by others for a window
+// their runtime would overlap, and confines jobs that name a reservation
+// to its GPUs while the window is open. Jobs without a MaxRuntime are
+// treated as running forever.
+type reservationPlugin struct {
+	allocator *Allocator
+}
+
+// reservationState records the request's own reservation and the GPUs
+// held back from it
+type reservationState struct {
+	own     *models.Reservation
+	blocked map[string]bool
+}
+
+const reservationStateKey = "Reservation/state"
+
+func newReservationPlugin(a *Allocator) (Plugin, error) {
+	return &reservationPlugin{allocator: a}, nil
+}
+
+func (p *reservationPlugin) Name() string { return PluginReservation }
+
+// PreFilter resolves the request's reservation and the GPUs of every
+// other reservation overlapping the request's runtime
+func (p *reservationPlugin) PreFilter(ctx context.Context, state *CycleState, request *models.AllocationRequest) *Status {
+	reservations, err := p.allocator.storage.ListReservations(ctx)
+	if err != nil {
+		return NewInsufficientStatus("failed to list reservations: %v", err)
+	}
+
+	now := time.Now()
+	var end time.Time
+	if request.MaxRuntime > 0 {
+		end = now.Add(request.MaxRuntime)
+	}
+
+	reservationState := &reservationState{blocked: make(map[string]bool)}
+	for _, reservation := range reservations {
+		if reservation.ID == request.ReservationID {
+			reservationState.own = reservation
+			continue
+		}
+		if reservation.Overlaps(now, end) {
+			for _, gpuID := range reservation.GPUIDs {
+				reservationState.blocked[gpuID] = true
+			}
+		}
+	}
+
+	if request.ReservationID != "" {
+		own := reservationState.own
+		switch {
+		case own == nil:
+			return NewUnschedulableStatus("reservation %s does not exist", request.ReservationID)
+		case own.TenantID != request.TenantID:
+			return NewUnschedulableStatus("reservation %s belongs to another tenant", own.ID)
+		case own.PhaseAt(now) == models.ReservationScheduled:
+			return NewUnschedulableStatus("waiting for reservation %s to start at %s",
+				own.ID, own.StartTime.Format(time.RFC3339))
+		case own.PhaseAt(now) == models.ReservationEnded:
+			return NewUnschedulableStatus("reservation %s ended at %s",
+				own.ID, own.EndTime.Format(time.RFC3339))
+		}
+
+		// A full reservation keeps the job pending rather than starving
+		// the queue or preempting jobs outside the reservation
+		free := 0
+		for _, gpuID := range own.GPUIDs {
+			gpu, err := p.allocator.storage.GetGPU(ctx, gpuID)
+			if err == nil && candidateGPU(request, gpu) {
+				free++
+			}
+		}
+		if free < request.GPUCount {
+			return NewUnschedulableStatus("reservation %s has %d of %d GPUs free",
+				own.ID, free, request.GPUCount)
+		}
+	}
+
+	state.Write(reservationStateKey, reservationState)
+	return nil
+}
+
+func (p *reservationPlugin) Filter(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status {
+	value, ok := state.Read(reservationStateKey)
+	if !ok {
+		return nil
+	}
+	reservationState := value.(*reservationState)
+
+	before := len(info.Available)
+	kept := make([]*models.GPU, 0, before)
+	for _, gpu := range info.Available {
+		if reservationState.blocked[gpu.ID] {
+			continue
+		}
+		if reservationState.own != nil && !reservationState.own.Holds(gpu.ID) {
+			continue
+		}
+		kept = append(kept, gpu)
+	}
+	info.Available = kept
+
+	if len(kept) >= request.GPUCount {
+		return nil
+	}
+	// Freeing other jobs' GPUs cannot make room, so the job waits
+	// without blocking the queue
+	if reservationState.own != nil {
+		return NewUnschedulableStatus("reservation %s has no node with %d free GPUs",
+			reservationState.own.ID, request.GPUCount)
+	}
+
+	// Freeing GPUs on the node can only make room if enough of them lie
+	// outside the overlapping reservations
+	unreserved := 0
+	for _, gpu := range info.GPUs {
+		if !reservationState.blocked[gpu.ID] {
+			unreserved++
+		}
+	}
+	if unreserved < request.GPUCount && len(info.GPUs) >= request.GPUCount {
+		return NewUnschedulableStatus("GPUs are reserved for a window the job's runtime overlaps")
+	}
+	if before >= request.GPUCount {
+		return NewInsufficientStatus("free GPUs on node %s are reserved for a window the job's runtime overlaps",
+			info.Node.ID)
+	}
+	return nil
+}
+
+// CreateReservation sets GPUs aside for the reservation's window. GPUs
+// are taken from the nodes with the most eligible GPUs: healthy ones
+// matching the node and model restrictions, not held by an overlapping
+// reservation, and idle or running a job bound to finish before the
+// window starts.
+func (s *Scheduler) CreateReservation(ctx context.Context, reservation *models.Reservation) error {
+	if err := reservation.Validate(); err != nil {
+		return fmt.Errorf("reservation validation failed: %w", err)
+	}
+	if _, err := s.storage.GetTenant(ctx, reservation.TenantID); err != nil {
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	gpuIDs, err := s.reservableGPUs(ctx, reservation)
+	if err != nil {
+		return err
+	}
+	if len(gpuIDs) < reservation.GPUCount {
+		return fmt.Errorf("%w: %d of %d GPUs free between %s and %s",
+			utils.ErrReservationConflict, len(gpuIDs), reservation.GPUCount,
+			reservation.StartTime.Format(time.RFC3339), reservation.EndTime.Format(time.RFC3339))
+	}
+
+	if reservation.ID == "" {
+		reservation.ID = generateReservationID()
+	}
+	reservation.GPUIDs = gpuIDs[:reservation.GPUCount]
+
+	if err := s.storage.CreateReservation(ctx, reservation); err != nil {
+		return fmt.Errorf("failed to create reservation: %w", err)
+	}
+	reservation.Phase = reservation.PhaseAt(time.Now())
+
+	utils.Info("Reservation created",
+		zap.String("reservation_id", reservation.ID),
+		zap.String("tenant_id", reservation.TenantID),
+		zap.Strings("gpu_ids", reservation.GPUIDs))
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:     models.EventReservationCreated,
+		Reason:   "tenant_request",
+		TenantID: reservation.TenantID,
+		Message: fmt.Sprintf("Reserved %d GPUs from %s to %s", reservation.GPUCount,
+			reservation.StartTime.Format(time.RFC3339), reservation.EndTime.Format(time.RFC3339)),
+		Metadata: map[string]string{
+			"reservation_id": reservation.ID,
+			"gpu_count":      strconv.Itoa(reservation.GPUCount),
+		},
+	})
+
+	return nil
+}
+
+// reservableGPUs returns the GPUs eligible for a reservation, grouped by
+// node with the nodes holding the most eligible GPUs first
+func (s *Scheduler) reservableGPUs(ctx context.Context, reservation *models.Reservation) ([]string, error) {
+	reservations, err := s.storage.ListReservations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reservations: %w", err)
+	}
+	held := make(map[string]bool)
+	for _, other := range reservations {
+		if other.ID != reservation.ID && other.Overlaps(reservation.StartTime, reservation.EndTime) {
+			for _, gpuID := range other.GPUIDs {
+				held[gpuID] = true
+			}
+		}
+	}
+
+	allocations, err := s.storage.ListActiveAllocations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active allocations: %w", err)
+	}
+	// Jobs without a deadline may hold their GPUs indefinitely
+	for _, alloc := range allocations {
+		job, err := s.storage.GetJob(ctx, alloc.JobID)
+		if err == nil && hasDeadline(job) && !alloc.Deadline().After(reservation.StartTime) {
+			continue
+		}
+		for _, gpuID := range alloc.GPUIDs {
+			held[gpuID] = true
+		}
+	}
+
+	nodes, err := s.storage.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var eligible [][]string
+	for _, node := range nodes {
+		gpus, err := s.storage.ListGPUsByNode(ctx, node.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GPUs on node %s: %w", node.ID, err)
+		}
+		var ids []string
+		for _, gpu := range gpus {
+			if gpu.Health == models.HealthUnhealthy || held[gpu.ID] || !reservation.Matches(gpu) {
+				continue
+			}
+			ids = append(ids, gpu.ID)
+		}
+		if len(ids) > 0 {
+			eligible = append(eligible, ids)
+		}
+	}
+
+	sort.SliceStable(eligible, func(i, j int) bool {
+		return len(eligible[i]) > len(eligible[j])
+	})
+
+	var gpuIDs []string
+	for _, ids := range eligible {
+		gpuIDs = append(gpuIDs, ids...)
+	}
+	return gpuIDs, nil
+}
+
+// GetReservation returns a reservation with its current phase
+func (s *Scheduler) GetReservation(ctx context.Context, reservationID string) (*models.Reservation, error) {
+	reservation, err := s.storage.GetReservation(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	reservation.Phase = reservation.PhaseAt(time.Now())
+	return reservation, nil
+}
+
+// ListReservations returns reservations ordered by start time, limited
+// to one tenant unless tenantID is empty
+func (s *Scheduler) ListReservations(ctx context.Context, tenantID string) ([]*models.Reservation, error) {
+	reservations, err := s.storage.ListReservations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]*models.Reservation, 0, len(reservations))
+	for _, reservation := range reservations {
+		if tenantID != "" && reservation.TenantID != tenantID {
+			continue
+		}
+		reservation.Phase = reservation.PhaseAt(now)
+		result = append(result, reservation)
+	}
+	return result, nil
+}
+
+// DeleteReservation releases a reservation's GPUs. Jobs already running
+// on them keep their allocations; jobs still pending or waiting to run in
+// the reservation fail.
+func (s *Scheduler) DeleteReservation(ctx context.Context, reservationID string) error {
+	reservation, err := s.storage.GetReservation(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	if err := s.storage.DeleteReservation(ctx, reservationID); err != nil {
+		return fmt.Errorf("failed to delete reservation: %w", err)
+	}
+	s.failReservationJobs(ctx, map[string]*models.Reservation{reservationID: reservation}, "reservation_deleted",
+		time.Now(), func(reservation *models.Reservation) string {
+			return fmt.Sprintf("reservation %s was deleted before the job could run", reservation.ID)
+		})
+
+	utils.Info("Reservation deleted", zap.String("reservation_id", reservationID))
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:     models.EventReservationDeleted,
+		Reason:   "tenant_request",
+		TenantID: reservation.TenantID,
+		Message:  fmt.Sprintf("Reservation %s released %d GPUs", reservationID, len(reservation.GPUIDs)),
+		Metadata: map[string]string{
+			"reservation_id": reservationID,
+		},
+	})
+
+	return nil
+}
+
+// expireReservations deletes reservations whose window has closed. Jobs
+// still pending or waiting to run in one fail, since its GPUs are no
+// longer set aside for them. Jobs running in it were given a max runtime
+// ending with the window.
+func (s *Scheduler) expireReservations(ctx context.Context, now time.Time) {
+	reservations, err := s.storage.ListReservations(ctx)
+	if err != nil {
+		utils.Error("Failed to list reservations", zap.Error(err))
+		return
+	}
+
+	ended := make(map[string]*models.Reservation)
+	for _, reservation := range reservations {
+		if reservation.PhaseAt(now) == models.ReservationEnded {
+			ended[reservation.ID] = reservation
+		}
+	}
+	if len(ended) == 0 {
+		return
+	}
+
+	s.failReservationJobs(ctx, ended, "reservation_ended", now, func(reservation *models.Reservation) string {
+		return fmt.Sprintf("reservation %s ended at %s before the job could run",
+			reservation.ID, reservation.EndTime.Format(time.RFC3339))
+	})
+
+	for _, reservation := range ended {
+		if err := s.storage.DeleteReservation(ctx, reservation.ID); err != nil {
+			utils.Error("Failed to delete expired reservation",
+				zap.String("reservation_id", reservation.ID),
+				zap.Error(err))
+			continue
+		}
+
+		utils.Info("Reservation expired", zap.String("reservation_id", reservation.ID))
+
+		recordEvent(ctx, s.storage, &models.Event{
+			Type:     models.EventReservationExpired,
+			Reason:   "window_closed",
+			TenantID: reservation.TenantID,
+			Message: fmt.Sprintf("Reservation %s ended at %s and released %d GPUs", reservation.ID,
+				reservation.EndTime.Format(time.RFC3339), len(reservation.GPUIDs)),
+			Metadata: map[string]string{
+				"reservation_id": reservation.ID,
+			},
+		})
+	}
+}
+
+// failReservationJobs fails the jobs still pending or waiting to run in
+// one of the reservations
+func (s *Scheduler) failReservationJobs(ctx context.Context, reservations map[string]*models.Reservation,
+	reason string, now time.Time, message func(*models.Reservation) string) {
+	for _, state := range []models.JobState{models.JobStatePending, models.JobStateWaiting} {
+		jobs, err := s.storage.ListJobsByState(ctx, state)
+		if err != nil {
+			utils.Error("Failed to list jobs", zap.String("state", string(state)), zap.Error(err))
+			continue
+		}
+		for _, job := range jobs {
+			if reservation, ok := reservations[job.ReservationID]; ok {
+				s.failReservationJob(ctx, job, reservation, reason, message(reservation), now)
+			}
+		}
+	}
+}
+
+// failReservationJob fails a job that never ran in its reservation
+func (s *Scheduler) failReservationJob(ctx context.Context, job *models.Job, reservation *models.Reservation,
+	reason, message string, now time.Time) {
+	s.jobPartition(job).queue.Remove(job.ID)
+	job.State = models.JobStateFailed
+	job.FailureReason = message
+	job.PendingReason = ""
+	job.CompletedAt = timePtr(now)
+	if err := s.storage.UpdateJob(ctx, job); err != nil {
+		utils.Error("Failed to update job", zap.String("job_id", job.ID), zap.Error(err))
+		return
+	}
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:     models.EventJobFailed,
+		Reason:   reason,
+		JobID:    job.ID,
+		TenantID: job.TenantID,
+		Message:  job.FailureReason,
+		Metadata: map[string]string{
+			"reservation_id": reservation.ID,
+		},
+	})
+}
+
+func generateReservationID() string {
+	return fmt.Sprintf("resv-%d", time.Now().UnixNano())
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reserve(t *testing.T, scheduler *Scheduler, tenantID string, gpus int, start time.Time, window time.Duration) *models.Reservation {
+	t.Helper()
+	reservation := &models.Reservation{TenantID: tenantID, GPUCount: gpus, StartTime: start, EndTime: start.Add(window)}
+	require.NoError(t, scheduler.CreateReservation(context.Background(), reservation))
+	return reservation
+}
+
+// jobGPUs returns the GPUs held by a job's active allocations
+func jobGPUs(scheduler *Scheduler, jobID string) []string {
+	var gpus []string
+	for _, alloc := range scheduler.activeAllocations(context.Background(), jobID) {
+		gpus = append(gpus, alloc.GPUIDs...)
+	}
+	return gpus
+}
+
+func TestActiveReservationConfinesJobs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a"), withTenants("tenant-1", "tenant-2"))
+
+	reservation := reserve(t, scheduler, "tenant-2", 2, time.Now().Add(-time.Minute), time.Hour)
+
+	wide := &models.Job{ID: "wide", TenantID: "tenant-1", GPUCount: 4, Priority: 300}
+	small := &models.Job{ID: "small", TenantID: "tenant-1", GPUCount: 2, Priority: 200}
+	mine := &models.Job{ID: "mine", TenantID: "tenant-2", GPUCount: 2, Priority: 100, ReservationID: reservation.ID}
+	extra := &models.Job{ID: "extra", TenantID: "tenant-2", GPUCount: 1, Priority: 50, ReservationID: reservation.ID}
+	for _, job := range []*models.Job{wide, small, mine, extra} {
+		require.NoError(t, scheduler.SubmitJob(ctx, job))
+	}
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	// Reserved GPUs only go to jobs naming the reservation, without
+	// blocking the rest of the queue
+	assert.Equal(t, models.JobStatePending, wide.State)
+	assert.Contains(t, wide.PendingReason, "reserved")
+
+	assert.Equal(t, models.JobStateRunning, small.State)
+	for _, gpuID := range jobGPUs(scheduler, "small") {
+		assert.False(t, reservation.Holds(gpuID))
+	}
+
+	assert.Equal(t, models.JobStateRunning, mine.State)
+	for _, gpuID := range jobGPUs(scheduler, "mine") {
+		assert.True(t, reservation.Holds(gpuID))
+	}
+
+	assert.Equal(t, models.JobStatePending, extra.State)
+	assert.Contains(t, extra.PendingReason, reservation.ID)
+}
+
+func TestReservationWindowUsesMaxRuntime(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a"), withTenants("tenant-1", "tenant-2"))
+
+	reserve(t, scheduler, "tenant-2", 4, time.Now().Add(time.Hour), time.Hour)
+
+	// Without a max runtime the job could still be running at the start
+	unbounded := &models.Job{ID: "unbounded", TenantID: "tenant-1", GPUCount: 4, Priority: 200}
+	bounded := &models.Job{ID: "bounded", TenantID: "tenant-1", GPUCount: 4, Priority: 100, MaxRuntime: 30 * time.Minute}
+	require.NoError(t, scheduler.SubmitJob(ctx, unbounded))
+	require.NoError(t, scheduler.SubmitJob(ctx, bounded))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePending, unbounded.State)
+	assert.Equal(t, models.JobStateRunning, bounded.State)
+}
+
+func TestReservedNodeLeavesPreemptionToOthers(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a", "node-b"),
+		withTenants("tenant-1", "tenant-2"), withPreemption())
+
+	reservation := &models.Reservation{TenantID: "tenant-2", GPUCount: 4, NodeIDs: []string{"node-a"},
+		StartTime: time.Now().Add(time.Hour), EndTime: time.Now().Add(2 * time.Hour)}
+	require.NoError(t, scheduler.CreateReservation(ctx, reservation))
+
+	low := &models.Job{ID: "low", TenantID: "tenant-1", GPUCount: 4, Priority: 10}
+	require.NoError(t, scheduler.SubmitJob(ctx, low))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, low.State)
+
+	// node-a is reserved for a window the job would overlap, but node-b
+	// frees up once the low priority job is preempted
+	high := &models.Job{ID: "high", TenantID: "tenant-1", GPUCount: 4, Priority: 900}
+	require.NoError(t, scheduler.SubmitJob(ctx, high))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePreempted, low.State)
+	require.Equal(t, models.JobStateRunning, high.State)
+	assert.Equal(t, "node-b", scheduler.primaryAllocation(ctx, "high").NodeID)
+}
+
+func TestPreemptionFreesGPUsOutsideReservation(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a"),
+		withTenants("tenant-1", "tenant-2"), withPreemption())
+
+	reservation := reserve(t, scheduler, "tenant-2", 2, time.Now().Add(time.Hour), time.Hour)
+	low := &models.Job{ID: "low", TenantID: "tenant-1", GPUCount: 2, Priority: 10}
+	require.NoError(t, scheduler.SubmitJob(ctx, low))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, low.State)
+
+	// Only reserved GPUs are idle, but the low priority job holds the
+	// rest of the node
+	high := &models.Job{ID: "high", TenantID: "tenant-1", GPUCount: 2, Priority: 900}
+	require.NoError(t, scheduler.SubmitJob(ctx, high))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStatePreempted, low.State)
+	require.Equal(t, models.JobStateRunning, high.State)
+	for _, gpuID := range jobGPUs(scheduler, "high") {
+		assert.False(t, reservation.Holds(gpuID))
+	}
+}
+
+func TestReservationJobWaitsForWindow(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a"), withTenants("tenant-1", "tenant-2"))
+
+	reservation := reserve(t, scheduler, "tenant-2", 2, time.Now().Add(time.Hour), time.Hour)
+
+	early := &models.Job{ID: "early", TenantID: "tenant-2", GPUCount: 2, ReservationID: reservation.ID}
+	require.NoError(t, scheduler.SubmitJob(ctx, early))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStatePending, early.State)
+	assert.Contains(t, early.PendingReason, "waiting for reservation")
+
+	reservation.StartTime = time.Now().Add(-time.Minute)
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateRunning, early.State)
+
+	// Other tenants cannot name the reservation
+	intruder := &models.Job{ID: "intruder", TenantID: "tenant-1", GPUCount: 1, ReservationID: reservation.ID}
+	err := scheduler.SubmitJob(ctx, intruder)
+	assert.True(t, errors.Is(err, utils.ErrReservationNotFound))
+}
+
+func TestReservationJobsEndWithWindow(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(4, "node-a"), withTenants("tenant-1", "tenant-2"))
+	scheduler.config.Runtime.MaxExtensions = 3
+
+	reservation := reserve(t, scheduler, "tenant-2", 2, time.Now().Add(-time.Minute), 30*time.Minute)
+
+	// The job's allocation is planned to end with the window
+	mine := &models.Job{ID: "mine", TenantID: "tenant-2", GPUCount: 2, ReservationID: reservation.ID}
+	late := &models.Job{ID: "late", TenantID: "tenant-2", GPUCount: 1, ReservationID: reservation.ID}
+	require.NoError(t, scheduler.SubmitJob(ctx, mine))
+	require.NoError(t, scheduler.SubmitJob(ctx, late))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, mine.State)
+	require.Equal(t, models.JobStatePending, late.State)
+
+	alloc := scheduler.primaryAllocation(ctx, "mine")
+	assert.WithinDuration(t, reservation.EndTime, alloc.Deadline(), time.Second)
+	assert.InDelta(t, float64(29*time.Minute), float64(alloc.PlannedDuration), float64(time.Second))
+	assert.Zero(t, mine.MaxRuntime)
+	status, err := scheduler.GetJobStatus(ctx, "mine")
+	require.NoError(t, err)
+	require.NotNil(t, status.RuntimeDeadline)
+	assert.WithinDuration(t, reservation.EndTime, *status.RuntimeDeadline, time.Second)
+	_, err = scheduler.ExtendJob(ctx, "mine", time.Minute)
+	assert.ErrorIs(t, err, utils.ErrExtensionDenied)
+	assert.Contains(t, err.Error(), "reservation")
+
+	// Once the window closes the reservation is dropped and the job that
+	// never ran fails
+	reservation.StartTime = time.Now().Add(-2 * time.Hour)
+	reservation.EndTime = time.Now().Add(-time.Second)
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStateFailed, late.State)
+	assert.Contains(t, late.FailureReason, reservation.ID)
+	assert.Zero(t, scheduler.queue.Size())
+	_, err = repo.GetReservation(ctx, reservation.ID)
+	assert.ErrorIs(t, err, utils.ErrReservationNotFound)
+	events, _ := repo.ListEvents(ctx, models.EventFilter{Type: models.EventReservationExpired})
+	assert.Len(t, events, 1)
+
+	// The running job is timed out at the end of the window
+	alloc.AllocatedAt = time.Now().Add(-time.Hour)
+	scheduler.enforceRuntimeLimits(ctx)
+	assert.Equal(t, models.JobStateFailed, mine.State)
+	assert.Contains(t, mine.FailureReason, "exceeded max runtime")
+}
+
+func TestDeleteReservationFailsItsPendingJobs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(4, "node-a"), withTenants("tenant-1", "tenant-2"))
+
+	reservation := reserve(t, scheduler, "tenant-2", 2, time.Now().Add(time.Hour), time.Hour)
+	early := &models.Job{ID: "early", TenantID: "tenant-2", GPUCount: 2, ReservationID: reservation.ID}
+	require.NoError(t, scheduler.SubmitJob(ctx, early))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStatePending, early.State)
+
+	// The job would otherwise wait for a reservation that no longer exists
+	require.NoError(t, scheduler.DeleteReservation(ctx, reservation.ID))
+	assert.Equal(t, models.JobStateFailed, early.State)
+	assert.Contains(t, early.FailureReason, "deleted")
+	assert.Zero(t, scheduler.queue.Size())
+	events, _ := repo.ListEvents(ctx, models.EventFilter{Type: models.EventJobFailed, JobID: "early"})
+	require.Len(t, events, 1)
+	assert.Equal(t, "reservation_deleted", events[0].Reason)
+}
+
+func TestSubmitToEndedReservationFails(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a"), withTenants("tenant-1", "tenant-2"))
+
+	reservation := reserve(t, scheduler, "tenant-2", 2, time.Now().Add(-2*time.Hour), time.Hour)
+
+	err := scheduler.SubmitJob(ctx, &models.Job{ID: "late", TenantID: "tenant-2", GPUCount: 1, ReservationID: reservation.ID})
+	assert.ErrorIs(t, err, utils.ErrReservationEnded)
+}
+
+func TestCreateReservationPicksFreeGPUs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a", "node-b"), withTenants("tenant-1", "tenant-2"))
+
+	busy := &models.Job{ID: "busy", TenantID: "tenant-1", GPUCount: 2}
+	require.NoError(t, scheduler.SubmitJob(ctx, busy))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, "node-a", scheduler.primaryAllocation(ctx, "busy").NodeID)
+
+	// The node with the most free GPUs is used first
+	start := time.Now().Add(time.Hour)
+	first := reserve(t, scheduler, "tenant-2", 4, start, time.Hour)
+	assert.Equal(t, []string{"node-b-gpu-0", "node-b-gpu-1", "node-b-gpu-2", "node-b-gpu-3"}, first.GPUIDs)
+	assert.Equal(t, models.ReservationScheduled, first.Phase)
+
+	// Only the two idle GPUs on node-a are left for an overlapping window
+	overlapping := &models.Reservation{TenantID: "tenant-2", GPUCount: 4,
+		StartTime: start.Add(30 * time.Minute), EndTime: start.Add(2 * time.Hour)}
+	err := scheduler.CreateReservation(ctx, overlapping)
+	assert.True(t, errors.Is(err, utils.ErrReservationConflict))
+
+	h100 := &models.Reservation{TenantID: "tenant-2", GPUCount: 1, GPUModel: models.GPUModel("H100"),
+		StartTime: start, EndTime: start.Add(time.Hour)}
+	err = scheduler.CreateReservation(ctx, h100)
+	assert.True(t, errors.Is(err, utils.ErrReservationConflict))
+
+	// Deleting the first reservation frees node-b for the window
+	require.NoError(t, scheduler.DeleteReservation(ctx, first.ID))
+	require.NoError(t, scheduler.CreateReservation(ctx, overlapping))
+
+	reservations, err := scheduler.ListReservations(ctx, "tenant-2")
+	require.NoError(t, err)
+	assert.Len(t, reservations, 1)
+
+	_, err = scheduler.GetReservation(ctx, first.ID)
+	assert.True(t, utils.IsNotFound(err))
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// FailJob records that the current attempt of a running job failed,
+// frees its resources, and requeues the job if its retry policy covers
+// the failure class. Otherwise the job ends failed.
+func (s *Scheduler) FailJob(ctx context.Context, jobID string, class models.FailureClass, reason string, exitCode int) error {
+	if !class.IsValid() {
+		return fmt.Errorf("unknown failure class %s", class)
+	}
+
+	job, err := s.storage.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.State != models.JobStateRunning {
+		return fmt.Errorf("%w: cannot fail job in state %s", utils.ErrInvalidJobState, job.State)
+	}
+
+	return s.failAttempt(ctx, job, class, reason, exitCode)
+}
+
+// failAttempt ends the current attempt of a running job
+func (s *Scheduler) failAttempt(ctx context.Context, job *models.Job, class models.FailureClass, reason string, exitCode int) error {
+	attempt := s.newAttempt(ctx, job, class, reason, exitCode)
+
+	if err := s.freeJobResources(ctx, job); err != nil {
+		utils.Error("Failed to free job resources", zap.String("job_id", job.ID), zap.Error(err))
+	}
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:     models.EventJobFailed,
+		Reason:   string(class),
+		JobID:    job.ID,
+		TenantID: job.TenantID,
+		NodeID:   attempt.NodeID,
+		Message:  reason,
+		Metadata: map[string]string{
+			"attempt":   strconv.Itoa(attempt.Attempt),
+			"exit_code": strconv.Itoa(exitCode),
+		},
+	})
+
+	return s.retryOrFinish(ctx, job, attempt, models.JobStateFailed)
+}
+
+// newAttempt describes the attempt a running job is about to end. It
+// must be called while the job still holds its allocations.
+func (s *Scheduler) newAttempt(ctx context.Context, job *models.Job, class models.FailureClass, reason string, exitCode int) models.JobAttempt {
+	attempt := models.JobAttempt{
+		Attempt:      len(job.Attempts) + 1,
+		StartedAt:    job.StartedAt,
+		EndedAt:      time.Now(),
+		FailureClass: class,
+		Reason:       reason,
+		ExitCode:     exitCode,
+	}
+	if primary := s.primaryAllocation(ctx, job.ID); primary != nil {
+		attempt.NodeID = primary.NodeID
+	}
+	return attempt
+}
+
+// retryOrFinish adds an ended attempt to the job's history. A job its
+// policy retries waits out the backoff before it is queued again, and
+// will avoid the node it just failed on; any other job moves to final.
+func (s *Scheduler) retryOrFinish(ctx context.Context, job *models.Job, attempt models.JobAttempt, final models.JobState) error {
+	job.Attempts = append(job.Attempts, attempt)
+	job.FailureReason = attempt.Reason
+
+	if !job.RetryPolicy.ShouldRetry(attempt.FailureClass, len(job.Attempts)) {
+		job.State = final
+		if final == models.JobStateFailed {
+			job.CompletedAt = timePtr(time.Now())
+		}
+		return s.storage.UpdateJob(ctx, job)
+	}
+
+	// Elastic jobs start over at their minimum
+	if job.IsElastic() && job.GPUCount > job.MinGPUs {
+		job.CPUCores = job.CPUCores * job.MinGPUs / job.GPUCount
+		job.MemoryMB = job.MemoryMB * int64(job.MinGPUs) / int64(job.GPUCount)
+		job.GPUCount = job.MinGPUs
+	}
+
+	delay := job.RetryPolicy.Backoff(len(job.Attempts))
+	retryAt := time.Now().Add(delay)
+	job.State = models.JobStateWaiting
+	job.RetryAt = &retryAt
+	job.PendingReason = fmt.Sprintf("retrying after %s, attempt %d of %d",
+		attempt.FailureClass, len(job.Attempts)+1, job.RetryPolicy.MaxAttempts)
+
+	if err := s.storage.UpdateJob(ctx, job); err != nil {
+		return err
+	}
+
+	utils.Info("Job scheduled for retry",
+		zap.String("job_id", job.ID),
+		zap.String("failure_class", string(attempt.FailureClass)),
+		zap.Duration("backoff", delay))
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:     models.EventJobRetry,
+		Reason:   string(attempt.FailureClass),
+		JobID:    job.ID,
+		TenantID: job.TenantID,
+		NodeID:   attempt.NodeID,
+		Message: fmt.Sprintf("Retrying in %s, attempt %d of %d",
+			delay, len(job.Attempts)+1, job.RetryPolicy.MaxAttempts),
+		Metadata: map[string]string{
+			"attempt":  strconv.Itoa(len(job.Attempts) + 1),
+			"retry_at": retryAt.Format(time.RFC3339),
+		},
+	})
+
+	return nil
+}
+
+// avoidNodes returns the node a retried job last failed on
+func avoidNodes(job *models.Job) []string {
+	if len(job.Attempts) == 0 {
+		return nil
+	}
+	if node := job.Attempts[len(job.Attempts)-1].NodeID; node != "" {
+		return []string{node}
+	}
+	return nil
+}
+
+// detectNodeFailures fails the current attempt of running jobs whose
+// node went offline or one of whose GPUs became unhealthy
+func (s *Scheduler) detectNodeFailures(ctx context.Context) {
+	running, err := s.storage.ListJobsByState(ctx, models.JobStateRunning)
+	if err != nil {
+		utils.Error("Failed to list running jobs", zap.Error(err))
+		return
+	}
+
+	for _, job := range running {
+		reason := s.allocationFault(ctx, job)
+		if reason == "" {
+			continue
+		}
+
+		utils.Info("Job lost its node", zap.String("job_id", job.ID), zap.String("reason", reason))
+		if err := s.failAttempt(ctx, job, models.FailureNodeFailure, reason, 0); err != nil {
+			utils.Error("Failed to update failed job", zap.String("job_id", job.ID), zap.Error(err))
+		}
+	}
+}
+
+// allocationFault describes why a running job's allocations can no
+// longer run it, or returns an empty string
+func (s *Scheduler) allocationFault(ctx context.Context, job *models.Job) string {
+	for _, alloc := range s.activeAllocations(ctx, job.ID) {
+		node, err := s.storage.GetNode(ctx, alloc.NodeID)
+		if err != nil {
+			continue
+		}
+		if !node.Online {
+			return fmt.Sprintf("node %s went offline", node.ID)
+		}
+
+		for _, gpuID := range alloc.GPUIDs {
+			gpu, err := s.storage.GetGPU(ctx, gpuID)
+			if err != nil {
+				continue
+			}
+			if gpu.Health == models.HealthUnhealthy {
+				return fmt.Sprintf("GPU %s on node %s is unhealthy", gpu.ID, node.ID)
+			}
+		}
+	}
+	return ""
+}
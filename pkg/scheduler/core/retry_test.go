@@ -0,0 +1,186 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func retriedJob(id string, priority int, policy *models.RetryPolicy) *models.Job {
+	return &models.Job{ID: id, TenantID: "tenant-1", GPUCount: 2, Priority: priority, RetryPolicy: policy}
+}
+
+// skipBackoff lets a retried job be released on the next cycle
+func skipBackoff(job *models.Job) {
+	past := time.Now().Add(-time.Second)
+	job.RetryAt = &past
+}
+
+func TestFailedJobRetriesOnAnotherNode(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(4, "node-a", "node-b"), withPreemption())
+
+	job := retriedJob("train", 100, &models.RetryPolicy{MaxAttempts: 3, BackoffSec: 60})
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, "node-a", scheduler.primaryAllocation(ctx, "train").NodeID)
+
+	require.NoError(t, scheduler.FailJob(ctx, "train", models.FailureNodeFailure, "agent stopped responding", 0))
+
+	assert.Equal(t, models.JobStateWaiting, job.State)
+	require.NotNil(t, job.RetryAt)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *job.RetryAt, 5*time.Second)
+	require.Len(t, job.Attempts, 1)
+	assert.Equal(t, "node-a", job.Attempts[0].NodeID)
+	assert.Equal(t, 4, repo.nodes["node-a"].AvailableGPUs)
+
+	tenant, _ := repo.GetTenant(ctx, "tenant-1")
+	assert.Equal(t, 0, tenant.CurrentGPUs)
+
+	// The job sits out its backoff
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateWaiting, job.State)
+
+	skipBackoff(job)
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateRunning, job.State)
+	assert.Equal(t, "node-b", scheduler.primaryAllocation(ctx, "train").NodeID)
+	assert.Nil(t, job.RetryAt)
+
+	status, err := scheduler.GetJobStatus(ctx, "train")
+	require.NoError(t, err)
+	assert.Equal(t, 2, status.Attempt)
+	assert.Len(t, status.Attempts, 1)
+
+	events, _ := repo.ListEvents(ctx, models.EventFilter{Type: models.EventJobRetry, JobID: "train"})
+	assert.Len(t, events, 1)
+}
+
+func TestRetryFallsBackToAvoidedNode(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a"), withPreemption())
+
+	job := retriedJob("train", 100, &models.RetryPolicy{MaxAttempts: 2})
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.NoError(t, scheduler.FailJob(ctx, "train", models.FailureNodeFailure, "agent restarted", 0))
+
+	skipBackoff(job)
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, models.JobStateRunning, job.State)
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a", "node-b"), withPreemption())
+
+	job := retriedJob("train", 100, &models.RetryPolicy{MaxAttempts: 2})
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		skipBackoff(job)
+		require.NoError(t, scheduler.schedulingCycle(ctx))
+		require.Equal(t, models.JobStateRunning, job.State)
+		require.NoError(t, scheduler.FailJob(ctx, "train", models.FailureNodeFailure, "node lost", 0))
+	}
+
+	assert.Equal(t, models.JobStateFailed, job.State)
+	assert.NotNil(t, job.CompletedAt)
+	assert.Len(t, job.Attempts, 2)
+	assert.Equal(t, "node lost", job.FailureReason)
+}
+
+func TestNonRetryableFailure(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(4, "node-a"), withPreemption())
+
+	job := retriedJob("train", 100, &models.RetryPolicy{MaxAttempts: 3})
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	require.NoError(t, scheduler.FailJob(ctx, "train", models.FailureNonZeroExit, "exit status 1", 1))
+	assert.Equal(t, models.JobStateFailed, job.State)
+	assert.Equal(t, 1, job.Attempts[0].ExitCode)
+
+	err := scheduler.FailJob(ctx, "train", models.FailureNodeFailure, "", 0)
+	assert.True(t, errors.Is(err, utils.ErrInvalidJobState))
+}
+
+func TestUnhealthyGPUFailsRunningJob(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(4, "node-a", "node-b"), withPreemption())
+
+	job := retriedJob("train", 100, &models.RetryPolicy{MaxAttempts: 2})
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	alloc := scheduler.primaryAllocation(ctx, "train")
+	repo.gpus[alloc.GPUIDs[0]].Health = models.HealthUnhealthy
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStateWaiting, job.State)
+	require.Len(t, job.Attempts, 1)
+	assert.Equal(t, models.FailureNodeFailure, job.Attempts[0].FailureClass)
+	assert.Contains(t, job.Attempts[0].Reason, "unhealthy")
+}
+
+func TestPreemptedJobRetries(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(4, "node-a"), withPreemption())
+
+	victim := &models.Job{ID: "batch", TenantID: "tenant-1", GPUCount: 4, Priority: 100,
+		RetryPolicy: &models.RetryPolicy{MaxAttempts: 3}}
+	require.NoError(t, scheduler.SubmitJob(ctx, victim))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, victim.State)
+
+	urgent := &models.Job{ID: "urgent", TenantID: "tenant-1", GPUCount: 4, Priority: 1000}
+	require.NoError(t, scheduler.SubmitJob(ctx, urgent))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	assert.Equal(t, models.JobStateRunning, urgent.State)
+	assert.Equal(t, models.JobStateWaiting, victim.State)
+	require.Len(t, victim.Attempts, 1)
+	assert.Equal(t, models.FailurePreemption, victim.Attempts[0].FailureClass)
+
+	tenant, _ := repo.GetTenant(ctx, "tenant-1")
+	assert.Equal(t, 4, tenant.CurrentGPUs)
+}
+
+func TestPreemptingRetriedJobFreesOnlyItsCurrentAllocation(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(4, "node-a"), withPreemption())
+
+	victim := &models.Job{ID: "batch", TenantID: "tenant-1", GPUCount: 4, CPUCores: 8, MemoryMB: 16000,
+		Priority: 100, RetryPolicy: &models.RetryPolicy{MaxAttempts: 3}}
+	require.NoError(t, scheduler.SubmitJob(ctx, victim))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	first := scheduler.primaryAllocation(ctx, "batch")
+	require.NoError(t, scheduler.FailJob(ctx, "batch", models.FailureNodeFailure, "agent restarted", 0))
+	firstState := repo.allocations[first.ID].State
+
+	skipBackoff(victim)
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, victim.State)
+	require.Len(t, victim.Attempts, 1)
+
+	urgent := &models.Job{ID: "urgent", TenantID: "tenant-1", GPUCount: 4, CPUCores: 8, MemoryMB: 16000, Priority: 1000}
+	require.NoError(t, scheduler.SubmitJob(ctx, urgent))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, urgent.State)
+
+	// Only the urgent job holds resources; the first attempt's allocation
+	// was freed once and is left as it was
+	node := repo.nodes["node-a"]
+	assert.Equal(t, 56, node.AvailableCPUCores)
+	assert.Equal(t, int64(496000), node.AvailableMemoryMB)
+	assert.Equal(t, 0, node.AvailableGPUs)
+	assert.Equal(t, firstState, repo.allocations[first.ID].State)
+	assert.NotEqual(t, models.AllocationPreempted, repo.allocations[first.ID].State)
+}
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// hasDeadline returns true if the job's allocations must end by their
+// deadline: the job set a max runtime, or runs in a reservation whose
+// window ends
+func hasDeadline(job *models.Job) bool {
+	return job.MaxRuntime > 0 || job.ReservationID != ""
+}
+
+// primaryAllocation returns the job's oldest active allocation, whose
+// deadline bounds the job's runtime
+func (s *Scheduler) primaryAllocation(ctx context.Context, jobID string) *models.Allocation {
+	allocations := s.activeAllocations(ctx, jobID)
+	if len(allocations) == 0 {
+		return nil
+	}
+	sort.SliceStable(allocations, func(i, j int) bool {
+		return allocations[i].AllocatedAt.Before(allocations[j].AllocatedAt)
+	})
+	return allocations[0]
+}
+
+// enforceRuntimeLimits walks running jobs with a deadline. A job is
+// warned ahead of its deadline, told to checkpoint and terminate at the
+// deadline, and failed with its resources freed once the grace period
+// has passed.
+func (s *Scheduler) enforceRuntimeLimits(ctx context.Context) {
+	running, err := s.storage.ListJobsByState(ctx, models.JobStateRunning)
+	if err != nil {
+		utils.Error("Failed to list running jobs", zap.Error(err))
+		return
+	}
+
+	warning := time.Duration(s.config.Runtime.WarningSec) * time.Second
+	grace := time.Duration(s.config.Runtime.GracePeriodSec) * time.Second
+	now := time.Now()
+
+	for _, job := range running {
+		if !hasDeadline(job) {
+			continue
+		}
+		alloc := s.primaryAllocation(ctx, job.ID)
+		if alloc == nil {
+			continue
+		}
+
+		deadline := alloc.Deadline()
+		switch {
+		case !now.Before(deadline.Add(grace)):
+			s.timeoutJob(ctx, job, alloc)
+
+		case !now.Before(deadline) && alloc.TerminateSignaledAt == nil:
+			signal := "terminate"
+			if job.CheckpointEnabled {
+				signal = "checkpoint"
+			}
+			alloc.TerminateSignaledAt = &now
+			s.updateRuntimeAllocation(ctx, alloc)
+
+			recordEvent(ctx, s.storage, &models.Event{
+				Type:         models.EventRuntimeExpired,
+				Reason:       signal,
+				JobID:        job.ID,
+				TenantID:     job.TenantID,
+				NodeID:       alloc.NodeID,
+				AllocationID: alloc.ID,
+				Message: fmt.Sprintf("Max runtime %s reached, %s before %s",
+					alloc.PlannedDuration, signal, deadline.Add(grace).Format(time.RFC3339)),
+				Metadata: map[string]string{
+					"signal":          signal,
+					"kill_at":         deadline.Add(grace).Format(time.RFC3339),
+					"checkpoint_path": job.CheckpointPath,
+				},
+			})
+
+		case !now.Before(deadline.Add(-warning)) && now.Before(deadline) && alloc.RuntimeWarnedAt == nil:
+			alloc.RuntimeWarnedAt = &now
+			s.updateRuntimeAllocation(ctx, alloc)
+
+			recordEvent(ctx, s.storage, &models.Event{
+				Type:         models.EventRuntimeWarning,
+				Reason:       "approaching_max_runtime",
+				JobID:        job.ID,
+				TenantID:     job.TenantID,
+				NodeID:       alloc.NodeID,
+				AllocationID: alloc.ID,
+				Message: fmt.Sprintf("Max runtime %s expires at %s",
+					alloc.PlannedDuration, deadline.Format(time.RFC3339)),
+				Metadata: map[string]string{
+					"deadline": deadline.Format(time.RFC3339),
+				},
+			})
+		}
+	}
+}
+
+// timeoutJob ends the attempt of a job that outlived its deadline and
+// grace period. The job fails unless its retry policy covers timeouts.
+func (s *Scheduler) timeoutJob(ctx context.Context, job *models.Job, alloc *models.Allocation) {
+	deadline := alloc.Deadline()
+	utils.Info("Job exceeded max runtime",
+		zap.String("job_id", job.ID),
+		zap.Duration("max_runtime", alloc.PlannedDuration))
+
+	reason := fmt.Sprintf("timeout: exceeded max runtime %s (deadline %s)",
+		alloc.PlannedDuration, deadline.Format(time.RFC3339))
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:     models.EventJobTimeout,
+		Reason:   "max_runtime_exceeded",
+		JobID:    job.ID,
+		TenantID: job.TenantID,
+		Message:  reason,
+	})
+
+	if err := s.failAttempt(ctx, job, models.FailureTimeout, reason, 0); err != nil {
+		utils.Error("Failed to update timed out job", zap.String("job_id", job.ID), zap.Error(err))
+	}
+}
+
+func (s *Scheduler) updateRuntimeAllocation(ctx context.Context, alloc *models.Allocation) {
+	if err := s.storage.UpdateAllocation(ctx, alloc); err != nil {
+		utils.Error("Failed to update allocation",
+			zap.String("allocation_id", alloc.ID),
+			zap.Error(err))
+	}
+}
+
+// ExtendJob pushes back the runtime deadline of a running job. Each
+// extension is counted in the job's allocations, up to the configured
+// limit, and clears any warning or terminate signal already sent. The
+// new deadline may not overlap a reservation holding the job's GPUs.
+func (s *Scheduler) ExtendJob(ctx context.Context, jobID string, extension time.Duration) (*models.Allocation, error) {
+	job, err := s.storage.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.State != models.JobStateRunning || !hasDeadline(job) {
+		return nil, fmt.Errorf("%w: job %s is not running with a max runtime", utils.ErrExtensionDenied, jobID)
+	}
+	if extension <= 0 {
+		return nil, fmt.Errorf("%w: extension must be positive", utils.ErrExtensionDenied)
+	}
+
+	primary := s.primaryAllocation(ctx, jobID)
+	if primary == nil {
+		return nil, fmt.Errorf("%w: job %s holds no allocation", utils.ErrExtensionDenied, jobID)
+	}
+	if primary.ExtendedCount >= s.config.Runtime.MaxExtensions {
+		return nil, fmt.Errorf("%w: job %s already extended %d times",
+			utils.ErrExtensionDenied, jobID, primary.ExtendedCount)
+	}
+	if part := s.jobPartition(job); part.maxRuntime() > 0 && primary.PlannedDuration+extension > part.maxRuntime() {
+		return nil, fmt.Errorf("%w: queue %s allows a max runtime of at most %s",
+			utils.ErrExtensionDenied, part.config.Name, part.maxRuntime())
+	}
+	allocations := s.activeAllocations(ctx, jobID)
+	if err := s.checkExtensionReservations(ctx, job, allocations, primary.Deadline().Add(extension)); err != nil {
+		return nil, err
+	}
+
+	for _, alloc := range allocations {
+		alloc.PlannedDuration += extension
+		alloc.ExtendedCount++
+		alloc.RuntimeWarnedAt = nil
+		alloc.TerminateSignaledAt = nil
+		if err := s.storage.UpdateAllocation(ctx, alloc); err != nil {
+			return nil, err
+		}
+		if alloc.ID == primary.ID {
+			primary = alloc
+		}
+	}
+
+	utils.Info("Job runtime extended",
+		zap.String("job_id", jobID),
+		zap.Duration("extension", extension),
+		zap.Time("deadline", primary.Deadline()))
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:         models.EventRuntimeExtended,
+		Reason:       "tenant_request",
+		JobID:        job.ID,
+		TenantID:     job.TenantID,
+		NodeID:       primary.NodeID,
+		AllocationID: primary.ID,
+		Message: fmt.Sprintf("Runtime extended by %s, new deadline %s",
+			extension, primary.Deadline().Format(time.RFC3339)),
+		Metadata: map[string]string{
+			"extended_count": strconv.Itoa(primary.ExtendedCount),
+		},
+	})
+
+	return primary, nil
+}
+
+// checkExtensionReservations denies an extension that would run the job
+// past the end of its own reservation, or into the window of another
+// reservation holding any of its GPUs
+func (s *Scheduler) checkExtensionReservations(ctx context.Context, job *models.Job, allocations []*models.Allocation, deadline time.Time) error {
+	reservations, err := s.storage.ListReservations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list reservations: %w", err)
+	}
+
+	now := time.Now()
+	for _, reservation := range reservations {
+		if reservation.ID == job.ReservationID {
+			if deadline.After(reservation.EndTime) {
+				return fmt.Errorf("%w: reservation %s ends at %s", utils.ErrExtensionDenied,
+					reservation.ID, reservation.EndTime.Format(time.RFC3339))
+			}
+			continue
+		}
+		if !reservation.Overlaps(now, deadline) {
+			continue
+		}
+		for _, alloc := range allocations {
+			for _, gpuID := range alloc.GPUIDs {
+				if reservation.Holds(gpuID) {
+					return fmt.Errorf("%w: GPU %s is reserved by %s from %s", utils.ErrExtensionDenied,
+						gpuID, reservation.ID, reservation.StartTime.Format(time.RFC3339))
+				}
+			}
+		}
+	}
+	return nil
+}
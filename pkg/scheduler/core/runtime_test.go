@@ -0,0 +1,173 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runtimeSetup warns jobs ten minutes before their max runtime, gives
+// them five minutes of grace and allows two extensions
+func runtimeSetup() []testOption {
+	return []testOption{
+		withNodes(8, "node-a"),
+		withConfig(func(config *utils.SchedulerConfig) {
+			config.Runtime = utils.RuntimeConfig{
+				WarningSec:     600,
+				GracePeriodSec: 300,
+				MaxExtensions:  2,
+			}
+		}),
+	}
+}
+
+// startTimedJob runs a job with a one hour max runtime and returns its
+// allocation
+func startTimedJob(t *testing.T, scheduler *Scheduler, job *models.Job) *models.Allocation {
+	t.Helper()
+	ctx := context.Background()
+	job.MaxRuntime = time.Hour
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	require.Equal(t, models.JobStateRunning, job.State)
+
+	alloc := scheduler.primaryAllocation(ctx, job.ID)
+	require.NotNil(t, alloc)
+	return alloc
+}
+
+func runtimeEvents(t *testing.T, repo *fakeRepository, eventType models.EventType, jobID string) []*models.Event {
+	t.Helper()
+	events, err := repo.ListEvents(context.Background(), models.EventFilter{Type: eventType, JobID: jobID})
+	require.NoError(t, err)
+	return events
+}
+
+func TestAllocationPlannedFromMaxRuntime(t *testing.T) {
+	scheduler, _ := newTestScheduler(t, runtimeSetup()...)
+
+	alloc := startTimedJob(t, scheduler, &models.Job{ID: "train", TenantID: "tenant-1", GPUCount: 2})
+	assert.Equal(t, time.Hour, alloc.PlannedDuration)
+
+	status, err := scheduler.GetJobStatus(context.Background(), "train")
+	require.NoError(t, err)
+	require.NotNil(t, status.RuntimeDeadline)
+	assert.Equal(t, alloc.Deadline(), *status.RuntimeDeadline)
+}
+
+func TestRuntimeWarningThenSignalThenTimeout(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, runtimeSetup()...)
+	job := &models.Job{ID: "train", TenantID: "tenant-1", GPUCount: 2, CheckpointEnabled: true}
+	alloc := startTimedJob(t, scheduler, job)
+
+	// Five minutes before the deadline the job is warned, once
+	alloc.AllocatedAt = time.Now().Add(-55 * time.Minute)
+	scheduler.enforceRuntimeLimits(ctx)
+	scheduler.enforceRuntimeLimits(ctx)
+	require.Len(t, runtimeEvents(t, repo, models.EventRuntimeWarning, "train"), 1)
+	assert.NotNil(t, alloc.RuntimeWarnedAt)
+	assert.Empty(t, runtimeEvents(t, repo, models.EventRuntimeExpired, "train"))
+
+	// Past the deadline it is told to checkpoint, once
+	alloc.AllocatedAt = time.Now().Add(-62 * time.Minute)
+	scheduler.enforceRuntimeLimits(ctx)
+	scheduler.enforceRuntimeLimits(ctx)
+	expired := runtimeEvents(t, repo, models.EventRuntimeExpired, "train")
+	require.Len(t, expired, 1)
+	assert.Equal(t, "checkpoint", expired[0].Reason)
+	assert.Equal(t, models.JobStateRunning, job.State)
+
+	// Once the grace period is over it fails and frees its GPUs
+	alloc.AllocatedAt = time.Now().Add(-66 * time.Minute)
+	scheduler.enforceRuntimeLimits(ctx)
+
+	assert.Equal(t, models.JobStateFailed, job.State)
+	assert.Contains(t, job.FailureReason, "exceeded max runtime")
+	assert.False(t, alloc.IsActive())
+	assert.Equal(t, 8, repo.nodes["node-a"].AvailableGPUs)
+	require.Len(t, runtimeEvents(t, repo, models.EventJobTimeout, "train"), 1)
+
+	status, err := scheduler.GetJobStatus(ctx, "train")
+	require.NoError(t, err)
+	assert.Equal(t, job.FailureReason, status.Message)
+}
+
+func TestRuntimeLimitIgnoresUnboundedJobs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, runtimeSetup()...)
+	job := &models.Job{ID: "serve", TenantID: "tenant-1", GPUCount: 1}
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	alloc := scheduler.primaryAllocation(ctx, "serve")
+	require.NotNil(t, alloc)
+	alloc.AllocatedAt = time.Now().Add(-48 * time.Hour)
+	scheduler.enforceRuntimeLimits(ctx)
+
+	assert.Equal(t, models.JobStateRunning, job.State)
+}
+
+func TestExtendJobPushesDeadline(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, runtimeSetup()...)
+	job := &models.Job{ID: "train", TenantID: "tenant-1", GPUCount: 2}
+	alloc := startTimedJob(t, scheduler, job)
+
+	// A job already told to terminate gets a fresh deadline
+	alloc.AllocatedAt = time.Now().Add(-62 * time.Minute)
+	scheduler.enforceRuntimeLimits(ctx)
+	require.NotNil(t, alloc.TerminateSignaledAt)
+
+	extended, err := scheduler.ExtendJob(ctx, "train", 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, extended.PlannedDuration)
+	assert.Equal(t, 1, extended.ExtendedCount)
+	assert.Nil(t, extended.TerminateSignaledAt)
+	require.Len(t, runtimeEvents(t, repo, models.EventRuntimeExtended, "train"), 1)
+
+	alloc.AllocatedAt = time.Now().Add(-70 * time.Minute)
+	scheduler.enforceRuntimeLimits(ctx)
+	assert.Equal(t, models.JobStateRunning, job.State)
+}
+
+func TestExtendJobDenied(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, runtimeSetup()...)
+	startTimedJob(t, scheduler, &models.Job{ID: "train", TenantID: "tenant-1", GPUCount: 2})
+
+	_, err := scheduler.ExtendJob(ctx, "train", 0)
+	assert.True(t, errors.Is(err, utils.ErrExtensionDenied))
+
+	for i := 0; i < 2; i++ {
+		_, err := scheduler.ExtendJob(ctx, "train", time.Minute)
+		require.NoError(t, err)
+	}
+	_, err = scheduler.ExtendJob(ctx, "train", time.Minute)
+	assert.True(t, errors.Is(err, utils.ErrExtensionDenied))
+
+	_, err = scheduler.ExtendJob(ctx, "missing", time.Minute)
+	assert.True(t, utils.IsNotFound(err))
+}
+
+func TestExtendJobDeniedIntoReservation(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, append(runtimeSetup(), withTenants("tenant-1", "tenant-2"))...)
+	startTimedJob(t, scheduler, &models.Job{ID: "train", TenantID: "tenant-1", GPUCount: 8})
+
+	// The reservation takes the job's GPUs once its runtime is over
+	reservation := reserve(t, scheduler, "tenant-2", 2, time.Now().Add(90*time.Minute), time.Hour)
+
+	_, err := scheduler.ExtendJob(ctx, "train", 15*time.Minute)
+	require.NoError(t, err)
+
+	_, err = scheduler.ExtendJob(ctx, "train", 30*time.Minute)
+	assert.ErrorIs(t, err, utils.ErrExtensionDenied)
+	assert.Contains(t, err.Error(), reservation.ID)
+}
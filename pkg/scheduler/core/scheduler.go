@@ -14,7 +14,11 @@ import (
 
 // Scheduler is the main scheduling orchestrator
 type Scheduler struct {
+	// queue is the default queue; partitions holds every queue in the
+	// order they are served
 	queue       *Queue
+	partitions  []*partition
+	defaultPartition *partition
 	allocator   *Allocator
 	preemptor   *Preemptor
 	storage     storage.Repository
@@ -22,6 +26,8 @@ type Scheduler struct {
 	
 	mu          sync.RWMutex
 	running     bool
+	// quotaMu makes checking and charging tenant usage atomic
+	quotaMu     sync.Mutex
 	stopChan    chan struct{}
 	
 	// Metrics
@@ -32,12 +38,14 @@ type Scheduler struct {
 
 // NewScheduler creates a new scheduler instance
 func NewScheduler(config *utils.SchedulerConfig, storage storage.Repository) *Scheduler {
-	queue := NewQueue(config.MaxQueueSize)
-	allocator := NewAllocator(storage)
+	partitions, defaultPartition := newPartitions(config)
+	allocator := NewAllocator(storage, config)
 	preemptor := NewPreemptor(storage)
 
 	return &Scheduler{
-		queue:     queue,
+		queue:     defaultPartition.queue,
+		partitions: partitions,
+		defaultPartition: defaultPartition,
 		allocator: allocator,
 		preemptor: preemptor,
 		storage:   storage,
@@ -105,39 +113,67 @@ func (s *Scheduler) SubmitJob(ctx context.Context, job *models.Job) error {
 		return fmt.Errorf("job validation failed: %w", err)
 	}
 
-	// Check tenant quota
+	// Check the job against the tenant's limits
 	tenant, err := s.storage.GetTenant(ctx, job.TenantID)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant: %w", err)
 	}
+	if !tenant.Active {
+		return fmt.Errorf("%w: %s", utils.ErrTenantInactive, tenant.ID)
+	}
 
-	if !tenant.HasAvailableQuota(job.GPUCount, job.GPUMemoryMB, job.CPUCores, job.MemoryMB) {
-		return &utils.QuotaExceededError{
-			TenantID: tenant.ID,
-			Resource: "GPUs",
-			Requested: job.GPUCount,
-			Quota: tenant.MaxGPUs,
-			Current: tenant.CurrentGPUs,
-		}
+	// Only jobs that could never fit are refused; the rest wait in the
+	// queue until their quota has room
+	if err := s.checkQuotaFits(ctx, tenant, job); err != nil {
+		return err
+	}
+
+	// Estimate power draw from previous runs when none was declared
+	if s.allocator.powerEnabled() && job.ExpectedPowerW == 0 {
+		job.ExpectedPowerW = estimatePowerFromHistory(ctx, s.storage, job)
 	}
 
-	// Set job state
+	// Set job state. Jobs with unmet dependencies wait outside the queue.
 	job.State = models.JobStatePending
 	job.SubmittedAt = time.Now()
 
+	if len(job.Dependencies) > 0 {
+		met, reason, failed := s.dependencyStatus(ctx, job)
+		if failed != "" {
+			return fmt.Errorf("job validation failed: %w: %s", utils.ErrInvalidDependency, failed)
+		}
+		if !met {
+			job.State = models.JobStateWaiting
+			job.PendingReason = reason
+		}
+	}
+
+	if job.IsArrayParent() {
+		return s.submitArray(ctx, job)
+	}
+
 	// Save to storage
 	if err := s.storage.CreateJob(ctx, job); err != nil {
 		return fmt.Errorf("failed to create job: %w", err)
 	}
 
-	// Add to queue
-	if err := s.queue.Enqueue(job); err != nil {
+	if job.State == models.JobStateWaiting {
+		utils.Info("Job waiting for dependencies",
+			zap.String("job_id", job.ID),
+			zap.String("reason", job.PendingReason))
+		return nil
+	}
+
+	// Add to the job's queue
+	queue := s.jobPartition(job).queue
+	if err := queue.Enqueue(job); err != nil {
 		return fmt.Errorf("failed to enqueue job: %w", err)
 	}
 
 	utils.Info("Job submitted successfully", 
 		zap.String("job_id", job.ID),
-		zap.Int("queue_size", s.queue.Size()))
+		zap.String("queue", job.Queue),
+		zap.Int("queue_size", queue.Size()))
 
 	return nil
 }
@@ -151,10 +187,15 @@ func (s *Scheduler) CancelJob(ctx context.Context, jobID string) error {
 		return err
 	}
 
+	// One cancel covers every job in an array
+	if job.IsArrayParent() {
+		return s.cancelArray(ctx, job)
+	}
+
 	switch job.State {
-	case models.JobStatePending:
+	case models.JobStatePending, models.JobStateWaiting:
 		// Remove from queue
-		s.queue.Remove(jobID)
+		s.jobPartition(job).queue.Remove(jobID)
 		job.State = models.JobStateCancelled
 		job.CompletedAt = timePtr(time.Now())
 		
@@ -191,58 +232,173 @@ func (s *Scheduler) GetJobStatus(ctx context.Context, jobID string) (*models.Job
 		JobID:   job.ID,
 		State:   job.State,
 		Message: "",
+		Queue:   job.Queue,
+	}
+
+	if job.State == models.JobStateWaiting {
+		status.Message = job.PendingReason
+		status.RetryAt = job.RetryAt
+	}
+
+	if job.Deadline != nil {
+		status.Deadline = job.Deadline
+		if !job.IsTerminal() {
+			slack, _ := job.Slack(time.Now())
+			status.DeadlineSlack = &slack
+			status.DeadlineUnreachable = slack < 0
+		}
+	}
+
+	// Attempts so far, counting the current one while it runs or waits
+	status.Attempts = job.Attempts
+	status.Attempt = len(job.Attempts)
+	if !job.IsTerminal() && job.State != models.JobStatePreempted {
+		status.Attempt++
+	}
+
+	// Array parents report the aggregate of their children
+	if job.IsArrayParent() {
+		children, err := s.storage.ListArrayJobs(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		status.ArrayJobs = models.ArrayCounts(children)
+		if job.State == models.JobStateWaiting {
+			status.State = models.AggregateArrayState(children)
+		}
+		return status, nil
 	}
 
 	if job.State == models.JobStatePending {
-		status.QueuePosition = s.queue.GetPosition(jobID)
+		status.Message = job.PendingReason
+		status.QueuePosition = s.jobPartition(job).queue.GetPosition(jobID)
 		status.EstimatedWait = s.estimateWaitTime(job)
 	}
 
 	// Get allocation info if running
 	if job.State == models.JobStateRunning {
 		allocations, err := s.storage.GetJobAllocations(ctx, jobID)
-		if err == nil && len(allocations) > 0 {
-			status.AllocatedGPUs = allocations[0].GPUIDs
-			status.NodeName = allocations[0].NodeID
+		// Elastic jobs hold one allocation per growth step
+		if err == nil {
+			for _, alloc := range allocations {
+				if !alloc.IsActive() {
+					continue
+				}
+				if status.NodeName == "" {
+					status.NodeName = alloc.NodeID
+				}
+				status.AllocatedGPUs = append(status.AllocatedGPUs, alloc.GPUIDs...)
+			}
+		}
+
+		if hasDeadline(job) {
+			if primary := s.primaryAllocation(ctx, jobID); primary != nil {
+				deadline := primary.Deadline()
+				status.RuntimeDeadline = &deadline
+			}
 		}
 	}
 
+	if job.State == models.JobStateFailed {
+		status.Message = job.FailureReason
+	}
+
 	return status, nil
 }
 
+// PowerStatus reports committed power draw and headroom per budget
+func (s *Scheduler) PowerStatus(ctx context.Context) (*PowerStatus, error) {
+	return s.allocator.PowerStatus(ctx)
+}
+
 // schedulingCycle performs one scheduling cycle
 func (s *Scheduler) schedulingCycle(ctx context.Context) error {
+	// Submit cron jobs that have come due
+	s.runCronJobs(ctx, time.Now())
+
+	// Start new budget periods and warn tenants nearing their budget
+	s.checkBudgets(ctx, time.Now())
+
 	// Apply aging to prevent starvation
-	s.queue.ApplyAging(10, 5*time.Minute)
+	for _, part := range s.partitions {
+		part.queue.ApplyAging(10, 5*time.Minute)
+	}
+
+	// Boost jobs at risk of missing their deadline
+	s.boostDeadlines(time.Now())
+
+	// Take nodes whose agent stopped sending heartbeats offline, then
+	// fail jobs whose node or GPUs failed under them
+	s.expireAgents(ctx, time.Now())
+	s.detectNodeFailures(ctx)
+
+	// Drop reservations whose window has closed, failing the jobs still
+	// waiting to run in them
+	s.expireReservations(ctx, time.Now())
 
-	// Process pending jobs
-	for !s.queue.IsEmpty() {
-		job := s.queue.Peek()
-		if job == nil {
-			break
+	// Queue jobs whose dependencies have been met or whose retry
+	// backoff has passed
+	s.releaseWaitingJobs(ctx)
+
+	// Warn, signal and time out jobs past their max runtime
+	s.enforceRuntimeLimits(ctx)
+
+	// Process each queue's pending jobs in its order. A queue that runs
+	// out of resources does not hold up the others.
+	starved := false
+	for _, part := range s.partitions {
+		if s.scheduleQueue(ctx, part.queue) {
+			starved = true
 		}
+	}
+
+	// GPUs left idle once the queues are served go to elastic jobs
+	if !starved {
+		s.growElasticJobs(ctx)
+	}
+
+	return nil
+}
+
+// scheduleQueue places a queue's pending jobs in order until one cannot
+// be placed for lack of resources, and reports whether that happened
+func (s *Scheduler) scheduleQueue(ctx context.Context, queue *Queue) bool {
+	jobs := queue.Sorted()
+	for i := 0; i < len(jobs); i++ {
+		job := jobs[i]
 
 		// Try to allocate resources
 		allocated, err := s.tryAllocateJob(ctx, job)
 		if err != nil {
+			// Constraint violations keep the job pending without
+			// blocking the jobs behind it
+			if utils.IsUnschedulable(err) {
+				s.markUnschedulable(ctx, job, utils.UnschedulableReason(err))
+				continue
+			}
+
 			utils.Error("Allocation error", 
 				zap.String("job_id", job.ID), 
 				zap.Error(err))
 			
-			// If resource error, try preemption if enabled
-			if s.config.EnablePreemption && utils.IsResourceError(err) {
-				if s.tryPreemption(ctx, job) {
+			// If resource error, shrink elastic jobs, reclaim the
+			// tenant's guarantee from borrowers and then try
+			// preemption if enabled or a deadline is critical
+			if utils.IsResourceError(err) {
+				if s.tryShrink(ctx, job) || s.tryReclaim(ctx, job) || s.tryPreemption(ctx, job) {
+					// Retry the same job against the freed resources
+					i--
 					continue
 				}
 			}
 			
 			// Can't schedule this job now, try next one
-			break
+			return true
 		}
 
 		if allocated {
 			// Remove from queue and start job
-			s.queue.Dequeue()
+			queue.Remove(job.ID)
 			if err := s.startJob(ctx, job); err != nil {
 				utils.Error("Failed to start job", 
 					zap.String("job_id", job.ID), 
@@ -253,11 +409,29 @@ func (s *Scheduler) schedulingCycle(ctx context.Context) error {
 			}
 		} else {
 			// No resources available, stop trying
-			break
+			return true
 		}
 	}
 
-	return nil
+	return false
+}
+
+// markUnschedulable records why a pending job could not be placed
+func (s *Scheduler) markUnschedulable(ctx context.Context, job *models.Job, reason string) {
+	if job.PendingReason == reason {
+		return
+	}
+
+	utils.Debug("Job unschedulable",
+		zap.String("job_id", job.ID),
+		zap.String("reason", reason))
+
+	job.PendingReason = reason
+	if err := s.storage.UpdateJob(ctx, job); err != nil {
+		utils.Error("Failed to record pending reason",
+			zap.String("job_id", job.ID),
+			zap.Error(err))
+	}
 }
 
 // tryAllocateJob attempts to allocate resources for a job
@@ -270,14 +444,70 @@ func (s *Scheduler) tryAllocateJob(ctx context.Context, job *models.Job) (bool,
 		CPUCores:       job.CPUCores,
 		MemoryMB:       job.MemoryMB,
 		GangScheduling: job.GangScheduling,
+		Affinity:       job.Affinity,
+		ExpectedPowerW: job.ExpectedPowerW,
+		GPUSlice:       job.GPUSlice,
+		MaxRuntime:     job.MaxRuntime,
+		AvoidNodes:     avoidNodes(job),
+		ReservationID:  job.ReservationID,
+		Queue:          job.Queue,
+	}
+
+	// Quota is checked and charged under one lock so no release or other
+	// charge can slip in between
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	tenant, err := s.storage.GetTenant(ctx, job.TenantID)
+	if err != nil {
+		return false, &utils.UnschedulableError{JobID: job.ID, Reason: fmt.Sprintf("tenant %s not found", job.TenantID)}
+	}
+	if !tenant.Active {
+		return false, &utils.UnschedulableError{JobID: job.ID, Reason: fmt.Sprintf("tenant %s is inactive", job.TenantID)}
+	}
+	shortfall, err := s.quotaShortfall(ctx, tenant, job)
+	if err != nil {
+		return false, err
+	}
+	if shortfall != "" {
+		return false, &utils.UnschedulableError{JobID: job.ID, Reason: "waiting for " + shortfall}
+	}
+
+	// Tenants may override their queue's placement strategy, and queues
+	// the global one
+	request.Strategy = s.placementStrategy(job, tenant)
+
+	// GPUs beyond the tenant's guarantee are borrowed and may be reclaimed
+	request.Reclaimable = !tenant.WithinGuarantee(job.RequestedGPUs())
+
+	// Allocations in a reservation must end with its window, while the
+	// job keeps the max runtime it asked for
+	if job.ReservationID != "" {
+		if reservation, err := s.storage.GetReservation(ctx, job.ReservationID); err == nil {
+			left := time.Until(reservation.EndTime)
+			if left > 0 && (request.MaxRuntime <= 0 || request.MaxRuntime > left) {
+				request.MaxRuntime = left
+			}
+		}
 	}
 
 	result, err := s.allocator.Allocate(ctx, request)
 	if err != nil {
 		return false, err
 	}
+	if !result.Success {
+		return false, nil
+	}
 
-	return result.Success, nil
+	job.GPUFraction = result.GPUFraction
+	tenant.UpdateUsage(job.WholeGPUs(), job.GPUMemoryMB, job.CPUCores, job.MemoryMB, 1)
+	tenant.UpdateFractionalUsage(job.GPUFraction)
+	if err := s.storage.UpdateTenant(ctx, tenant); err != nil {
+		utils.Error("Failed to update tenant usage",
+			zap.String("tenant_id", tenant.ID),
+			zap.Error(err))
+	}
+	return true, nil
 }
 
 // startJob transitions a job to running state
@@ -286,22 +516,13 @@ func (s *Scheduler) startJob(ctx context.Context, job *models.Job) error {
 	job.State = models.JobStateRunning
 	job.ScheduledAt = &now
 	job.StartedAt = &now
+	job.PendingReason = ""
 
+	// Tenant usage was charged when the job was allocated
 	if err := s.storage.UpdateJob(ctx, job); err != nil {
 		return err
 	}
 
-	// Update tenant usage
-	tenant, err := s.storage.GetTenant(ctx, job.TenantID)
-	if err != nil {
-		return err
-	}
-
-	tenant.UpdateUsage(job.GPUCount, job.GPUMemoryMB, job.CPUCores, job.MemoryMB, 1)
-	if err := s.storage.UpdateTenant(ctx, tenant); err != nil {
-		return err
-	}
-
 	utils.Info("Job started", 
 		zap.String("job_id", job.ID),
 		zap.String("tenant_id", job.TenantID))
@@ -311,11 +532,13 @@ func (s *Scheduler) startJob(ctx context.Context, job *models.Job) error {
 
 // tryPreemption attempts to preempt lower priority jobs
 func (s *Scheduler) tryPreemption(ctx context.Context, job *models.Job) bool {
-	if !s.config.EnablePreemption {
+	if !s.canPreempt(job) {
 		return false
 	}
 
-	victims, err := s.preemptor.SelectVictims(ctx, job)
+	victims, err := s.preemptor.SelectVictims(ctx, job, func(victim *models.Job) bool {
+		return s.mayPreempt(ctx, job, victim)
+	})
 	if err != nil || len(victims) == 0 {
 		return false
 	}
@@ -324,14 +547,43 @@ func (s *Scheduler) tryPreemption(ctx context.Context, job *models.Job) bool {
 		zap.String("job_id", job.ID),
 		zap.Int("victims", len(victims)))
 
+	return s.evictVictims(ctx, job, victims, s.preemptor.Preempt, "preempted by job %s")
+}
+
+// evictVictims preempts victims with preempt to make room for job,
+// recording reasonFormat with the job's ID in each victim's attempts
+func (s *Scheduler) evictVictims(ctx context.Context, job *models.Job, victims []*models.Job,
+	preempt func(context.Context, *models.Job, string) error, reasonFormat string) bool {
 	for _, victim := range victims {
-		if err := s.preemptor.Preempt(ctx, victim, job.ID); err != nil {
+		attempt := s.newAttempt(ctx, victim, models.FailurePreemption,
+			fmt.Sprintf(reasonFormat, job.ID), 0)
+		active := s.activeAllocations(ctx, victim.ID)
+
+		if err := preempt(ctx, victim, job.ID); err != nil {
 			utils.Error("Preemption failed", 
 				zap.String("victim_id", victim.ID),
 				zap.Error(err))
 			return false
 		}
 		s.preemptedJobs++
+
+		// Bill the intervals the preemption cut short
+		for _, alloc := range active {
+			s.billInterval(ctx, victim, alloc.ID, alloc.GPUs(), time.Now(), endKind(victim, alloc, true))
+		}
+
+		// The victim no longer holds resources; requeue it if its
+		// retry policy covers preemption
+		if err := s.releaseTenantUsage(ctx, victim); err != nil {
+			utils.Error("Failed to release tenant usage",
+				zap.String("victim_id", victim.ID),
+				zap.Error(err))
+		}
+		if err := s.retryOrFinish(ctx, victim, attempt, models.JobStatePreempted); err != nil {
+			utils.Error("Failed to update preempted job",
+				zap.String("victim_id", victim.ID),
+				zap.Error(err))
+		}
 	}
 
 	return true
@@ -345,25 +597,59 @@ func (s *Scheduler) freeJobResources(ctx context.Context, job *models.Job) error
 	}
 
 	for _, alloc := range allocations {
+		// Allocations released by an elastic shrink are already free
+		if !alloc.IsActive() {
+			continue
+		}
 		if err := s.allocator.Free(ctx, alloc.ID); err != nil {
 			utils.Error("Failed to free allocation", 
 				zap.String("allocation_id", alloc.ID),
 				zap.Error(err))
+			continue
 		}
+		s.billInterval(ctx, job, alloc.ID, alloc.GPUs(), time.Now(), endKind(job, alloc, false))
 	}
 
-	// Update tenant usage
+	return s.releaseTenantUsage(ctx, job)
+}
+
+// releaseTenantUsage returns a job's resources to its tenant's quota.
+// The GPU-hours it used are charged as its allocations are billed.
+func (s *Scheduler) releaseTenantUsage(ctx context.Context, job *models.Job) error {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
 	tenant, err := s.storage.GetTenant(ctx, job.TenantID)
 	if err != nil {
 		return err
 	}
 
-	tenant.UpdateUsage(-job.GPUCount, -job.GPUMemoryMB, -job.CPUCores, -job.MemoryMB, -1)
+	tenant.UpdateUsage(-job.WholeGPUs(), -job.GPUMemoryMB, -job.CPUCores, -job.MemoryMB, -1)
+	tenant.UpdateFractionalUsage(-job.GPUFraction)
 	return s.storage.UpdateTenant(ctx, tenant)
 }
 
 // validateJob validates job parameters
 func (s *Scheduler) validateJob(ctx context.Context, job *models.Job) error {
+	if job.IsFractional() {
+		if err := job.GPUSlice.Validate(); err != nil {
+			return err
+		}
+		if job.GPUCount > 1 || job.GangScheduling {
+			return fmt.Errorf("fractional jobs take a slice of a single GPU")
+		}
+		job.GPUCount = 1
+	}
+	if job.IsElastic() {
+		if job.IsFractional() {
+			return fmt.Errorf("elastic jobs take whole GPUs")
+		}
+		if job.MinGPUs <= 0 || job.MaxGPUs < job.MinGPUs {
+			return fmt.Errorf("elastic jobs need 0 < min_gpus <= max_gpus")
+		}
+		// Elastic jobs start at their minimum and grow from there
+		job.GPUCount = job.MinGPUs
+	}
 	if job.GPUCount <= 0 {
 		return fmt.Errorf("GPU count must be positive")
 	}
@@ -373,7 +659,33 @@ func (s *Scheduler) validateJob(ctx context.Context, job *models.Job) error {
 	if job.TenantID == "" {
 		return fmt.Errorf("tenant ID is required")
 	}
-	return nil
+	if err := s.validateQueue(job); err != nil {
+		return err
+	}
+	if job.Array != nil {
+		if err := job.Array.Validate(); err != nil {
+			return err
+		}
+	}
+	if job.RetryPolicy != nil {
+		if err := job.RetryPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+	if job.ReservationID != "" {
+		reservation, err := s.storage.GetReservation(ctx, job.ReservationID)
+		if err != nil {
+			return err
+		}
+		if reservation.TenantID != job.TenantID {
+			return fmt.Errorf("%w: %s belongs to another tenant", utils.ErrReservationNotFound, job.ReservationID)
+		}
+		if reservation.PhaseAt(time.Now()) == models.ReservationEnded {
+			return fmt.Errorf("%w: %s ended at %s", utils.ErrReservationEnded,
+				job.ReservationID, reservation.EndTime.Format(time.RFC3339))
+		}
+	}
+	return validateDependencies(job)
 }
 
 // loadPendingJobs loads pending jobs from storage into queue
@@ -384,7 +696,7 @@ func (s *Scheduler) loadPendingJobs(ctx context.Context) error {
 	}
 
 	for _, job := range jobs {
-		if err := s.queue.Enqueue(job); err != nil {
+		if err := s.jobPartition(job).queue.Enqueue(job); err != nil {
 			utils.Error("Failed to enqueue pending job", 
 				zap.String("job_id", job.ID),
 				zap.Error(err))
@@ -397,7 +709,7 @@ func (s *Scheduler) loadPendingJobs(ctx context.Context) error {
 
 // estimateWaitTime estimates wait time for a job
 func (s *Scheduler) estimateWaitTime(job *models.Job) time.Duration {
-	position := s.queue.GetPosition(job.ID)
+	position := s.jobPartition(job).queue.GetPosition(job.ID)
 	if position <= 0 {
 		return 0
 	}
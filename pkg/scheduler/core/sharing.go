@@ -0,0 +1,38 @@
+package core
+
+import (
+	"sort"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+)
+
+// candidateGPU reports whether a GPU can serve the request: idle for
+// whole-GPU requests, or shared with room left for fractional ones
+func candidateGPU(request *models.AllocationRequest, gpu *models.GPU) bool {
+	if request.IsFractional() {
+		_, ok := gpu.FitSlice(request.GPUSlice)
+		return ok
+	}
+	return gpu.IsAvailable()
+}
+
+// packSlices orders shared GPUs fullest first, so slices fill partially
+// used GPUs before opening idle ones
+func packSlices(gpus []*models.GPU) {
+	sort.SliceStable(gpus, func(i, j int) bool {
+		return gpus[i].SharedFraction() > gpus[j].SharedFraction()
+	})
+}
+
+// sliceFraction returns the share of the GPU the request would hold, or
+// 1 for whole-GPU requests
+func sliceFraction(request *models.AllocationRequest, gpu *models.GPU) float64 {
+	if !request.IsFractional() {
+		return 1
+	}
+	slice, ok := gpu.FitSlice(request.GPUSlice)
+	if !ok {
+		return 1
+	}
+	return slice.Fraction
+}
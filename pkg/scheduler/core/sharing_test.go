@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// share switches every GPU on a node to the given sharing mode
+func share(repo *fakeRepository, nodeID string, mode models.GPUSharingMode) {
+	gpus, _ := repo.ListGPUsByNode(context.Background(), nodeID)
+	for _, gpu := range gpus {
+		gpu.SharingMode = mode
+	}
+}
+
+func allocateSlice(t *testing.T, allocator *Allocator, jobID string, slice *models.SliceRequest) *models.AllocationResult {
+	t.Helper()
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    jobID,
+		GPUCount: 1,
+		GPUSlice: slice,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestMIGSlicesPackOntoOneGPU(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	node := repo.addNode("node-a", 2)
+	share(repo, "node-a", models.SharingMIG)
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{})
+
+	first := allocateSlice(t, allocator, "job-1", &models.SliceRequest{MIGProfile: "3g.40gb"})
+	second := allocateSlice(t, allocator, "job-2", &models.SliceRequest{MIGProfile: "4g.40gb"})
+
+	assert.Equal(t, first.GPUIDs, second.GPUIDs)
+	assert.InDelta(t, 3.0/7.0, first.GPUFraction, 1e-9)
+	assert.Equal(t, 1, node.AvailableGPUs)
+
+	allocation, err := repo.GetAllocation(ctx, first.AllocationID)
+	require.NoError(t, err)
+	require.NotNil(t, allocation.Slice)
+	assert.Equal(t, "3g.40gb", allocation.Slice.Profile)
+	assert.InDelta(t, 3.0/7.0, allocation.GPUs(), 1e-9)
+
+	// The first GPU is full, so the next slice opens the second one
+	third := allocateSlice(t, allocator, "job-3", &models.SliceRequest{MIGProfile: "1g.10gb"})
+	assert.NotEqual(t, first.GPUIDs, third.GPUIDs)
+	assert.Equal(t, 0, node.AvailableGPUs)
+
+	// Releasing one slice keeps the GPU busy; releasing both frees it
+	require.NoError(t, allocator.Free(ctx, first.AllocationID))
+	assert.Equal(t, 0, node.AvailableGPUs)
+	require.NoError(t, allocator.Free(ctx, second.AllocationID))
+	assert.Equal(t, 1, node.AvailableGPUs)
+
+	gpu, _ := repo.GetGPU(ctx, first.GPUIDs[0])
+	assert.False(t, gpu.InUse())
+}
+
+func TestMemorySliceOnTimeSlicedGPU(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 1)
+	share(repo, "node-a", models.SharingTimeSlice)
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{})
+
+	result := allocateSlice(t, allocator, "job-1", &models.SliceRequest{MemoryMB: 61440})
+	assert.InDelta(t, 0.75, result.GPUFraction, 1e-9)
+
+	_, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-2",
+		GPUCount: 1,
+		GPUSlice: &models.SliceRequest{MemoryMB: 40960},
+	})
+	assert.Error(t, err)
+
+	// Whole-GPU requests cannot take a GPU that holds slices
+	_, err = allocator.Allocate(context.Background(), &models.AllocationRequest{JobID: "job-3", GPUCount: 1})
+	assert.Error(t, err)
+}
+
+func TestSliceRequestsSkipExclusiveGPUs(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 4)
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{})
+
+	_, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: 1,
+		GPUSlice: &models.SliceRequest{MIGProfile: "1g.10gb"},
+	})
+	assert.Error(t, err)
+}
+
+func TestTenantUsageCountsFractionalGPUs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(1, "node-a"), withTenants("research"),
+		withTenantSettings(func(tenant *models.Tenant) { tenant.MaxGPUs = 1 }))
+	share(repo, "node-a", models.SharingMIG)
+	job := &models.Job{
+		ID:       "notebook",
+		TenantID: "research",
+		GPUSlice: &models.SliceRequest{MIGProfile: "2g.20gb"},
+	}
+	require.NoError(t, scheduler.SubmitJob(ctx, job))
+	assert.Equal(t, 1, job.GPUCount)
+
+	allocated, err := scheduler.tryAllocateJob(ctx, job)
+	require.NoError(t, err)
+	require.True(t, allocated)
+	require.NoError(t, scheduler.startJob(ctx, job))
+
+	tenant, _ := repo.GetTenant(ctx, "research")
+	assert.Equal(t, 0, tenant.CurrentGPUs)
+	assert.InDelta(t, 2.0/7.0, tenant.CurrentGPUFraction, 1e-9)
+
+	// A second job needing a whole GPU waits for the quota
+	train := &models.Job{ID: "train", TenantID: "research", GPUCount: 1}
+	require.NoError(t, scheduler.SubmitJob(ctx, train))
+	_, err = scheduler.tryAllocateJob(ctx, train)
+	assert.Equal(t, "waiting for quota of tenant research", utils.UnschedulableReason(err))
+
+	require.NoError(t, scheduler.freeJobResources(ctx, job))
+	tenant, _ = repo.GetTenant(ctx, "research")
+	assert.Zero(t, tenant.CurrentGPUFraction)
+}
+
+func TestSlicesPackWithThermalAwareness(t *testing.T) {
+	repo := newFakeRepository()
+	node := repo.addNode("node-a", 3)
+	share(repo, "node-a", models.SharingMIG)
+	setTemperatures(repo, "node-a", 40.0, 30.0, 78.0)
+	allocator := NewAllocator(repo, thermalConfig())
+
+	// The coolest GPU opens first; the hot idle one starts cooling
+	first := allocateSlice(t, allocator, "job-1", &models.SliceRequest{MIGProfile: "3g.40gb"})
+	assert.Equal(t, []string{"node-a-gpu-1"}, first.GPUIDs)
+	hot, _ := repo.GetGPU(context.Background(), "node-a-gpu-2")
+	assert.True(t, hot.IsCooling())
+
+	// A running slice warms its GPU, but later slices still pack onto it
+	// rather than opening the cooler idle GPU
+	setTemperatures(repo, "node-a", 40.0, 65.0)
+	second := allocateSlice(t, allocator, "job-2", &models.SliceRequest{MIGProfile: "2g.20gb"})
+	assert.Equal(t, first.GPUIDs, second.GPUIDs)
+	assert.Equal(t, 2, node.AvailableGPUs)
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// strategyScorer ranks placing a request on a node; higher is better
+type strategyScorer func(request *models.AllocationRequest, info *NodeInfo) float64
+
+var strategyScorers = map[models.PlacementStrategy]strategyScorer{
+	models.StrategyBestFit:          bestFitScore,
+	models.StrategySpread:           spreadScore,
+	models.StrategyMostAllocated:    mostAllocatedScore,
+	models.StrategyMinFragmentation: minFragmentationScore,
+}
+
+// bestFitScore prefers the node left with the fewest idle GPUs
+func bestFitScore(request *models.AllocationRequest, info *NodeInfo) float64 {
+	return -float64(len(info.Available) - request.GPUCount)
+}
+
+// spreadScore prefers the node left with the largest share of idle GPUs
+func spreadScore(request *models.AllocationRequest, info *NodeInfo) float64 {
+	left := float64(len(info.Available) - request.GPUCount)
+	if info.Node.TotalGPUs == 0 {
+		return left
+	}
+	return left / float64(info.Node.TotalGPUs)
+}
+
+// mostAllocatedScore prefers the node with the highest average GPU, CPU
+// and memory utilization once the request is placed
+func mostAllocatedScore(request *models.AllocationRequest, info *NodeInfo) float64 {
+	node := info.Node
+	total, dimensions := 0.0, 0
+
+	if node.TotalGPUs > 0 {
+		used := node.TotalGPUs - len(info.Available) + request.GPUCount
+		total += float64(used) / float64(node.TotalGPUs)
+		dimensions++
+	}
+	if node.TotalCPUCores > 0 {
+		used := node.TotalCPUCores - node.AvailableCPUCores + request.CPUCores
+		total += float64(used) / float64(node.TotalCPUCores)
+		dimensions++
+	}
+	if node.TotalMemoryMB > 0 {
+		used := node.TotalMemoryMB - node.AvailableMemoryMB + request.MemoryMB
+		total += float64(used) / float64(node.TotalMemoryMB)
+		dimensions++
+	}
+
+	if dimensions == 0 {
+		return 0
+	}
+	return total / float64(dimensions)
+}
+
+// minFragmentationScore minimizes idle GPUs stranded on partially used
+// nodes. Filling a partially used node reduces stranding, an exact fit
+// on an idle node keeps it neutral, and breaking into an idle node
+// strands whatever is left. Ties fall back to the tightest fit.
+func minFragmentationScore(request *models.AllocationRequest, info *NodeInfo) float64 {
+	free := len(info.Available)
+	left := free - request.GPUCount
+
+	stranded := func(idle int) int {
+		if idle >= info.Node.TotalGPUs {
+			return 0
+		}
+		return idle
+	}
+
+	delta := stranded(left) - stranded(free)
+	return -float64(delta) - float64(left)/float64(info.Node.TotalGPUs+1)
+}
+
+// placementPlugin ranks nodes with the request's placement strategy,
+// falling back to the configured default. Heavy jobs are spread by the
+// thermal plugin instead.
+type placementPlugin struct {
+	allocator *Allocator
+	strategy  models.PlacementStrategy
+}
+
+func newPlacementPlugin(a *Allocator) (Plugin, error) {
+	strategy := models.StrategyBestFit
+	if a.config != nil && a.config.PlacementStrategy != "" {
+		strategy = models.PlacementStrategy(a.config.PlacementStrategy)
+	}
+	if !strategy.IsValid() {
+		utils.Warn("Unknown placement strategy, using best-fit", zap.String("strategy", string(strategy)))
+		strategy = models.StrategyBestFit
+	}
+	return &placementPlugin{allocator: a, strategy: strategy}, nil
+}
+
+func (p *placementPlugin) Name() string { return PluginPlacement }
+
+func (p *placementPlugin) Score(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) float64 {
+	if p.allocator.isHeavyJob(request) {
+		return 0
+	}
+	scorer, ok := strategyScorers[request.Strategy]
+	if !ok {
+		scorer = strategyScorers[p.strategy]
+	}
+	return scorer(request, info)
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// occupy marks the first count GPUs of a node as allocated
+func occupy(repo *fakeRepository, nodeID string, count int) {
+	gpus, _ := repo.ListGPUsByNode(context.Background(), nodeID)
+	for _, gpu := range gpus[:count] {
+		gpu.Allocated = true
+	}
+	repo.nodes[nodeID].AvailableGPUs -= count
+}
+
+func allocateWith(t *testing.T, repo *fakeRepository, strategy models.PlacementStrategy, gpuCount int) string {
+	t.Helper()
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{PlacementStrategy: string(strategy)})
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: gpuCount,
+	})
+	require.NoError(t, err)
+	require.NoError(t, allocator.Free(context.Background(), result.AllocationID))
+	return result.NodeID
+}
+
+func TestSpreadStrategy(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+	repo.addNode("node-b", 8)
+
+	assert.Equal(t, "node-a", allocateWith(t, repo, models.StrategyBestFit, 2))
+	assert.Equal(t, "node-b", allocateWith(t, repo, models.StrategySpread, 2))
+}
+
+func TestMostAllocatedStrategyCountsCPUAndMemory(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 8).AvailableCPUCores = 4
+	repo.addNode("node-b", 4)
+
+	assert.Equal(t, "node-b", allocateWith(t, repo, models.StrategyBestFit, 1))
+	assert.Equal(t, "node-a", allocateWith(t, repo, models.StrategyMostAllocated, 1))
+}
+
+func TestMinFragmentationKeepsIdleNodesWhole(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 8)
+	repo.addNode("node-b", 2)
+	occupy(repo, "node-a", 4)
+
+	// Best-fit breaks into the idle node; min-fragmentation fills the
+	// partially used one
+	assert.Equal(t, "node-b", allocateWith(t, repo, models.StrategyBestFit, 1))
+	assert.Equal(t, "node-a", allocateWith(t, repo, models.StrategyMinFragmentation, 1))
+
+	// Among idle nodes, an exact fit strands nothing
+	idle := newFakeRepository()
+	idle.addNode("node-a", 8)
+	idle.addNode("node-b", 2)
+	assert.Equal(t, "node-b", allocateWith(t, idle, models.StrategyMinFragmentation, 2))
+}
+
+func TestTenantStrategyOverridesGlobal(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(2, "node-a"), withNodes(8, "node-b"), withTenants("inference"),
+		withTenantSettings(func(tenant *models.Tenant) { tenant.PlacementStrategy = models.StrategySpread }),
+		withConfig(func(config *utils.SchedulerConfig) { config.PlacementStrategy = "best-fit" }))
+	job := &models.Job{ID: "serve", TenantID: "inference", GPUCount: 2, State: models.JobStatePending}
+	require.NoError(t, repo.CreateJob(ctx, job))
+
+	allocated, err := scheduler.tryAllocateJob(ctx, job)
+	require.NoError(t, err)
+	assert.True(t, allocated)
+
+	allocations, _ := repo.GetJobAllocations(ctx, "serve")
+	require.Len(t, allocations, 1)
+	assert.Equal(t, "node-b", allocations[0].NodeID)
+}
+
+func TestUnknownStrategyFallsBackToBestFit(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+	repo.addNode("node-b", 8)
+
+	assert.Equal(t, "node-a", allocateWith(t, repo, "round-robin", 2))
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// GetTenant returns a tenant with its limits and current usage
+func (s *Scheduler) GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	return s.storage.GetTenant(ctx, tenantID)
+}
+
+// ListTenants returns all tenants ordered by name
+func (s *Scheduler) ListTenants(ctx context.Context) ([]*models.Tenant, error) {
+	tenants, err := s.storage.ListTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		if tenants[i].Name != tenants[j].Name {
+			return tenants[i].Name < tenants[j].Name
+		}
+		return tenants[i].ID < tenants[j].ID
+	})
+	return tenants, nil
+}
+
+// UpdateTenant changes a tenant's limits and policies. Lowered limits
+// apply to jobs that have not started yet; running jobs are left alone.
+func (s *Scheduler) UpdateTenant(ctx context.Context, tenantID string, update *models.TenantUpdate) (*models.Tenant, error) {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	stored, err := s.storage.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant := *stored
+	update.Apply(&tenant)
+	if err := tenant.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidTenant, err)
+	}
+
+	tenant.UpdatedAt = time.Now()
+	if err := s.storage.UpdateTenant(ctx, &tenant); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	utils.Info("Tenant updated",
+		zap.String("tenant_id", tenantID),
+		zap.Int("max_gpus", tenant.MaxGPUs),
+		zap.Int("max_concurrent_jobs", tenant.MaxConcurrentJobs))
+	return &tenant, nil
+}
+
+// DeactivateTenant stops the tenant from submitting jobs. Its queued jobs
+// stay pending until it is activated again, and its running jobs finish
+// unless drain is set, in which case all of its active jobs are
+// cancelled. It returns the number of jobs cancelled.
+func (s *Scheduler) DeactivateTenant(ctx context.Context, tenantID string, drain bool) (*models.Tenant, int, error) {
+	tenant, err := s.setTenantActive(ctx, tenantID, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	drained := 0
+	if drain {
+		jobs, err := s.tenantActiveJobs(ctx, tenantID)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, job := range jobs {
+			// Cancelling an array's parent cancels its jobs
+			if job.ArrayParentID != "" {
+				continue
+			}
+			if err := s.CancelJob(ctx, job.ID); err != nil {
+				utils.Error("Failed to cancel job of inactive tenant",
+					zap.String("job_id", job.ID),
+					zap.Error(err))
+				continue
+			}
+			drained++
+		}
+	}
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:     models.EventTenantDeactivated,
+		TenantID: tenantID,
+		Message:  fmt.Sprintf("tenant deactivated, %d jobs cancelled", drained),
+	})
+	utils.Info("Tenant deactivated",
+		zap.String("tenant_id", tenantID),
+		zap.Bool("drain", drain),
+		zap.Int("cancelled_jobs", drained))
+	return tenant, drained, nil
+}
+
+// ActivateTenant lets an inactive tenant submit and run jobs again
+func (s *Scheduler) ActivateTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	tenant, err := s.setTenantActive(ctx, tenantID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	recordEvent(ctx, s.storage, &models.Event{
+		Type:     models.EventTenantActivated,
+		TenantID: tenantID,
+		Message:  "tenant activated",
+	})
+	utils.Info("Tenant activated", zap.String("tenant_id", tenantID))
+	return tenant, nil
+}
+
+// DeleteTenant removes a tenant. Tenants with pending, waiting or running
+// jobs cannot be deleted; deactivate and drain them first. Neither can
+// tenants that still own cron jobs or reservations, which would go on
+// submitting jobs or holding GPUs for a missing tenant.
+func (s *Scheduler) DeleteTenant(ctx context.Context, tenantID string) error {
+	if _, err := s.storage.GetTenant(ctx, tenantID); err != nil {
+		return err
+	}
+	jobs, err := s.tenantActiveJobs(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if len(jobs) > 0 {
+		return fmt.Errorf("%w: %s has %d active jobs", utils.ErrTenantInUse, tenantID, len(jobs))
+	}
+
+	cronJobs, err := s.storage.ListCronJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list cron jobs: %w", err)
+	}
+	for _, cronJob := range cronJobs {
+		if cronJob.TenantID == tenantID {
+			return fmt.Errorf("%w: %s owns cron job %s", utils.ErrTenantInUse, tenantID, cronJob.ID)
+		}
+	}
+
+	reservations, err := s.storage.ListReservations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list reservations: %w", err)
+	}
+	for _, reservation := range reservations {
+		if reservation.TenantID == tenantID {
+			return fmt.Errorf("%w: %s owns reservation %s", utils.ErrTenantInUse, tenantID, reservation.ID)
+		}
+	}
+
+	if err := s.storage.DeleteTenant(ctx, tenantID); err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+
+	utils.Info("Tenant deleted", zap.String("tenant_id", tenantID))
+	return nil
+}
+
+func (s *Scheduler) setTenantActive(ctx context.Context, tenantID string, active bool) (*models.Tenant, error) {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	tenant, err := s.storage.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	tenant.Active = active
+	tenant.UpdatedAt = time.Now()
+	if err := s.storage.UpdateTenant(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// tenantActiveJobs returns the tenant's pending, waiting and running jobs
+func (s *Scheduler) tenantActiveJobs(ctx context.Context, tenantID string) ([]*models.Job, error) {
+	jobs, err := s.storage.ListJobsByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant jobs: %w", err)
+	}
+
+	var active []*models.Job
+	for _, job := range jobs {
+		switch job.State {
+		case models.JobStatePending, models.JobStateWaiting, models.JobStateRunning:
+			active = append(active, job)
+		}
+	}
+	return active, nil
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateTenantChangesOnlyGivenFields(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(2, "node-1"))
+
+	maxGPUs, tier := 6, models.PriorityHigh
+	tenant, err := scheduler.UpdateTenant(ctx, "tenant-1", &models.TenantUpdate{MaxGPUs: &maxGPUs, PriorityTier: &tier})
+	require.NoError(t, err)
+	assert.Equal(t, 6, tenant.MaxGPUs)
+	assert.Equal(t, models.PriorityHigh, tenant.PriorityTier)
+	assert.Equal(t, "tenant-1", tenant.Name)
+	assert.Equal(t, 10, tenant.MaxConcurrentJobs)
+
+	guaranteed := 9
+	_, err = scheduler.UpdateTenant(ctx, "tenant-1", &models.TenantUpdate{GuaranteedGPUs: &guaranteed})
+	assert.True(t, errors.Is(err, utils.ErrInvalidTenant))
+
+	stored, err := scheduler.GetTenant(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Zero(t, stored.GuaranteedGPUs)
+
+	_, err = scheduler.UpdateTenant(ctx, "missing", &models.TenantUpdate{MaxGPUs: &maxGPUs})
+	assert.True(t, utils.IsNotFound(err))
+}
+
+func TestInactiveTenantCannotSubmitOrStartJobs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(2, "node-1"))
+
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "queued", TenantID: "tenant-1", GPUCount: 1}))
+	_, cancelled, err := scheduler.DeactivateTenant(ctx, "tenant-1", false)
+	require.NoError(t, err)
+	assert.Zero(t, cancelled)
+
+	err = scheduler.SubmitJob(ctx, &models.Job{ID: "refused", TenantID: "tenant-1", GPUCount: 1})
+	assert.True(t, errors.Is(err, utils.ErrTenantInactive))
+
+	// The queued job waits until the tenant is active again
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	job, _ := repo.GetJob(ctx, "queued")
+	assert.Equal(t, models.JobStatePending, job.State)
+	assert.Contains(t, job.PendingReason, "inactive")
+
+	_, err = scheduler.ActivateTenant(ctx, "tenant-1")
+	require.NoError(t, err)
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	job, _ = repo.GetJob(ctx, "queued")
+	assert.Equal(t, models.JobStateRunning, job.State)
+
+	for _, eventType := range []models.EventType{models.EventTenantDeactivated, models.EventTenantActivated} {
+		events, err := repo.ListEvents(ctx, models.EventFilter{Type: eventType})
+		require.NoError(t, err)
+		assert.Len(t, events, 1, eventType)
+	}
+}
+
+func TestDeactivateTenantDrainsJobs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(2, "node-1"))
+
+	for _, id := range []string{"running-1", "running-2", "queued"} {
+		require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: id, TenantID: "tenant-1", GPUCount: 1}))
+	}
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	tenant, cancelled, err := scheduler.DeactivateTenant(ctx, "tenant-1", true)
+	require.NoError(t, err)
+	assert.False(t, tenant.Active)
+	assert.Equal(t, 3, cancelled)
+
+	for _, id := range []string{"running-1", "running-2", "queued"} {
+		job, _ := repo.GetJob(ctx, id)
+		assert.Equal(t, models.JobStateCancelled, job.State, id)
+	}
+	stored, _ := repo.GetTenant(ctx, "tenant-1")
+	assert.Zero(t, stored.CurrentGPUs)
+	assert.Zero(t, stored.CurrentJobs)
+}
+
+func TestDeleteTenantRefusedWhileJobsAreActive(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withNodes(2, "node-1"))
+
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "train", TenantID: "tenant-1", GPUCount: 1}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+
+	err := scheduler.DeleteTenant(ctx, "tenant-1")
+	assert.True(t, errors.Is(err, utils.ErrTenantInUse))
+
+	require.NoError(t, scheduler.CancelJob(ctx, "train"))
+	require.NoError(t, scheduler.DeleteTenant(ctx, "tenant-1"))
+	_, err = repo.GetTenant(ctx, "tenant-1")
+	assert.True(t, utils.IsNotFound(err))
+
+	assert.True(t, utils.IsNotFound(scheduler.DeleteTenant(ctx, "tenant-1")))
+}
+
+func TestDeleteTenantRefusedWhileCronJobsOrReservationsExist(t *testing.T) {
+	ctx := context.Background()
+	scheduler, _ := newTestScheduler(t, withNodes(2, "node-1"))
+
+	cronJob := createCronJob(t, scheduler, models.ConcurrencyAllow, models.MissedRunSkip)
+	reservation := reserve(t, scheduler, "tenant-1", 1, time.Now().Add(time.Hour), time.Hour)
+
+	err := scheduler.DeleteTenant(ctx, "tenant-1")
+	assert.True(t, errors.Is(err, utils.ErrTenantInUse))
+	assert.Contains(t, err.Error(), cronJob.ID)
+
+	require.NoError(t, scheduler.DeleteCronJob(ctx, cronJob.ID))
+	err = scheduler.DeleteTenant(ctx, "tenant-1")
+	assert.True(t, errors.Is(err, utils.ErrTenantInUse))
+	assert.Contains(t, err.Error(), reservation.ID)
+
+	require.NoError(t, scheduler.DeleteReservation(ctx, reservation.ID))
+	require.NoError(t, scheduler.DeleteTenant(ctx, "tenant-1"))
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// thermalAware reports whether thermal-aware placement is enabled
+func (a *Allocator) thermalAware() bool {
+	return a.config != nil && a.config.EnableThermalAware
+}
+
+// holdBack reports whether an idle GPU is cooling or has crossed the
+// thermal threshold and should start cooling
+func (a *Allocator) holdBack(gpu *models.GPU) bool {
+	if !a.thermalAware() || gpu.InUse() {
+		return false
+	}
+	return gpu.IsCooling() || gpu.NeedsCooling(a.config.ThermalThreshold)
+}
+
+// startCooling moves a GPU that crossed the thermal threshold into its
+// cooling period. GPUs already cooling are left alone.
+func (a *Allocator) startCooling(ctx context.Context, gpu *models.GPU) {
+	if gpu.IsCooling() {
+		return
+	}
+
+	period := time.Duration(a.config.ThermalCoolingPeriod) * time.Second
+	gpu.StartCooling(period)
+
+	if err := a.storage.UpdateGPU(ctx, gpu); err != nil {
+		utils.Error("Failed to start GPU cooling", zap.String("gpu_id", gpu.ID), zap.Error(err))
+	}
+
+	utils.Info("GPU entered cooling period",
+		zap.String("gpu_id", gpu.ID),
+		zap.Float64("temperature", gpu.Temperature),
+		zap.Duration("period", period))
+
+	recordEvent(ctx, a.storage, &models.Event{
+		Type:   models.EventThermalCooling,
+		Reason: "threshold_exceeded",
+		NodeID: gpu.NodeID,
+		GPUID:  gpu.ID,
+		Message: fmt.Sprintf("GPU at %.1fC crossed threshold %.1fC, cooling until %s",
+			gpu.Temperature, a.config.ThermalThreshold, gpu.CoolingPeriod.Format(time.RFC3339)),
+	})
+}
+
+// nearThreshold checks if a GPU is within the thermal margin of the threshold
+func (a *Allocator) nearThreshold(gpu *models.GPU) bool {
+	return a.thermalAware() && gpu.ThermalHeadroom(a.config.ThermalThreshold) <= a.config.ThermalMargin
+}
+
+// isHeavyJob checks if a request should be spread by thermal headroom
+func (a *Allocator) isHeavyJob(request *models.AllocationRequest) bool {
+	return a.thermalAware() && a.config.HeavyJobGPUs > 0 && request.GPUCount >= a.config.HeavyJobGPUs
+}
+
+// holdBackGPUs removes hot GPUs from a node's available GPUs and orders
+// the rest coolest first. Fractional requests keep packing partly used
+// GPUs first, with temperature only breaking ties. Idle GPUs held back
+// to cool, including those already cooling, are listed in Cooling.
+func (a *Allocator) holdBackGPUs(request *models.AllocationRequest, info *NodeInfo) {
+	held := make(map[string]bool)
+	var kept []*models.GPU
+	for _, gpu := range info.Available {
+		if a.holdBack(gpu) {
+			held[gpu.ID] = true
+			continue
+		}
+		kept = append(kept, gpu)
+	}
+
+	info.Cooling = nil
+	for _, gpu := range info.GPUs {
+		if held[gpu.ID] || (!gpu.InUse() && gpu.IsCooling()) {
+			info.Cooling = append(info.Cooling, gpu)
+		}
+	}
+
+	fractional := request.IsFractional()
+	sort.SliceStable(kept, func(i, j int) bool {
+		if fractional {
+			if fi, fj := kept[i].SharedFraction(), kept[j].SharedFraction(); fi != fj {
+				return fi > fj
+			}
+		}
+		return kept[i].Temperature < kept[j].Temperature
+	})
+	info.Available = kept
+}
+
+// countNearThreshold counts selected GPUs running close to the threshold
+func (a *Allocator) countNearThreshold(gpus []*models.GPU) int {
+	count := 0
+	for _, gpu := range gpus {
+		if a.nearThreshold(gpu) {
+			count++
+		}
+	}
+	return count
+}
+
+// nodeHeadroom averages thermal headroom over every GPU on a node,
+// including busy ones, since they heat the whole chassis
+func (a *Allocator) nodeHeadroom(gpus []*models.GPU) float64 {
+	if len(gpus) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, gpu := range gpus {
+		total += gpu.ThermalHeadroom(a.config.ThermalThreshold)
+	}
+	return total / float64(len(gpus))
+}
+
+// recordThermalSkips explains which GPUs on the chosen node were passed
+// over for thermal reasons
+func (a *Allocator) recordThermalSkips(ctx context.Context, request *models.AllocationRequest, node *models.Node, cooling, available, selected []*models.GPU) {
+	if !a.thermalAware() {
+		return
+	}
+
+	chosen := make(map[string]bool, len(selected))
+	for _, gpu := range selected {
+		chosen[gpu.ID] = true
+	}
+
+	for _, gpu := range cooling {
+		recordEvent(ctx, a.storage, &models.Event{
+			Type:     models.EventThermalSkip,
+			Reason:   "cooling",
+			JobID:    request.JobID,
+			TenantID: request.TenantID,
+			NodeID:   node.ID,
+			GPUID:    gpu.ID,
+			Message: fmt.Sprintf("GPU skipped at %.1fC, cooling until %s",
+				gpu.Temperature, gpu.CoolingPeriod.Format(time.RFC3339)),
+		})
+	}
+
+	for _, gpu := range available {
+		if chosen[gpu.ID] || !a.nearThreshold(gpu) {
+			continue
+		}
+		recordEvent(ctx, a.storage, &models.Event{
+			Type:     models.EventThermalSkip,
+			Reason:   "near_threshold",
+			JobID:    request.JobID,
+			TenantID: request.TenantID,
+			NodeID:   node.ID,
+			GPUID:    gpu.ID,
+			Message: fmt.Sprintf("GPU deprioritized at %.1fC, within %.1fC of threshold %.1fC",
+				gpu.Temperature, a.config.ThermalMargin, a.config.ThermalThreshold),
+		})
+	}
+}
+
+// thermalPlugin holds back hot GPUs, orders the rest coolest first,
+// spreads heavy jobs by thermal headroom and records skip events
+type thermalPlugin struct {
+	allocator *Allocator
+}
+
+func newThermalPlugin(a *Allocator) (Plugin, error) {
+	return &thermalPlugin{allocator: a}, nil
+}
+
+func (p *thermalPlugin) Name() string { return PluginThermal }
+
+func (p *thermalPlugin) Filter(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) *Status {
+	if p.allocator.thermalAware() {
+		p.allocator.holdBackGPUs(request, info)
+	}
+	return nil
+}
+
+// Score ranks heavy jobs by node headroom. Other jobs only lose a
+// fraction of a point per near-threshold GPU, so thermal state breaks
+// best-fit ties without overriding it.
+func (p *thermalPlugin) Score(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) float64 {
+	if !p.allocator.thermalAware() {
+		return 0
+	}
+	if p.allocator.isHeavyJob(request) {
+		return p.allocator.nodeHeadroom(info.GPUs)
+	}
+	if request.GangScheduling {
+		return 0
+	}
+	hot := p.allocator.countNearThreshold(info.Selection(request.GPUCount))
+	return -float64(hot) / float64(request.GPUCount+1)
+}
+
+// Reserve starts the cooling period of hot GPUs on the chosen node whose
+// readings did not start it already, and records why GPUs were passed
+// over
+func (p *thermalPlugin) Reserve(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) error {
+	if !p.allocator.thermalAware() {
+		return nil
+	}
+	for _, gpu := range info.Cooling {
+		p.allocator.startCooling(ctx, gpu)
+	}
+	p.allocator.recordThermalSkips(ctx, request, info.Node, info.Cooling, info.Available, info.Selection(request.GPUCount))
+	return nil
+}
+
+func (p *thermalPlugin) Unreserve(ctx context.Context, state *CycleState, request *models.AllocationRequest, info *NodeInfo) {
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func thermalConfig() *utils.SchedulerConfig {
+	return &utils.SchedulerConfig{
+		EnableThermalAware:   true,
+		ThermalThreshold:     75.0,
+		ThermalMargin:        5.0,
+		ThermalCoolingPeriod: 300,
+		HeavyJobGPUs:         4,
+	}
+}
+
+// setTemperatures assigns temperatures to a node's GPUs by index
+func setTemperatures(repo *fakeRepository, nodeID string, temps ...float64) {
+	gpus, _ := repo.ListGPUsByNode(context.Background(), nodeID)
+	for i, temp := range temps {
+		gpus[i].Temperature = temp
+	}
+}
+
+func TestHotGPUEntersCoolingAndIsSkipped(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+	setTemperatures(repo, "node-a", 82.0, 50.0)
+
+	allocator := NewAllocator(repo, thermalConfig())
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: 1,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node-a-gpu-1"}, result.GPUIDs)
+
+	hot, _ := repo.GetGPU(context.Background(), "node-a-gpu-0")
+	assert.True(t, hot.IsCooling())
+
+	cooling, _ := repo.ListEvents(context.Background(), models.EventFilter{Type: models.EventThermalCooling})
+	assert.Len(t, cooling, 1)
+
+	skips, _ := repo.ListEvents(context.Background(), models.EventFilter{Type: models.EventThermalSkip, JobID: "job-1"})
+	require.Len(t, skips, 1)
+	assert.Equal(t, "node-a-gpu-0", skips[0].GPUID)
+	assert.Equal(t, "cooling", skips[0].Reason)
+}
+
+func TestHotReadingStartsCooling(t *testing.T) {
+	ctx := context.Background()
+	scheduler, repo := newTestScheduler(t, withConfig(func(config *utils.SchedulerConfig) {
+		*config = *thermalConfig()
+		config.MaxQueueSize = 10
+	}))
+	_, err := scheduler.RegisterAgent(ctx, agentRegistration("node-1", 2))
+	require.NoError(t, err)
+
+	// The reading alone starts the cooling period, with nothing placed
+	_, err = scheduler.AgentHeartbeat(ctx, &models.NodeHeartbeat{
+		NodeID:     "node-1",
+		GPUMetrics: []models.GPUMetrics{{GPUID: "node-1-gpu-0", Temperature: 82}},
+	})
+	require.NoError(t, err)
+	hot, _ := repo.GetGPU(ctx, "node-1-gpu-0")
+	assert.True(t, hot.IsCooling())
+	cooling, _ := repo.ListEvents(ctx, models.EventFilter{Type: models.EventThermalCooling})
+	require.Len(t, cooling, 1)
+	assert.Equal(t, "node-1-gpu-0", cooling[0].GPUID)
+
+	// Dropping just below the threshold does not end the cooling period
+	_, err = scheduler.AgentHeartbeat(ctx, &models.NodeHeartbeat{
+		NodeID:     "node-1",
+		GPUMetrics: []models.GPUMetrics{{GPUID: "node-1-gpu-0", Temperature: 74}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, scheduler.SubmitJob(ctx, &models.Job{ID: "train", TenantID: "tenant-1", GPUCount: 1}))
+	require.NoError(t, scheduler.schedulingCycle(ctx))
+	assert.Equal(t, []string{"node-1-gpu-1"}, jobGPUs(scheduler, "train"))
+}
+
+func TestNearThresholdGPUDeprioritized(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+	setTemperatures(repo, "node-a", 72.0, 45.0)
+
+	allocator := NewAllocator(repo, thermalConfig())
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: 1,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node-a-gpu-1"}, result.GPUIDs)
+
+	skips, _ := repo.ListEvents(context.Background(), models.EventFilter{Type: models.EventThermalSkip})
+	require.Len(t, skips, 1)
+	assert.Equal(t, "near_threshold", skips[0].Reason)
+}
+
+func TestHeavyJobSpreadByThermalHeadroom(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 4)
+	repo.addNode("node-b", 8)
+	setTemperatures(repo, "node-a", 68.0, 68.0, 68.0, 68.0)
+	setTemperatures(repo, "node-b", 40.0, 40.0, 40.0, 40.0, 40.0, 40.0, 40.0, 40.0)
+
+	// Best-fit alone would pick node-a (zero waste)
+	allocator := NewAllocator(repo, thermalConfig())
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-heavy",
+		GPUCount: 4,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", result.NodeID)
+}
+
+func TestThermalAwareDisabled(t *testing.T) {
+	repo := newFakeRepository()
+	repo.addNode("node-a", 2)
+	setTemperatures(repo, "node-a", 82.0, 50.0)
+
+	allocator := NewAllocator(repo, &utils.SchedulerConfig{ThermalThreshold: 75.0})
+	result, err := allocator.Allocate(context.Background(), &models.AllocationRequest{
+		JobID:    "job-1",
+		GPUCount: 1,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node-a-gpu-0"}, result.GPUIDs)
+	assert.Empty(t, repo.events)
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/azizbahloul/gpu-scheduler/pkg/models"
 )
@@ -16,6 +17,7 @@ type Repository interface {
 	ListJobs(ctx context.Context, limit, offset int) ([]*models.Job, error)
 	ListJobsByTenant(ctx context.Context, tenantID string) ([]*models.Job, error)
 	ListJobsByState(ctx context.Context, state models.JobState) ([]*models.Job, error)
+	ListArrayJobs(ctx context.Context, parentID string) ([]*models.Job, error)
 
 	// Tenant operations
 	CreateTenant(ctx context.Context, tenant *models.Tenant) error
@@ -24,6 +26,13 @@ type Repository interface {
 	DeleteTenant(ctx context.Context, tenantID string) error
 	ListTenants(ctx context.Context) ([]*models.Tenant, error)
 
+	// Quota group operations
+	CreateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error
+	GetQuotaGroup(ctx context.Context, groupID string) (*models.QuotaGroup, error)
+	UpdateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error
+	DeleteQuotaGroup(ctx context.Context, groupID string) error
+	ListQuotaGroups(ctx context.Context) ([]*models.QuotaGroup, error)
+
 	// GPU operations
 	CreateGPU(ctx context.Context, gpu *models.GPU) error
 	GetGPU(ctx context.Context, gpuID string) (*models.GPU, error)
@@ -48,6 +57,29 @@ type Repository interface {
 	GetJobAllocations(ctx context.Context, jobID string) ([]*models.Allocation, error)
 	ListActiveAllocations(ctx context.Context) ([]*models.Allocation, error)
 
+	// Reservation operations
+	CreateReservation(ctx context.Context, reservation *models.Reservation) error
+	GetReservation(ctx context.Context, reservationID string) (*models.Reservation, error)
+	DeleteReservation(ctx context.Context, reservationID string) error
+	ListReservations(ctx context.Context) ([]*models.Reservation, error)
+
+	// Cron job operations
+	CreateCronJob(ctx context.Context, cronJob *models.CronJob) error
+	GetCronJob(ctx context.Context, cronJobID string) (*models.CronJob, error)
+	UpdateCronJob(ctx context.Context, cronJob *models.CronJob) error
+	DeleteCronJob(ctx context.Context, cronJobID string) error
+	ListCronJobs(ctx context.Context) ([]*models.CronJob, error)
+	CreateCronRun(ctx context.Context, run *models.CronRun) error
+	ListCronRuns(ctx context.Context, cronJobID string, limit int) ([]*models.CronRun, error)
+
+	// Event operations
+	CreateEvent(ctx context.Context, event *models.Event) error
+	ListEvents(ctx context.Context, filter models.EventFilter) ([]*models.Event, error)
+
+	// Ledger operations. Entries are append-only.
+	CreateLedgerEntry(ctx context.Context, entry *models.LedgerEntry) error
+	ListLedgerEntries(ctx context.Context, tenantID string, from, to time.Time) ([]*models.LedgerEntry, error)
+
 	// Health check
 	Ping(ctx context.Context) error
 	Close() error
@@ -48,9 +48,15 @@ func NewPostgresRepository(config *utils.DatabaseConfig) (storage.Repository, er
 	if err := db.AutoMigrate(
 		&models.Job{},
 		&models.Tenant{},
+		&models.QuotaGroup{},
 		&models.GPU{},
 		&models.Node{},
 		&models.Allocation{},
+		&models.Event{},
+		&models.Reservation{},
+		&models.CronJob{},
+		&models.CronRun{},
+		&models.LedgerEntry{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
@@ -100,6 +106,12 @@ func (r *PostgresRepository) ListJobsByState(ctx context.Context, state models.J
 	return jobs, err
 }
 
+func (r *PostgresRepository) ListArrayJobs(ctx context.Context, parentID string) ([]*models.Job, error) {
+	var jobs []*models.Job
+	err := r.db.WithContext(ctx).Where("array_parent_id = ?", parentID).Order("array_index ASC").Find(&jobs).Error
+	return jobs, err
+}
+
 // Tenant operations
 func (r *PostgresRepository) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
 	return r.db.WithContext(ctx).Create(tenant).Error
@@ -130,6 +142,36 @@ func (r *PostgresRepository) ListTenants(ctx context.Context) ([]*models.Tenant,
 	return tenants, err
 }
 
+// Quota group operations
+func (r *PostgresRepository) CreateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error {
+	return r.db.WithContext(ctx).Create(group).Error
+}
+
+func (r *PostgresRepository) GetQuotaGroup(ctx context.Context, groupID string) (*models.QuotaGroup, error) {
+	var group models.QuotaGroup
+	if err := r.db.WithContext(ctx).First(&group, "id = ?", groupID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.ErrQuotaGroupNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *PostgresRepository) UpdateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error {
+	return r.db.WithContext(ctx).Save(group).Error
+}
+
+func (r *PostgresRepository) DeleteQuotaGroup(ctx context.Context, groupID string) error {
+	return r.db.WithContext(ctx).Delete(&models.QuotaGroup{}, "id = ?", groupID).Error
+}
+
+func (r *PostgresRepository) ListQuotaGroups(ctx context.Context) ([]*models.QuotaGroup, error) {
+	var groups []*models.QuotaGroup
+	err := r.db.WithContext(ctx).Order("created_at ASC").Find(&groups).Error
+	return groups, err
+}
+
 // GPU operations
 func (r *PostgresRepository) CreateGPU(ctx context.Context, gpu *models.GPU) error {
 	return r.db.WithContext(ctx).Create(gpu).Error
@@ -238,6 +280,114 @@ func (r *PostgresRepository) ListActiveAllocations(ctx context.Context) ([]*mode
 	return allocations, err
 }
 
+// Reservation operations
+func (r *PostgresRepository) CreateReservation(ctx context.Context, reservation *models.Reservation) error {
+	return r.db.WithContext(ctx).Create(reservation).Error
+}
+
+func (r *PostgresRepository) GetReservation(ctx context.Context, reservationID string) (*models.Reservation, error) {
+	var reservation models.Reservation
+	if err := r.db.WithContext(ctx).First(&reservation, "id = ?", reservationID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.ErrReservationNotFound
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+func (r *PostgresRepository) DeleteReservation(ctx context.Context, reservationID string) error {
+	return r.db.WithContext(ctx).Delete(&models.Reservation{}, "id = ?", reservationID).Error
+}
+
+func (r *PostgresRepository) ListReservations(ctx context.Context) ([]*models.Reservation, error) {
+	var reservations []*models.Reservation
+	err := r.db.WithContext(ctx).Order("start_time ASC").Find(&reservations).Error
+	return reservations, err
+}
+
+// Cron job operations
+func (r *PostgresRepository) CreateCronJob(ctx context.Context, cronJob *models.CronJob) error {
+	return r.db.WithContext(ctx).Create(cronJob).Error
+}
+
+func (r *PostgresRepository) GetCronJob(ctx context.Context, cronJobID string) (*models.CronJob, error) {
+	var cronJob models.CronJob
+	if err := r.db.WithContext(ctx).First(&cronJob, "id = ?", cronJobID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.ErrCronJobNotFound
+		}
+		return nil, err
+	}
+	return &cronJob, nil
+}
+
+func (r *PostgresRepository) UpdateCronJob(ctx context.Context, cronJob *models.CronJob) error {
+	return r.db.WithContext(ctx).Save(cronJob).Error
+}
+
+func (r *PostgresRepository) DeleteCronJob(ctx context.Context, cronJobID string) error {
+	return r.db.WithContext(ctx).Delete(&models.CronJob{}, "id = ?", cronJobID).Error
+}
+
+func (r *PostgresRepository) ListCronJobs(ctx context.Context) ([]*models.CronJob, error) {
+	var cronJobs []*models.CronJob
+	err := r.db.WithContext(ctx).Order("created_at ASC").Find(&cronJobs).Error
+	return cronJobs, err
+}
+
+func (r *PostgresRepository) CreateCronRun(ctx context.Context, run *models.CronRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+func (r *PostgresRepository) ListCronRuns(ctx context.Context, cronJobID string, limit int) ([]*models.CronRun, error) {
+	var runs []*models.CronRun
+	query := r.db.WithContext(ctx).Where("cron_job_id = ?", cronJobID).Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&runs).Error
+	return runs, err
+}
+
+// Event operations
+func (r *PostgresRepository) CreateEvent(ctx context.Context, event *models.Event) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *PostgresRepository) ListEvents(ctx context.Context, filter models.EventFilter) ([]*models.Event, error) {
+	var events []*models.Event
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.JobID != "" {
+		query = query.Where("job_id = ?", filter.JobID)
+	}
+	if filter.NodeID != "" {
+		query = query.Where("node_id = ?", filter.NodeID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := query.Find(&events).Error
+	return events, err
+}
+
+// Ledger operations
+func (r *PostgresRepository) CreateLedgerEntry(ctx context.Context, entry *models.LedgerEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *PostgresRepository) ListLedgerEntries(ctx context.Context, tenantID string, from, to time.Time) ([]*models.LedgerEntry, error) {
+	var entries []*models.LedgerEntry
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND ended_at >= ? AND ended_at < ?", tenantID, from, to).
+		Order("ended_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
 // Health check
 func (r *PostgresRepository) Ping(ctx context.Context) error {
 	sqlDB, err := r.db.DB()
@@ -27,17 +27,170 @@ type SchedulerConfig struct {
 	EnableGangScheduling bool    `mapstructure:"enable_gang_scheduling"`
 	EnableThermalAware   bool    `mapstructure:"enable_thermal_aware"`
 	ThermalThreshold     float64 `mapstructure:"thermal_threshold"`
+	ThermalMargin        float64 `mapstructure:"thermal_margin"`
+	ThermalCoolingPeriod int     `mapstructure:"thermal_cooling_period_sec"`
+	HeavyJobGPUs         int     `mapstructure:"heavy_job_gpus"`
 	DefaultPriority      int     `mapstructure:"default_priority"`
+	PlacementStrategy    string  `mapstructure:"placement_strategy"`
+	Power                PowerConfig `mapstructure:"power"`
+	Runtime              RuntimeConfig `mapstructure:"runtime"`
+	Deadline             DeadlineConfig `mapstructure:"deadline"`
+	Cron                 CronConfig    `mapstructure:"cron"`
+	DefaultQueue         string        `mapstructure:"default_queue"`
+	Queues               []QueueConfig `mapstructure:"queues"`
+	Plugins              PluginsConfig `mapstructure:"plugins"`
+	Billing              BillingConfig `mapstructure:"billing"`
+	// NodeTimeoutSec is how long an agent may go without a heartbeat
+	// before its node is marked offline. Zero disables the check.
+	NodeTimeoutSec       int           `mapstructure:"node_timeout_sec"`
 }
 
+// BillingConfig prices the GPU-hours written to the usage ledger. A GPU
+// model's rate replaces DefaultGPUHourRate, and a tenant's own
+// cost_per_gpu_hour replaces both. The queue's multiplier then scales the
+// rate; queues without one are billed at the rate unchanged.
+type BillingConfig struct {
+	Currency           string             `mapstructure:"currency"`
+	DefaultGPUHourRate float64            `mapstructure:"default_gpu_hour_rate"`
+	GPUModelRates      map[string]float64 `mapstructure:"gpu_model_rates"`
+	QueueMultipliers   map[string]float64 `mapstructure:"queue_multipliers"`
+}
+
+// PluginsConfig enables and orders scheduling plugins per extension
+// point. An unset list falls back to the built-in defaults.
+type PluginsConfig struct {
+	Filter  []string            `mapstructure:"filter"`
+	Score   []ScorePluginConfig `mapstructure:"score"`
+	Reserve []string            `mapstructure:"reserve"`
+	Permit  []string            `mapstructure:"permit"`
+}
+
+// ScorePluginConfig enables a score plugin with a weight
+type ScorePluginConfig struct {
+	Name   string  `mapstructure:"name"`
+	Weight float64 `mapstructure:"weight"`
+}
+
+// RuntimeConfig controls how job MaxRuntime is enforced. Jobs are warned
+// WarningSec before their deadline, told to checkpoint and terminate at
+// the deadline, and failed once GracePeriodSec has passed.
+type RuntimeConfig struct {
+	WarningSec     int `mapstructure:"warning_sec"`
+	GracePeriodSec int `mapstructure:"grace_period_sec"`
+	MaxExtensions  int `mapstructure:"max_extensions"`
+}
+
+// DeadlineConfig controls deadline-aware queueing. Pending jobs whose
+// slack (time to deadline minus expected runtime) falls below
+// BoostWindowSec are boosted by up to MaxBoost priority, more as the slack
+// shrinks. Jobs with less than CriticalSlackSec of slack may preempt
+// lower-priority work when PreemptForCritical is set.
+type DeadlineConfig struct {
+	BoostWindowSec     int  `mapstructure:"boost_window_sec"`
+	MaxBoost           int  `mapstructure:"max_boost"`
+	CriticalSlackSec   int  `mapstructure:"critical_slack_sec"`
+	PreemptForCritical bool `mapstructure:"preempt_for_critical"`
+}
+
+// CronConfig controls cron job submissions. A run that comes due more
+// than StartingDeadlineSec late, e.g. while the scheduler was down, is
+// treated as missed and handled by the cron job's missed run policy.
+type CronConfig struct {
+	StartingDeadlineSec int `mapstructure:"starting_deadline_sec"`
+}
+
+// QueueConfig defines a named queue, or partition, whose jobs run on the
+// nodes matching NodeSelector. Zero limits mean unlimited and an empty
+// Tenants list admits every tenant.
+type QueueConfig struct {
+	Name              string            `mapstructure:"name"`
+	NodeSelector      map[string]string `mapstructure:"node_selector"`
+	MaxRuntimeSec     int               `mapstructure:"max_runtime_sec"`
+	MaxGPUsPerJob     int               `mapstructure:"max_gpus_per_job"`
+	Ordering          string            `mapstructure:"ordering"`
+	Preemption        string            `mapstructure:"preemption"`
+	PlacementStrategy string            `mapstructure:"placement_strategy"`
+	Tenants           []string          `mapstructure:"tenants"`
+}
+
+// PowerConfig defines power budgets enforced at placement time.
+// A zero budget means unlimited.
+type PowerConfig struct {
+	Enabled            bool               `mapstructure:"enabled"`
+	ClusterBudgetW     float64            `mapstructure:"cluster_budget_w"`
+	DefaultNodeBudgetW float64            `mapstructure:"default_node_budget_w"`
+	DefaultGPUDrawW    float64            `mapstructure:"default_gpu_draw_w"`
+	NodeBudgets        []NodePowerBudget  `mapstructure:"node_budgets"`
+	GroupBudgets       []GroupPowerBudget `mapstructure:"group_budgets"`
+}
+
+// NodePowerBudget overrides the default budget for a single node
+type NodePowerBudget struct {
+	NodeID  string  `mapstructure:"node_id"`
+	BudgetW float64 `mapstructure:"budget_w"`
+}
+
+// GroupPowerBudget caps every node carrying a label value, such as a rack
+type GroupPowerBudget struct {
+	Label   string  `mapstructure:"label"`
+	Value   string  `mapstructure:"value"`
+	BudgetW float64 `mapstructure:"budget_w"`
+}
+
+// AgentConfig configures the node agent. The node ID defaults to the
+// host name.
 type AgentConfig struct {
-	NodeID              string `mapstructure:"node_id"`
-	HeartbeatInterval   int    `mapstructure:"heartbeat_interval_ms"`
-	MetricsInterval     int    `mapstructure:"metrics_interval_ms"`
-	HealthCheckInterval int    `mapstructure:"health_check_interval_ms"`
-	DCGMEnabled         bool   `mapstructure:"dcgm_enabled"`
-	DCGMHostPort        string `mapstructure:"dcgm_host_port"`
-	ContainerRuntime    string `mapstructure:"container_runtime"`
+	NodeID              string            `mapstructure:"node_id"`
+	SchedulerURL        string            `mapstructure:"scheduler_url"`
+	HeartbeatInterval   int               `mapstructure:"heartbeat_interval_ms"`
+	MetricsInterval     int               `mapstructure:"metrics_interval_ms"`
+	HealthCheckInterval int               `mapstructure:"health_check_interval_ms"`
+	DCGMEnabled         bool              `mapstructure:"dcgm_enabled"`
+	DCGMHostPort        string            `mapstructure:"dcgm_host_port"`
+	ContainerRuntime    string            `mapstructure:"container_runtime"`
+	Labels              map[string]string `mapstructure:"labels"`
+	// GPUProvider selects how GPUs are found and sampled: nvidia-smi,
+	// dcgm to scrape the DCGM exporter at DCGMHostPort, or simulated to
+	// run without GPUs using the Simulation inventory. When unset, dcgm is
+	// used if DCGMEnabled is set and nvidia-smi otherwise.
+	GPUProvider string           `mapstructure:"gpu_provider"`
+	Simulation  SimulationConfig `mapstructure:"simulation"`
+	// Protocol selects how the agent talks to the scheduler: rest polls
+	// the REST API at SchedulerURL, grpc uses the AgentService at
+	// SchedulerGRPCAddress, which pushes job assignments. TLSCAPath turns
+	// on TLS for gRPC, trusting the given CA.
+	Protocol             string `mapstructure:"protocol"`
+	SchedulerGRPCAddress string `mapstructure:"scheduler_grpc_address"`
+	TLSCAPath            string `mapstructure:"tls_ca_path"`
+}
+
+// SimulationConfig describes the GPUs of a simulated node and the faults
+// to inject into them. Seed makes the synthetic metrics repeatable.
+type SimulationConfig struct {
+	Seed   int64                  `mapstructure:"seed"`
+	GPUs   []SimulatedGPUConfig   `mapstructure:"gpus"`
+	Faults []SimulatedFaultConfig `mapstructure:"faults"`
+}
+
+// SimulatedGPUConfig adds Count GPUs of a model. A zero MemoryMB uses the
+// model's usual memory. GPUs sharing an NVLinkDomain are linked by
+// NVLink; others talk over PCIe.
+type SimulatedGPUConfig struct {
+	Model        string `mapstructure:"model"`
+	Count        int    `mapstructure:"count"`
+	MemoryMB     int64  `mapstructure:"memory_mb"`
+	NUMANode     int    `mapstructure:"numa_node"`
+	NVLinkDomain string `mapstructure:"nvlink_domain"`
+}
+
+// SimulatedFaultConfig injects a fault into a simulated GPU AfterSec
+// seconds after the agent starts. Kind is ecc, thermal_throttle or
+// fall_off_bus; Count is the number of ECC errors.
+type SimulatedFaultConfig struct {
+	GPU      int    `mapstructure:"gpu"`
+	Kind     string `mapstructure:"kind"`
+	AfterSec int    `mapstructure:"after_sec"`
+	Count    int    `mapstructure:"count"`
 }
 
 type DatabaseConfig struct {
@@ -140,15 +293,36 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("scheduler.enable_preemption", true)
 	v.SetDefault("scheduler.enable_gang_scheduling", true)
 	v.SetDefault("scheduler.enable_thermal_aware", true)
+	v.SetDefault("scheduler.placement_strategy", "best-fit")
 	v.SetDefault("scheduler.thermal_threshold", 75.0)
+	v.SetDefault("scheduler.thermal_margin", 5.0)
+	v.SetDefault("scheduler.thermal_cooling_period_sec", 300)
+	v.SetDefault("scheduler.heavy_job_gpus", 4)
+	v.SetDefault("scheduler.power.enabled", false)
+	v.SetDefault("scheduler.power.default_gpu_draw_w", 300.0)
 	v.SetDefault("scheduler.default_priority", 100)
+	v.SetDefault("scheduler.runtime.warning_sec", 600)
+	v.SetDefault("scheduler.runtime.grace_period_sec", 300)
+	v.SetDefault("scheduler.runtime.max_extensions", 3)
+	v.SetDefault("scheduler.deadline.boost_window_sec", 3600)
+	v.SetDefault("scheduler.deadline.max_boost", 1000)
+	v.SetDefault("scheduler.deadline.critical_slack_sec", 600)
+	v.SetDefault("scheduler.deadline.preempt_for_critical", true)
+	v.SetDefault("scheduler.cron.starting_deadline_sec", 300)
+	v.SetDefault("scheduler.default_queue", "default")
+	v.SetDefault("scheduler.billing.currency", "USD")
+	v.SetDefault("scheduler.node_timeout_sec", 60)
 
 	// Agent
+	v.SetDefault("agent.scheduler_url", "http://localhost:8080")
+	v.SetDefault("agent.protocol", "rest")
+	v.SetDefault("agent.scheduler_grpc_address", "localhost:9090")
+	v.SetDefault("agent.tls_ca_path", "")
 	v.SetDefault("agent.heartbeat_interval_ms", 5000)
 	v.SetDefault("agent.metrics_interval_ms", 10000)
 	v.SetDefault("agent.health_check_interval_ms", 30000)
-	v.SetDefault("agent.dcgm_enabled", true)
-	v.SetDefault("agent.dcgm_host_port", "localhost:5555")
+	v.SetDefault("agent.dcgm_enabled", false)
+	v.SetDefault("agent.dcgm_host_port", "localhost:9400")
 	v.SetDefault("agent.container_runtime", "docker")
 
 	// Database
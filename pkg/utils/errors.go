@@ -12,6 +12,7 @@ var (
 	ErrGPUNotFound             = errors.New("GPU not found")
 	ErrNodeNotFound            = errors.New("node not found")
 	ErrNoAvailableNodes        = errors.New("no available nodes found")
+	ErrInvalidAgentRequest     = errors.New("invalid agent request")
 	
 	// Job errors
 	ErrJobNotFound             = errors.New("job not found")
@@ -19,16 +20,41 @@ var (
 	ErrJobAlreadyCompleted     = errors.New("job is already completed")
 	ErrInvalidJobState         = errors.New("invalid job state")
 	ErrJobCancelled            = errors.New("job was cancelled")
+	ErrInvalidDependency       = errors.New("invalid job dependency")
+	ErrExtensionDenied         = errors.New("runtime extension denied")
 	
 	// Tenant errors
 	ErrTenantNotFound          = errors.New("tenant not found")
 	ErrQuotaExceeded           = errors.New("tenant quota exceeded")
 	ErrUnauthorized            = errors.New("unauthorized access")
+	ErrInvalidBudget           = errors.New("invalid tenant budget")
+	ErrInvalidInvoicePeriod    = errors.New("invalid invoice period")
+	ErrInvalidTenant           = errors.New("invalid tenant")
+	ErrTenantInactive          = errors.New("tenant is inactive")
+	ErrTenantInUse             = errors.New("tenant is still in use")
+
+	// Quota group errors
+	ErrQuotaGroupNotFound      = errors.New("quota group not found")
+	ErrInvalidQuotaGroup       = errors.New("invalid quota group")
+	ErrQuotaGroupInUse         = errors.New("quota group still has members")
 	
 	// Allocation errors
 	ErrAllocationFailed        = errors.New("resource allocation failed")
 	ErrAllocationNotFound      = errors.New("allocation not found")
 	ErrGangSchedulingFailed    = errors.New("gang scheduling failed - partial allocation")
+
+	// Reservation errors
+	ErrReservationNotFound     = errors.New("reservation not found")
+	ErrReservationConflict     = errors.New("not enough GPUs free for the reservation window")
+	ErrReservationEnded        = errors.New("reservation window has closed")
+
+	// Queue errors
+	ErrQueueNotFound           = errors.New("queue not found")
+	ErrQueueAccessDenied       = errors.New("tenant may not submit to queue")
+	ErrQueueLimitExceeded      = errors.New("job exceeds queue limits")
+
+	// Cron job errors
+	ErrCronJobNotFound         = errors.New("cron job not found")
 	
 	// Configuration errors
 	ErrInvalidConfig           = errors.New("invalid configuration")
@@ -116,13 +142,29 @@ func (e *JobStateError) Error() string {
 		e.JobID, e.CurrentState, e.TargetState)
 }
 
+// UnschedulableError represents a job that cannot be placed for a reason
+// other than raw capacity, such as an unsatisfied placement constraint.
+// The job stays pending and the reason is surfaced in its status.
+type UnschedulableError struct {
+	JobID  string
+	Reason string
+}
+
+func (e *UnschedulableError) Error() string {
+	return fmt.Sprintf("job %s is unschedulable: %s", e.JobID, e.Reason)
+}
+
 // IsNotFound checks if error is a not-found error
 func IsNotFound(err error) bool {
 	return errors.Is(err, ErrJobNotFound) ||
 		errors.Is(err, ErrTenantNotFound) ||
 		errors.Is(err, ErrGPUNotFound) ||
 		errors.Is(err, ErrNodeNotFound) ||
-		errors.Is(err, ErrAllocationNotFound)
+		errors.Is(err, ErrAllocationNotFound) ||
+		errors.Is(err, ErrReservationNotFound) ||
+		errors.Is(err, ErrQueueNotFound) ||
+		errors.Is(err, ErrCronJobNotFound) ||
+		errors.Is(err, ErrQuotaGroupNotFound)
 }
 
 // IsQuotaExceeded checks if error is quota-related
@@ -137,6 +179,21 @@ func IsResourceError(err error) bool {
 	return errors.As(err, &rErr) || errors.Is(err, ErrInsufficientResources)
 }
 
+// IsUnschedulable checks if error is a placement constraint violation
+func IsUnschedulable(err error) bool {
+	var uErr *UnschedulableError
+	return errors.As(err, &uErr)
+}
+
+// UnschedulableReason returns the reason carried by an UnschedulableError
+func UnschedulableReason(err error) string {
+	var uErr *UnschedulableError
+	if errors.As(err, &uErr) {
+		return uErr.Reason
+	}
+	return ""
+}
+
 // WrapError wraps an error with operation context
 func WrapError(op string, err error, message string) error {
 	if err == nil {
@@ -5,15 +5,17 @@ set -e
 echo "Generating protobuf code..."
 
 # Create output directory
-mkdir -p pkg/api/grpc/generated
+mkdir -p pkg/api/grpc/generated/agent
 
 # Generate Go code from proto files
-protoc --go_out=. --go_opt=paths=source_relative \
-    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
-    pkg/api/grpc/scheduler.proto
+protoc -I pkg/api/grpc \
+    --go_out=pkg/api/grpc/generated --go_opt=paths=source_relative \
+    --go-grpc_out=pkg/api/grpc/generated --go-grpc_opt=paths=source_relative \
+    scheduler.proto
 
-protoc --go_out=. --go_opt=paths=source_relative \
-    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
-    pkg/api/grpc/agent.proto
+protoc -I pkg/api/grpc \
+    --go_out=pkg/api/grpc/generated/agent --go_opt=paths=source_relative \
+    --go-grpc_out=pkg/api/grpc/generated/agent --go-grpc_opt=paths=source_relative \
+    agent.proto
 
 echo "Protobuf code generation complete!"
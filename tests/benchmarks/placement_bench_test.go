@@ -0,0 +1,225 @@
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/azizbahloul/gpu-scheduler/pkg/models"
+	"github.com/azizbahloul/gpu-scheduler/pkg/scheduler/core"
+	"github.com/azizbahloul/gpu-scheduler/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// ClusterRepository keeps nodes, GPUs and allocations in memory so
+// placement decisions accumulate across a simulated job stream
+type ClusterRepository struct {
+	MockRepository
+	nodes       []*models.Node
+	gpus        map[string][]*models.GPU
+	gpuByID     map[string]*models.GPU
+	allocations map[string]*models.Allocation
+}
+
+// nodeShape describes one class of node in a simulated cluster
+type nodeShape struct {
+	count    int
+	gpus     int
+	cpus     int
+	memoryMB int64
+}
+
+func NewClusterRepository(shapes []nodeShape) *ClusterRepository {
+	r := &ClusterRepository{
+		gpus:        make(map[string][]*models.GPU),
+		gpuByID:     make(map[string]*models.GPU),
+		allocations: make(map[string]*models.Allocation),
+	}
+
+	for _, shape := range shapes {
+		for n := 0; n < shape.count; n++ {
+			node := &models.Node{
+				ID:                fmt.Sprintf("node-%02d", len(r.nodes)),
+				TotalGPUs:         shape.gpus,
+				AvailableGPUs:     shape.gpus,
+				TotalCPUCores:     shape.cpus,
+				AvailableCPUCores: shape.cpus,
+				TotalMemoryMB:     shape.memoryMB,
+				AvailableMemoryMB: shape.memoryMB,
+				Online:            true,
+				Schedulable:       true,
+			}
+			r.nodes = append(r.nodes, node)
+
+			for i := 0; i < shape.gpus; i++ {
+				gpu := &models.GPU{
+					ID:     fmt.Sprintf("%s-gpu-%d", node.ID, i),
+					NodeID: node.ID,
+					Index:  i,
+					Health: models.HealthHealthy,
+				}
+				r.gpus[node.ID] = append(r.gpus[node.ID], gpu)
+				r.gpuByID[gpu.ID] = gpu
+			}
+		}
+	}
+	return r
+}
+
+func (r *ClusterRepository) ListNodes(ctx context.Context) ([]*models.Node, error) {
+	return r.nodes, nil
+}
+func (r *ClusterRepository) GetNode(ctx context.Context, nodeID string) (*models.Node, error) {
+	for _, node := range r.nodes {
+		if node.ID == nodeID {
+			return node, nil
+		}
+	}
+	return nil, utils.ErrNodeNotFound
+}
+func (r *ClusterRepository) ListGPUsByNode(ctx context.Context, nodeID string) ([]*models.GPU, error) {
+	return r.gpus[nodeID], nil
+}
+func (r *ClusterRepository) GetGPU(ctx context.Context, gpuID string) (*models.GPU, error) {
+	return r.gpuByID[gpuID], nil
+}
+func (r *ClusterRepository) CreateAllocation(ctx context.Context, allocation *models.Allocation) error {
+	r.allocations[allocation.ID] = allocation
+	return nil
+}
+func (r *ClusterRepository) GetAllocation(ctx context.Context, allocationID string) (*models.Allocation, error) {
+	return r.allocations[allocationID], nil
+}
+
+// Fragmentation returns the share of idle GPUs stranded on partially
+// used nodes, where they cannot serve a full-node job
+func (r *ClusterRepository) Fragmentation() float64 {
+	idle, stranded := 0, 0
+	for _, node := range r.nodes {
+		idle += node.AvailableGPUs
+		if node.AvailableGPUs < node.TotalGPUs {
+			stranded += node.AvailableGPUs
+		}
+	}
+	if idle == 0 {
+		return 0
+	}
+	return float64(stranded) / float64(idle)
+}
+
+// placementCluster mixes full 8-GPU nodes with smaller nodes that carry
+// more CPU and memory per GPU
+var placementCluster = []nodeShape{
+	{count: 6, gpus: 8, cpus: 64, memoryMB: 512000},
+	{count: 6, gpus: 4, cpus: 96, memoryMB: 768000},
+	{count: 8, gpus: 2, cpus: 48, memoryMB: 384000},
+}
+
+// simulatePlacement replays a fixed stream of mixed-size arrivals and
+// departures and returns the mean fragmentation and the number of
+// full-node jobs that could not be placed
+func simulatePlacement(strategy models.PlacementStrategy, seed int64) (float64, int) {
+	ctx := context.Background()
+	repo := NewClusterRepository(placementCluster)
+	allocator := core.NewAllocator(repo, &utils.SchedulerConfig{PlacementStrategy: string(strategy)})
+
+	rng := rand.New(rand.NewSource(seed))
+	sizes := []int{1, 1, 1, 2, 2, 3, 4, 6, 8}
+	running := make(map[int][]string)
+
+	const steps = 600
+	totalFragmentation := 0.0
+	failedFullNode := 0
+
+	for step := 0; step < steps; step++ {
+		for _, allocationID := range running[step] {
+			allocator.Free(ctx, allocationID)
+		}
+		delete(running, step)
+
+		for arrivals := rng.Intn(3); arrivals > 0; arrivals-- {
+			size := sizes[rng.Intn(len(sizes))]
+			cpusPerGPU := 2 + rng.Intn(14)
+			duration := 5 + rng.Intn(20)
+			result, err := allocator.Allocate(ctx, &models.AllocationRequest{
+				JobID:    fmt.Sprintf("job-%d-%d", step, arrivals),
+				GPUCount: size,
+				CPUCores: cpusPerGPU * size,
+				MemoryMB: 8000 * int64(cpusPerGPU*size),
+			})
+			if err != nil {
+				if size == 8 {
+					failedFullNode++
+				}
+				continue
+			}
+			running[step+duration] = append(running[step+duration], result.AllocationID)
+		}
+
+		totalFragmentation += repo.Fragmentation()
+	}
+
+	return totalFragmentation / steps, failedFullNode
+}
+
+// meanFragmentation averages the strategy's fragmentation over several
+// job streams
+func meanFragmentation(strategy models.PlacementStrategy, streams int) float64 {
+	total := 0.0
+	for seed := int64(1); seed <= int64(streams); seed++ {
+		fragmentation, _ := simulatePlacement(strategy, seed)
+		total += fragmentation
+	}
+	return total / float64(streams)
+}
+
+func TestPlacementStrategiesFragmentation(t *testing.T) {
+	utils.Logger = zap.NewNop()
+
+	fragmentation := make(map[models.PlacementStrategy]float64)
+	for _, strategy := range []models.PlacementStrategy{
+		models.StrategyBestFit,
+		models.StrategySpread,
+		models.StrategyMostAllocated,
+		models.StrategyMinFragmentation,
+	} {
+		fragmentation[strategy] = meanFragmentation(strategy, 10)
+		t.Logf("%s: %.2f%% fragmentation", strategy, fragmentation[strategy]*100)
+	}
+
+	// Packing on idle GPUs strands far fewer of them than spreading
+	assert.Less(t, fragmentation[models.StrategyBestFit], fragmentation[models.StrategySpread]/2)
+	assert.Less(t, fragmentation[models.StrategyMinFragmentation], fragmentation[models.StrategySpread]/2)
+	assert.Less(t, fragmentation[models.StrategyMostAllocated], fragmentation[models.StrategySpread]/2)
+
+	// Most-allocated also packs by CPU and memory, which on nodes with
+	// different CPU and memory per GPU leaves more GPUs stranded
+	assert.Less(t, fragmentation[models.StrategyBestFit], fragmentation[models.StrategyMostAllocated])
+	assert.Less(t, fragmentation[models.StrategyMinFragmentation], fragmentation[models.StrategyMostAllocated])
+	assert.NotEqual(t, fragmentation[models.StrategyBestFit], fragmentation[models.StrategyMinFragmentation])
+}
+
+func BenchmarkPlacementStrategies(b *testing.B) {
+	utils.Logger = zap.NewNop()
+
+	strategies := []models.PlacementStrategy{
+		models.StrategyBestFit,
+		models.StrategySpread,
+		models.StrategyMostAllocated,
+		models.StrategyMinFragmentation,
+	}
+
+	for _, strategy := range strategies {
+		b.Run(string(strategy), func(b *testing.B) {
+			var fragmentation float64
+			var failed int
+			for i := 0; i < b.N; i++ {
+				fragmentation, failed = simulatePlacement(strategy, 42)
+			}
+			b.ReportMetric(fragmentation*100, "frag%")
+			b.ReportMetric(float64(failed), "failed-8gpu")
+		})
+	}
+}
@@ -27,15 +27,31 @@ func (m *MockRepository) ListJobsByTenant(ctx context.Context, tenantID string)
 func (m *MockRepository) ListJobsByState(ctx context.Context, state models.JobState) ([]*models.Job, error) {
 	return []*models.Job{}, nil
 }
+func (m *MockRepository) ListArrayJobs(ctx context.Context, parentID string) ([]*models.Job, error) {
+	return []*models.Job{}, nil
+}
 func (m *MockRepository) CreateTenant(ctx context.Context, tenant *models.Tenant) error { return nil }
 func (m *MockRepository) GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
-	return &models.Tenant{ID: tenantID, MaxGPUs: 10, MaxConcurrentJobs: 100}, nil
+	return &models.Tenant{ID: tenantID, MaxGPUs: 10, MaxConcurrentJobs: 100, Active: true}, nil
 }
 func (m *MockRepository) UpdateTenant(ctx context.Context, tenant *models.Tenant) error { return nil }
 func (m *MockRepository) DeleteTenant(ctx context.Context, tenantID string) error       { return nil }
 func (m *MockRepository) ListTenants(ctx context.Context) ([]*models.Tenant, error) {
 	return []*models.Tenant{}, nil
 }
+func (m *MockRepository) CreateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error {
+	return nil
+}
+func (m *MockRepository) GetQuotaGroup(ctx context.Context, groupID string) (*models.QuotaGroup, error) {
+	return nil, nil
+}
+func (m *MockRepository) UpdateQuotaGroup(ctx context.Context, group *models.QuotaGroup) error {
+	return nil
+}
+func (m *MockRepository) DeleteQuotaGroup(ctx context.Context, groupID string) error { return nil }
+func (m *MockRepository) ListQuotaGroups(ctx context.Context) ([]*models.QuotaGroup, error) {
+	return []*models.QuotaGroup{}, nil
+}
 func (m *MockRepository) CreateGPU(ctx context.Context, gpu *models.GPU) error                 { return nil }
 func (m *MockRepository) GetGPU(ctx context.Context, gpuID string) (*models.GPU, error)        { return nil, nil }
 func (m *MockRepository) UpdateGPU(ctx context.Context, gpu *models.GPU) error                 { return nil }
@@ -70,6 +86,49 @@ func (m *MockRepository) GetJobAllocations(ctx context.Context, jobID string) ([
 func (m *MockRepository) ListActiveAllocations(ctx context.Context) ([]*models.Allocation, error) {
 	return []*models.Allocation{}, nil
 }
+func (m *MockRepository) CreateReservation(ctx context.Context, reservation *models.Reservation) error {
+	return nil
+}
+func (m *MockRepository) GetReservation(ctx context.Context, reservationID string) (*models.Reservation, error) {
+	return nil, nil
+}
+func (m *MockRepository) DeleteReservation(ctx context.Context, reservationID string) error {
+	return nil
+}
+func (m *MockRepository) ListReservations(ctx context.Context) ([]*models.Reservation, error) {
+	return []*models.Reservation{}, nil
+}
+func (m *MockRepository) CreateCronJob(ctx context.Context, cronJob *models.CronJob) error {
+	return nil
+}
+func (m *MockRepository) GetCronJob(ctx context.Context, cronJobID string) (*models.CronJob, error) {
+	return nil, nil
+}
+func (m *MockRepository) UpdateCronJob(ctx context.Context, cronJob *models.CronJob) error {
+	return nil
+}
+func (m *MockRepository) DeleteCronJob(ctx context.Context, cronJobID string) error {
+	return nil
+}
+func (m *MockRepository) ListCronJobs(ctx context.Context) ([]*models.CronJob, error) {
+	return []*models.CronJob{}, nil
+}
+func (m *MockRepository) CreateCronRun(ctx context.Context, run *models.CronRun) error {
+	return nil
+}
+func (m *MockRepository) ListCronRuns(ctx context.Context, cronJobID string, limit int) ([]*models.CronRun, error) {
+	return []*models.CronRun{}, nil
+}
+func (m *MockRepository) CreateLedgerEntry(ctx context.Context, entry *models.LedgerEntry) error {
+	return nil
+}
+func (m *MockRepository) ListLedgerEntries(ctx context.Context, tenantID string, from, to time.Time) ([]*models.LedgerEntry, error) {
+	return []*models.LedgerEntry{}, nil
+}
+func (m *MockRepository) CreateEvent(ctx context.Context, event *models.Event) error { return nil }
+func (m *MockRepository) ListEvents(ctx context.Context, filter models.EventFilter) ([]*models.Event, error) {
+	return []*models.Event{}, nil
+}
 func (m *MockRepository) Ping(ctx context.Context) error { return nil }
 func (m *MockRepository) Close() error                   { return nil }
 